@@ -0,0 +1,116 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+
+	"product-test/internal/export"
+	"product-test/internal/models"
+	"product-test/internal/service"
+)
+
+// runExport implements the "export" subcommand:
+//
+//	go run ./cmd export -o products.xlsx
+//	go run ./cmd export -format ndjson -o - > products.ndjson
+func runExport(args []string) int {
+	fs := flag.NewFlagSet("export", flag.ContinueOnError)
+	output := fs.String("o", "", "output file, or - for stdout (required)")
+	formatFlag := fs.String("format", "", "csv, ndjson or xlsx (default: from the output file extension, else csv)")
+	limit := fs.Int("limit", 0, "max products to export, 0 for all")
+	offset := fs.Int("offset", 0, "number of products to skip")
+	fields := fs.String("fields", "", "comma-separated fields to export (default: all)")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if *output == "" {
+		fmt.Fprintln(os.Stderr, "export: -o is required")
+		fs.Usage()
+		return 2
+	}
+	name := *formatFlag
+	if name == "" && *output != "-" {
+		name = strings.TrimPrefix(filepath.Ext(*output), ".")
+	}
+	format, err := export.ParseFormat(name)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "export:", err)
+		return 2
+	}
+
+	db, cfg, ok := openDB("export")
+	if !ok {
+		return 1
+	}
+	defer db.Close()
+
+	var out io.WriteCloser = os.Stdout
+	var tmp *os.File
+	if *output != "-" {
+		// The export is written next to the output file and renamed once
+		// complete, so a failed one leaves no partial file behind.
+		tmp, err = os.CreateTemp(filepath.Dir(*output), "."+filepath.Base(*output)+".*")
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "export:", err)
+			return 1
+		}
+		out = tmp
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	filter := models.ProductFilter{Limit: *limit, Offset: *offset}
+	if *fields != "" {
+		filter.Fields = strings.Split(*fields, ",")
+	}
+	count, err := exportProducts(ctx, newServices(db, cfg).products, format, out, filter)
+	if cerr := out.Close(); err == nil {
+		err = cerr
+	}
+	if tmp != nil {
+		if err == nil {
+			err = os.Chmod(tmp.Name(), 0o644)
+		}
+		if err == nil {
+			err = os.Rename(tmp.Name(), *output)
+		}
+		if err != nil {
+			os.Remove(tmp.Name())
+		}
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "export:", err)
+		return 1
+	}
+	fmt.Fprintf(os.Stderr, "exported %d products\n", count)
+	return 0
+}
+
+func exportProducts(ctx context.Context, svc service.ProductService, format export.Format, out io.Writer, filter models.ProductFilter) (int, error) {
+	ew, err := export.NewWriter(format, out, filter.Fields)
+	if err != nil {
+		return 0, err
+	}
+	count := 0
+	err = svc.ExportProducts(ctx, filter, func(batch []models.Product) error {
+		for i := range batch {
+			if err := ew.Write(&batch[i]); err != nil {
+				return err
+			}
+		}
+		count += len(batch)
+		return ew.Flush()
+	})
+	if err != nil {
+		return count, err
+	}
+	return count, ew.Close()
+}
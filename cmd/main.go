@@ -19,7 +19,8 @@ import (
 	"product-test/internal/config"
 	"product-test/internal/database"
 	"product-test/internal/handlers"
-	"product-test/internal/repository"
+	"product-test/internal/jobs"
+	"product-test/internal/reqctx"
 	"product-test/internal/service"
 
 	_ "product-test/docs"
@@ -27,6 +28,15 @@ import (
 )
 
 func main() {
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "export":
+			os.Exit(runExport(os.Args[2:]))
+		case "import-rates":
+			os.Exit(runImportRates(os.Args[2:]))
+		}
+	}
+
 	config.LoadEnv()
 	cfg, err := config.New()
 	if err != nil {
@@ -41,18 +51,90 @@ func main() {
 	}
 	defer db.Close()
 
-	productRepo := repository.NewProductRepository(db)
-	productService := service.NewProductService(productRepo)
+	svc := newServices(db, cfg)
 	logger := slog.New(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelInfo}))
-	productHandler := handlers.NewProductHandler(productService, logger)
+	productHandler := handlers.NewProductHandler(svc.products, svc.exchange, logger)
+	auditHandler := handlers.NewAuditHandler(svc.audit, logger)
+	revisionHandler := handlers.NewRevisionHandler(svc.revisions, logger)
+	priceHandler := handlers.NewPriceHandler(svc.prices, logger)
+	scheduleHandler := handlers.NewPriceScheduleHandler(svc.schedules, logger)
+	exchangeHandler := handlers.NewExchangeHandler(svc.exchange, logger)
+	priceListHandler := handlers.NewPriceListHandler(svc.pricing, logger)
+	categoryHandler := handlers.NewCategoryHandler(svc.categories, logger)
+	tagHandler := handlers.NewTagHandler(svc.tags, logger)
+	variantHandler := handlers.NewVariantHandler(svc.variants, logger)
+	inventoryHandler := handlers.NewInventoryHandler(svc.inventory, logger)
+	warehouseHandler := handlers.NewWarehouseHandler(svc.warehouses, logger)
+	transferHandler := handlers.NewTransferHandler(svc.transfers, logger)
+	reservationHandler := handlers.NewReservationHandler(svc.reservations, logger)
+	alertHandler := handlers.NewAlertHandler(svc.alerts, logger)
+	supplierHandler := handlers.NewSupplierHandler(svc.suppliers, logger)
+	purchaseOrderHandler := handlers.NewPurchaseOrderHandler(svc.purchasing, logger)
+	orderHandler := handlers.NewOrderHandler(svc.orders, logger)
+
+	// Products created before slugs existed get one once; the next start
+	// picks up whatever this one could not finish.
+	if n, err := svc.products.BackfillSlugs(context.Background()); err != nil {
+		logger.Error("backfill slugs", "error", err)
+	} else if n > 0 {
+		logger.Info("backfilled slugs", "count", n)
+	}
+
+	jobsCtx, stopJobs := context.WithCancel(context.Background())
+	runner := jobs.NewRunner(logger)
+	runner.Every(jobsCtx, "purge-trash", cfg.PurgeInterval, func(ctx context.Context) error {
+		n, err := svc.products.PurgeDeletedProducts(ctx, cfg.TrashRetention)
+		if n > 0 {
+			logger.Info("purged trashed products", "count", n)
+		}
+		return err
+	})
+	runner.Every(jobsCtx, "price-schedules", cfg.ScheduleInterval, func(ctx context.Context) error {
+		run, err := svc.schedules.RunSchedules(ctx)
+		if run != (service.ScheduleRun{}) {
+			logger.Info("ran price schedules", "activated", run.Activated, "expired", run.Expired, "applied", run.Applied, "failed", run.Failed)
+		}
+		return err
+	})
+	runner.Every(jobsCtx, "expire-reservations", cfg.ReservationInterval, func(ctx context.Context) error {
+		n, err := svc.reservations.ExpireReservations(ctx)
+		if n > 0 {
+			logger.Info("expired reservations", "count", n)
+		}
+		return err
+	})
+	runner.Every(jobsCtx, "stock-alerts", cfg.AlertInterval, func(ctx context.Context) error {
+		run, err := svc.alerts.CheckStock(ctx)
+		if run != (service.AlertRun{}) {
+			logger.Info("checked stock alerts", "raised", run.Raised, "resolved", run.Resolved, "notified", run.Notified)
+		}
+		return err
+	})
 
 	mux := http.NewServeMux()
 	productHandler.RegisterRoutes(mux)
+	auditHandler.RegisterRoutes(mux)
+	revisionHandler.RegisterRoutes(mux)
+	priceHandler.RegisterRoutes(mux)
+	scheduleHandler.RegisterRoutes(mux)
+	exchangeHandler.RegisterRoutes(mux)
+	priceListHandler.RegisterRoutes(mux)
+	categoryHandler.RegisterRoutes(mux)
+	tagHandler.RegisterRoutes(mux)
+	variantHandler.RegisterRoutes(mux)
+	inventoryHandler.RegisterRoutes(mux)
+	warehouseHandler.RegisterRoutes(mux)
+	transferHandler.RegisterRoutes(mux)
+	reservationHandler.RegisterRoutes(mux)
+	alertHandler.RegisterRoutes(mux)
+	supplierHandler.RegisterRoutes(mux)
+	purchaseOrderHandler.RegisterRoutes(mux)
+	orderHandler.RegisterRoutes(mux)
 	mux.HandleFunc("GET /swagger/", httpSwagger.WrapHandler)
 
 	server := &http.Server{
 		Addr:    cfg.ServerPort,
-		Handler: mux,
+		Handler: reqctx.Middleware(productHandler.Lookups(mux)),
 	}
 
 	go func() {
@@ -72,5 +154,7 @@ func main() {
 	if err := server.Shutdown(ctx); err != nil {
 		logger.Error("server shutdown", "error", err)
 	}
+	stopJobs()
+	runner.Wait()
 	logger.Info("server stopped")
 }
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+
+	"product-test/internal/exchange"
+)
+
+// runImportRates implements the "import-rates" subcommand:
+//
+//	go run ./cmd import-rates -f eurofxref-daily.xml
+//	go run ./cmd import-rates -f rates.csv
+func runImportRates(args []string) int {
+	fs := flag.NewFlagSet("import-rates", flag.ContinueOnError)
+	file := fs.String("f", "", "rates file (required)")
+	formatFlag := fs.String("format", "", "csv or ecb (default: from the file extension)")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if *file == "" {
+		fmt.Fprintln(os.Stderr, "import-rates: -f is required")
+		fs.Usage()
+		return 2
+	}
+	name := *formatFlag
+	if name == "" {
+		name = strings.TrimPrefix(filepath.Ext(*file), ".")
+	}
+	format, err := exchange.ParseFormat(name)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "import-rates:", err)
+		return 2
+	}
+
+	f, err := os.Open(*file)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "import-rates:", err)
+		return 1
+	}
+	rates, err := exchange.Parse(format, f)
+	f.Close()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "import-rates:", err)
+		return 1
+	}
+
+	db, cfg, ok := openDB("import-rates")
+	if !ok {
+		return 1
+	}
+	defer db.Close()
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	result, err := newServices(db, cfg).exchange.ImportRates(ctx, rates)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "import-rates:", err)
+		return 1
+	}
+	fmt.Fprintf(os.Stderr, "imported %d rates\n", result.Imported)
+	if len(result.Skipped) > 0 {
+		fmt.Fprintf(os.Stderr, "skipped unsupported currencies: %s\n", strings.Join(result.Skipped, ", "))
+	}
+	return 0
+}
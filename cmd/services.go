@@ -0,0 +1,100 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+
+	"product-test/internal/config"
+	"product-test/internal/database"
+	"product-test/internal/repository"
+	"product-test/internal/service"
+	"product-test/internal/webhook"
+)
+
+// services holds the application services built on one database handle;
+// it is shared by the server and the CLI subcommands.
+type services struct {
+	products     service.ProductService
+	audit        service.AuditService
+	revisions    service.RevisionService
+	prices       service.PriceService
+	schedules    service.PriceScheduleService
+	exchange     service.ExchangeService
+	pricing      service.PriceListService
+	categories   service.CategoryService
+	tags         service.TagService
+	variants     service.VariantService
+	inventory    service.InventoryService
+	warehouses   service.WarehouseService
+	transfers    service.TransferService
+	reservations service.ReservationService
+	alerts       service.AlertService
+	suppliers    service.SupplierService
+	purchasing   service.PurchaseOrderService
+	orders       service.OrderService
+}
+
+func newServices(db *sql.DB, cfg *config.Config) *services {
+	txManager := repository.NewTxManager(db)
+	productRepo := repository.NewProductRepository(db)
+	auditRepo := repository.NewAuditRepository(db)
+	revisionRepo := repository.NewRevisionRepository(db)
+	priceRepo := repository.NewPriceRepository(db)
+	scheduleRepo := repository.NewPriceScheduleRepository(db)
+	categoryRepo := repository.NewCategoryRepository(db)
+	tagRepo := repository.NewTagRepository(db)
+	variantRepo := repository.NewVariantRepository(db)
+	warehouseRepo := repository.NewWarehouseRepository(db)
+	inventoryRepo := repository.NewInventoryRepository(db)
+	supplierRepo := repository.NewSupplierRepository(db)
+
+	products := service.NewProductService(productRepo, auditRepo, revisionRepo, priceRepo, scheduleRepo, categoryRepo, tagRepo, variantRepo, inventoryRepo, txManager)
+	inventory := service.NewInventoryService(inventoryRepo, warehouseRepo, productRepo, variantRepo, txManager)
+	return &services{
+		products:     products,
+		audit:        service.NewAuditService(auditRepo),
+		revisions:    service.NewRevisionService(revisionRepo, products),
+		prices:       service.NewPriceService(priceRepo, scheduleRepo),
+		schedules:    service.NewPriceScheduleService(scheduleRepo, products, txManager),
+		exchange:     service.NewExchangeService(repository.NewExchangeRepository(db), txManager),
+		pricing:      service.NewPriceListService(repository.NewPriceListRepository(db), products, txManager),
+		categories:   service.NewCategoryService(categoryRepo, products, txManager),
+		tags:         service.NewTagService(tagRepo, products, txManager),
+		variants:     service.NewVariantService(variantRepo, categoryRepo, products, txManager),
+		inventory:    inventory,
+		warehouses:   service.NewWarehouseService(warehouseRepo, txManager),
+		transfers:    service.NewTransferService(repository.NewTransferRepository(db), warehouseRepo, productRepo, variantRepo, inventory, txManager),
+		reservations: service.NewReservationService(repository.NewReservationRepository(db), warehouseRepo, productRepo, variantRepo, inventory, txManager, cfg.ReservationTTL),
+		alerts:       service.NewAlertService(repository.NewAlertRepository(db), alertNotifier(cfg)),
+		suppliers:    service.NewSupplierService(supplierRepo, productRepo, txManager),
+		purchasing:   service.NewPurchaseOrderService(repository.NewPurchaseOrderRepository(db), supplierRepo, warehouseRepo, productRepo, variantRepo, inventory, txManager, service.CostMethod(cfg.CostMethod)),
+		orders:       service.NewOrderService(repository.NewOrderRepository(db), warehouseRepo, products, inventory, txManager),
+	}
+}
+
+// alertNotifier returns the webhook alerts are posted to, or nil when none
+// is configured.
+func alertNotifier(cfg *config.Config) service.AlertNotifier {
+	if cfg.AlertWebhookURL == "" {
+		return nil
+	}
+	return webhook.New(cfg.AlertWebhookURL)
+}
+
+// openDB connects a CLI subcommand to the configured database, reporting
+// failures on stderr under the subcommand's name.
+func openDB(cmd string) (*sql.DB, *config.Config, bool) {
+	config.LoadEnv()
+	cfg, err := config.New()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s: invalid config: %v\n", cmd, err)
+		return nil, nil, false
+	}
+	db, err := database.InitDB(cfg.DBUser, cfg.DBPassword, cfg.DBName, cfg.DBHost, cfg.DBPort)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s: database init: %v\n", cmd, err)
+		return nil, nil, false
+	}
+	return db, cfg, true
+}
@@ -1,4 +1,5 @@
-// Package docs Product API.
+// Package docs Product API. The spec below is kept by hand and is the only
+// copy; it is served at /swagger/.
 package docs
 
 import "github.com/swaggo/swag"
@@ -17,22 +18,43 @@ const docTemplate = `{
         "/products": {
             "get": {
                 "description": "Returns a list of products with optional pagination",
-                "produces": ["application/json"],
+                "produces": ["application/json", "application/xml", "application/msgpack", "text/csv"],
                 "summary": "List products",
                 "operationId": "getAll",
                 "parameters": [
                     {"type": "integer", "default": 100, "description": "Max items to return", "name": "limit", "in": "query"},
-                    {"type": "integer", "default": 0, "description": "Offset", "name": "offset", "in": "query"}
+                    {"type": "integer", "default": 0, "description": "Offset", "name": "offset", "in": "query"},
+                    {"type": "string", "description": "Comma-separated fields to return, e.g. id,name,price", "name": "fields", "in": "query"},
+                    {"type": "string", "description": "Comma-separated relations to embed", "name": "include", "in": "query"},
+                    {"type": "boolean", "default": false, "description": "Wrap the result in a pagination envelope", "name": "envelope", "in": "query"},
+                    {"type": "string", "enum": ["exact", "estimated"], "default": "exact", "description": "How to compute the total: exact count or table statistics", "name": "count", "in": "query"},
+                    {"type": "string", "description": "ISO 4217 code to convert prices to; adds converted_price with the rate used", "name": "currency", "in": "query"},
+                    {"type": "integer", "description": "Only products assigned to this category", "name": "category", "in": "query"},
+                    {"type": "boolean", "default": false, "description": "With category, also products of its subcategories", "name": "include_descendants", "in": "query"},
+                    {"type": "string", "description": "Comma-separated tags, e.g. eco,new-arrival", "name": "tags", "in": "query"},
+                    {"type": "string", "enum": ["all", "any"], "default": "all", "description": "Whether products must carry all or any of the tags", "name": "tag_mode", "in": "query"},
+                    {"type": "string", "description": "Filter on an attribute: attr.<name>=v1,v2 matches any of the values; attr.<name>_gt, _gte, _lt and _lte compare with a number. Any number of attr.* parameters may be combined", "name": "attr.{name}", "in": "query"}
                 ],
                 "responses": {
-                    "200": {"description": "OK", "schema": {"type": "array", "items": {"$ref": "#/definitions/Product"}}},
+                    "200": {
+                        "description": "OK (a ProductPage when envelope=true)",
+                        "schema": {"type": "array", "items": {"$ref": "#/definitions/Product"}},
+                        "headers": {
+                            "X-Total-Count": {"type": "integer", "description": "Total number of matching products"},
+                            "X-Total-Count-Estimated": {"type": "boolean", "description": "Set when the total comes from table statistics"},
+                            "Link": {"type": "string", "description": "RFC 8288 links with first, prev, next and last relations"}
+                        }
+                    },
+                    "400": {"description": "Unknown field or include", "schema": {"$ref": "#/definitions/APIError"}},
+                    "404": {"description": "Category not found", "schema": {"$ref": "#/definitions/APIError"}},
+                    "406": {"description": "Not acceptable", "schema": {"$ref": "#/definitions/APIError"}},
                     "500": {"description": "Internal error", "schema": {"$ref": "#/definitions/APIError"}}
                 }
             },
             "post": {
-                "description": "Create a new product",
-                "consumes": ["application/json"],
-                "produces": ["application/json"],
+                "description": "Create a new product. The body must have a Content-Type header and is decoded strictly: unknown fields and trailing data are rejected.",
+                "consumes": ["application/json", "application/xml", "application/msgpack"],
+                "produces": ["application/json", "application/xml", "application/msgpack", "text/csv"],
                 "summary": "Create product",
                 "operationId": "create",
                 "parameters": [
@@ -40,84 +62,2614 @@ const docTemplate = `{
                 ],
                 "responses": {
                     "201": {"description": "Created", "schema": {"$ref": "#/definitions/Product"}},
-                    "400": {"description": "Validation error", "schema": {"$ref": "#/definitions/APIError"}},
+                    "400": {"description": "Validation error; invalid attributes are listed in fields", "schema": {"$ref": "#/definitions/APIError"}},
+                    "409": {"description": "SKU, barcode or slug already used by another product", "schema": {"$ref": "#/definitions/APIError"}},
+                    "406": {"description": "Not acceptable", "schema": {"$ref": "#/definitions/APIError"}},
+                    "413": {"description": "Body larger than 64 KiB", "schema": {"$ref": "#/definitions/APIError"}},
+                    "415": {"description": "Missing or unsupported Content-Type", "schema": {"$ref": "#/definitions/APIError"}},
                     "500": {"description": "Internal error", "schema": {"$ref": "#/definitions/APIError"}}
                 }
             }
         },
-        "/products/{id}": {
+        "/products/export": {
             "get": {
-                "description": "Returns a product by ID",
-                "produces": ["application/json"],
-                "summary": "Get product by ID",
-                "operationId": "getByID",
+                "description": "Streams the whole catalog (or the requested range) as a file",
+                "produces": ["text/csv", "application/x-ndjson", "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"],
+                "summary": "Export products",
+                "operationId": "export",
+                "parameters": [
+                    {"type": "string", "enum": ["csv", "ndjson", "xlsx"], "default": "csv", "description": "File format", "name": "format", "in": "query"},
+                    {"type": "integer", "description": "Max items to export (all by default)", "name": "limit", "in": "query"},
+                    {"type": "integer", "default": 0, "description": "Offset", "name": "offset", "in": "query"},
+                    {"type": "string", "description": "Comma-separated fields (columns) to export", "name": "fields", "in": "query"},
+                    {"type": "integer", "description": "Only products assigned to this category", "name": "category", "in": "query"},
+                    {"type": "boolean", "default": false, "description": "With category, also products of its subcategories", "name": "include_descendants", "in": "query"},
+                    {"type": "string", "description": "Comma-separated tags, e.g. eco,new-arrival", "name": "tags", "in": "query"},
+                    {"type": "string", "enum": ["all", "any"], "default": "all", "description": "Whether products must carry all or any of the tags", "name": "tag_mode", "in": "query"},
+                    {"type": "string", "description": "Filter on an attribute: attr.<name>=v1,v2 matches any of the values; attr.<name>_gt, _gte, _lt and _lte compare with a number. Any number of attr.* parameters may be combined", "name": "attr.{name}", "in": "query"}
+                ],
+                "responses": {
+                    "200": {"description": "OK", "schema": {"type": "file"}},
+                    "400": {"description": "Unsupported format or unknown field", "schema": {"$ref": "#/definitions/APIError"}},
+                    "500": {"description": "Internal error", "schema": {"$ref": "#/definitions/APIError"}}
+                }
+            }
+        },
+        "/products/trash": {
+            "get": {
+                "description": "Lists soft-deleted products, most recently deleted first. They are purged after the configured retention period.",
+                "produces": ["application/json", "application/xml", "application/msgpack", "text/csv"],
+                "summary": "List trashed products",
+                "operationId": "trash",
+                "parameters": [
+                    {"type": "integer", "default": 100, "description": "Max items to return", "name": "limit", "in": "query"},
+                    {"type": "integer", "default": 0, "description": "Offset", "name": "offset", "in": "query"},
+                    {"type": "string", "description": "Comma-separated fields to return", "name": "fields", "in": "query"},
+                    {"type": "boolean", "default": false, "description": "Wrap the result in a pagination envelope", "name": "envelope", "in": "query"}
+                ],
+                "responses": {
+                    "200": {"description": "OK", "schema": {"type": "array", "items": {"$ref": "#/definitions/Product"}}},
+                    "400": {"description": "Unknown field", "schema": {"$ref": "#/definitions/APIError"}},
+                    "406": {"description": "Not acceptable", "schema": {"$ref": "#/definitions/APIError"}},
+                    "500": {"description": "Internal error", "schema": {"$ref": "#/definitions/APIError"}}
+                }
+            }
+        },
+        "/products/{id}/restore": {
+            "post": {
+                "description": "Moves a product out of the trash",
+                "produces": ["application/json", "application/xml", "application/msgpack", "text/csv"],
+                "summary": "Restore product",
+                "operationId": "restore",
                 "parameters": [
                     {"type": "integer", "description": "Product ID", "name": "id", "in": "path", "required": true}
                 ],
                 "responses": {
                     "200": {"description": "OK", "schema": {"$ref": "#/definitions/Product"}},
                     "400": {"description": "Invalid ID", "schema": {"$ref": "#/definitions/APIError"}},
-                    "404": {"description": "Not found", "schema": {"$ref": "#/definitions/APIError"}},
+                    "404": {"description": "Not in trash", "schema": {"$ref": "#/definitions/APIError"}},
+                    "500": {"description": "Internal error", "schema": {"$ref": "#/definitions/APIError"}}
+                }
+            }
+        },
+        "/products/{id}/history": {
+            "get": {
+                "description": "Returns the audit trail of a product, newest first",
+                "produces": ["application/json", "application/xml", "application/msgpack", "text/csv"],
+                "summary": "Product change history",
+                "operationId": "history",
+                "parameters": [
+                    {"type": "integer", "description": "Product ID", "name": "id", "in": "path", "required": true},
+                    {"type": "string", "description": "Only changes by this actor", "name": "actor", "in": "query"},
+                    {"type": "string", "enum": ["create", "update", "delete", "restore", "purge"], "description": "Only this kind of change", "name": "action", "in": "query"},
+                    {"type": "string", "format": "date-time", "description": "Changes at or after this time", "name": "from", "in": "query"},
+                    {"type": "string", "format": "date-time", "description": "Changes before this time", "name": "to", "in": "query"},
+                    {"type": "integer", "default": 100, "description": "Max items to return", "name": "limit", "in": "query"},
+                    {"type": "integer", "default": 0, "description": "Offset", "name": "offset", "in": "query"},
+                    {"type": "boolean", "default": false, "description": "Wrap the result in a pagination envelope", "name": "envelope", "in": "query"}
+                ],
+                "responses": {
+                    "200": {"description": "OK", "schema": {"type": "array", "items": {"$ref": "#/definitions/AuditEntry"}}},
+                    "400": {"description": "Invalid filter", "schema": {"$ref": "#/definitions/APIError"}},
+                    "500": {"description": "Internal error", "schema": {"$ref": "#/definitions/APIError"}}
+                }
+            }
+        },
+        "/products/{id}/revisions": {
+            "get": {
+                "description": "Lists the snapshots taken after every change of a product, newest first",
+                "produces": ["application/json", "application/xml", "application/msgpack"],
+                "summary": "List product revisions",
+                "operationId": "listRevisions",
+                "parameters": [
+                    {"type": "integer", "description": "Product ID", "name": "id", "in": "path", "required": true},
+                    {"type": "integer", "default": 100, "description": "Max items to return", "name": "limit", "in": "query"},
+                    {"type": "integer", "default": 0, "description": "Offset", "name": "offset", "in": "query"},
+                    {"type": "boolean", "default": false, "description": "Wrap the result in a pagination envelope", "name": "envelope", "in": "query"}
+                ],
+                "responses": {
+                    "200": {"description": "OK", "schema": {"type": "array", "items": {"$ref": "#/definitions/ProductRevision"}}},
+                    "404": {"description": "Product has no revisions", "schema": {"$ref": "#/definitions/APIError"}},
+                    "500": {"description": "Internal error", "schema": {"$ref": "#/definitions/APIError"}}
+                }
+            }
+        },
+        "/products/{id}/revisions/{a}/diff/{b}": {
+            "get": {
+                "description": "Lists the fields that differ between two revisions",
+                "produces": ["application/json", "application/xml", "application/msgpack"],
+                "summary": "Diff two revisions",
+                "operationId": "diffRevisions",
+                "parameters": [
+                    {"type": "integer", "description": "Product ID", "name": "id", "in": "path", "required": true},
+                    {"type": "integer", "description": "Revision to diff from", "name": "a", "in": "path", "required": true},
+                    {"type": "integer", "description": "Revision to diff to", "name": "b", "in": "path", "required": true}
+                ],
+                "responses": {
+                    "200": {"description": "OK", "schema": {"$ref": "#/definitions/RevisionDiff"}},
+                    "404": {"description": "Revision not found", "schema": {"$ref": "#/definitions/APIError"}},
+                    "500": {"description": "Internal error", "schema": {"$ref": "#/definitions/APIError"}}
+                }
+            }
+        },
+        "/products/{id}/revisions/{n}/revert": {
+            "post": {
+                "description": "Applies an old snapshot as a new update; the revert becomes a revision of its own",
+                "produces": ["application/json", "application/xml", "application/msgpack", "text/csv"],
+                "summary": "Revert to a revision",
+                "operationId": "revertRevision",
+                "parameters": [
+                    {"type": "integer", "description": "Product ID", "name": "id", "in": "path", "required": true},
+                    {"type": "integer", "description": "Revision to restore", "name": "n", "in": "path", "required": true}
+                ],
+                "responses": {
+                    "200": {"description": "OK", "schema": {"$ref": "#/definitions/Product"}},
+                    "400": {"description": "Snapshot fails current validation", "schema": {"$ref": "#/definitions/APIError"}},
+                    "404": {"description": "Product or revision not found", "schema": {"$ref": "#/definitions/APIError"}},
+                    "500": {"description": "Internal error", "schema": {"$ref": "#/definitions/APIError"}}
+                }
+            }
+        },
+        "/products/{id}/prices": {
+            "get": {
+                "description": "Lists the price periods of a product that overlap the given range, oldest first. Sales appear as periods of their own, with schedule_id set, next to the product's price",
+                "produces": ["application/json", "application/xml", "application/msgpack"],
+                "summary": "Price history",
+                "operationId": "priceHistory",
+                "parameters": [
+                    {"type": "integer", "description": "Product ID", "name": "id", "in": "path", "required": true},
+                    {"type": "string", "format": "date-time", "description": "Start of the range", "name": "from", "in": "query"},
+                    {"type": "string", "format": "date-time", "description": "End of the range (exclusive)", "name": "to", "in": "query"},
+                    {"type": "integer", "default": 100, "description": "Max items to return", "name": "limit", "in": "query"},
+                    {"type": "integer", "default": 0, "description": "Offset", "name": "offset", "in": "query"},
+                    {"type": "boolean", "default": false, "description": "Wrap the result in a pagination envelope", "name": "envelope", "in": "query"}
+                ],
+                "responses": {
+                    "200": {"description": "OK", "schema": {"type": "array", "items": {"$ref": "#/definitions/PricePeriod"}}},
+                    "400": {"description": "Invalid range", "schema": {"$ref": "#/definitions/APIError"}},
+                    "500": {"description": "Internal error", "schema": {"$ref": "#/definitions/APIError"}}
+                }
+            }
+        },
+        "/products/{id}/price": {
+            "get": {
+                "description": "Returns the price period in effect at the given instant, taking price schedules into account",
+                "produces": ["application/json", "application/xml", "application/msgpack"],
+                "summary": "Price at an instant",
+                "operationId": "priceAt",
+                "parameters": [
+                    {"type": "integer", "description": "Product ID", "name": "id", "in": "path", "required": true},
+                    {"type": "string", "format": "date-time", "description": "Instant to look up (now by default)", "name": "at", "in": "query"}
+                ],
+                "responses": {
+                    "200": {"description": "OK", "schema": {"$ref": "#/definitions/PricePeriod"}},
+                    "404": {"description": "No price in effect", "schema": {"$ref": "#/definitions/APIError"}},
+                    "500": {"description": "Internal error", "schema": {"$ref": "#/definitions/APIError"}}
+                }
+            }
+        },
+        "/products/{id}/price-schedules": {
+            "get": {
+                "description": "Lists the price schedules of a product, ordered by start",
+                "produces": ["application/json", "application/xml", "application/msgpack"],
+                "summary": "List price schedules",
+                "operationId": "listPriceSchedules",
+                "parameters": [
+                    {"type": "integer", "description": "Product ID", "name": "id", "in": "path", "required": true},
+                    {"type": "string", "enum": ["scheduled", "active", "expired", "applied", "cancelled", "failed"], "description": "Only schedules with this status", "name": "status", "in": "query"}
+                ],
+                "responses": {
+                    "200": {"description": "OK", "schema": {"type": "array", "items": {"$ref": "#/definitions/PriceSchedule"}}},
+                    "400": {"description": "Invalid status", "schema": {"$ref": "#/definitions/APIError"}},
+                    "404": {"description": "Product not found", "schema": {"$ref": "#/definitions/APIError"}},
                     "500": {"description": "Internal error", "schema": {"$ref": "#/definitions/APIError"}}
                 }
             },
+            "post": {
+                "description": "Schedules a price for a product. With ends_at the price is a sale that applies until then; without it the price replaces the product's price once starts_at is reached",
+                "consumes": ["application/json", "application/xml", "application/msgpack"],
+                "produces": ["application/json", "application/xml", "application/msgpack"],
+                "summary": "Create price schedule",
+                "operationId": "createPriceSchedule",
+                "parameters": [
+                    {"type": "integer", "description": "Product ID", "name": "id", "in": "path", "required": true},
+                    {"description": "Schedule", "name": "schedule", "in": "body", "required": true, "schema": {"$ref": "#/definitions/PriceScheduleInput"}}
+                ],
+                "responses": {
+                    "201": {"description": "Created", "schema": {"$ref": "#/definitions/PriceSchedule"}},
+                    "400": {"description": "Invalid input", "schema": {"$ref": "#/definitions/APIError"}},
+                    "404": {"description": "Product not found", "schema": {"$ref": "#/definitions/APIError"}},
+                    "500": {"description": "Internal error", "schema": {"$ref": "#/definitions/APIError"}}
+                }
+            }
+        },
+        "/products/{id}/price-schedules/{sid}": {
             "put": {
-                "description": "Update a product",
-                "consumes": ["application/json"],
-                "produces": ["application/json"],
-                "summary": "Update product",
-                "operationId": "update",
+                "description": "Changes a schedule that has not started yet",
+                "consumes": ["application/json", "application/xml", "application/msgpack"],
+                "produces": ["application/json", "application/xml", "application/msgpack"],
+                "summary": "Update price schedule",
+                "operationId": "updatePriceSchedule",
                 "parameters": [
                     {"type": "integer", "description": "Product ID", "name": "id", "in": "path", "required": true},
-                    {"description": "Product body", "name": "body", "in": "body", "required": true, "schema": {"$ref": "#/definitions/ProductInput"}}
+                    {"type": "integer", "description": "Schedule ID", "name": "sid", "in": "path", "required": true},
+                    {"description": "Schedule", "name": "schedule", "in": "body", "required": true, "schema": {"$ref": "#/definitions/PriceScheduleInput"}}
                 ],
                 "responses": {
-                    "200": {"description": "OK", "schema": {"$ref": "#/definitions/Product"}},
-                    "400": {"description": "Validation error", "schema": {"$ref": "#/definitions/APIError"}},
-                    "404": {"description": "Not found", "schema": {"$ref": "#/definitions/APIError"}},
+                    "200": {"description": "OK", "schema": {"$ref": "#/definitions/PriceSchedule"}},
+                    "400": {"description": "Invalid input or schedule already started", "schema": {"$ref": "#/definitions/APIError"}},
+                    "404": {"description": "Schedule not found", "schema": {"$ref": "#/definitions/APIError"}},
                     "500": {"description": "Internal error", "schema": {"$ref": "#/definitions/APIError"}}
                 }
             },
             "delete": {
-                "description": "Delete a product",
-                "summary": "Delete product",
-                "operationId": "delete",
+                "description": "Cancels a pending schedule or ends a running sale; a sale that has started expires now and stays in the price history",
+                "summary": "Cancel price schedule",
+                "operationId": "cancelPriceSchedule",
                 "parameters": [
-                    {"type": "integer", "description": "Product ID", "name": "id", "in": "path", "required": true}
+                    {"type": "integer", "description": "Product ID", "name": "id", "in": "path", "required": true},
+                    {"type": "integer", "description": "Schedule ID", "name": "sid", "in": "path", "required": true}
                 ],
                 "responses": {
-                    "204": {"description": "No Content"},
-                    "400": {"description": "Invalid ID", "schema": {"$ref": "#/definitions/APIError"}},
-                    "404": {"description": "Not found", "schema": {"$ref": "#/definitions/APIError"}},
+                    "204": {"description": "Cancelled"},
+                    "400": {"description": "Schedule already finished", "schema": {"$ref": "#/definitions/APIError"}},
+                    "404": {"description": "Schedule not found", "schema": {"$ref": "#/definitions/APIError"}},
                     "500": {"description": "Internal error", "schema": {"$ref": "#/definitions/APIError"}}
                 }
             }
-        }
-    },
-    "definitions": {
-        "Product": {
-            "type": "object",
-            "properties": {
-                "id": {"type": "integer"},
-                "name": {"type": "string"},
-                "description": {"type": "string"},
-                "price": {"type": "integer", "description": "Price in minor units (e.g. cents)"}
+        },
+        "/admin/exchange-rates": {
+            "get": {
+                "description": "Lists the stored exchange rates",
+                "produces": ["application/json", "application/xml", "application/msgpack"],
+                "summary": "List exchange rates",
+                "operationId": "listExchangeRates",
+                "responses": {
+                    "200": {"description": "OK", "schema": {"type": "array", "items": {"$ref": "#/definitions/ExchangeRate"}}},
+                    "500": {"description": "Internal error", "schema": {"$ref": "#/definitions/APIError"}}
+                }
             }
         },
-        "ProductInput": {
-            "type": "object",
-            "required": ["name"],
-            "properties": {
-                "name": {"type": "string", "maxLength": 500},
-                "description": {"type": "string", "maxLength": 2000},
-                "price": {"type": "integer", "minimum": 0}
+        "/admin/exchange-rates/import": {
+            "post": {
+                "description": "Loads rates from a CSV file (header base,quote,rate[,updated_at]) or an ECB reference rates XML file. Rates for unsupported currencies are skipped",
+                "consumes": ["text/csv", "application/xml"],
+                "produces": ["application/json", "application/xml", "application/msgpack"],
+                "summary": "Import exchange rates",
+                "operationId": "importExchangeRates",
+                "parameters": [
+                    {"type": "string", "enum": ["csv", "ecb"], "description": "File format; defaults to the Content-Type", "name": "format", "in": "query"},
+                    {"description": "Rates file", "name": "file", "in": "body", "required": true, "schema": {"type": "string"}}
+                ],
+                "responses": {
+                    "200": {"description": "OK", "schema": {"$ref": "#/definitions/RateImport"}},
+                    "400": {"description": "Invalid file", "schema": {"$ref": "#/definitions/APIError"}},
+                    "413": {"description": "File too large", "schema": {"$ref": "#/definitions/APIError"}},
+                    "415": {"description": "Unsupported format", "schema": {"$ref": "#/definitions/APIError"}},
+                    "500": {"description": "Internal error", "schema": {"$ref": "#/definitions/APIError"}}
+                }
             }
         },
-        "APIError": {
-            "type": "object",
-            "properties": {
-                "code": {"type": "string"},
-                "message": {"type": "string"}
+        "/admin/exchange-rates/{base}/{quote}": {
+            "put": {
+                "description": "Sets the rate of a currency pair: one unit of base buys rate units of quote",
+                "consumes": ["application/json", "application/xml", "application/msgpack"],
+                "produces": ["application/json", "application/xml", "application/msgpack"],
+                "summary": "Set exchange rate",
+                "operationId": "setExchangeRate",
+                "parameters": [
+                    {"type": "string", "description": "Base currency", "name": "base", "in": "path", "required": true},
+                    {"type": "string", "description": "Quote currency", "name": "quote", "in": "path", "required": true},
+                    {"description": "Rate", "name": "rate", "in": "body", "required": true, "schema": {"$ref": "#/definitions/ExchangeRateInput"}}
+                ],
+                "responses": {
+                    "200": {"description": "OK", "schema": {"$ref": "#/definitions/ExchangeRate"}},
+                    "400": {"description": "Invalid input", "schema": {"$ref": "#/definitions/APIError"}},
+                    "500": {"description": "Internal error", "schema": {"$ref": "#/definitions/APIError"}}
+                }
+            },
+            "delete": {
+                "description": "Removes the rate of a currency pair",
+                "summary": "Delete exchange rate",
+                "operationId": "deleteExchangeRate",
+                "parameters": [
+                    {"type": "string", "description": "Base currency", "name": "base", "in": "path", "required": true},
+                    {"type": "string", "description": "Quote currency", "name": "quote", "in": "path", "required": true}
+                ],
+                "responses": {
+                    "204": {"description": "Deleted"},
+                    "404": {"description": "Rate not found", "schema": {"$ref": "#/definitions/APIError"}},
+                    "500": {"description": "Internal error", "schema": {"$ref": "#/definitions/APIError"}}
+                }
+            }
+        },
+        "/admin/currency-rounding": {
+            "get": {
+                "description": "Lists the rounding rules for converted prices",
+                "produces": ["application/json", "application/xml", "application/msgpack"],
+                "summary": "List rounding rules",
+                "operationId": "listRoundingRules",
+                "responses": {
+                    "200": {"description": "OK", "schema": {"type": "array", "items": {"$ref": "#/definitions/RoundingRule"}}},
+                    "500": {"description": "Internal error", "schema": {"$ref": "#/definitions/APIError"}}
+                }
+            }
+        },
+        "/admin/currency-rounding/{currency}": {
+            "put": {
+                "description": "Sets how converted prices in a currency are rounded. Currencies without a rule round half up to the minor unit",
+                "consumes": ["application/json", "application/xml", "application/msgpack"],
+                "produces": ["application/json", "application/xml", "application/msgpack"],
+                "summary": "Set rounding rule",
+                "operationId": "setRoundingRule",
+                "parameters": [
+                    {"type": "string", "description": "Currency", "name": "currency", "in": "path", "required": true},
+                    {"description": "Rule", "name": "rule", "in": "body", "required": true, "schema": {"$ref": "#/definitions/RoundingRuleInput"}}
+                ],
+                "responses": {
+                    "200": {"description": "OK", "schema": {"$ref": "#/definitions/RoundingRule"}},
+                    "400": {"description": "Invalid input", "schema": {"$ref": "#/definitions/APIError"}},
+                    "500": {"description": "Internal error", "schema": {"$ref": "#/definitions/APIError"}}
+                }
+            },
+            "delete": {
+                "description": "Removes the rounding rule of a currency",
+                "summary": "Delete rounding rule",
+                "operationId": "deleteRoundingRule",
+                "parameters": [
+                    {"type": "string", "description": "Currency", "name": "currency", "in": "path", "required": true}
+                ],
+                "responses": {
+                    "204": {"description": "Deleted"},
+                    "404": {"description": "Rule not found", "schema": {"$ref": "#/definitions/APIError"}},
+                    "500": {"description": "Internal error", "schema": {"$ref": "#/definitions/APIError"}}
+                }
+            }
+        },
+        "/price-lists": {
+            "get": {
+                "description": "Lists the price lists",
+                "produces": ["application/json", "application/xml", "application/msgpack"],
+                "summary": "List price lists",
+                "operationId": "listPriceLists",
+                "responses": {
+                    "200": {"description": "OK", "schema": {"type": "array", "items": {"$ref": "#/definitions/PriceList"}}},
+                    "500": {"description": "Internal error", "schema": {"$ref": "#/definitions/APIError"}}
+                }
+            },
+            "post": {
+                "description": "Creates a price list in one currency",
+                "consumes": ["application/json", "application/xml", "application/msgpack"],
+                "produces": ["application/json", "application/xml", "application/msgpack"],
+                "summary": "Create price list",
+                "operationId": "createPriceList",
+                "parameters": [
+                    {"description": "Price list", "name": "list", "in": "body", "required": true, "schema": {"$ref": "#/definitions/PriceListInput"}}
+                ],
+                "responses": {
+                    "201": {"description": "Created", "schema": {"$ref": "#/definitions/PriceList"}},
+                    "400": {"description": "Invalid input or code in use", "schema": {"$ref": "#/definitions/APIError"}},
+                    "500": {"description": "Internal error", "schema": {"$ref": "#/definitions/APIError"}}
+                }
+            }
+        },
+        "/price-lists/{id}": {
+            "put": {
+                "description": "Renames a price list; its currency cannot change",
+                "consumes": ["application/json", "application/xml", "application/msgpack"],
+                "produces": ["application/json", "application/xml", "application/msgpack"],
+                "summary": "Update price list",
+                "operationId": "updatePriceList",
+                "parameters": [
+                    {"type": "integer", "description": "Price list ID", "name": "id", "in": "path", "required": true},
+                    {"description": "Price list", "name": "list", "in": "body", "required": true, "schema": {"$ref": "#/definitions/PriceListInput"}}
+                ],
+                "responses": {
+                    "200": {"description": "OK", "schema": {"$ref": "#/definitions/PriceList"}},
+                    "400": {"description": "Invalid input or code in use", "schema": {"$ref": "#/definitions/APIError"}},
+                    "404": {"description": "Price list not found", "schema": {"$ref": "#/definitions/APIError"}},
+                    "500": {"description": "Internal error", "schema": {"$ref": "#/definitions/APIError"}}
+                }
+            },
+            "delete": {
+                "description": "Deletes a price list with its tiers; customer groups using it fall back to public prices",
+                "summary": "Delete price list",
+                "operationId": "deletePriceList",
+                "parameters": [
+                    {"type": "integer", "description": "Price list ID", "name": "id", "in": "path", "required": true}
+                ],
+                "responses": {
+                    "204": {"description": "Deleted"},
+                    "404": {"description": "Price list not found", "schema": {"$ref": "#/definitions/APIError"}},
+                    "500": {"description": "Internal error", "schema": {"$ref": "#/definitions/APIError"}}
+                }
+            }
+        },
+        "/price-lists/{id}/products/{product}/tiers": {
+            "get": {
+                "description": "Returns the quantity tiers of a product in a price list",
+                "produces": ["application/json", "application/xml", "application/msgpack"],
+                "summary": "Get price tiers",
+                "operationId": "getPriceTiers",
+                "parameters": [
+                    {"type": "integer", "description": "Price list ID", "name": "id", "in": "path", "required": true},
+                    {"type": "integer", "description": "Product ID", "name": "product", "in": "path", "required": true}
+                ],
+                "responses": {
+                    "200": {"description": "OK", "schema": {"$ref": "#/definitions/PriceTiers"}},
+                    "404": {"description": "Price list or product not found", "schema": {"$ref": "#/definitions/APIError"}},
+                    "500": {"description": "Internal error", "schema": {"$ref": "#/definitions/APIError"}}
+                }
+            },
+            "put": {
+                "description": "Replaces the quantity tiers of a product in a price list; an empty list removes the product from it",
+                "consumes": ["application/json", "application/xml", "application/msgpack"],
+                "produces": ["application/json", "application/xml", "application/msgpack"],
+                "summary": "Set price tiers",
+                "operationId": "setPriceTiers",
+                "parameters": [
+                    {"type": "integer", "description": "Price list ID", "name": "id", "in": "path", "required": true},
+                    {"type": "integer", "description": "Product ID", "name": "product", "in": "path", "required": true},
+                    {"description": "Tiers", "name": "tiers", "in": "body", "required": true, "schema": {"$ref": "#/definitions/PriceTiers"}}
+                ],
+                "responses": {
+                    "200": {"description": "OK", "schema": {"$ref": "#/definitions/PriceTiers"}},
+                    "400": {"description": "Invalid tiers", "schema": {"$ref": "#/definitions/APIError"}},
+                    "404": {"description": "Price list or product not found", "schema": {"$ref": "#/definitions/APIError"}},
+                    "500": {"description": "Internal error", "schema": {"$ref": "#/definitions/APIError"}}
+                }
+            }
+        },
+        "/customer-groups": {
+            "get": {
+                "description": "Lists the customer groups",
+                "produces": ["application/json", "application/xml", "application/msgpack"],
+                "summary": "List customer groups",
+                "operationId": "listCustomerGroups",
+                "responses": {
+                    "200": {"description": "OK", "schema": {"type": "array", "items": {"$ref": "#/definitions/CustomerGroup"}}},
+                    "500": {"description": "Internal error", "schema": {"$ref": "#/definitions/APIError"}}
+                }
+            },
+            "post": {
+                "description": "Creates a customer group, optionally with a price list",
+                "consumes": ["application/json", "application/xml", "application/msgpack"],
+                "produces": ["application/json", "application/xml", "application/msgpack"],
+                "summary": "Create customer group",
+                "operationId": "createCustomerGroup",
+                "parameters": [
+                    {"description": "Customer group", "name": "group", "in": "body", "required": true, "schema": {"$ref": "#/definitions/CustomerGroupInput"}}
+                ],
+                "responses": {
+                    "201": {"description": "Created", "schema": {"$ref": "#/definitions/CustomerGroup"}},
+                    "400": {"description": "Invalid input or code in use", "schema": {"$ref": "#/definitions/APIError"}},
+                    "500": {"description": "Internal error", "schema": {"$ref": "#/definitions/APIError"}}
+                }
+            }
+        },
+        "/customer-groups/{id}": {
+            "put": {
+                "description": "Changes a customer group",
+                "consumes": ["application/json", "application/xml", "application/msgpack"],
+                "produces": ["application/json", "application/xml", "application/msgpack"],
+                "summary": "Update customer group",
+                "operationId": "updateCustomerGroup",
+                "parameters": [
+                    {"type": "integer", "description": "Customer group ID", "name": "id", "in": "path", "required": true},
+                    {"description": "Customer group", "name": "group", "in": "body", "required": true, "schema": {"$ref": "#/definitions/CustomerGroupInput"}}
+                ],
+                "responses": {
+                    "200": {"description": "OK", "schema": {"$ref": "#/definitions/CustomerGroup"}},
+                    "400": {"description": "Invalid input or code in use", "schema": {"$ref": "#/definitions/APIError"}},
+                    "404": {"description": "Customer group not found", "schema": {"$ref": "#/definitions/APIError"}},
+                    "500": {"description": "Internal error", "schema": {"$ref": "#/definitions/APIError"}}
+                }
+            },
+            "delete": {
+                "description": "Deletes a customer group",
+                "summary": "Delete customer group",
+                "operationId": "deleteCustomerGroup",
+                "parameters": [
+                    {"type": "integer", "description": "Customer group ID", "name": "id", "in": "path", "required": true}
+                ],
+                "responses": {
+                    "204": {"description": "Deleted"},
+                    "404": {"description": "Customer group not found", "schema": {"$ref": "#/definitions/APIError"}},
+                    "500": {"description": "Internal error", "schema": {"$ref": "#/definitions/APIError"}}
+                }
+            }
+        },
+        "/products/{id}/quote": {
+            "get": {
+                "description": "Prices a quantity of a product. The effective price applies unless the price list has a tier for the quantity that is cheaper or in another currency",
+                "produces": ["application/json", "application/xml", "application/msgpack"],
+                "summary": "Quote product",
+                "operationId": "quoteProduct",
+                "parameters": [
+                    {"type": "integer", "description": "Product ID", "name": "id", "in": "path", "required": true},
+                    {"type": "integer", "default": 1, "description": "Quantity", "name": "qty", "in": "query"},
+                    {"type": "string", "description": "Price list code", "name": "price_list", "in": "query"},
+                    {"type": "string", "description": "Customer group code, whose price list applies", "name": "customer_group", "in": "query"}
+                ],
+                "responses": {
+                    "200": {"description": "OK", "schema": {"$ref": "#/definitions/Quote"}},
+                    "400": {"description": "Invalid quantity", "schema": {"$ref": "#/definitions/APIError"}},
+                    "404": {"description": "Product, price list or customer group not found", "schema": {"$ref": "#/definitions/APIError"}},
+                    "500": {"description": "Internal error", "schema": {"$ref": "#/definitions/APIError"}}
+                }
+            }
+        },
+        "/categories": {
+            "get": {
+                "description": "Returns all categories ordered by depth and name",
+                "produces": ["application/json", "application/xml", "application/msgpack"],
+                "summary": "List categories",
+                "operationId": "listCategories",
+                "responses": {
+                    "200": {"description": "OK", "schema": {"type": "array", "items": {"$ref": "#/definitions/Category"}}},
+                    "500": {"description": "Internal error", "schema": {"$ref": "#/definitions/APIError"}}
+                }
+            },
+            "post": {
+                "description": "Creates a category below parent_id, or at the root",
+                "consumes": ["application/json", "application/xml", "application/msgpack"],
+                "produces": ["application/json", "application/xml", "application/msgpack"],
+                "summary": "Create category",
+                "operationId": "createCategory",
+                "parameters": [
+                    {"description": "Category", "name": "body", "in": "body", "required": true, "schema": {"$ref": "#/definitions/CategoryInput"}}
+                ],
+                "responses": {
+                    "201": {"description": "Created", "schema": {"$ref": "#/definitions/Category"}},
+                    "400": {"description": "Invalid category, unknown parent or name taken among siblings", "schema": {"$ref": "#/definitions/APIError"}},
+                    "500": {"description": "Internal error", "schema": {"$ref": "#/definitions/APIError"}}
+                }
+            }
+        },
+        "/categories/{id}": {
+            "get": {
+                "description": "Returns a category with its path from the root",
+                "produces": ["application/json", "application/xml", "application/msgpack"],
+                "summary": "Get category",
+                "operationId": "getCategory",
+                "parameters": [
+                    {"type": "integer", "description": "Category ID", "name": "id", "in": "path", "required": true}
+                ],
+                "responses": {
+                    "200": {"description": "OK", "schema": {"$ref": "#/definitions/Category"}},
+                    "404": {"description": "Category not found", "schema": {"$ref": "#/definitions/APIError"}},
+                    "500": {"description": "Internal error", "schema": {"$ref": "#/definitions/APIError"}}
+                }
+            },
+            "put": {
+                "description": "Renames a category and moves it with its subtree below parent_id. Moving a category below itself or one of its subcategories is rejected",
+                "consumes": ["application/json", "application/xml", "application/msgpack"],
+                "produces": ["application/json", "application/xml", "application/msgpack"],
+                "summary": "Update category",
+                "operationId": "updateCategory",
+                "parameters": [
+                    {"type": "integer", "description": "Category ID", "name": "id", "in": "path", "required": true},
+                    {"description": "Category", "name": "body", "in": "body", "required": true, "schema": {"$ref": "#/definitions/CategoryInput"}}
+                ],
+                "responses": {
+                    "200": {"description": "OK", "schema": {"$ref": "#/definitions/Category"}},
+                    "400": {"description": "Invalid category, unknown parent, name taken or move would create a cycle", "schema": {"$ref": "#/definitions/APIError"}},
+                    "404": {"description": "Category not found", "schema": {"$ref": "#/definitions/APIError"}},
+                    "500": {"description": "Internal error", "schema": {"$ref": "#/definitions/APIError"}}
+                }
+            },
+            "delete": {
+                "description": "Deletes a category without subcategories. Products that had it as primary category fall back to another of their categories",
+                "summary": "Delete category",
+                "operationId": "deleteCategory",
+                "parameters": [
+                    {"type": "integer", "description": "Category ID", "name": "id", "in": "path", "required": true}
+                ],
+                "responses": {
+                    "204": {"description": "Deleted"},
+                    "400": {"description": "Category has subcategories", "schema": {"$ref": "#/definitions/APIError"}},
+                    "404": {"description": "Category not found", "schema": {"$ref": "#/definitions/APIError"}},
+                    "500": {"description": "Internal error", "schema": {"$ref": "#/definitions/APIError"}}
+                }
+            }
+        },
+        "/categories/{id}/products": {
+            "get": {
+                "description": "Lists the products of a category; same parameters and response as GET /products",
+                "produces": ["application/json", "application/xml", "application/msgpack"],
+                "summary": "List category products",
+                "operationId": "listCategoryProducts",
+                "parameters": [
+                    {"type": "integer", "description": "Category ID", "name": "id", "in": "path", "required": true},
+                    {"type": "boolean", "default": false, "description": "Also products of subcategories", "name": "include_descendants", "in": "query"},
+                    {"type": "integer", "default": 100, "description": "Max items to return", "name": "limit", "in": "query"},
+                    {"type": "integer", "default": 0, "description": "Offset", "name": "offset", "in": "query"},
+                    {"type": "string", "description": "Comma-separated fields to return, e.g. id,name,price", "name": "fields", "in": "query"},
+                    {"type": "boolean", "default": false, "description": "Wrap the result in a pagination envelope", "name": "envelope", "in": "query"}
+                ],
+                "responses": {
+                    "200": {"description": "OK (a ProductPage when envelope=true)", "schema": {"type": "array", "items": {"$ref": "#/definitions/Product"}}},
+                    "400": {"description": "Invalid parameters", "schema": {"$ref": "#/definitions/APIError"}},
+                    "404": {"description": "Category not found", "schema": {"$ref": "#/definitions/APIError"}},
+                    "500": {"description": "Internal error", "schema": {"$ref": "#/definitions/APIError"}}
+                }
+            }
+        },
+        "/products/{id}/categories": {
+            "get": {
+                "description": "Returns the categories of a product with their paths, primary first",
+                "produces": ["application/json", "application/xml", "application/msgpack"],
+                "summary": "Get product categories",
+                "operationId": "getProductCategories",
+                "parameters": [
+                    {"type": "integer", "description": "Product ID", "name": "id", "in": "path", "required": true}
+                ],
+                "responses": {
+                    "200": {"description": "OK", "schema": {"$ref": "#/definitions/ProductCategories"}},
+                    "404": {"description": "Product not found", "schema": {"$ref": "#/definitions/APIError"}},
+                    "500": {"description": "Internal error", "schema": {"$ref": "#/definitions/APIError"}}
+                }
+            },
+            "put": {
+                "description": "Replaces the categories of a product. At most one may be primary; without one, the first becomes primary",
+                "consumes": ["application/json", "application/xml", "application/msgpack"],
+                "produces": ["application/json", "application/xml", "application/msgpack"],
+                "summary": "Set product categories",
+                "operationId": "setProductCategories",
+                "parameters": [
+                    {"type": "integer", "description": "Product ID", "name": "id", "in": "path", "required": true},
+                    {"description": "Categories", "name": "body", "in": "body", "required": true, "schema": {"$ref": "#/definitions/ProductCategories"}}
+                ],
+                "responses": {
+                    "200": {"description": "OK", "schema": {"$ref": "#/definitions/ProductCategories"}},
+                    "400": {"description": "Unknown or duplicate category, or several primary", "schema": {"$ref": "#/definitions/APIError"}},
+                    "404": {"description": "Product not found", "schema": {"$ref": "#/definitions/APIError"}},
+                    "500": {"description": "Internal error", "schema": {"$ref": "#/definitions/APIError"}}
+                }
+            }
+        },
+        "/tags": {
+            "get": {
+                "description": "Returns the tags carried by live products with their usage counts, most used first",
+                "produces": ["application/json", "application/xml", "application/msgpack"],
+                "summary": "List tags",
+                "operationId": "listTags",
+                "parameters": [
+                    {"type": "integer", "default": 100, "description": "Max tags to return (at most 1000)", "name": "limit", "in": "query"}
+                ],
+                "responses": {
+                    "200": {"description": "OK", "schema": {"type": "array", "items": {"$ref": "#/definitions/Tag"}}},
+                    "400": {"description": "Invalid limit", "schema": {"$ref": "#/definitions/APIError"}},
+                    "500": {"description": "Internal error", "schema": {"$ref": "#/definitions/APIError"}}
+                }
+            }
+        },
+        "/products/{id}/tags": {
+            "get": {
+                "description": "Returns the tags of a product",
+                "produces": ["application/json", "application/xml", "application/msgpack"],
+                "summary": "Get product tags",
+                "operationId": "getProductTags",
+                "parameters": [
+                    {"type": "integer", "description": "Product ID", "name": "id", "in": "path", "required": true}
+                ],
+                "responses": {
+                    "200": {"description": "OK", "schema": {"$ref": "#/definitions/ProductTags"}},
+                    "404": {"description": "Product not found", "schema": {"$ref": "#/definitions/APIError"}},
+                    "500": {"description": "Internal error", "schema": {"$ref": "#/definitions/APIError"}}
+                }
+            },
+            "post": {
+                "description": "Adds tags to a product. Names are lower-cased and their words joined with '-'; tags the product already has are ignored",
+                "consumes": ["application/json", "application/xml", "application/msgpack"],
+                "produces": ["application/json", "application/xml", "application/msgpack"],
+                "summary": "Add product tags",
+                "operationId": "addProductTags",
+                "parameters": [
+                    {"type": "integer", "description": "Product ID", "name": "id", "in": "path", "required": true},
+                    {"description": "Tags", "name": "body", "in": "body", "required": true, "schema": {"$ref": "#/definitions/ProductTags"}}
+                ],
+                "responses": {
+                    "200": {"description": "All tags of the product", "schema": {"$ref": "#/definitions/ProductTags"}},
+                    "400": {"description": "Invalid tag or too many tags", "schema": {"$ref": "#/definitions/APIError"}},
+                    "404": {"description": "Product not found", "schema": {"$ref": "#/definitions/APIError"}},
+                    "500": {"description": "Internal error", "schema": {"$ref": "#/definitions/APIError"}}
+                }
+            },
+            "delete": {
+                "description": "Removes tags from a product",
+                "summary": "Remove product tags",
+                "operationId": "removeProductTags",
+                "parameters": [
+                    {"type": "integer", "description": "Product ID", "name": "id", "in": "path", "required": true},
+                    {"type": "string", "description": "Comma-separated tags to remove", "name": "tags", "in": "query", "required": true}
+                ],
+                "responses": {
+                    "204": {"description": "Removed"},
+                    "400": {"description": "No or invalid tags", "schema": {"$ref": "#/definitions/APIError"}},
+                    "404": {"description": "Product not found", "schema": {"$ref": "#/definitions/APIError"}},
+                    "500": {"description": "Internal error", "schema": {"$ref": "#/definitions/APIError"}}
+                }
+            }
+        },
+        "/products/{id}/options": {
+            "get": {
+                "description": "Returns the option axes of a product, such as size and colour",
+                "produces": ["application/json", "application/xml", "application/msgpack"],
+                "summary": "Get product options",
+                "operationId": "getProductOptions",
+                "parameters": [
+                    {"type": "integer", "description": "Product ID", "name": "id", "in": "path", "required": true}
+                ],
+                "responses": {
+                    "200": {"description": "OK", "schema": {"$ref": "#/definitions/ProductOptions"}},
+                    "404": {"description": "Product not found", "schema": {"$ref": "#/definitions/APIError"}},
+                    "500": {"description": "Internal error", "schema": {"$ref": "#/definitions/APIError"}}
+                }
+            },
+            "put": {
+                "description": "Replaces the option axes of a product. Existing variants must remain valid combinations of the new options",
+                "consumes": ["application/json", "application/xml", "application/msgpack"],
+                "produces": ["application/json", "application/xml", "application/msgpack"],
+                "summary": "Set product options",
+                "operationId": "setProductOptions",
+                "parameters": [
+                    {"type": "integer", "description": "Product ID", "name": "id", "in": "path", "required": true},
+                    {"description": "Options", "name": "body", "in": "body", "required": true, "schema": {"$ref": "#/definitions/ProductOptions"}}
+                ],
+                "responses": {
+                    "200": {"description": "OK", "schema": {"$ref": "#/definitions/ProductOptions"}},
+                    "400": {"description": "Invalid options, or a variant would no longer match them", "schema": {"$ref": "#/definitions/APIError"}},
+                    "404": {"description": "Product not found", "schema": {"$ref": "#/definitions/APIError"}},
+                    "500": {"description": "Internal error", "schema": {"$ref": "#/definitions/APIError"}}
+                }
+            }
+        },
+        "/products/{id}/variants": {
+            "get": {
+                "description": "Returns the variants of a product with their effective prices",
+                "produces": ["application/json", "application/xml", "application/msgpack"],
+                "summary": "List variants",
+                "operationId": "listVariants",
+                "parameters": [
+                    {"type": "integer", "description": "Product ID", "name": "id", "in": "path", "required": true}
+                ],
+                "responses": {
+                    "200": {"description": "OK", "schema": {"$ref": "#/definitions/Variants"}},
+                    "404": {"description": "Product not found", "schema": {"$ref": "#/definitions/APIError"}},
+                    "500": {"description": "Internal error", "schema": {"$ref": "#/definitions/APIError"}}
+                }
+            },
+            "post": {
+                "description": "Creates a variant for one combination of the product's options",
+                "consumes": ["application/json", "application/xml", "application/msgpack"],
+                "produces": ["application/json", "application/xml", "application/msgpack"],
+                "summary": "Create variant",
+                "operationId": "createVariant",
+                "parameters": [
+                    {"type": "integer", "description": "Product ID", "name": "id", "in": "path", "required": true},
+                    {"description": "Variant", "name": "body", "in": "body", "required": true, "schema": {"$ref": "#/definitions/VariantInput"}}
+                ],
+                "responses": {
+                    "201": {"description": "Created", "schema": {"$ref": "#/definitions/Variant"}},
+                    "400": {"description": "Invalid variant or duplicate options", "schema": {"$ref": "#/definitions/APIError"}},
+                    "409": {"description": "SKU already used by a product or variant", "schema": {"$ref": "#/definitions/APIError"}},
+                    "404": {"description": "Product not found", "schema": {"$ref": "#/definitions/APIError"}},
+                    "500": {"description": "Internal error", "schema": {"$ref": "#/definitions/APIError"}}
+                }
+            }
+        },
+        "/products/{id}/variants/generate": {
+            "post": {
+                "description": "Creates a variant for every combination of options that has none yet, with SKUs such as TSHIRT-M-RED. The body is optional; the SKU prefix defaults to P{id}",
+                "consumes": ["application/json", "application/xml", "application/msgpack"],
+                "produces": ["application/json", "application/xml", "application/msgpack"],
+                "summary": "Generate variant matrix",
+                "operationId": "generateVariants",
+                "parameters": [
+                    {"type": "integer", "description": "Product ID", "name": "id", "in": "path", "required": true},
+                    {"description": "SKU prefix", "name": "body", "in": "body", "schema": {"$ref": "#/definitions/VariantMatrix"}}
+                ],
+                "responses": {
+                    "201": {"description": "Created variants", "schema": {"$ref": "#/definitions/Variants"}},
+                    "200": {"description": "Every combination already has a variant", "schema": {"$ref": "#/definitions/Variants"}},
+                    "400": {"description": "No options or too many combinations", "schema": {"$ref": "#/definitions/APIError"}},
+                    "409": {"description": "SKU already used by a product or variant", "schema": {"$ref": "#/definitions/APIError"}},
+                    "404": {"description": "Product not found", "schema": {"$ref": "#/definitions/APIError"}},
+                    "500": {"description": "Internal error", "schema": {"$ref": "#/definitions/APIError"}}
+                }
+            }
+        },
+        "/products/{id}/variants/{vid}": {
+            "put": {
+                "description": "Replaces a variant",
+                "consumes": ["application/json", "application/xml", "application/msgpack"],
+                "produces": ["application/json", "application/xml", "application/msgpack"],
+                "summary": "Update variant",
+                "operationId": "updateVariant",
+                "parameters": [
+                    {"type": "integer", "description": "Product ID", "name": "id", "in": "path", "required": true},
+                    {"type": "integer", "description": "Variant ID", "name": "vid", "in": "path", "required": true},
+                    {"description": "Variant", "name": "body", "in": "body", "required": true, "schema": {"$ref": "#/definitions/VariantInput"}}
+                ],
+                "responses": {
+                    "200": {"description": "OK", "schema": {"$ref": "#/definitions/Variant"}},
+                    "400": {"description": "Invalid variant or duplicate options", "schema": {"$ref": "#/definitions/APIError"}},
+                    "409": {"description": "SKU already used by a product or variant", "schema": {"$ref": "#/definitions/APIError"}},
+                    "404": {"description": "Product or variant not found", "schema": {"$ref": "#/definitions/APIError"}},
+                    "500": {"description": "Internal error", "schema": {"$ref": "#/definitions/APIError"}}
+                }
+            },
+            "delete": {
+                "description": "Deletes a variant",
+                "summary": "Delete variant",
+                "operationId": "deleteVariant",
+                "parameters": [
+                    {"type": "integer", "description": "Product ID", "name": "id", "in": "path", "required": true},
+                    {"type": "integer", "description": "Variant ID", "name": "vid", "in": "path", "required": true}
+                ],
+                "responses": {
+                    "204": {"description": "Deleted"},
+                    "404": {"description": "Product or variant not found", "schema": {"$ref": "#/definitions/APIError"}},
+                    "500": {"description": "Internal error", "schema": {"$ref": "#/definitions/APIError"}}
+                }
+            }
+        },
+        "/products/{id}/stock": {
+            "get": {
+                "description": "Returns the stock on hand of a product in total and per warehouse, taken from the latest ledger movement in each warehouse",
+                "produces": ["application/json", "application/xml", "application/msgpack"],
+                "summary": "Get stock",
+                "operationId": "getStock",
+                "parameters": [
+                    {"type": "integer", "description": "Product ID", "name": "id", "in": "path", "required": true},
+                    {"type": "integer", "description": "Variant ID; omit for the stock of the product itself", "name": "variant", "in": "query"}
+                ],
+                "responses": {
+                    "200": {"description": "OK", "schema": {"$ref": "#/definitions/Stock"}},
+                    "400": {"description": "Invalid variant", "schema": {"$ref": "#/definitions/APIError"}},
+                    "404": {"description": "Product or variant not found", "schema": {"$ref": "#/definitions/APIError"}},
+                    "500": {"description": "Internal error", "schema": {"$ref": "#/definitions/APIError"}}
+                }
+            },
+            "put": {
+                "description": "Replaces the inventory settings of a product: backorders and the reorder point and quantity used by low-stock alerts and reorder suggestions",
+                "consumes": ["application/json", "application/xml", "application/msgpack"],
+                "produces": ["application/json", "application/xml", "application/msgpack"],
+                "summary": "Set stock settings",
+                "operationId": "setStockSettings",
+                "parameters": [
+                    {"type": "integer", "description": "Product ID", "name": "id", "in": "path", "required": true},
+                    {"type": "integer", "description": "Variant ID; omit for the stock of the product itself", "name": "variant", "in": "query"},
+                    {"description": "Settings", "name": "body", "in": "body", "required": true, "schema": {"$ref": "#/definitions/StockSettings"}}
+                ],
+                "responses": {
+                    "200": {"description": "OK", "schema": {"$ref": "#/definitions/Stock"}},
+                    "400": {"description": "Invalid body", "schema": {"$ref": "#/definitions/APIError"}},
+                    "404": {"description": "Product or variant not found", "schema": {"$ref": "#/definitions/APIError"}},
+                    "500": {"description": "Internal error", "schema": {"$ref": "#/definitions/APIError"}}
+                }
+            }
+        },
+        "/products/{id}/stock/movements": {
+            "get": {
+                "description": "Returns the stock ledger of a product, newest first",
+                "produces": ["application/json", "application/xml", "application/msgpack"],
+                "summary": "List stock movements",
+                "operationId": "listStockMovements",
+                "parameters": [
+                    {"type": "integer", "description": "Product ID", "name": "id", "in": "path", "required": true},
+                    {"type": "integer", "description": "Variant ID; omit for the stock of the product itself", "name": "variant", "in": "query"},
+                    {"type": "string", "enum": ["receipt", "adjustment", "sale", "return", "transfer_out", "transfer_in"], "description": "Only movements of this type", "name": "type", "in": "query"},
+                    {"type": "integer", "description": "Only movements in this warehouse", "name": "warehouse", "in": "query"},
+                    {"type": "integer", "default": 100, "description": "Page size, at most 500", "name": "limit", "in": "query"},
+                    {"type": "integer", "default": 0, "description": "Entries to skip", "name": "offset", "in": "query"},
+                    {"type": "boolean", "description": "Wrap the page in an object with pagination fields", "name": "envelope", "in": "query"}
+                ],
+                "responses": {
+                    "200": {"description": "OK", "schema": {"type": "array", "items": {"$ref": "#/definitions/StockMovement"}}},
+                    "400": {"description": "Unknown type or invalid warehouse", "schema": {"$ref": "#/definitions/APIError"}},
+                    "404": {"description": "Product or variant not found", "schema": {"$ref": "#/definitions/APIError"}},
+                    "500": {"description": "Internal error", "schema": {"$ref": "#/definitions/APIError"}}
+                }
+            },
+            "post": {
+                "description": "Appends a movement to the stock ledger of a warehouse, the default one if warehouse_id is omitted. The product's inventory row is locked while the balance is checked, so concurrent movements cannot take stock in a warehouse below zero, or sell stock held by reservations, unless the product allows backorders. Stock going out without a lot comes out of the lots that have not expired, first to expire first (FEFO), then out of untracked stock. Expired lots cannot be sold; a negative adjustment that names the lot writes them off",
+                "consumes": ["application/json", "application/xml", "application/msgpack"],
+                "produces": ["application/json", "application/xml", "application/msgpack"],
+                "summary": "Record stock movement",
+                "operationId": "recordStockMovement",
+                "parameters": [
+                    {"type": "integer", "description": "Product ID", "name": "id", "in": "path", "required": true},
+                    {"description": "Movement", "name": "body", "in": "body", "required": true, "schema": {"$ref": "#/definitions/StockMovementInput"}}
+                ],
+                "responses": {
+                    "201": {"description": "Created", "schema": {"$ref": "#/definitions/StockMovement"}},
+                    "400": {"description": "Invalid movement", "schema": {"$ref": "#/definitions/APIError"}},
+                    "404": {"description": "Product or variant not found", "schema": {"$ref": "#/definitions/APIError"}},
+                    "409": {"description": "Not enough stock and backorders are not allowed, or the lot has expired", "schema": {"$ref": "#/definitions/APIError"}},
+                    "500": {"description": "Internal error", "schema": {"$ref": "#/definitions/APIError"}}
+                }
+            }
+        },
+        "/products/{id}/availability": {
+            "get": {
+                "description": "Returns what a product has on hand, reserved, available to promise and in transit, summed across all warehouses or for the one given by warehouse. in_transit counts stock shipped by transfers that have not been received yet",
+                "produces": ["application/json", "application/xml", "application/msgpack"],
+                "summary": "Get availability",
+                "operationId": "getAvailability",
+                "parameters": [
+                    {"type": "integer", "description": "Product ID", "name": "id", "in": "path", "required": true},
+                    {"type": "integer", "description": "Variant ID; omit for the stock of the product itself", "name": "variant", "in": "query"},
+                    {"type": "integer", "description": "Only this warehouse", "name": "warehouse", "in": "query"}
+                ],
+                "responses": {
+                    "200": {"description": "OK", "schema": {"$ref": "#/definitions/Availability"}},
+                    "400": {"description": "Invalid or unknown warehouse", "schema": {"$ref": "#/definitions/APIError"}},
+                    "404": {"description": "Product or variant not found", "schema": {"$ref": "#/definitions/APIError"}},
+                    "500": {"description": "Internal error", "schema": {"$ref": "#/definitions/APIError"}}
+                }
+            }
+        },
+        "/warehouses": {
+            "get": {
+                "description": "Lists warehouses",
+                "produces": ["application/json", "application/xml", "application/msgpack"],
+                "summary": "List warehouses",
+                "operationId": "listWarehouses",
+                "responses": {
+                    "200": {"description": "OK", "schema": {"type": "array", "items": {"$ref": "#/definitions/Warehouse"}}},
+                    "500": {"description": "Internal error", "schema": {"$ref": "#/definitions/APIError"}}
+                }
+            },
+            "post": {
+                "description": "Creates a warehouse. Making it the default takes that role from the previous default warehouse",
+                "consumes": ["application/json", "application/xml", "application/msgpack"],
+                "produces": ["application/json", "application/xml", "application/msgpack"],
+                "summary": "Create warehouse",
+                "operationId": "createWarehouse",
+                "parameters": [
+                    {"description": "Warehouse", "name": "body", "in": "body", "required": true, "schema": {"$ref": "#/definitions/WarehouseInput"}}
+                ],
+                "responses": {
+                    "201": {"description": "Created", "schema": {"$ref": "#/definitions/Warehouse"}},
+                    "400": {"description": "Invalid warehouse or code already in use", "schema": {"$ref": "#/definitions/APIError"}},
+                    "500": {"description": "Internal error", "schema": {"$ref": "#/definitions/APIError"}}
+                }
+            }
+        },
+        "/warehouses/{id}": {
+            "get": {
+                "description": "Returns a warehouse",
+                "produces": ["application/json", "application/xml", "application/msgpack"],
+                "summary": "Get warehouse",
+                "operationId": "getWarehouse",
+                "parameters": [
+                    {"type": "integer", "description": "Warehouse ID", "name": "id", "in": "path", "required": true}
+                ],
+                "responses": {
+                    "200": {"description": "OK", "schema": {"$ref": "#/definitions/Warehouse"}},
+                    "400": {"description": "Invalid ID", "schema": {"$ref": "#/definitions/APIError"}},
+                    "404": {"description": "Warehouse not found", "schema": {"$ref": "#/definitions/APIError"}},
+                    "500": {"description": "Internal error", "schema": {"$ref": "#/definitions/APIError"}}
+                }
+            },
+            "put": {
+                "description": "Updates a warehouse. The default warehouse stays the default until another one is made the default",
+                "consumes": ["application/json", "application/xml", "application/msgpack"],
+                "produces": ["application/json", "application/xml", "application/msgpack"],
+                "summary": "Update warehouse",
+                "operationId": "updateWarehouse",
+                "parameters": [
+                    {"type": "integer", "description": "Warehouse ID", "name": "id", "in": "path", "required": true},
+                    {"description": "Warehouse", "name": "body", "in": "body", "required": true, "schema": {"$ref": "#/definitions/WarehouseInput"}}
+                ],
+                "responses": {
+                    "200": {"description": "OK", "schema": {"$ref": "#/definitions/Warehouse"}},
+                    "400": {"description": "Invalid warehouse or code already in use", "schema": {"$ref": "#/definitions/APIError"}},
+                    "404": {"description": "Warehouse not found", "schema": {"$ref": "#/definitions/APIError"}},
+                    "500": {"description": "Internal error", "schema": {"$ref": "#/definitions/APIError"}}
+                }
+            },
+            "delete": {
+                "description": "Deletes a warehouse that has no stock movements, transfers or reservations. The default warehouse cannot be deleted",
+                "summary": "Delete warehouse",
+                "operationId": "deleteWarehouse",
+                "parameters": [
+                    {"type": "integer", "description": "Warehouse ID", "name": "id", "in": "path", "required": true}
+                ],
+                "responses": {
+                    "204": {"description": "Deleted"},
+                    "400": {"description": "Invalid ID", "schema": {"$ref": "#/definitions/APIError"}},
+                    "404": {"description": "Warehouse not found", "schema": {"$ref": "#/definitions/APIError"}},
+                    "409": {"description": "Default warehouse or warehouse with stock history", "schema": {"$ref": "#/definitions/APIError"}},
+                    "500": {"description": "Internal error", "schema": {"$ref": "#/definitions/APIError"}}
+                }
+            }
+        },
+        "/transfers": {
+            "get": {
+                "description": "Lists transfers, newest first",
+                "produces": ["application/json", "application/xml", "application/msgpack"],
+                "summary": "List transfers",
+                "operationId": "listTransfers",
+                "parameters": [
+                    {"type": "string", "enum": ["in_transit", "received", "cancelled"], "description": "Only transfers with this status", "name": "status", "in": "query"},
+                    {"type": "integer", "description": "Only transfers from or to this warehouse", "name": "warehouse", "in": "query"},
+                    {"type": "integer", "default": 100, "description": "Page size, at most 500", "name": "limit", "in": "query"},
+                    {"type": "integer", "default": 0, "description": "Entries to skip", "name": "offset", "in": "query"},
+                    {"type": "boolean", "description": "Wrap the page in an object with pagination fields", "name": "envelope", "in": "query"}
+                ],
+                "responses": {
+                    "200": {"description": "OK", "schema": {"type": "array", "items": {"$ref": "#/definitions/Transfer"}}},
+                    "400": {"description": "Unknown status or invalid warehouse", "schema": {"$ref": "#/definitions/APIError"}},
+                    "500": {"description": "Internal error", "schema": {"$ref": "#/definitions/APIError"}}
+                }
+            },
+            "post": {
+                "description": "Ships stock from the source warehouse to the destination. In one transaction the transfer is created and a transfer_out movement takes each line out of the source; the stock is then in transit until the transfer is received or cancelled",
+                "consumes": ["application/json", "application/xml", "application/msgpack"],
+                "produces": ["application/json", "application/xml", "application/msgpack"],
+                "summary": "Create transfer",
+                "operationId": "createTransfer",
+                "parameters": [
+                    {"description": "Transfer", "name": "body", "in": "body", "required": true, "schema": {"$ref": "#/definitions/TransferInput"}}
+                ],
+                "responses": {
+                    "201": {"description": "Created", "schema": {"$ref": "#/definitions/Transfer"}},
+                    "400": {"description": "Invalid transfer", "schema": {"$ref": "#/definitions/APIError"}},
+                    "409": {"description": "Not enough stock in the source warehouse", "schema": {"$ref": "#/definitions/APIError"}},
+                    "500": {"description": "Internal error", "schema": {"$ref": "#/definitions/APIError"}}
+                }
+            }
+        },
+        "/transfers/{id}": {
+            "get": {
+                "description": "Returns a transfer with its lines",
+                "produces": ["application/json", "application/xml", "application/msgpack"],
+                "summary": "Get transfer",
+                "operationId": "getTransfer",
+                "parameters": [
+                    {"type": "integer", "description": "Transfer ID", "name": "id", "in": "path", "required": true}
+                ],
+                "responses": {
+                    "200": {"description": "OK", "schema": {"$ref": "#/definitions/Transfer"}},
+                    "400": {"description": "Invalid ID", "schema": {"$ref": "#/definitions/APIError"}},
+                    "404": {"description": "Transfer not found", "schema": {"$ref": "#/definitions/APIError"}},
+                    "500": {"description": "Internal error", "schema": {"$ref": "#/definitions/APIError"}}
+                }
+            }
+        },
+        "/transfers/{id}/receive": {
+            "post": {
+                "description": "Puts the stock of an in-transit transfer into the destination warehouse with transfer_in movements",
+                "produces": ["application/json", "application/xml", "application/msgpack"],
+                "summary": "Receive transfer",
+                "operationId": "receiveTransfer",
+                "parameters": [
+                    {"type": "integer", "description": "Transfer ID", "name": "id", "in": "path", "required": true}
+                ],
+                "responses": {
+                    "200": {"description": "OK", "schema": {"$ref": "#/definitions/Transfer"}},
+                    "400": {"description": "Invalid ID", "schema": {"$ref": "#/definitions/APIError"}},
+                    "404": {"description": "Transfer not found", "schema": {"$ref": "#/definitions/APIError"}},
+                    "409": {"description": "Transfer is no longer in transit", "schema": {"$ref": "#/definitions/APIError"}},
+                    "500": {"description": "Internal error", "schema": {"$ref": "#/definitions/APIError"}}
+                }
+            }
+        },
+        "/transfers/{id}/cancel": {
+            "post": {
+                "description": "Puts the stock of an in-transit transfer back into the source warehouse with transfer_in movements",
+                "produces": ["application/json", "application/xml", "application/msgpack"],
+                "summary": "Cancel transfer",
+                "operationId": "cancelTransfer",
+                "parameters": [
+                    {"type": "integer", "description": "Transfer ID", "name": "id", "in": "path", "required": true}
+                ],
+                "responses": {
+                    "200": {"description": "OK", "schema": {"$ref": "#/definitions/Transfer"}},
+                    "400": {"description": "Invalid ID", "schema": {"$ref": "#/definitions/APIError"}},
+                    "404": {"description": "Transfer not found", "schema": {"$ref": "#/definitions/APIError"}},
+                    "409": {"description": "Transfer is no longer in transit", "schema": {"$ref": "#/definitions/APIError"}},
+                    "500": {"description": "Internal error", "schema": {"$ref": "#/definitions/APIError"}}
+                }
+            }
+        },
+        "/reservations": {
+            "post": {
+                "description": "Holds stock in a warehouse, the default one if warehouse_id is omitted, for the configured RESERVATION_TTL. Products are locked while their available stock is checked, so two checkouts cannot both reserve the last unit. Held stock cannot be sold or shipped by anything but the reservation",
+                "consumes": ["application/json", "application/xml", "application/msgpack"],
+                "produces": ["application/json", "application/xml", "application/msgpack"],
+                "summary": "Create reservation",
+                "operationId": "createReservation",
+                "parameters": [
+                    {"description": "Reservation", "name": "body", "in": "body", "required": true, "schema": {"$ref": "#/definitions/ReservationInput"}}
+                ],
+                "responses": {
+                    "201": {"description": "Created", "schema": {"$ref": "#/definitions/Reservation"}},
+                    "400": {"description": "Invalid reservation", "schema": {"$ref": "#/definitions/APIError"}},
+                    "409": {"description": "Not enough stock available", "schema": {"$ref": "#/definitions/APIError"}},
+                    "500": {"description": "Internal error", "schema": {"$ref": "#/definitions/APIError"}}
+                }
+            }
+        },
+        "/reservations/{id}": {
+            "get": {
+                "description": "Returns a reservation with its lines",
+                "produces": ["application/json", "application/xml", "application/msgpack"],
+                "summary": "Get reservation",
+                "operationId": "getReservation",
+                "parameters": [
+                    {"type": "integer", "description": "Reservation ID", "name": "id", "in": "path", "required": true}
+                ],
+                "responses": {
+                    "200": {"description": "OK", "schema": {"$ref": "#/definitions/Reservation"}},
+                    "400": {"description": "Invalid ID", "schema": {"$ref": "#/definitions/APIError"}},
+                    "404": {"description": "Reservation not found", "schema": {"$ref": "#/definitions/APIError"}},
+                    "500": {"description": "Internal error", "schema": {"$ref": "#/definitions/APIError"}}
+                }
+            }
+        },
+        "/reservations/{id}/confirm": {
+            "post": {
+                "description": "Sells the reserved stock with sale movements",
+                "produces": ["application/json", "application/xml", "application/msgpack"],
+                "summary": "Confirm reservation",
+                "operationId": "confirmReservation",
+                "parameters": [
+                    {"type": "integer", "description": "Reservation ID", "name": "id", "in": "path", "required": true}
+                ],
+                "responses": {
+                    "200": {"description": "OK", "schema": {"$ref": "#/definitions/Reservation"}},
+                    "400": {"description": "Invalid ID", "schema": {"$ref": "#/definitions/APIError"}},
+                    "404": {"description": "Reservation not found", "schema": {"$ref": "#/definitions/APIError"}},
+                    "409": {"description": "Reservation is no longer active or has expired", "schema": {"$ref": "#/definitions/APIError"}},
+                    "500": {"description": "Internal error", "schema": {"$ref": "#/definitions/APIError"}}
+                }
+            }
+        },
+        "/reservations/{id}/release": {
+            "post": {
+                "description": "Frees the reserved stock",
+                "produces": ["application/json", "application/xml", "application/msgpack"],
+                "summary": "Release reservation",
+                "operationId": "releaseReservation",
+                "parameters": [
+                    {"type": "integer", "description": "Reservation ID", "name": "id", "in": "path", "required": true}
+                ],
+                "responses": {
+                    "200": {"description": "OK", "schema": {"$ref": "#/definitions/Reservation"}},
+                    "400": {"description": "Invalid ID", "schema": {"$ref": "#/definitions/APIError"}},
+                    "404": {"description": "Reservation not found", "schema": {"$ref": "#/definitions/APIError"}},
+                    "409": {"description": "Reservation is no longer active or has expired", "schema": {"$ref": "#/definitions/APIError"}},
+                    "500": {"description": "Internal error", "schema": {"$ref": "#/definitions/APIError"}}
+                }
+            }
+        },
+        "/alerts": {
+            "get": {
+                "description": "Lists low-stock alerts, newest first. A background job (ALERT_INTERVAL) opens an alert when a product's stock level, available to promise plus in transit, falls to its reorder point, and resolves it once the stock level is above the reorder point again. New alerts are also posted to ALERT_WEBHOOK_URL, if set, as {\"alerts\": [...]}",
+                "produces": ["application/json", "application/xml", "application/msgpack"],
+                "summary": "List alerts",
+                "operationId": "listAlerts",
+                "parameters": [
+                    {"type": "string", "enum": ["open", "resolved"], "description": "Only open or resolved alerts", "name": "status", "in": "query"},
+                    {"type": "integer", "default": 100, "description": "Page size, at most 500", "name": "limit", "in": "query"},
+                    {"type": "integer", "default": 0, "description": "Entries to skip", "name": "offset", "in": "query"},
+                    {"type": "boolean", "description": "Wrap the page in an object with pagination fields", "name": "envelope", "in": "query"}
+                ],
+                "responses": {
+                    "200": {"description": "OK", "schema": {"type": "array", "items": {"$ref": "#/definitions/Alert"}}},
+                    "400": {"description": "Unknown status", "schema": {"$ref": "#/definitions/APIError"}},
+                    "500": {"description": "Internal error", "schema": {"$ref": "#/definitions/APIError"}}
+                }
+            }
+        },
+        "/inventory/reorder-suggestions": {
+            "get": {
+                "description": "Suggests reorders for live products at or below their reorder point, or without enough stock to cover cover_days of sales at the rate of net sales (sales less returns) in the ledger over the last days. The suggested quantity brings the stock level up to the expected demand plus the reorder point, and is at least the reorder quantity. Least covered products come first. Also available as CSV",
+                "produces": ["application/json", "application/xml", "application/msgpack"],
+                "summary": "Reorder suggestions",
+                "operationId": "reorderSuggestions",
+                "parameters": [
+                    {"type": "integer", "default": 30, "description": "Sales window in days, 1 to 365", "name": "days", "in": "query"},
+                    {"type": "integer", "default": 30, "description": "Days of sales the stock should cover, 1 to 365", "name": "cover_days", "in": "query"}
+                ],
+                "responses": {
+                    "200": {"description": "OK", "schema": {"type": "array", "items": {"$ref": "#/definitions/ReorderSuggestion"}}},
+                    "400": {"description": "Invalid days", "schema": {"$ref": "#/definitions/APIError"}},
+                    "500": {"description": "Internal error", "schema": {"$ref": "#/definitions/APIError"}}
+                }
+            }
+        },
+        "/products/{id}/stock/lots": {
+            "get": {
+                "description": "Lists the lots of a product with stock, by expiry date with lots that do not expire last",
+                "produces": ["application/json", "application/xml", "application/msgpack"],
+                "summary": "List lots",
+                "operationId": "listLots",
+                "parameters": [
+                    {"type": "integer", "description": "Product ID", "name": "id", "in": "path", "required": true},
+                    {"type": "integer", "description": "Variant ID; omit for the stock of the product itself", "name": "variant", "in": "query"},
+                    {"type": "integer", "description": "Only lots in this warehouse", "name": "warehouse", "in": "query"}
+                ],
+                "responses": {
+                    "200": {"description": "OK", "schema": {"type": "array", "items": {"$ref": "#/definitions/Lot"}}},
+                    "400": {"description": "Invalid warehouse", "schema": {"$ref": "#/definitions/APIError"}},
+                    "404": {"description": "Product or variant not found", "schema": {"$ref": "#/definitions/APIError"}},
+                    "500": {"description": "Internal error", "schema": {"$ref": "#/definitions/APIError"}}
+                }
+            }
+        },
+        "/inventory/expiring": {
+            "get": {
+                "description": "Lists the lots of live products with stock that have expired or expire within the given number of days, first to expire first. Also available as CSV",
+                "produces": ["application/json", "application/xml", "application/msgpack"],
+                "summary": "Expiring lots",
+                "operationId": "expiringLots",
+                "parameters": [
+                    {"type": "string", "default": "30d", "description": "Days ahead, such as 30d, 0 to 365", "name": "within", "in": "query"},
+                    {"type": "integer", "description": "Only lots in this warehouse", "name": "warehouse", "in": "query"}
+                ],
+                "responses": {
+                    "200": {"description": "OK", "schema": {"type": "array", "items": {"$ref": "#/definitions/Lot"}}},
+                    "400": {"description": "Invalid within or warehouse", "schema": {"$ref": "#/definitions/APIError"}},
+                    "500": {"description": "Internal error", "schema": {"$ref": "#/definitions/APIError"}}
+                }
+            }
+        },
+        "/suppliers": {
+            "get": {
+                "description": "Lists suppliers",
+                "produces": ["application/json", "application/xml", "application/msgpack"],
+                "summary": "List suppliers",
+                "operationId": "listSuppliers",
+                "responses": {
+                    "200": {"description": "OK", "schema": {"type": "array", "items": {"$ref": "#/definitions/Supplier"}}},
+                    "500": {"description": "Internal error", "schema": {"$ref": "#/definitions/APIError"}}
+                }
+            },
+            "post": {
+                "description": "Creates a supplier",
+                "consumes": ["application/json", "application/xml", "application/msgpack"],
+                "produces": ["application/json", "application/xml", "application/msgpack"],
+                "summary": "Create supplier",
+                "operationId": "createSupplier",
+                "parameters": [
+                    {"description": "Supplier", "name": "body", "in": "body", "required": true, "schema": {"$ref": "#/definitions/SupplierInput"}}
+                ],
+                "responses": {
+                    "201": {"description": "Created", "schema": {"$ref": "#/definitions/Supplier"}},
+                    "400": {"description": "Invalid supplier or code in use", "schema": {"$ref": "#/definitions/APIError"}},
+                    "500": {"description": "Internal error", "schema": {"$ref": "#/definitions/APIError"}}
+                }
+            }
+        },
+        "/suppliers/{id}": {
+            "get": {
+                "description": "Returns a supplier",
+                "produces": ["application/json", "application/xml", "application/msgpack"],
+                "summary": "Get supplier",
+                "operationId": "getSupplier",
+                "parameters": [
+                    {"type": "integer", "description": "Supplier ID", "name": "id", "in": "path", "required": true}
+                ],
+                "responses": {
+                    "200": {"description": "OK", "schema": {"$ref": "#/definitions/Supplier"}},
+                    "400": {"description": "Invalid ID", "schema": {"$ref": "#/definitions/APIError"}},
+                    "404": {"description": "Supplier not found", "schema": {"$ref": "#/definitions/APIError"}},
+                    "500": {"description": "Internal error", "schema": {"$ref": "#/definitions/APIError"}}
+                }
+            },
+            "put": {
+                "description": "Replaces a supplier",
+                "consumes": ["application/json", "application/xml", "application/msgpack"],
+                "produces": ["application/json", "application/xml", "application/msgpack"],
+                "summary": "Update supplier",
+                "operationId": "updateSupplier",
+                "parameters": [
+                    {"type": "integer", "description": "Supplier ID", "name": "id", "in": "path", "required": true},
+                    {"description": "Supplier", "name": "body", "in": "body", "required": true, "schema": {"$ref": "#/definitions/SupplierInput"}}
+                ],
+                "responses": {
+                    "200": {"description": "OK", "schema": {"$ref": "#/definitions/Supplier"}},
+                    "400": {"description": "Invalid supplier or code in use", "schema": {"$ref": "#/definitions/APIError"}},
+                    "404": {"description": "Supplier not found", "schema": {"$ref": "#/definitions/APIError"}},
+                    "500": {"description": "Internal error", "schema": {"$ref": "#/definitions/APIError"}}
+                }
+            },
+            "delete": {
+                "description": "Deletes a supplier and its product list; suppliers with purchase orders cannot be deleted",
+                "summary": "Delete supplier",
+                "operationId": "deleteSupplier",
+                "parameters": [
+                    {"type": "integer", "description": "Supplier ID", "name": "id", "in": "path", "required": true}
+                ],
+                "responses": {
+                    "204": {"description": "Deleted"},
+                    "400": {"description": "Invalid ID", "schema": {"$ref": "#/definitions/APIError"}},
+                    "404": {"description": "Supplier not found", "schema": {"$ref": "#/definitions/APIError"}},
+                    "409": {"description": "Supplier with purchase orders", "schema": {"$ref": "#/definitions/APIError"}},
+                    "500": {"description": "Internal error", "schema": {"$ref": "#/definitions/APIError"}}
+                }
+            }
+        },
+        "/suppliers/{id}/products": {
+            "get": {
+                "description": "Lists the products a supplier supplies, with the supplier's SKUs and cost prices",
+                "produces": ["application/json", "application/xml", "application/msgpack"],
+                "summary": "List supplier products",
+                "operationId": "listSupplierProducts",
+                "parameters": [
+                    {"type": "integer", "description": "Supplier ID", "name": "id", "in": "path", "required": true}
+                ],
+                "responses": {
+                    "200": {"description": "OK", "schema": {"type": "array", "items": {"$ref": "#/definitions/SupplierProduct"}}},
+                    "400": {"description": "Invalid ID", "schema": {"$ref": "#/definitions/APIError"}},
+                    "404": {"description": "Supplier not found", "schema": {"$ref": "#/definitions/APIError"}},
+                    "500": {"description": "Internal error", "schema": {"$ref": "#/definitions/APIError"}}
+                }
+            }
+        },
+        "/suppliers/{id}/products/{product_id}": {
+            "put": {
+                "description": "Adds a product to the products a supplier supplies, or replaces the supplier's SKU and cost price of it. Purchase orders default to the cost price",
+                "consumes": ["application/json", "application/xml", "application/msgpack"],
+                "produces": ["application/json", "application/xml", "application/msgpack"],
+                "summary": "Set supplier product",
+                "operationId": "setSupplierProduct",
+                "parameters": [
+                    {"type": "integer", "description": "Supplier ID", "name": "id", "in": "path", "required": true},
+                    {"type": "integer", "description": "Product ID", "name": "product_id", "in": "path", "required": true},
+                    {"description": "Supplier terms", "name": "body", "in": "body", "required": true, "schema": {"$ref": "#/definitions/SupplierProductInput"}}
+                ],
+                "responses": {
+                    "200": {"description": "OK", "schema": {"$ref": "#/definitions/SupplierProduct"}},
+                    "400": {"description": "Invalid terms or supplier SKU in use", "schema": {"$ref": "#/definitions/APIError"}},
+                    "404": {"description": "Supplier or product not found", "schema": {"$ref": "#/definitions/APIError"}},
+                    "500": {"description": "Internal error", "schema": {"$ref": "#/definitions/APIError"}}
+                }
+            },
+            "delete": {
+                "description": "Removes a product from the products a supplier supplies",
+                "summary": "Delete supplier product",
+                "operationId": "deleteSupplierProduct",
+                "parameters": [
+                    {"type": "integer", "description": "Supplier ID", "name": "id", "in": "path", "required": true},
+                    {"type": "integer", "description": "Product ID", "name": "product_id", "in": "path", "required": true}
+                ],
+                "responses": {
+                    "204": {"description": "Deleted"},
+                    "400": {"description": "Invalid ID", "schema": {"$ref": "#/definitions/APIError"}},
+                    "404": {"description": "Supplier not found or product not supplied", "schema": {"$ref": "#/definitions/APIError"}},
+                    "500": {"description": "Internal error", "schema": {"$ref": "#/definitions/APIError"}}
+                }
+            }
+        },
+        "/purchase-orders": {
+            "get": {
+                "description": "Lists purchase orders, newest first",
+                "produces": ["application/json", "application/xml", "application/msgpack"],
+                "summary": "List purchase orders",
+                "operationId": "listPurchaseOrders",
+                "parameters": [
+                    {"type": "string", "enum": ["draft", "sent", "partially_received", "received", "cancelled"], "description": "Only purchase orders in this status", "name": "status", "in": "query"},
+                    {"type": "integer", "description": "Only purchase orders from this supplier", "name": "supplier", "in": "query"},
+                    {"type": "integer", "default": 100, "description": "Page size, at most 500", "name": "limit", "in": "query"},
+                    {"type": "integer", "default": 0, "description": "Entries to skip", "name": "offset", "in": "query"},
+                    {"type": "boolean", "description": "Wrap the page in an object with pagination fields", "name": "envelope", "in": "query"}
+                ],
+                "responses": {
+                    "200": {"description": "OK", "schema": {"type": "array", "items": {"$ref": "#/definitions/PurchaseOrder"}}},
+                    "400": {"description": "Unknown status or invalid supplier", "schema": {"$ref": "#/definitions/APIError"}},
+                    "500": {"description": "Internal error", "schema": {"$ref": "#/definitions/APIError"}}
+                }
+            },
+            "post": {
+                "description": "Drafts a purchase order for products the supplier supplies. Lines without a unit cost cost the supplier's cost price, which must be in the order's currency",
+                "consumes": ["application/json", "application/xml", "application/msgpack"],
+                "produces": ["application/json", "application/xml", "application/msgpack"],
+                "summary": "Create purchase order",
+                "operationId": "createPurchaseOrder",
+                "parameters": [
+                    {"description": "Purchase order", "name": "body", "in": "body", "required": true, "schema": {"$ref": "#/definitions/PurchaseOrderInput"}}
+                ],
+                "responses": {
+                    "201": {"description": "Created", "schema": {"$ref": "#/definitions/PurchaseOrder"}},
+                    "400": {"description": "Invalid purchase order", "schema": {"$ref": "#/definitions/APIError"}},
+                    "500": {"description": "Internal error", "schema": {"$ref": "#/definitions/APIError"}}
+                }
+            }
+        },
+        "/purchase-orders/{id}": {
+            "get": {
+                "description": "Returns a purchase order",
+                "produces": ["application/json", "application/xml", "application/msgpack"],
+                "summary": "Get purchase order",
+                "operationId": "getPurchaseOrder",
+                "parameters": [
+                    {"type": "integer", "description": "Purchase order ID", "name": "id", "in": "path", "required": true}
+                ],
+                "responses": {
+                    "200": {"description": "OK", "schema": {"$ref": "#/definitions/PurchaseOrder"}},
+                    "400": {"description": "Invalid ID", "schema": {"$ref": "#/definitions/APIError"}},
+                    "404": {"description": "Purchase order not found", "schema": {"$ref": "#/definitions/APIError"}},
+                    "500": {"description": "Internal error", "schema": {"$ref": "#/definitions/APIError"}}
+                }
+            },
+            "put": {
+                "description": "Replaces a draft purchase order",
+                "consumes": ["application/json", "application/xml", "application/msgpack"],
+                "produces": ["application/json", "application/xml", "application/msgpack"],
+                "summary": "Update purchase order",
+                "operationId": "updatePurchaseOrder",
+                "parameters": [
+                    {"type": "integer", "description": "Purchase order ID", "name": "id", "in": "path", "required": true},
+                    {"description": "Purchase order", "name": "body", "in": "body", "required": true, "schema": {"$ref": "#/definitions/PurchaseOrderInput"}}
+                ],
+                "responses": {
+                    "200": {"description": "OK", "schema": {"$ref": "#/definitions/PurchaseOrder"}},
+                    "400": {"description": "Invalid purchase order", "schema": {"$ref": "#/definitions/APIError"}},
+                    "404": {"description": "Purchase order not found", "schema": {"$ref": "#/definitions/APIError"}},
+                    "409": {"description": "Purchase order is not a draft", "schema": {"$ref": "#/definitions/APIError"}},
+                    "500": {"description": "Internal error", "schema": {"$ref": "#/definitions/APIError"}}
+                }
+            }
+        },
+        "/purchase-orders/{id}/send": {
+            "post": {
+                "description": "Marks a draft purchase order as sent to the supplier; it can no longer be changed",
+                "produces": ["application/json", "application/xml", "application/msgpack"],
+                "summary": "Send purchase order",
+                "operationId": "sendPurchaseOrder",
+                "parameters": [
+                    {"type": "integer", "description": "Purchase order ID", "name": "id", "in": "path", "required": true}
+                ],
+                "responses": {
+                    "200": {"description": "OK", "schema": {"$ref": "#/definitions/PurchaseOrder"}},
+                    "400": {"description": "Invalid ID", "schema": {"$ref": "#/definitions/APIError"}},
+                    "404": {"description": "Purchase order not found", "schema": {"$ref": "#/definitions/APIError"}},
+                    "409": {"description": "Purchase order is not a draft", "schema": {"$ref": "#/definitions/APIError"}},
+                    "500": {"description": "Internal error", "schema": {"$ref": "#/definitions/APIError"}}
+                }
+            }
+        },
+        "/purchase-orders/{id}/cancel": {
+            "post": {
+                "description": "Cancels a draft or sent purchase order of which nothing has been received",
+                "produces": ["application/json", "application/xml", "application/msgpack"],
+                "summary": "Cancel purchase order",
+                "operationId": "cancelPurchaseOrder",
+                "parameters": [
+                    {"type": "integer", "description": "Purchase order ID", "name": "id", "in": "path", "required": true}
+                ],
+                "responses": {
+                    "200": {"description": "OK", "schema": {"$ref": "#/definitions/PurchaseOrder"}},
+                    "400": {"description": "Invalid ID", "schema": {"$ref": "#/definitions/APIError"}},
+                    "404": {"description": "Purchase order not found", "schema": {"$ref": "#/definitions/APIError"}},
+                    "409": {"description": "Purchase order is already being received or finished", "schema": {"$ref": "#/definitions/APIError"}},
+                    "500": {"description": "Internal error", "schema": {"$ref": "#/definitions/APIError"}}
+                }
+            }
+        },
+        "/purchase-orders/{id}/receive": {
+            "post": {
+                "description": "Receives stock of a sent purchase order into its warehouse as receipt movements referencing purchase_order:ID, optionally into lots. The order becomes partially_received, or received once every line is. The cost price of each product becomes the weighted average of the stock on hand and the stock received, or the unit cost last received, as set by COST_METHOD",
+                "consumes": ["application/json", "application/xml", "application/msgpack"],
+                "produces": ["application/json", "application/xml", "application/msgpack"],
+                "summary": "Receive purchase order",
+                "operationId": "receivePurchaseOrder",
+                "parameters": [
+                    {"type": "integer", "description": "Purchase order ID", "name": "id", "in": "path", "required": true},
+                    {"description": "Receipt", "name": "body", "in": "body", "required": true, "schema": {"$ref": "#/definitions/ReceiptInput"}}
+                ],
+                "responses": {
+                    "200": {"description": "OK", "schema": {"$ref": "#/definitions/PurchaseOrder"}},
+                    "400": {"description": "Invalid receipt or more than is still to be received", "schema": {"$ref": "#/definitions/APIError"}},
+                    "404": {"description": "Purchase order not found", "schema": {"$ref": "#/definitions/APIError"}},
+                    "409": {"description": "Purchase order is not sent or partially received", "schema": {"$ref": "#/definitions/APIError"}},
+                    "500": {"description": "Internal error", "schema": {"$ref": "#/definitions/APIError"}}
+                }
+            }
+        },
+        "/orders": {
+            "get": {
+                "description": "Lists orders, newest first",
+                "produces": ["application/json", "application/xml", "application/msgpack"],
+                "summary": "List orders",
+                "operationId": "listOrders",
+                "parameters": [
+                    {"type": "string", "enum": ["pending", "paid", "fulfilled", "delivered", "cancelled", "refunded"], "description": "Only orders in this status", "name": "status", "in": "query"},
+                    {"type": "integer", "default": 100, "description": "Page size, at most 500", "name": "limit", "in": "query"},
+                    {"type": "integer", "default": 0, "description": "Entries to skip", "name": "offset", "in": "query"},
+                    {"type": "boolean", "description": "Wrap the page in an object with pagination fields", "name": "envelope", "in": "query"}
+                ],
+                "responses": {
+                    "200": {"description": "OK", "schema": {"type": "array", "items": {"$ref": "#/definitions/Order"}}},
+                    "400": {"description": "Unknown status", "schema": {"$ref": "#/definitions/APIError"}},
+                    "500": {"description": "Internal error", "schema": {"$ref": "#/definitions/APIError"}}
+                }
+            },
+            "post": {
+                "description": "Places a pending order for live products at their effective prices, all in one currency, and takes its stock out of the warehouse",
+                "consumes": ["application/json", "application/xml", "application/msgpack"],
+                "produces": ["application/json", "application/xml", "application/msgpack"],
+                "summary": "Create order",
+                "operationId": "createOrder",
+                "parameters": [
+                    {"description": "Order", "name": "body", "in": "body", "required": true, "schema": {"$ref": "#/definitions/OrderInput"}}
+                ],
+                "responses": {
+                    "201": {"description": "Created", "schema": {"$ref": "#/definitions/Order"}},
+                    "400": {"description": "Invalid order", "schema": {"$ref": "#/definitions/APIError"}},
+                    "409": {"description": "Not enough stock", "schema": {"$ref": "#/definitions/APIError"}},
+                    "500": {"description": "Internal error", "schema": {"$ref": "#/definitions/APIError"}}
+                }
+            }
+        },
+        "/orders/{id}": {
+            "get": {
+                "description": "Returns an order",
+                "produces": ["application/json", "application/xml", "application/msgpack"],
+                "summary": "Get order",
+                "operationId": "getOrder",
+                "parameters": [
+                    {"type": "integer", "description": "Order ID", "name": "id", "in": "path", "required": true}
+                ],
+                "responses": {
+                    "200": {"description": "OK", "schema": {"$ref": "#/definitions/Order"}},
+                    "400": {"description": "Invalid ID", "schema": {"$ref": "#/definitions/APIError"}},
+                    "404": {"description": "Order not found", "schema": {"$ref": "#/definitions/APIError"}},
+                    "500": {"description": "Internal error", "schema": {"$ref": "#/definitions/APIError"}}
+                }
+            }
+        },
+        "/orders/{id}/pay": {
+            "post": {
+                "description": "Marks a pending order as paid",
+                "produces": ["application/json", "application/xml", "application/msgpack"],
+                "summary": "Pay order",
+                "operationId": "payOrder",
+                "parameters": [
+                    {"type": "integer", "description": "Order ID", "name": "id", "in": "path", "required": true}
+                ],
+                "responses": {
+                    "200": {"description": "OK", "schema": {"$ref": "#/definitions/Order"}},
+                    "400": {"description": "Invalid ID", "schema": {"$ref": "#/definitions/APIError"}},
+                    "404": {"description": "Order not found", "schema": {"$ref": "#/definitions/APIError"}},
+                    "409": {"description": "Order is not pending", "schema": {"$ref": "#/definitions/APIError"}},
+                    "500": {"description": "Internal error", "schema": {"$ref": "#/definitions/APIError"}}
+                }
+            }
+        },
+        "/orders/{id}/fulfill": {
+            "post": {
+                "description": "Marks a paid order as fulfilled",
+                "produces": ["application/json", "application/xml", "application/msgpack"],
+                "summary": "Fulfill order",
+                "operationId": "fulfillOrder",
+                "parameters": [
+                    {"type": "integer", "description": "Order ID", "name": "id", "in": "path", "required": true}
+                ],
+                "responses": {
+                    "200": {"description": "OK", "schema": {"$ref": "#/definitions/Order"}},
+                    "400": {"description": "Invalid ID", "schema": {"$ref": "#/definitions/APIError"}},
+                    "404": {"description": "Order not found", "schema": {"$ref": "#/definitions/APIError"}},
+                    "409": {"description": "Order is not paid", "schema": {"$ref": "#/definitions/APIError"}},
+                    "500": {"description": "Internal error", "schema": {"$ref": "#/definitions/APIError"}}
+                }
+            }
+        },
+        "/orders/{id}/deliver": {
+            "post": {
+                "description": "Marks a fulfilled order as delivered",
+                "produces": ["application/json", "application/xml", "application/msgpack"],
+                "summary": "Deliver order",
+                "operationId": "deliverOrder",
+                "parameters": [
+                    {"type": "integer", "description": "Order ID", "name": "id", "in": "path", "required": true}
+                ],
+                "responses": {
+                    "200": {"description": "OK", "schema": {"$ref": "#/definitions/Order"}},
+                    "400": {"description": "Invalid ID", "schema": {"$ref": "#/definitions/APIError"}},
+                    "404": {"description": "Order not found", "schema": {"$ref": "#/definitions/APIError"}},
+                    "409": {"description": "Order is not fulfilled", "schema": {"$ref": "#/definitions/APIError"}},
+                    "500": {"description": "Internal error", "schema": {"$ref": "#/definitions/APIError"}}
+                }
+            }
+        },
+        "/orders/{id}/cancel": {
+            "post": {
+                "description": "Cancels a pending order and puts its stock back",
+                "produces": ["application/json", "application/xml", "application/msgpack"],
+                "summary": "Cancel order",
+                "operationId": "cancelOrder",
+                "parameters": [
+                    {"type": "integer", "description": "Order ID", "name": "id", "in": "path", "required": true}
+                ],
+                "responses": {
+                    "200": {"description": "OK", "schema": {"$ref": "#/definitions/Order"}},
+                    "400": {"description": "Invalid ID", "schema": {"$ref": "#/definitions/APIError"}},
+                    "404": {"description": "Order not found", "schema": {"$ref": "#/definitions/APIError"}},
+                    "409": {"description": "Order is not pending", "schema": {"$ref": "#/definitions/APIError"}},
+                    "500": {"description": "Internal error", "schema": {"$ref": "#/definitions/APIError"}}
+                }
+            }
+        },
+        "/orders/{id}/refund": {
+            "post": {
+                "description": "Refunds a paid, fulfilled or delivered order. The stock of an order that was not fulfilled yet is put back; returns of shipped goods are posted as stock movements",
+                "produces": ["application/json", "application/xml", "application/msgpack"],
+                "summary": "Refund order",
+                "operationId": "refundOrder",
+                "parameters": [
+                    {"type": "integer", "description": "Order ID", "name": "id", "in": "path", "required": true}
+                ],
+                "responses": {
+                    "200": {"description": "OK", "schema": {"$ref": "#/definitions/Order"}},
+                    "400": {"description": "Invalid ID", "schema": {"$ref": "#/definitions/APIError"}},
+                    "404": {"description": "Order not found", "schema": {"$ref": "#/definitions/APIError"}},
+                    "409": {"description": "Order has not been paid or is already refunded", "schema": {"$ref": "#/definitions/APIError"}},
+                    "500": {"description": "Internal error", "schema": {"$ref": "#/definitions/APIError"}}
+                }
+            }
+        },
+        "/categories/{id}/attribute-schema": {
+            "get": {
+                "description": "Returns the attribute schema set on the category (JSON only)",
+                "summary": "Get attribute schema",
+                "operationId": "getAttributeSchema",
+                "parameters": [
+                    {"type": "integer", "description": "Category ID", "name": "id", "in": "path", "required": true}
+                ],
+                "responses": {
+                    "200": {"description": "OK", "schema": {"$ref": "#/definitions/AttributeSchema"}},
+                    "404": {"description": "Category not found or without a schema", "schema": {"$ref": "#/definitions/APIError"}},
+                    "500": {"description": "Internal error", "schema": {"$ref": "#/definitions/APIError"}}
+                }
+            },
+            "put": {
+                "description": "Sets the JSON Schema that the attributes of products in this category, or in subcategories without a schema of their own, must match. Supported: an object with properties of type string, number, integer, boolean or array of those; enum, minimum, maximum, exclusiveMinimum, exclusiveMaximum, minLength, maxLength, pattern, minItems, maxItems, uniqueItems, required and additionalProperties. Other keywords are rejected. Products are checked when their attributes or primary category change",
+                "summary": "Set attribute schema",
+                "operationId": "setAttributeSchema",
+                "parameters": [
+                    {"type": "integer", "description": "Category ID", "name": "id", "in": "path", "required": true},
+                    {"description": "JSON Schema", "name": "body", "in": "body", "required": true, "schema": {"$ref": "#/definitions/AttributeSchema"}}
+                ],
+                "responses": {
+                    "200": {"description": "OK", "schema": {"$ref": "#/definitions/AttributeSchema"}},
+                    "400": {"description": "Invalid or unsupported schema", "schema": {"$ref": "#/definitions/APIError"}},
+                    "404": {"description": "Category not found", "schema": {"$ref": "#/definitions/APIError"}},
+                    "415": {"description": "Body is not JSON", "schema": {"$ref": "#/definitions/APIError"}},
+                    "500": {"description": "Internal error", "schema": {"$ref": "#/definitions/APIError"}}
+                }
+            },
+            "delete": {
+                "description": "Removes the attribute schema of a category",
+                "summary": "Delete attribute schema",
+                "operationId": "deleteAttributeSchema",
+                "parameters": [
+                    {"type": "integer", "description": "Category ID", "name": "id", "in": "path", "required": true}
+                ],
+                "responses": {
+                    "204": {"description": "Deleted"},
+                    "404": {"description": "Category not found or without a schema", "schema": {"$ref": "#/definitions/APIError"}},
+                    "500": {"description": "Internal error", "schema": {"$ref": "#/definitions/APIError"}}
+                }
+            }
+        },
+        "/products/by-sku/{sku}": {
+            "get": {
+                "description": "Finds a live product by its SKU or the SKU of one of its variants",
+                "produces": ["application/json", "application/xml", "application/msgpack"],
+                "summary": "Get product by SKU",
+                "operationId": "getBySKU",
+                "parameters": [
+                    {"type": "string", "description": "SKU", "name": "sku", "in": "path", "required": true},
+                    {"type": "string", "description": "ISO 4217 code to convert prices to; adds converted_price with the rate used", "name": "currency", "in": "query"}
+                ],
+                "responses": {
+                    "200": {"description": "OK", "schema": {"$ref": "#/definitions/Product"}},
+                    "404": {"description": "Product not found", "schema": {"$ref": "#/definitions/APIError"}},
+                    "500": {"description": "Internal error", "schema": {"$ref": "#/definitions/APIError"}}
+                }
+            }
+        },
+        "/products/by-barcode/{code}": {
+            "get": {
+                "description": "Finds a live product by barcode; a UPC-A code finds the product by the equivalent EAN-13 with a leading zero",
+                "produces": ["application/json", "application/xml", "application/msgpack"],
+                "summary": "Get product by barcode",
+                "operationId": "getByBarcode",
+                "parameters": [
+                    {"type": "string", "description": "EAN-13, UPC-A or EAN-8 code", "name": "code", "in": "path", "required": true},
+                    {"type": "string", "description": "ISO 4217 code to convert prices to; adds converted_price with the rate used", "name": "currency", "in": "query"}
+                ],
+                "responses": {
+                    "200": {"description": "OK", "schema": {"$ref": "#/definitions/Product"}},
+                    "404": {"description": "Product not found", "schema": {"$ref": "#/definitions/APIError"}},
+                    "500": {"description": "Internal error", "schema": {"$ref": "#/definitions/APIError"}}
+                }
+            }
+        },
+        "/products/by-slug/{slug}": {
+            "get": {
+                "description": "Finds a live product by its URL slug",
+                "produces": ["application/json", "application/xml", "application/msgpack"],
+                "summary": "Get product by slug",
+                "operationId": "getBySlug",
+                "parameters": [
+                    {"type": "string", "description": "Slug", "name": "slug", "in": "path", "required": true},
+                    {"type": "string", "description": "ISO 4217 code to convert prices to; adds converted_price with the rate used", "name": "currency", "in": "query"}
+                ],
+                "responses": {
+                    "200": {"description": "OK", "schema": {"$ref": "#/definitions/Product"}},
+                    "404": {"description": "Product not found", "schema": {"$ref": "#/definitions/APIError"}},
+                    "500": {"description": "Internal error", "schema": {"$ref": "#/definitions/APIError"}}
+                }
+            }
+        },
+        "/products/{id}": {
+            "get": {
+                "description": "Returns a product by ID",
+                "produces": ["application/json", "application/xml", "application/msgpack", "text/csv"],
+                "summary": "Get product by ID",
+                "operationId": "getByID",
+                "parameters": [
+                    {"type": "integer", "description": "Product ID", "name": "id", "in": "path", "required": true},
+                    {"type": "string", "format": "date-time", "description": "Return the product as it was at this instant", "name": "as_of", "in": "query"},
+                    {"type": "string", "description": "ISO 4217 code to convert prices to; adds converted_price with the rate used", "name": "currency", "in": "query"}
+                ],
+                "responses": {
+                    "200": {"description": "OK", "schema": {"$ref": "#/definitions/Product"}},
+                    "400": {"description": "Invalid ID", "schema": {"$ref": "#/definitions/APIError"}},
+                    "406": {"description": "Not acceptable", "schema": {"$ref": "#/definitions/APIError"}},
+                    "404": {"description": "Not found", "schema": {"$ref": "#/definitions/APIError"}},
+                    "500": {"description": "Internal error", "schema": {"$ref": "#/definitions/APIError"}}
+                }
+            },
+            "put": {
+                "description": "Update a product. The body is decoded strictly, as for create.",
+                "consumes": ["application/json", "application/xml", "application/msgpack"],
+                "produces": ["application/json", "application/xml", "application/msgpack", "text/csv"],
+                "summary": "Update product",
+                "operationId": "update",
+                "parameters": [
+                    {"type": "integer", "description": "Product ID", "name": "id", "in": "path", "required": true},
+                    {"description": "Product body", "name": "body", "in": "body", "required": true, "schema": {"$ref": "#/definitions/ProductInput"}}
+                ],
+                "responses": {
+                    "200": {"description": "OK", "schema": {"$ref": "#/definitions/Product"}},
+                    "400": {"description": "Validation error; invalid attributes are listed in fields", "schema": {"$ref": "#/definitions/APIError"}},
+                    "409": {"description": "SKU, barcode or slug already used by another product, or the currency changes while variants have their own prices", "schema": {"$ref": "#/definitions/APIError"}},
+                    "406": {"description": "Not acceptable", "schema": {"$ref": "#/definitions/APIError"}},
+                    "413": {"description": "Body larger than 64 KiB", "schema": {"$ref": "#/definitions/APIError"}},
+                    "415": {"description": "Missing or unsupported Content-Type", "schema": {"$ref": "#/definitions/APIError"}},
+                    "404": {"description": "Not found", "schema": {"$ref": "#/definitions/APIError"}},
+                    "500": {"description": "Internal error", "schema": {"$ref": "#/definitions/APIError"}}
+                }
+            },
+            "delete": {
+                "description": "Moves a product to the trash. It can be restored until it is purged.",
+                "summary": "Delete product",
+                "operationId": "delete",
+                "parameters": [
+                    {"type": "integer", "description": "Product ID", "name": "id", "in": "path", "required": true}
+                ],
+                "responses": {
+                    "204": {"description": "No Content"},
+                    "400": {"description": "Invalid ID", "schema": {"$ref": "#/definitions/APIError"}},
+                    "404": {"description": "Not found", "schema": {"$ref": "#/definitions/APIError"}},
+                    "500": {"description": "Internal error", "schema": {"$ref": "#/definitions/APIError"}}
+                }
+            }
+        },
+        "/audit": {
+            "get": {
+                "description": "Searches the audit log of all product changes, newest first. The actor is taken from the X-Actor header set by the gateway.",
+                "produces": ["application/json", "application/xml", "application/msgpack", "text/csv"],
+                "summary": "Audit log",
+                "operationId": "audit",
+                "parameters": [
+                    {"type": "integer", "description": "Only changes of this product", "name": "product_id", "in": "query"},
+                    {"type": "string", "description": "Only changes by this actor", "name": "actor", "in": "query"},
+                    {"type": "string", "enum": ["create", "update", "delete", "restore", "purge"], "description": "Only this kind of change", "name": "action", "in": "query"},
+                    {"type": "string", "format": "date-time", "description": "Changes at or after this time", "name": "from", "in": "query"},
+                    {"type": "string", "format": "date-time", "description": "Changes before this time", "name": "to", "in": "query"},
+                    {"type": "integer", "default": 100, "description": "Max items to return", "name": "limit", "in": "query"},
+                    {"type": "integer", "default": 0, "description": "Offset", "name": "offset", "in": "query"},
+                    {"type": "boolean", "default": false, "description": "Wrap the result in a pagination envelope", "name": "envelope", "in": "query"}
+                ],
+                "responses": {
+                    "200": {"description": "OK", "schema": {"type": "array", "items": {"$ref": "#/definitions/AuditEntry"}}},
+                    "400": {"description": "Invalid filter", "schema": {"$ref": "#/definitions/APIError"}},
+                    "500": {"description": "Internal error", "schema": {"$ref": "#/definitions/APIError"}}
+                }
+            }
+        }
+    },
+    "definitions": {
+        "AuditEntry": {
+            "type": "object",
+            "properties": {
+                "id": {"type": "integer"},
+                "product_id": {"type": "integer"},
+                "action": {"type": "string", "enum": ["create", "update", "delete", "restore", "purge"]},
+                "actor": {"type": "string"},
+                "request_id": {"type": "string"},
+                "changes": {"type": "array", "items": {"$ref": "#/definitions/FieldChange"}},
+                "created_at": {"type": "string", "format": "date-time"}
+            }
+        },
+        "ProductRevision": {
+            "type": "object",
+            "properties": {
+                "product_id": {"type": "integer"},
+                "revision": {"type": "integer"},
+                "snapshot": {"$ref": "#/definitions/Product"},
+                "actor": {"type": "string"},
+                "request_id": {"type": "string"},
+                "created_at": {"type": "string", "format": "date-time"}
+            }
+        },
+        "RevisionDiff": {
+            "type": "object",
+            "properties": {
+                "product_id": {"type": "integer"},
+                "from": {"type": "integer"},
+                "to": {"type": "integer"},
+                "changes": {"type": "array", "items": {"$ref": "#/definitions/FieldChange"}}
+            }
+        },
+        "PricePeriod": {
+            "type": "object",
+            "properties": {
+                "product_id": {"type": "integer"},
+                "price": {"$ref": "#/definitions/Money"},
+                "effective_from": {"type": "string", "format": "date-time"},
+                "effective_to": {"type": "string", "format": "date-time", "description": "Null while the price is current"},
+                "schedule_id": {"type": "integer", "description": "Set when the price comes from a price schedule"}
+            }
+        },
+        "PriceSchedule": {
+            "type": "object",
+            "properties": {
+                "id": {"type": "integer"},
+                "product_id": {"type": "integer"},
+                "price": {"$ref": "#/definitions/Money"},
+                "starts_at": {"type": "string", "format": "date-time"},
+                "ends_at": {"type": "string", "format": "date-time", "description": "End of a sale; absent for a permanent price change"},
+                "status": {"type": "string", "enum": ["scheduled", "active", "expired", "applied", "cancelled", "failed"]},
+                "note": {"type": "string"},
+                "created_at": {"type": "string", "format": "date-time"},
+                "activated_at": {"type": "string", "format": "date-time"},
+                "finished_at": {"type": "string", "format": "date-time"},
+                "error": {"type": "string", "description": "Why a failed permanent change could not be applied"}
+            }
+        },
+        "PriceScheduleInput": {
+            "type": "object",
+            "required": ["price", "starts_at"],
+            "properties": {
+                "price": {"$ref": "#/definitions/Money"},
+                "starts_at": {"type": "string", "format": "date-time"},
+                "ends_at": {"type": "string", "format": "date-time"},
+                "note": {"type": "string", "maxLength": 2000}
+            }
+        },
+        "FieldChange": {
+            "type": "object",
+            "properties": {
+                "field": {"type": "string"},
+                "old": {"description": "Value before the change, null if the product did not exist"},
+                "new": {"description": "Value after the change, null if the product was purged"}
+            }
+        },
+        "Product": {
+            "type": "object",
+            "properties": {
+                "id": {"type": "integer"},
+                "name": {"type": "string"},
+                "description": {"type": "string"},
+                "price": {"$ref": "#/definitions/Money"},
+                "attributes": {"$ref": "#/definitions/Attributes"},
+                "sku": {"type": "string"},
+                "barcode": {"type": "string"},
+                "slug": {"type": "string"},
+                "deleted_at": {"type": "string", "format": "date-time", "description": "Set on trashed products only"},
+                "effective_price": {"description": "Price currently charged, which differs from price while a price schedule applies", "allOf": [{"$ref": "#/definitions/Money"}]},
+                "converted_price": {"description": "Effective price in the currency requested with ?currency=; absent when there is no rate for the product's currency", "allOf": [{"$ref": "#/definitions/ConvertedPrice"}]},
+                "breadcrumbs": {"type": "array", "description": "Path from the root to the primary category", "items": {"$ref": "#/definitions/CategoryRef"}},
+                "tags": {"type": "array", "items": {"type": "string"}},
+                "options": {"type": "array", "description": "Only embedded by GET /products/{id}", "items": {"$ref": "#/definitions/ProductOption"}},
+                "variants": {"type": "array", "description": "Only embedded by GET /products/{id}", "items": {"$ref": "#/definitions/Variant"}},
+                "available": {"type": "integer", "description": "Available to promise: stock on hand across all warehouses minus active reservations, of the product and its variants together. Not included with ?fields="}
+            }
+        },
+        "ProductPage": {
+            "type": "object",
+            "properties": {
+                "data": {"type": "array", "items": {"$ref": "#/definitions/Product"}},
+                "total": {"type": "integer"},
+                "total_estimated": {"type": "boolean"},
+                "limit": {"type": "integer"},
+                "offset": {"type": "integer"},
+                "has_more": {"type": "boolean"}
+            }
+        },
+        "ProductInput": {
+            "type": "object",
+            "required": ["name", "price"],
+            "properties": {
+                "name": {"type": "string", "maxLength": 500},
+                "description": {"type": "string", "maxLength": 2000},
+                "price": {"$ref": "#/definitions/Money"},
+                "attributes": {"$ref": "#/definitions/Attributes"},
+                "sku": {"type": "string", "maxLength": 64, "description": "Letters, digits, '.', '_' and '-'; unique across products and variants"},
+                "barcode": {"type": "string", "description": "EAN-13, UPC-A or EAN-8 with a valid check digit; a UPC-A is stored as the EAN-13 with a leading zero; unique"},
+                "slug": {"type": "string", "maxLength": 200, "description": "Unique URL slug; generated from the name when empty. An update without a slug keeps the current one"}
+            }
+        },
+        "Money": {
+            "type": "object",
+            "required": ["amount", "currency"],
+            "properties": {
+                "amount": {"type": "string", "example": "12.34", "description": "Decimal amount with at most as many decimal places as the currency's minor unit"},
+                "currency": {"type": "string", "enum": ["EUR", "USD", "KZT", "GBP", "CHF", "RUB", "JPY", "KWD"], "description": "ISO 4217 code"}
+            }
+        },
+        "ConvertedPrice": {
+            "type": "object",
+            "properties": {
+                "price": {"$ref": "#/definitions/Money"},
+                "rate": {"type": "string", "example": "1.0921", "description": "Rate applied to the original currency"},
+                "rate_at": {"type": "string", "format": "date-time", "description": "Time of the rate; for cross rates, of the older leg"}
+            }
+        },
+        "ExchangeRate": {
+            "type": "object",
+            "properties": {
+                "base": {"type": "string"},
+                "quote": {"type": "string"},
+                "rate": {"type": "string", "example": "1.0921"},
+                "source": {"type": "string", "enum": ["manual", "csv", "ecb"]},
+                "updated_at": {"type": "string", "format": "date-time"}
+            }
+        },
+        "ExchangeRateInput": {
+            "type": "object",
+            "required": ["rate"],
+            "properties": {
+                "rate": {"type": "string", "example": "1.0921"}
+            }
+        },
+        "RateImport": {
+            "type": "object",
+            "properties": {
+                "imported": {"type": "integer"},
+                "skipped": {"type": "array", "items": {"type": "string"}, "description": "Unsupported currencies found in the file"}
+            }
+        },
+        "RoundingRule": {
+            "type": "object",
+            "properties": {
+                "currency": {"type": "string"},
+                "mode": {"type": "string", "enum": ["half_up", "half_even", "down", "up"]},
+                "increment": {"$ref": "#/definitions/Money"},
+                "updated_at": {"type": "string", "format": "date-time"}
+            }
+        },
+        "RoundingRuleInput": {
+            "type": "object",
+            "required": ["mode"],
+            "properties": {
+                "mode": {"type": "string", "enum": ["half_up", "half_even", "down", "up"]},
+                "increment": {"type": "string", "example": "0.05", "description": "Step to round to, in the currency; the minor unit by default"}
+            }
+        },
+        "PriceList": {
+            "type": "object",
+            "properties": {
+                "id": {"type": "integer"},
+                "code": {"type": "string"},
+                "name": {"type": "string"},
+                "currency": {"type": "string"},
+                "created_at": {"type": "string", "format": "date-time"}
+            }
+        },
+        "PriceListInput": {
+            "type": "object",
+            "required": ["code", "name", "currency"],
+            "properties": {
+                "code": {"type": "string", "pattern": "^[a-z0-9][a-z0-9_-]{0,63}$"},
+                "name": {"type": "string"},
+                "currency": {"type": "string"}
+            }
+        },
+        "PriceTier": {
+            "type": "object",
+            "properties": {
+                "min_qty": {"type": "integer", "minimum": 1},
+                "price": {"$ref": "#/definitions/Money"}
+            }
+        },
+        "PriceTiers": {
+            "type": "object",
+            "required": ["tiers"],
+            "properties": {
+                "price_list_id": {"type": "integer"},
+                "product_id": {"type": "integer"},
+                "tiers": {"type": "array", "items": {"$ref": "#/definitions/PriceTier"}}
+            }
+        },
+        "CustomerGroup": {
+            "type": "object",
+            "properties": {
+                "id": {"type": "integer"},
+                "code": {"type": "string"},
+                "name": {"type": "string"},
+                "price_list_id": {"type": "integer", "description": "Null when the group buys at public prices"},
+                "created_at": {"type": "string", "format": "date-time"}
+            }
+        },
+        "CustomerGroupInput": {
+            "type": "object",
+            "required": ["code", "name"],
+            "properties": {
+                "code": {"type": "string", "pattern": "^[a-z0-9][a-z0-9_-]{0,63}$"},
+                "name": {"type": "string"},
+                "price_list_id": {"type": "integer"}
+            }
+        },
+        "Quote": {
+            "type": "object",
+            "properties": {
+                "product_id": {"type": "integer"},
+                "qty": {"type": "integer"},
+                "price_list": {"type": "string"},
+                "source": {"type": "string", "enum": ["base", "sale", "price_list"]},
+                "min_qty": {"type": "integer", "description": "Break of the tier applied"},
+                "base_price": {"$ref": "#/definitions/Money"},
+                "unit_price": {"$ref": "#/definitions/Money"},
+                "total": {"$ref": "#/definitions/Money"}
+            }
+        },
+        "Category": {
+            "type": "object",
+            "properties": {
+                "id": {"type": "integer"},
+                "parent_id": {"type": "integer", "description": "Null for root categories"},
+                "name": {"type": "string"},
+                "depth": {"type": "integer", "description": "0 for root categories"},
+                "path": {"type": "array", "description": "From the root down to the category itself; single category reads only", "items": {"$ref": "#/definitions/CategoryRef"}},
+                "created_at": {"type": "string", "format": "date-time"}
+            }
+        },
+        "CategoryRef": {
+            "type": "object",
+            "properties": {
+                "id": {"type": "integer"},
+                "name": {"type": "string"}
+            }
+        },
+        "CategoryInput": {
+            "type": "object",
+            "required": ["name"],
+            "properties": {
+                "name": {"type": "string"},
+                "parent_id": {"type": "integer", "description": "Omit or null for a root category"}
+            }
+        },
+        "ProductCategory": {
+            "type": "object",
+            "required": ["id"],
+            "properties": {
+                "id": {"type": "integer"},
+                "name": {"type": "string"},
+                "is_primary": {"type": "boolean"},
+                "path": {"type": "array", "items": {"$ref": "#/definitions/CategoryRef"}}
+            }
+        },
+        "ProductCategories": {
+            "type": "object",
+            "required": ["categories"],
+            "properties": {
+                "categories": {"type": "array", "items": {"$ref": "#/definitions/ProductCategory"}}
+            }
+        },
+        "Tag": {
+            "type": "object",
+            "properties": {
+                "name": {"type": "string"},
+                "count": {"type": "integer", "description": "Number of live products with the tag"}
+            }
+        },
+        "ProductTags": {
+            "type": "object",
+            "required": ["tags"],
+            "properties": {
+                "tags": {"type": "array", "items": {"type": "string"}}
+            }
+        },
+        "ProductOption": {
+            "type": "object",
+            "required": ["name", "values"],
+            "properties": {
+                "name": {"type": "string", "description": "Lowercase letters, digits and '_'"},
+                "values": {"type": "array", "items": {"type": "string"}}
+            }
+        },
+        "ProductOptions": {
+            "type": "object",
+            "required": ["options"],
+            "properties": {
+                "options": {"type": "array", "items": {"$ref": "#/definitions/ProductOption"}}
+            }
+        },
+        "OptionValues": {
+            "type": "object",
+            "description": "One value per option of the product, e.g. {\"size\": \"M\", \"color\": \"red\"}. In XML each is an <option name=\"...\"> element",
+            "additionalProperties": {"type": "string"}
+        },
+        "Variant": {
+            "type": "object",
+            "properties": {
+                "id": {"type": "integer"},
+                "product_id": {"type": "integer"},
+                "sku": {"type": "string"},
+                "options": {"$ref": "#/definitions/OptionValues"},
+                "price": {"description": "Overrides the price of the product when set", "allOf": [{"$ref": "#/definitions/Money"}]},
+                "effective_price": {"description": "The variant's own price, or else the effective price of the product", "allOf": [{"$ref": "#/definitions/Money"}]},
+                "attributes": {"description": "Overrides the attributes of the product", "allOf": [{"$ref": "#/definitions/Attributes"}]},
+                "created_at": {"type": "string", "format": "date-time"},
+                "updated_at": {"type": "string", "format": "date-time"}
+            }
+        },
+        "VariantInput": {
+            "type": "object",
+            "required": ["sku", "options"],
+            "properties": {
+                "sku": {"type": "string", "maxLength": 64, "description": "Letters, digits, '.', '_' and '-'; unique across all products"},
+                "options": {"$ref": "#/definitions/OptionValues"},
+                "price": {"description": "Price override in the currency of the product", "allOf": [{"$ref": "#/definitions/Money"}]},
+                "attributes": {"$ref": "#/definitions/Attributes"}
+            }
+        },
+        "Variants": {
+            "type": "object",
+            "properties": {
+                "variants": {"type": "array", "items": {"$ref": "#/definitions/Variant"}}
+            }
+        },
+        "VariantMatrix": {
+            "type": "object",
+            "properties": {
+                "sku_prefix": {"type": "string", "description": "Defaults to P{id}"}
+            }
+        },
+        "Stock": {
+            "type": "object",
+            "properties": {
+                "product_id": {"type": "integer"},
+                "variant_id": {"type": "integer", "description": "Set when the stock is that of a variant"},
+                "on_hand": {"type": "integer", "description": "Negative only while backordered"},
+                "expired": {"type": "integer", "description": "On hand in lots past their expiry date"},
+                "reserved": {"type": "integer", "description": "Held by active reservations"},
+                "available": {"type": "integer", "description": "on_hand minus expired and reserved"},
+                "allow_backorder": {"type": "boolean"},
+                "reorder_point": {"type": "integer"},
+                "reorder_quantity": {"type": "integer"},
+                "cost_price": {"$ref": "#/definitions/Money", "description": "What a unit cost to buy, set as purchase orders are received"},
+                "updated_at": {"type": "string", "format": "date-time", "description": "Time of the latest movement"},
+                "warehouses": {"type": "array", "items": {"$ref": "#/definitions/WarehouseStock"}, "description": "Warehouses that ever held the product"}
+            }
+        },
+        "StockSettings": {
+            "type": "object",
+            "properties": {
+                "allow_backorder": {"type": "boolean", "description": "Let sales and adjustments take stock below zero"},
+                "reorder_point": {"type": "integer", "minimum": 0, "description": "Raise a low-stock alert when the stock level falls to this; null for none"},
+                "reorder_quantity": {"type": "integer", "minimum": 1, "description": "Least quantity to reorder; null for none"}
+            }
+        },
+        "StockMovement": {
+            "type": "object",
+            "properties": {
+                "id": {"type": "integer"},
+                "product_id": {"type": "integer"},
+                "variant_id": {"type": "integer", "description": "Set when the stock is that of a variant"},
+                "warehouse_id": {"type": "integer"},
+                "type": {"type": "string", "enum": ["receipt", "adjustment", "sale", "return", "transfer_out", "transfer_in"]},
+                "quantity": {"type": "integer", "description": "Positive for stock coming in, negative for stock going out"},
+                "balance_after": {"type": "integer", "description": "Stock on hand in the warehouse after the movement"},
+                "lots": {"type": "array", "items": {"$ref": "#/definitions/LotQuantity"}, "description": "The part of the quantity that went into or out of lots; the rest is untracked stock"},
+                "reference": {"type": "string"},
+                "note": {"type": "string"},
+                "actor": {"type": "string"},
+                "request_id": {"type": "string"},
+                "created_at": {"type": "string", "format": "date-time"}
+            }
+        },
+        "StockMovementInput": {
+            "type": "object",
+            "required": ["type", "quantity"],
+            "properties": {
+                "variant_id": {"type": "integer", "description": "Variant of the product; omit for the product itself"},
+                "warehouse_id": {"type": "integer", "description": "Defaults to the default warehouse"},
+                "type": {"type": "string", "enum": ["receipt", "adjustment", "sale", "return"]},
+                "quantity": {"type": "integer", "description": "Units; positive for receipts, sales and returns, signed for adjustments"},
+                "lot": {"type": "string", "maxLength": 100, "description": "Lot the stock goes into or comes out of. Without one, stock going out comes out of the first lots to expire, then untracked stock"},
+                "expires_on": {"type": "string", "format": "date", "description": "Expiry date of the lot, for stock coming in; a lot number keeps the date it was first received with"},
+                "reference": {"type": "string", "maxLength": 200, "description": "E.g. a delivery note or order number"},
+                "note": {"type": "string", "maxLength": 2000}
+            }
+        },
+        "WarehouseStock": {
+            "type": "object",
+            "properties": {
+                "warehouse_id": {"type": "integer"},
+                "code": {"type": "string"},
+                "on_hand": {"type": "integer"},
+                "expired": {"type": "integer"},
+                "reserved": {"type": "integer"},
+                "available": {"type": "integer"}
+            }
+        },
+        "Availability": {
+            "type": "object",
+            "properties": {
+                "product_id": {"type": "integer"},
+                "variant_id": {"type": "integer", "description": "Set when the stock is that of a variant"},
+                "on_hand": {"type": "integer", "description": "Stock on hand in the selected warehouses"},
+                "expired": {"type": "integer", "description": "On hand in lots past their expiry date"},
+                "reserved": {"type": "integer", "description": "Held by active reservations in the selected warehouses"},
+                "available": {"type": "integer", "description": "Available to promise: on_hand minus expired and reserved"},
+                "in_transit": {"type": "integer", "description": "Stock in transit to the selected warehouses"},
+                "warehouses": {"type": "array", "items": {"$ref": "#/definitions/WarehouseAvailability"}}
+            }
+        },
+        "WarehouseAvailability": {
+            "type": "object",
+            "properties": {
+                "warehouse_id": {"type": "integer"},
+                "code": {"type": "string"},
+                "name": {"type": "string"},
+                "on_hand": {"type": "integer"},
+                "expired": {"type": "integer"},
+                "reserved": {"type": "integer"},
+                "available": {"type": "integer"},
+                "incoming": {"type": "integer", "description": "Stock in transit to this warehouse"}
+            }
+        },
+        "Warehouse": {
+            "type": "object",
+            "properties": {
+                "id": {"type": "integer"},
+                "code": {"type": "string"},
+                "name": {"type": "string"},
+                "is_default": {"type": "boolean", "description": "Movements without a warehouse_id go here"},
+                "created_at": {"type": "string", "format": "date-time"}
+            }
+        },
+        "WarehouseInput": {
+            "type": "object",
+            "required": ["code", "name"],
+            "properties": {
+                "code": {"type": "string", "pattern": "^[a-z0-9][a-z0-9_-]{0,63}$"},
+                "name": {"type": "string"},
+                "is_default": {"type": "boolean"}
+            }
+        },
+        "Transfer": {
+            "type": "object",
+            "properties": {
+                "id": {"type": "integer"},
+                "source_warehouse_id": {"type": "integer"},
+                "destination_warehouse_id": {"type": "integer"},
+                "status": {"type": "string", "enum": ["in_transit", "received", "cancelled"]},
+                "lines": {"type": "array", "items": {"$ref": "#/definitions/TransferLine"}},
+                "note": {"type": "string"},
+                "actor": {"type": "string"},
+                "created_at": {"type": "string", "format": "date-time"},
+                "finished_at": {"type": "string", "format": "date-time", "description": "When the transfer was received or cancelled"}
+            }
+        },
+        "TransferLine": {
+            "type": "object",
+            "required": ["product_id", "quantity"],
+            "properties": {
+                "product_id": {"type": "integer"},
+                "variant_id": {"type": "integer", "description": "Variant of the product; omit for the product itself"},
+                "quantity": {"type": "integer", "minimum": 1}
+            }
+        },
+        "TransferInput": {
+            "type": "object",
+            "required": ["source_warehouse_id", "destination_warehouse_id", "lines"],
+            "properties": {
+                "source_warehouse_id": {"type": "integer"},
+                "destination_warehouse_id": {"type": "integer"},
+                "lines": {"type": "array", "maxItems": 500, "items": {"$ref": "#/definitions/TransferLine"}},
+                "note": {"type": "string", "maxLength": 2000}
+            }
+        },
+        "Reservation": {
+            "type": "object",
+            "properties": {
+                "id": {"type": "integer"},
+                "warehouse_id": {"type": "integer"},
+                "status": {"type": "string", "enum": ["active", "confirmed", "released", "expired"]},
+                "lines": {"type": "array", "items": {"$ref": "#/definitions/ReservationLine"}},
+                "reference": {"type": "string"},
+                "actor": {"type": "string"},
+                "created_at": {"type": "string", "format": "date-time"},
+                "expires_at": {"type": "string", "format": "date-time", "description": "The reservation stops holding stock at this time"},
+                "finished_at": {"type": "string", "format": "date-time", "description": "When the reservation was confirmed, released or expired"}
+            }
+        },
+        "ReservationLine": {
+            "type": "object",
+            "required": ["product_id", "quantity"],
+            "properties": {
+                "product_id": {"type": "integer"},
+                "variant_id": {"type": "integer", "description": "Variant of the product; omit for the product itself"},
+                "quantity": {"type": "integer", "minimum": 1}
+            }
+        },
+        "ReservationInput": {
+            "type": "object",
+            "required": ["lines"],
+            "properties": {
+                "warehouse_id": {"type": "integer", "description": "Defaults to the default warehouse"},
+                "lines": {"type": "array", "maxItems": 500, "items": {"$ref": "#/definitions/ReservationLine"}},
+                "reference": {"type": "string", "maxLength": 200, "description": "E.g. a cart or checkout ID"}
+            }
+        },
+        "Alert": {
+            "type": "object",
+            "properties": {
+                "id": {"type": "integer"},
+                "product_id": {"type": "integer"},
+                "variant_id": {"type": "integer", "description": "Set when the stock is that of a variant"},
+                "product_name": {"type": "string"},
+                "type": {"type": "string", "enum": ["low_stock"]},
+                "stock_level": {"type": "integer", "description": "Available to promise plus in transit when the alert was raised"},
+                "reorder_point": {"type": "integer"},
+                "reorder_quantity": {"type": "integer"},
+                "created_at": {"type": "string", "format": "date-time"},
+                "resolved_at": {"type": "string", "format": "date-time"},
+                "notified_at": {"type": "string", "format": "date-time", "description": "When the alert was posted to the webhook"}
+            }
+        },
+        "ReorderSuggestion": {
+            "type": "object",
+            "properties": {
+                "product_id": {"type": "integer"},
+                "variant_id": {"type": "integer", "description": "Set when the stock is that of a variant"},
+                "name": {"type": "string"},
+                "sku": {"type": "string"},
+                "stock_level": {"type": "integer", "description": "Available to promise across all warehouses plus in transit"},
+                "reorder_point": {"type": "integer"},
+                "reorder_quantity": {"type": "integer"},
+                "net_sales": {"type": "integer", "description": "Units sold less units returned over the sales window"},
+                "daily_sales": {"type": "number"},
+                "days_of_cover": {"type": "number", "description": "Days the stock level lasts at the daily sales rate; absent without sales"},
+                "suggested_quantity": {"type": "integer"}
+            }
+        },
+        "Lot": {
+            "type": "object",
+            "properties": {
+                "product_id": {"type": "integer"},
+                "variant_id": {"type": "integer", "description": "Set when the stock is that of a variant"},
+                "product_name": {"type": "string", "description": "Only when lots of many products are listed"},
+                "sku": {"type": "string"},
+                "warehouse_id": {"type": "integer"},
+                "warehouse_code": {"type": "string"},
+                "lot": {"type": "string"},
+                "expires_on": {"type": "string", "format": "date"},
+                "quantity": {"type": "integer"},
+                "expired": {"type": "boolean", "description": "Past its expiry date; cannot be sold"}
+            }
+        },
+        "LotQuantity": {
+            "type": "object",
+            "properties": {
+                "lot": {"type": "string"},
+                "expires_on": {"type": "string", "format": "date"},
+                "quantity": {"type": "integer", "description": "Positive into the lot, negative out of it"}
+            }
+        },
+        "Supplier": {
+            "type": "object",
+            "properties": {
+                "id": {"type": "integer"},
+                "code": {"type": "string"},
+                "name": {"type": "string"},
+                "email": {"type": "string"},
+                "phone": {"type": "string"},
+                "created_at": {"type": "string", "format": "date-time"},
+                "updated_at": {"type": "string", "format": "date-time"}
+            }
+        },
+        "SupplierInput": {
+            "type": "object",
+            "required": ["code", "name"],
+            "properties": {
+                "code": {"type": "string", "description": "1-64 lowercase letters, digits, '-' or '_'"},
+                "name": {"type": "string"},
+                "email": {"type": "string", "format": "email"},
+                "phone": {"type": "string", "maxLength": 50}
+            }
+        },
+        "SupplierProduct": {
+            "type": "object",
+            "properties": {
+                "supplier_id": {"type": "integer"},
+                "product_id": {"type": "integer"},
+                "product_name": {"type": "string"},
+                "supplier_sku": {"type": "string"},
+                "cost_price": {"$ref": "#/definitions/Money"},
+                "updated_at": {"type": "string", "format": "date-time"}
+            }
+        },
+        "SupplierProductInput": {
+            "type": "object",
+            "properties": {
+                "supplier_sku": {"type": "string", "maxLength": 64, "description": "The supplier's code for the product, unique per supplier"},
+                "cost_price": {"$ref": "#/definitions/Money"}
+            }
+        },
+        "PurchaseOrder": {
+            "type": "object",
+            "properties": {
+                "id": {"type": "integer"},
+                "supplier_id": {"type": "integer"},
+                "warehouse_id": {"type": "integer"},
+                "status": {"type": "string", "enum": ["draft", "sent", "partially_received", "received", "cancelled"]},
+                "currency": {"type": "string"},
+                "lines": {"type": "array", "items": {"$ref": "#/definitions/PurchaseOrderLine"}},
+                "total": {"$ref": "#/definitions/Money"},
+                "note": {"type": "string"},
+                "actor": {"type": "string"},
+                "created_at": {"type": "string", "format": "date-time"},
+                "updated_at": {"type": "string", "format": "date-time"},
+                "sent_at": {"type": "string", "format": "date-time"},
+                "finished_at": {"type": "string", "format": "date-time"}
+            }
+        },
+        "PurchaseOrderLine": {
+            "type": "object",
+            "properties": {
+                "product_id": {"type": "integer"},
+                "variant_id": {"type": "integer", "description": "Set when the stock is that of a variant"},
+                "supplier_sku": {"type": "string"},
+                "quantity": {"type": "integer"},
+                "received": {"type": "integer"},
+                "unit_cost": {"$ref": "#/definitions/Money"}
+            }
+        },
+        "PurchaseOrderInput": {
+            "type": "object",
+            "required": ["supplier_id", "currency", "lines"],
+            "properties": {
+                "supplier_id": {"type": "integer"},
+                "warehouse_id": {"type": "integer", "description": "Warehouse to receive into; defaults to the default warehouse"},
+                "currency": {"type": "string"},
+                "lines": {"type": "array", "items": {"$ref": "#/definitions/PurchaseOrderLineInput"}},
+                "note": {"type": "string", "maxLength": 2000}
+            }
+        },
+        "PurchaseOrderLineInput": {
+            "type": "object",
+            "required": ["product_id", "quantity"],
+            "properties": {
+                "product_id": {"type": "integer"},
+                "variant_id": {"type": "integer", "description": "Variant of the product; omit for the product itself"},
+                "quantity": {"type": "integer"},
+                "unit_cost": {"$ref": "#/definitions/Money", "description": "Defaults to the supplier's cost price"}
+            }
+        },
+        "ReceiptInput": {
+            "type": "object",
+            "required": ["lines"],
+            "properties": {
+                "lines": {"type": "array", "items": {"$ref": "#/definitions/ReceiptLine"}},
+                "note": {"type": "string", "maxLength": 2000}
+            }
+        },
+        "ReceiptLine": {
+            "type": "object",
+            "required": ["product_id", "quantity"],
+            "properties": {
+                "product_id": {"type": "integer"},
+                "variant_id": {"type": "integer", "description": "Variant of the product; omit for the product itself"},
+                "quantity": {"type": "integer"},
+                "lot": {"type": "string", "maxLength": 100},
+                "expires_on": {"type": "string", "format": "date"}
+            }
+        },
+        "Order": {
+            "type": "object",
+            "properties": {
+                "id": {"type": "integer"},
+                "warehouse_id": {"type": "integer"},
+                "status": {"type": "string", "enum": ["pending", "paid", "fulfilled", "delivered", "cancelled", "refunded"]},
+                "currency": {"type": "string"},
+                "customer": {"type": "string"},
+                "lines": {"type": "array", "items": {"$ref": "#/definitions/OrderLine"}},
+                "total": {"$ref": "#/definitions/Money"},
+                "note": {"type": "string"},
+                "actor": {"type": "string"},
+                "created_at": {"type": "string", "format": "date-time"},
+                "updated_at": {"type": "string", "format": "date-time"},
+                "paid_at": {"type": "string", "format": "date-time"},
+                "fulfilled_at": {"type": "string", "format": "date-time"},
+                "delivered_at": {"type": "string", "format": "date-time"},
+                "cancelled_at": {"type": "string", "format": "date-time"},
+                "refunded_at": {"type": "string", "format": "date-time"}
+            }
+        },
+        "OrderLine": {
+            "type": "object",
+            "properties": {
+                "product_id": {"type": "integer"},
+                "variant_id": {"type": "integer", "description": "Set when the stock is that of a variant"},
+                "name": {"type": "string", "description": "Product name when the order was placed"},
+                "sku": {"type": "string"},
+                "quantity": {"type": "integer"},
+                "unit_price": {"$ref": "#/definitions/Money", "description": "Effective price when the order was placed"},
+                "total": {"$ref": "#/definitions/Money"}
+            }
+        },
+        "OrderInput": {
+            "type": "object",
+            "required": ["lines"],
+            "properties": {
+                "warehouse_id": {"type": "integer", "description": "Warehouse to ship from; defaults to the default warehouse"},
+                "customer": {"type": "string", "maxLength": 200},
+                "lines": {"type": "array", "items": {"$ref": "#/definitions/OrderLineInput"}},
+                "note": {"type": "string", "maxLength": 2000}
+            }
+        },
+        "OrderLineInput": {
+            "type": "object",
+            "required": ["product_id", "quantity"],
+            "properties": {
+                "product_id": {"type": "integer"},
+                "variant_id": {"type": "integer", "description": "Variant of the product; omit for the product itself"},
+                "quantity": {"type": "integer"}
+            }
+        },
+        "Attributes": {
+            "type": "object",
+            "description": "Custom fields, validated against the schema of the primary category. Values are strings, numbers, booleans or arrays of those; names are lowercase letters, digits and '_'. In XML each is an <attribute name=\"...\"> element holding the JSON value, with strings unquoted",
+            "additionalProperties": true
+        },
+        "AttributeSchema": {
+            "type": "object",
+            "required": ["type"],
+            "properties": {
+                "type": {"type": "string", "enum": ["object"]},
+                "properties": {"type": "object"},
+                "required": {"type": "array", "items": {"type": "string"}},
+                "additionalProperties": {"type": "boolean"}
+            }
+        },
+        "FieldError": {
+            "type": "object",
+            "properties": {
+                "field": {"type": "string"},
+                "message": {"type": "string"}
+            }
+        },
+        "APIError": {
+            "type": "object",
+            "properties": {
+                "code": {"type": "string"},
+                "message": {"type": "string"},
+                "fields": {"type": "array", "description": "Offending fields of a request body, e.g. attributes.ram_gb", "items": {"$ref": "#/definitions/FieldError"}}
             }
         }
     }
@@ -8,30 +8,68 @@ import (
 type Code string
 
 const (
-	CodeInvalidInput Code = "invalid_input"
-	CodeNotFound     Code = "not_found"
-	CodeInternal     Code = "internal_error"
+	CodeInvalidInput         Code = "invalid_input"
+	CodeNotFound             Code = "not_found"
+	CodeConflict             Code = "conflict"
+	CodeNotAcceptable        Code = "not_acceptable"
+	CodePayloadTooLarge      Code = "payload_too_large"
+	CodeUnsupportedMediaType Code = "unsupported_media_type"
+	CodeInternal             Code = "internal_error"
 )
 
 type APIError struct {
-	Code    Code   `json:"code"`
+	Code    Code         `json:"code"`
+	Message string       `json:"message"`
+	Fields  []FieldError `json:"fields,omitempty"`
+}
+
+// FieldError points at one invalid field of the request body.
+type FieldError struct {
+	Field   string `json:"field"`
 	Message string `json:"message"`
 }
 
 func Write(w http.ResponseWriter, status int, code Code, message string) {
+	write(w, status, APIError{Code: code, Message: message})
+}
+
+func write(w http.ResponseWriter, status int, e APIError) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(status)
-	_ = json.NewEncoder(w).Encode(APIError{Code: code, Message: message})
+	_ = json.NewEncoder(w).Encode(e)
 }
 
 func BadRequest(w http.ResponseWriter, message string) {
 	Write(w, http.StatusBadRequest, CodeInvalidInput, message)
 }
 
+// InvalidFields is BadRequest with the offending fields listed.
+func InvalidFields(w http.ResponseWriter, message string, fields []FieldError) {
+	write(w, http.StatusBadRequest, APIError{Code: CodeInvalidInput, Message: message, Fields: fields})
+}
+
 func NotFound(w http.ResponseWriter, message string) {
 	Write(w, http.StatusNotFound, CodeNotFound, message)
 }
 
+// Conflict reports a write that clashes with existing data, such as a
+// unique identifier already taken by another record.
+func Conflict(w http.ResponseWriter, message string) {
+	Write(w, http.StatusConflict, CodeConflict, message)
+}
+
+func NotAcceptable(w http.ResponseWriter, message string) {
+	Write(w, http.StatusNotAcceptable, CodeNotAcceptable, message)
+}
+
+func PayloadTooLarge(w http.ResponseWriter, message string) {
+	Write(w, http.StatusRequestEntityTooLarge, CodePayloadTooLarge, message)
+}
+
+func UnsupportedMediaType(w http.ResponseWriter, message string) {
+	Write(w, http.StatusUnsupportedMediaType, CodeUnsupportedMediaType, message)
+}
+
 func Internal(w http.ResponseWriter) {
 	Write(w, http.StatusInternalServerError, CodeInternal, "internal server error")
 }
@@ -0,0 +1,354 @@
+// Package attrschema validates product attributes against the subset of
+// JSON Schema that categories can attach to their products: an object of
+// scalar or array-of-scalar properties with the usual constraints.
+package attrschema
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"product-test/internal/models"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	MaxAttributes   = 100
+	MaxStringLength = 1000
+	MaxArrayLength  = 100
+	maxSchemaBytes  = 64 << 10
+)
+
+var (
+	namePattern   = regexp.MustCompile(`^[a-z][a-z0-9_]{0,63}$`)
+	numberPattern = regexp.MustCompile(`^-?(0|[1-9][0-9]*)(\.[0-9]+)?([eE][+-]?[0-9]+)?$`)
+)
+
+// ValidName reports whether name can be used as an attribute name:
+// lowercase letters, digits and '_', starting with a letter.
+func ValidName(name string) bool {
+	return namePattern.MatchString(name)
+}
+
+// IsNumber reports whether s is a JSON number literal, which is how
+// numeric filter values are written.
+func IsNumber(s string) bool {
+	return numberPattern.MatchString(s)
+}
+
+// Schema is a parsed attribute schema. Unsupported JSON Schema keywords are
+// rejected when parsing rather than silently ignored.
+type Schema struct {
+	Schema               string               `json:"$schema,omitempty"`
+	Title                string               `json:"title,omitempty"`
+	Description          string               `json:"description,omitempty"`
+	Type                 string               `json:"type"`
+	Properties           map[string]*Property `json:"properties"`
+	Required             []string             `json:"required,omitempty"`
+	AdditionalProperties *bool                `json:"additionalProperties,omitempty"`
+}
+
+// Property constrains one attribute, or the items of an array attribute.
+type Property struct {
+	Title            string    `json:"title,omitempty"`
+	Description      string    `json:"description,omitempty"`
+	Type             string    `json:"type"`
+	Enum             []any     `json:"enum,omitempty"`
+	Minimum          *float64  `json:"minimum,omitempty"`
+	Maximum          *float64  `json:"maximum,omitempty"`
+	ExclusiveMinimum *float64  `json:"exclusiveMinimum,omitempty"`
+	ExclusiveMaximum *float64  `json:"exclusiveMaximum,omitempty"`
+	MinLength        *int      `json:"minLength,omitempty"`
+	MaxLength        *int      `json:"maxLength,omitempty"`
+	Pattern          string    `json:"pattern,omitempty"`
+	Items            *Property `json:"items,omitempty"`
+	MinItems         *int      `json:"minItems,omitempty"`
+	MaxItems         *int      `json:"maxItems,omitempty"`
+	UniqueItems      bool      `json:"uniqueItems,omitempty"`
+
+	pattern *regexp.Regexp
+}
+
+// Parse decodes and checks a schema document.
+func Parse(data []byte) (*Schema, error) {
+	if len(data) > maxSchemaBytes {
+		return nil, fmt.Errorf("schema must not exceed %d bytes", maxSchemaBytes)
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	dec.DisallowUnknownFields()
+	var s Schema
+	if err := dec.Decode(&s); err != nil {
+		return nil, fmt.Errorf("invalid schema: %w", err)
+	}
+	if dec.More() {
+		return nil, fmt.Errorf("invalid schema: trailing data")
+	}
+	if s.Type != "object" {
+		return nil, fmt.Errorf(`schema type must be "object"`)
+	}
+	if len(s.Properties) > MaxAttributes {
+		return nil, fmt.Errorf("schema can define at most %d properties", MaxAttributes)
+	}
+	for name, p := range s.Properties {
+		if !ValidName(name) {
+			return nil, fmt.Errorf("property %q: names must be lowercase letters, digits or '_', starting with a letter", name)
+		}
+		if err := p.compile(false); err != nil {
+			return nil, fmt.Errorf("property %q: %w", name, err)
+		}
+	}
+	for _, name := range s.Required {
+		if _, ok := s.Properties[name]; !ok {
+			return nil, fmt.Errorf("required property %q is not defined", name)
+		}
+	}
+	return &s, nil
+}
+
+func (p *Property) compile(item bool) error {
+	if p == nil {
+		return fmt.Errorf("definition is missing")
+	}
+	switch p.Type {
+	case "string", "number", "integer", "boolean":
+	case "array":
+		if item {
+			return fmt.Errorf("items cannot be arrays")
+		}
+		if p.Items == nil {
+			return fmt.Errorf("arrays need items")
+		}
+		if err := p.Items.compile(true); err != nil {
+			return fmt.Errorf("items: %w", err)
+		}
+	default:
+		return fmt.Errorf("unsupported type %q", p.Type)
+	}
+	if p.Items != nil && p.Type != "array" {
+		return fmt.Errorf("items only apply to arrays")
+	}
+	if p.Type == "array" && len(p.Enum) > 0 {
+		return fmt.Errorf("enum does not apply to arrays; set it on items")
+	}
+	if p.Pattern != "" {
+		re, err := regexp.Compile(p.Pattern)
+		if err != nil {
+			return fmt.Errorf("invalid pattern: %w", err)
+		}
+		p.pattern = re
+	}
+	for _, v := range p.Enum {
+		if msg := p.checkType(v); msg != "" {
+			return fmt.Errorf("enum value %v: %s", v, msg)
+		}
+	}
+	return nil
+}
+
+// CheckShape checks what every product's attributes must satisfy, with or
+// without a schema: valid names, no nulls or nested objects, and bounded
+// sizes.
+func CheckShape(attrs models.Attributes) []models.FieldError {
+	var errs []models.FieldError
+	if len(attrs) > MaxAttributes {
+		return append(errs, fieldError("attributes", "at most %d attributes are allowed", MaxAttributes))
+	}
+	for _, name := range sortedKeys(attrs) {
+		field := "attributes." + name
+		if !ValidName(name) {
+			errs = append(errs, fieldError(field, "names must be lowercase letters, digits or '_', starting with a letter"))
+			continue
+		}
+		switch v := attrs[name].(type) {
+		case []any:
+			if len(v) > MaxArrayLength {
+				errs = append(errs, fieldError(field, "at most %d items are allowed", MaxArrayLength))
+				continue
+			}
+			for i, item := range v {
+				if msg := checkScalar(item); msg != "" {
+					errs = append(errs, fieldError(fmt.Sprintf("%s[%d]", field, i), "%s", msg))
+				}
+			}
+		default:
+			if msg := checkScalar(v); msg != "" {
+				errs = append(errs, fieldError(field, "%s", msg))
+			}
+		}
+	}
+	return errs
+}
+
+func checkScalar(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return "must not be null"
+	case string:
+		if utf8.RuneCountInString(v) > MaxStringLength {
+			return fmt.Sprintf("must be at most %d characters", MaxStringLength)
+		}
+	case bool, json.Number, float64:
+	default:
+		return "must be a string, number, boolean or an array of those"
+	}
+	return ""
+}
+
+// Validate checks attributes against the schema and returns one error per
+// offending field, ordered by field name.
+func (s *Schema) Validate(attrs models.Attributes) []models.FieldError {
+	var errs []models.FieldError
+	for _, name := range s.Required {
+		if _, ok := attrs[name]; !ok {
+			errs = append(errs, fieldError("attributes."+name, "is required"))
+		}
+	}
+	for _, name := range sortedKeys(attrs) {
+		field := "attributes." + name
+		p, ok := s.Properties[name]
+		if !ok {
+			if s.AdditionalProperties != nil && !*s.AdditionalProperties {
+				errs = append(errs, fieldError(field, "is not allowed"))
+			}
+			continue
+		}
+		errs = append(errs, p.validate(field, attrs[name])...)
+	}
+	slices.SortStableFunc(errs, func(a, b models.FieldError) int { return strings.Compare(a.Field, b.Field) })
+	return errs
+}
+
+func (p *Property) validate(field string, v any) []models.FieldError {
+	if msg := p.checkType(v); msg != "" {
+		return []models.FieldError{fieldError(field, "%s", msg)}
+	}
+	var errs []models.FieldError
+	fail := func(format string, args ...any) {
+		errs = append(errs, fieldError(field, format, args...))
+	}
+	if len(p.Enum) > 0 && !slices.ContainsFunc(p.Enum, func(e any) bool { return equal(e, v) }) {
+		fail("must be one of %s", enumList(p.Enum))
+	}
+	switch v := v.(type) {
+	case string:
+		n := utf8.RuneCountInString(v)
+		if p.MinLength != nil && n < *p.MinLength {
+			fail("must be at least %d characters", *p.MinLength)
+		}
+		if p.MaxLength != nil && n > *p.MaxLength {
+			fail("must be at most %d characters", *p.MaxLength)
+		}
+		if p.pattern != nil && !p.pattern.MatchString(v) {
+			fail("must match %s", p.Pattern)
+		}
+	case []any:
+		if p.MinItems != nil && len(v) < *p.MinItems {
+			fail("must have at least %d items", *p.MinItems)
+		}
+		if p.MaxItems != nil && len(v) > *p.MaxItems {
+			fail("must have at most %d items", *p.MaxItems)
+		}
+		for i, item := range v {
+			if p.UniqueItems && slices.ContainsFunc(v[:i], func(prev any) bool { return equal(prev, item) }) {
+				fail("items must be unique")
+				break
+			}
+		}
+		for i, item := range v {
+			errs = append(errs, p.Items.validate(fmt.Sprintf("%s[%d]", field, i), item)...)
+		}
+	default:
+		if f, ok := number(v); ok {
+			if p.Minimum != nil && f < *p.Minimum {
+				fail("must be at least %s", formatFloat(*p.Minimum))
+			}
+			if p.Maximum != nil && f > *p.Maximum {
+				fail("must be at most %s", formatFloat(*p.Maximum))
+			}
+			if p.ExclusiveMinimum != nil && f <= *p.ExclusiveMinimum {
+				fail("must be greater than %s", formatFloat(*p.ExclusiveMinimum))
+			}
+			if p.ExclusiveMaximum != nil && f >= *p.ExclusiveMaximum {
+				fail("must be less than %s", formatFloat(*p.ExclusiveMaximum))
+			}
+		}
+	}
+	return errs
+}
+
+// checkType returns why v does not have the property's type, or "".
+func (p *Property) checkType(v any) string {
+	ok := false
+	switch p.Type {
+	case "string":
+		_, ok = v.(string)
+	case "boolean":
+		_, ok = v.(bool)
+	case "number":
+		_, ok = number(v)
+	case "integer":
+		f, isNumber := number(v)
+		ok = isNumber && f == math.Trunc(f) && math.Abs(f) <= 1<<53
+	case "array":
+		_, ok = v.([]any)
+	}
+	if !ok {
+		return "must be " + article(p.Type)
+	}
+	return ""
+}
+
+func article(typ string) string {
+	switch typ {
+	case "integer", "array":
+		return "an " + typ
+	}
+	return "a " + typ
+}
+
+func number(v any) (float64, bool) {
+	switch v := v.(type) {
+	case json.Number:
+		f, err := v.Float64()
+		return f, err == nil
+	case float64:
+		return v, true
+	}
+	return 0, false
+}
+
+func equal(a, b any) bool {
+	fa, aNum := number(a)
+	fb, bNum := number(b)
+	if aNum || bNum {
+		return aNum && bNum && fa == fb
+	}
+	return a == b
+}
+
+func enumList(values []any) string {
+	b, _ := json.Marshal(values)
+	return string(b)
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
+
+func fieldError(field, format string, args ...any) models.FieldError {
+	return models.FieldError{Field: field, Message: fmt.Sprintf(format, args...)}
+}
+
+func sortedKeys(attrs models.Attributes) []string {
+	keys := make([]string, 0, len(attrs))
+	for k := range attrs {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	return keys
+}
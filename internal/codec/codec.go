@@ -0,0 +1,197 @@
+// Package codec maps media types to request decoders and response encoders.
+package codec
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ErrUnsupported is returned by an encoder that cannot represent the given value.
+var ErrUnsupported = errors.New("codec: value cannot be encoded in this format")
+
+// TrailingDataError is returned by decoders when a complete value is
+// followed by anything but whitespace.
+type TrailingDataError struct {
+	Offset int64
+}
+
+func (e *TrailingDataError) Error() string {
+	return fmt.Sprintf("unexpected data after the body at offset %d", e.Offset)
+}
+
+type Encoder interface {
+	Encode(w io.Writer, v any) error
+}
+
+// Decoder reads exactly one value from r. Decoders are strict: they reject
+// trailing data and, where the format allows it, unknown fields.
+type Decoder interface {
+	Decode(r io.Reader, v any) error
+}
+
+// Codec is a format with its media types; the first one is sent as Content-Type.
+// Either side may be nil for formats that are only read or only written.
+type Codec struct {
+	MediaTypes []string
+	Encoder    Encoder
+	Decoder    Decoder
+}
+
+func (c *Codec) ContentType() string {
+	return c.MediaTypes[0]
+}
+
+// Registry holds codecs in order of server preference.
+type Registry struct {
+	codecs []*Codec
+}
+
+func NewRegistry(codecs ...*Codec) *Registry {
+	return &Registry{codecs: codecs}
+}
+
+// Default returns the registry used by the HTTP handlers. JSON comes first
+// so it is picked for requests without an Accept header.
+func Default() *Registry {
+	return NewRegistry(
+		&Codec{MediaTypes: []string{"application/json"}, Encoder: JSON{}, Decoder: JSON{}},
+		&Codec{MediaTypes: []string{"application/xml", "text/xml"}, Encoder: XML{}, Decoder: XML{}},
+		&Codec{MediaTypes: []string{"application/msgpack", "application/x-msgpack", "application/vnd.msgpack"}, Encoder: MessagePack{}, Decoder: MessagePack{}},
+		&Codec{MediaTypes: []string{"text/csv"}, Encoder: CSV{}},
+	)
+}
+
+// Encodable lists the content types that can be requested via Accept.
+func (reg *Registry) Encodable() []string {
+	var types []string
+	for _, c := range reg.codecs {
+		if c.Encoder != nil {
+			types = append(types, c.ContentType())
+		}
+	}
+	return types
+}
+
+// Decodable lists the content types accepted in request bodies.
+func (reg *Registry) Decodable() []string {
+	var types []string
+	for _, c := range reg.codecs {
+		if c.Decoder != nil {
+			types = append(types, c.ContentType())
+		}
+	}
+	return types
+}
+
+// ForContentType returns the codec that can decode a body of the given
+// Content-Type. An empty header is treated as the first decodable format.
+func (reg *Registry) ForContentType(header string) (*Codec, bool) {
+	if strings.TrimSpace(header) == "" {
+		for _, c := range reg.codecs {
+			if c.Decoder != nil {
+				return c, true
+			}
+		}
+		return nil, false
+	}
+	mt, _, err := mime.ParseMediaType(header)
+	if err != nil {
+		return nil, false
+	}
+	for _, c := range reg.codecs {
+		if c.Decoder == nil {
+			continue
+		}
+		for _, t := range c.MediaTypes {
+			if t == mt {
+				return c, true
+			}
+		}
+	}
+	return nil, false
+}
+
+// Negotiate picks an encoder for an Accept header following RFC 9110:
+// every media type gets the quality of the most specific range matching
+// it, and ties are broken by registry order.
+func (reg *Registry) Negotiate(accept string) (*Codec, bool) {
+	if strings.TrimSpace(accept) == "" {
+		accept = "*/*"
+	}
+	ranges := parseAccept(accept)
+
+	var (
+		best  *Codec
+		bestQ float64
+	)
+	for _, c := range reg.codecs {
+		if c.Encoder == nil {
+			continue
+		}
+		q := 0.0
+		for _, t := range c.MediaTypes {
+			if tq := quality(ranges, t); tq > q {
+				q = tq
+			}
+		}
+		if q > bestQ {
+			best, bestQ = c, q
+		}
+	}
+	return best, best != nil
+}
+
+type mediaRange struct {
+	typ, subtype string
+	q            float64
+}
+
+func (m mediaRange) specificity() int {
+	switch {
+	case m.typ == "*":
+		return 0
+	case m.subtype == "*":
+		return 1
+	}
+	return 2
+}
+
+func parseAccept(accept string) []mediaRange {
+	var ranges []mediaRange
+	for _, part := range strings.Split(accept, ",") {
+		mt, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		typ, subtype, ok := strings.Cut(mt, "/")
+		if !ok {
+			continue
+		}
+		q := 1.0
+		if v, ok := params["q"]; ok {
+			if f, err := strconv.ParseFloat(v, 64); err == nil && f >= 0 && f <= 1 {
+				q = f
+			}
+		}
+		ranges = append(ranges, mediaRange{typ: typ, subtype: subtype, q: q})
+	}
+	sort.SliceStable(ranges, func(i, j int) bool {
+		return ranges[i].specificity() > ranges[j].specificity()
+	})
+	return ranges
+}
+
+func quality(ranges []mediaRange, mediaType string) float64 {
+	typ, subtype, _ := strings.Cut(mediaType, "/")
+	for _, m := range ranges {
+		if (m.typ == "*" || m.typ == typ) && (m.subtype == "*" || m.subtype == subtype) {
+			return m.q
+		}
+	}
+	return 0
+}
@@ -0,0 +1,57 @@
+package codec
+
+import (
+	"encoding/csv"
+	"io"
+	"reflect"
+)
+
+// CSVRecorder is implemented by values that can be written as a CSV row.
+type CSVRecorder interface {
+	CSVHeader() []string
+	CSVRecord() []string
+}
+
+// CSV encodes a CSVRecorder, or a slice of them, as a header line followed
+// by one row per value. Anything else is rejected with ErrUnsupported.
+type CSV struct{}
+
+func (CSV) Encode(w io.Writer, v any) error {
+	var records []CSVRecorder
+	if rec, ok := v.(CSVRecorder); ok {
+		records = append(records, rec)
+	} else {
+		rv := reflect.ValueOf(v)
+		if rv.Kind() != reflect.Slice {
+			return ErrUnsupported
+		}
+		for i := 0; i < rv.Len(); i++ {
+			rec, ok := rv.Index(i).Interface().(CSVRecorder)
+			if !ok {
+				return ErrUnsupported
+			}
+			records = append(records, rec)
+		}
+		if len(records) == 0 {
+			if rec, ok := reflect.Zero(rv.Type().Elem()).Interface().(CSVRecorder); ok {
+				return writeCSV(w, rec.CSVHeader(), nil)
+			}
+			return ErrUnsupported
+		}
+	}
+	return writeCSV(w, records[0].CSVHeader(), records)
+}
+
+func writeCSV(w io.Writer, header []string, records []CSVRecorder) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+	for _, rec := range records {
+		if err := cw.Write(rec.CSVRecord()); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
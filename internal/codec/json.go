@@ -0,0 +1,31 @@
+package codec
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+)
+
+type JSON struct{}
+
+func (JSON) Encode(w io.Writer, v any) error {
+	return json.NewEncoder(w).Encode(v)
+}
+
+func (JSON) Decode(r io.Reader, v any) error {
+	dec := json.NewDecoder(r)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		return err
+	}
+	offset := dec.InputOffset()
+	_, err := dec.Token()
+	var syntaxErr *json.SyntaxError
+	switch {
+	case err == io.EOF:
+		return nil
+	case err == nil || errors.As(err, &syntaxErr):
+		return &TrailingDataError{Offset: offset}
+	}
+	return err
+}
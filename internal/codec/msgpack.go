@@ -0,0 +1,122 @@
+package codec
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/vmihailenco/msgpack/v5"
+	"github.com/vmihailenco/msgpack/v5/msgpcode"
+)
+
+// MessagePack encodes values with the msgpack library, reading the json
+// struct tags so that both formats share field names. Types with a
+// custom JSON shape implement msgpack.CustomEncoder and CustomDecoder to
+// keep it; times are msgpack timestamps.
+type MessagePack struct{}
+
+func (MessagePack) Encode(w io.Writer, v any) error {
+	bw := bufio.NewWriter(w)
+	enc := msgpack.NewEncoder(bw)
+	enc.SetCustomStructTag("json")
+	enc.SetSortMapKeys(true)
+	enc.UseCompactInts(true)
+	if err := enc.Encode(v); err != nil {
+		return err
+	}
+	return bw.Flush()
+}
+
+func (MessagePack) Decode(r io.Reader, v any) error {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	// The document is checked before it is decoded, as the library
+	// bounds neither nesting nor the types it accepts.
+	br := bytes.NewReader(data)
+	if err := checkMsgpack(msgpack.NewDecoder(br), 0); err != nil {
+		return err
+	}
+	if br.Len() > 0 {
+		return &TrailingDataError{Offset: int64(len(data) - br.Len())}
+	}
+	dec := msgpack.NewDecoder(bytes.NewReader(data))
+	dec.SetCustomStructTag("json")
+	dec.DisallowUnknownFields(true)
+	dec.UseLooseInterfaceDecoding(true)
+	return unexpectedEOF(dec.Decode(v))
+}
+
+const maxMsgpackDepth = 64
+
+var errMsgpackDepth = errors.New("msgpack: document nested too deeply")
+
+// timestampExt is the msgpack extension type of timestamps.
+const timestampExt = -1
+
+// checkMsgpack walks one value and fails on what JSON could not carry:
+// map keys other than strings and extension types other than
+// timestamps. Nesting is bounded by maxMsgpackDepth.
+func checkMsgpack(dec *msgpack.Decoder, depth int) error {
+	if depth > maxMsgpackDepth {
+		return errMsgpackDepth
+	}
+	c, err := dec.PeekCode()
+	if err != nil {
+		return unexpectedEOF(err)
+	}
+	switch {
+	case msgpcode.IsFixedArray(c) || c == msgpcode.Array16 || c == msgpcode.Array32:
+		n, err := dec.DecodeArrayLen()
+		if err != nil {
+			return unexpectedEOF(err)
+		}
+		for i := 0; i < n; i++ {
+			if err := checkMsgpack(dec, depth+1); err != nil {
+				return err
+			}
+		}
+		return nil
+	case msgpcode.IsFixedMap(c) || c == msgpcode.Map16 || c == msgpcode.Map32:
+		n, err := dec.DecodeMapLen()
+		if err != nil {
+			return unexpectedEOF(err)
+		}
+		for i := 0; i < n; i++ {
+			k, err := dec.PeekCode()
+			if err != nil {
+				return unexpectedEOF(err)
+			}
+			if !msgpcode.IsString(k) {
+				return fmt.Errorf("msgpack: map key must be a string, got type byte 0x%02x", k)
+			}
+			if err := dec.Skip(); err != nil {
+				return unexpectedEOF(err)
+			}
+			if err := checkMsgpack(dec, depth+1); err != nil {
+				return err
+			}
+		}
+		return nil
+	case msgpcode.IsExt(c):
+		id, n, err := dec.DecodeExtHeader()
+		if err != nil {
+			return unexpectedEOF(err)
+		}
+		if id != timestampExt || n > 12 {
+			return fmt.Errorf("msgpack: unsupported extension type %d", id)
+		}
+		return unexpectedEOF(dec.ReadFull(make([]byte, n)))
+	}
+	return unexpectedEOF(dec.Skip())
+}
+
+func unexpectedEOF(err error) error {
+	if errors.Is(err, io.EOF) {
+		return fmt.Errorf("msgpack: %w", io.ErrUnexpectedEOF)
+	}
+	return err
+}
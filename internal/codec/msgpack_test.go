@@ -0,0 +1,248 @@
+package codec
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"math"
+	"product-test/internal/models"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+func roundTrip[T any](t *testing.T, in T) T {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := (MessagePack{}).Encode(&buf, in); err != nil {
+		t.Fatalf("Encode(%v): %v", in, err)
+	}
+	var out T
+	if err := (MessagePack{}).Decode(&buf, &out); err != nil {
+		t.Fatalf("Decode of %v: %v", in, err)
+	}
+	return out
+}
+
+func TestMessagePackInts(t *testing.T) {
+	tests := []struct {
+		n    int64
+		wire []byte
+	}{
+		{0, []byte{0x00}},
+		{127, []byte{0x7f}},
+		{128, []byte{0xcc, 0x80}},
+		{-1, []byte{0xff}},
+		{-32, []byte{0xe0}},
+		{-33, []byte{0xd0, 0xdf}},
+		{math.MinInt8, []byte{0xd0, 0x80}},
+		{math.MaxUint16, []byte{0xcd, 0xff, 0xff}},
+		{math.MinInt16, []byte{0xd1, 0x80, 0x00}},
+		{math.MaxInt32, []byte{0xce, 0x7f, 0xff, 0xff, 0xff}},
+		{math.MinInt32, []byte{0xd2, 0x80, 0x00, 0x00, 0x00}},
+		{math.MaxInt64, []byte{0xcf, 0x7f, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff}},
+		{math.MinInt64, []byte{0xd3, 0x80, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00}},
+	}
+	for _, tt := range tests {
+		var buf bytes.Buffer
+		if err := (MessagePack{}).Encode(&buf, tt.n); err != nil {
+			t.Fatalf("Encode(%d): %v", tt.n, err)
+		}
+		if !bytes.Equal(buf.Bytes(), tt.wire) {
+			t.Errorf("Encode(%d) = % x, want % x", tt.n, buf.Bytes(), tt.wire)
+		}
+		if got := roundTrip(t, tt.n); got != tt.n {
+			t.Errorf("round trip of %d = %d", tt.n, got)
+		}
+	}
+	if got := roundTrip(t, uint64(math.MaxUint64)); got != math.MaxUint64 {
+		t.Errorf("round trip of MaxUint64 = %d", got)
+	}
+}
+
+func TestMessagePackFloats(t *testing.T) {
+	for _, f := range []float64{0.5, -1.25, 19.99, math.Pi, 1e300, -math.SmallestNonzeroFloat64} {
+		if got := roundTrip(t, f); got != f {
+			t.Errorf("round trip of %v = %v", f, got)
+		}
+	}
+	var buf bytes.Buffer
+	if err := (MessagePack{}).Encode(&buf, 1.5); err != nil {
+		t.Fatal(err)
+	}
+	if want := []byte{0xcb, 0x3f, 0xf8, 0, 0, 0, 0, 0, 0}; !bytes.Equal(buf.Bytes(), want) {
+		t.Errorf("Encode(1.5) = % x, want % x", buf.Bytes(), want)
+	}
+}
+
+func TestMessagePackNil(t *testing.T) {
+	var buf bytes.Buffer
+	if err := (MessagePack{}).Encode(&buf, nil); err != nil {
+		t.Fatal(err)
+	}
+	if want := []byte{0xc0}; !bytes.Equal(buf.Bytes(), want) {
+		t.Errorf("Encode(nil) = % x, want % x", buf.Bytes(), want)
+	}
+	type item struct {
+		Name *string `json:"name"`
+	}
+	if got := roundTrip(t, item{}); got.Name != nil {
+		t.Errorf("round trip of a nil field = %q", *got.Name)
+	}
+}
+
+func TestMessagePackNestedMaps(t *testing.T) {
+	type line struct {
+		ProductID int               `json:"product_id"`
+		Tags      []string          `json:"tags"`
+		Attrs     map[string]any    `json:"attrs"`
+		Labels    map[string]string `json:"labels,omitempty"`
+	}
+	type order struct {
+		ID    int64           `json:"id"`
+		Lines []line          `json:"lines"`
+		Meta  map[string]line `json:"meta"`
+	}
+	in := order{
+		ID: 7,
+		Lines: []line{
+			{ProductID: 1, Tags: []string{"a", "b"}, Attrs: map[string]any{"size": "M", "nested": map[string]any{"ok": true, "none": nil}}},
+			{ProductID: 2, Tags: []string{}, Attrs: map[string]any{}, Labels: map[string]string{"x": "y"}},
+		},
+		Meta: map[string]line{"first": {ProductID: 3, Tags: []string{"c"}, Attrs: map[string]any{"list": []any{"z", false}}}},
+	}
+	if got := roundTrip(t, in); !reflect.DeepEqual(got, in) {
+		t.Errorf("round trip = %+v, want %+v", got, in)
+	}
+
+	// Another implementation reads the same document.
+	var buf bytes.Buffer
+	if err := (MessagePack{}).Encode(&buf, in); err != nil {
+		t.Fatal(err)
+	}
+	var generic map[string]any
+	if err := msgpack.Unmarshal(buf.Bytes(), &generic); err != nil {
+		t.Fatalf("msgpack.Unmarshal: %v", err)
+	}
+	meta := generic["meta"].(map[string]any)["first"].(map[string]any)
+	if got := meta["attrs"].(map[string]any)["list"]; !reflect.DeepEqual(got, []any{"z", false}) {
+		t.Errorf("meta.first.attrs.list = %#v", got)
+	}
+}
+
+func TestMessagePackModels(t *testing.T) {
+	deleted := time.Date(2026, 3, 1, 12, 30, 0, 0, time.UTC)
+	in := models.Product{
+		ID:         5,
+		Name:       "Кеды",
+		Price:      models.Money{Amount: 1234, Currency: "EUR"},
+		Attributes: models.Attributes{"size": json.Number("42"), "weight": json.Number("0.75"), "colors": []any{"red", json.Number("7")}},
+		SKU:        "KD-42",
+		DeletedAt:  &deleted,
+	}
+	got := roundTrip(t, in)
+	// Timestamps carry no zone and come back in the local one.
+	if got.DeletedAt == nil || !got.DeletedAt.Equal(deleted) {
+		t.Errorf("deleted_at = %v, want %v", got.DeletedAt, deleted)
+	}
+	got.DeletedAt = &deleted
+	if !reflect.DeepEqual(got, in) {
+		t.Errorf("round trip = %+v, want %+v", got, in)
+	}
+
+	// Money and attributes keep their JSON shape.
+	var buf bytes.Buffer
+	if err := (MessagePack{}).Encode(&buf, in); err != nil {
+		t.Fatal(err)
+	}
+	var generic map[string]any
+	if err := msgpack.Unmarshal(buf.Bytes(), &generic); err != nil {
+		t.Fatalf("msgpack.Unmarshal: %v", err)
+	}
+	if want := map[string]any{"amount": "12.34", "currency": "EUR"}; !reflect.DeepEqual(generic["price"], want) {
+		t.Errorf("price = %#v, want %#v", generic["price"], want)
+	}
+	attrs := generic["attributes"].(map[string]any)
+	if attrs["size"] != int8(42) || attrs["weight"] != 0.75 {
+		t.Errorf("attributes = %#v", attrs)
+	}
+
+	view := models.ProductView{Product: &in, Fields: []string{"sku", "price"}}
+	buf.Reset()
+	if err := (MessagePack{}).Encode(&buf, view); err != nil {
+		t.Fatal(err)
+	}
+	var fields map[string]any
+	if err := msgpack.Unmarshal(buf.Bytes(), &fields); err != nil {
+		t.Fatalf("msgpack.Unmarshal: %v", err)
+	}
+	if len(fields) != 2 || fields["sku"] != "KD-42" || fields["price"] == nil {
+		t.Errorf("view = %#v", fields)
+	}
+
+	for name, doc := range map[string]map[string]any{
+		"unknown field":  {"name": "x", "colour": "red"},
+		"numeric amount": {"price": map[string]any{"amount": 12.34, "currency": "EUR"}},
+	} {
+		raw, err := msgpack.Marshal(doc)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var p models.Product
+		if err := (MessagePack{}).Decode(bytes.NewReader(raw), &p); err == nil {
+			t.Errorf("Decode of a product with %s succeeded", name)
+		}
+	}
+}
+
+func TestMessagePackLargeStrings(t *testing.T) {
+	for _, n := range []int{0, 31, 32, math.MaxUint8, math.MaxUint8 + 1, math.MaxUint16, math.MaxUint16 + 1, 1 << 20} {
+		s := strings.Repeat("я", n/2) + strings.Repeat("x", n%2)
+		if got := roundTrip(t, s); got != s {
+			t.Errorf("round trip of a %d-byte string lost data (got %d bytes)", len(s), len(got))
+		}
+	}
+}
+
+func TestMessagePackDecodeErrors(t *testing.T) {
+	deep := append(bytes.Repeat([]byte{0x91}, maxMsgpackDepth+2), 0xc0)
+	tests := []struct {
+		name string
+		in   []byte
+		ok   func(error) bool
+	}{
+		{"empty", nil, func(err error) bool { return errors.Is(err, io.ErrUnexpectedEOF) }},
+		{"truncated string", []byte{0xa5, 'a', 'b'}, func(err error) bool { return errors.Is(err, io.ErrUnexpectedEOF) }},
+		{"truncated map", []byte{0x82, 0xa1, 'a', 0x01}, func(err error) bool { return errors.Is(err, io.ErrUnexpectedEOF) }},
+		{"trailing data", []byte{0x01, 0x02}, func(err error) bool {
+			var trailing *TrailingDataError
+			return errors.As(err, &trailing) && trailing.Offset == 1
+		}},
+		{"too deep", deep, func(err error) bool { return errors.Is(err, errMsgpackDepth) }},
+		{"ext type", []byte{0xd4, 0x01, 0x00}, func(err error) bool { return err != nil }},
+		{"integer key", []byte{0x81, 0x01, 0x02}, func(err error) bool { return err != nil }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var v any
+			err := (MessagePack{}).Decode(bytes.NewReader(tt.in), &v)
+			if !tt.ok(err) {
+				t.Errorf("Decode(% x) = %v", tt.in, err)
+			}
+		})
+	}
+}
+
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) { return 0, errors.New("disk full") }
+
+func TestMessagePackEncodeWriteError(t *testing.T) {
+	if err := (MessagePack{}).Encode(failingWriter{}, strings.Repeat("x", 1<<16)); err == nil {
+		t.Error("Encode to a failing writer succeeded")
+	}
+}
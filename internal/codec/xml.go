@@ -0,0 +1,71 @@
+package codec
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"io"
+	"reflect"
+)
+
+// XML encodes values with encoding/xml. Slices are wrapped in an <items>
+// element so that the document always has a single root.
+type XML struct{}
+
+func (XML) Encode(w io.Writer, v any) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	rv := reflect.ValueOf(v)
+	if rv.Kind() == reflect.Slice || rv.Kind() == reflect.Array {
+		root := xml.StartElement{Name: xml.Name{Local: "items"}}
+		if err := enc.EncodeToken(root); err != nil {
+			return err
+		}
+		for i := 0; i < rv.Len(); i++ {
+			if err := enc.Encode(rv.Index(i).Interface()); err != nil {
+				return err
+			}
+		}
+		if err := enc.EncodeToken(root.End()); err != nil {
+			return err
+		}
+		return enc.Close()
+	}
+	if err := enc.Encode(v); err != nil {
+		return err
+	}
+	return enc.Close()
+}
+
+// Decode reads a single XML document. Unlike JSON, unknown elements are
+// ignored because encoding/xml cannot report them.
+func (XML) Decode(r io.Reader, v any) error {
+	dec := xml.NewDecoder(r)
+	if err := dec.Decode(v); err != nil {
+		return err
+	}
+	for {
+		offset := dec.InputOffset()
+		tok, err := dec.Token()
+		var syntaxErr *xml.SyntaxError
+		switch {
+		case err == io.EOF:
+			return nil
+		case errors.As(err, &syntaxErr):
+			return &TrailingDataError{Offset: offset}
+		case err != nil:
+			return err
+		}
+		switch t := tok.(type) {
+		case xml.Comment, xml.ProcInst:
+			continue
+		case xml.CharData:
+			if len(bytes.TrimSpace(t)) == 0 {
+				continue
+			}
+		}
+		return &TrailingDataError{Offset: offset}
+	}
+}
@@ -2,10 +2,13 @@ package config
 
 import (
 	"errors"
+	"fmt"
 	"log"
+	"net/url"
 	"os"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 )
@@ -23,6 +26,29 @@ type Config struct {
 	DBHost     string
 	DBPort     string
 	ServerPort string
+
+	// TrashRetention is how long soft-deleted products are kept before the
+	// purge job, which runs every PurgeInterval, removes them.
+	TrashRetention time.Duration
+	PurgeInterval  time.Duration
+
+	// ScheduleInterval is how often price schedules are activated, expired
+	// and applied.
+	ScheduleInterval time.Duration
+
+	// ReservationTTL is how long reservations hold stock; stale ones are
+	// marked expired every ReservationInterval.
+	ReservationTTL      time.Duration
+	ReservationInterval time.Duration
+
+	// AlertInterval is how often stock is checked for low-stock alerts,
+	// which are also posted to AlertWebhookURL when it is set.
+	AlertInterval   time.Duration
+	AlertWebhookURL string
+
+	// CostMethod is how receiving purchase orders changes cost prices:
+	// weighted_average or last_cost.
+	CostMethod string
 }
 
 var ErrInvalidConfig = errors.New("invalid config")
@@ -35,6 +61,28 @@ func New() (*Config, error) {
 		DBHost:     getEnv("DB_HOST", "localhost"),
 		DBPort:     getEnv("DB_PORT", "5432"),
 		ServerPort: getEnv("SERVER_PORT", ":8081"),
+
+		AlertWebhookURL: getEnv("ALERT_WEBHOOK_URL", ""),
+		CostMethod:      getEnv("COST_METHOD", "weighted_average"),
+	}
+	var err error
+	if cfg.TrashRetention, err = getDuration("TRASH_RETENTION", 30*24*time.Hour); err != nil {
+		return nil, err
+	}
+	if cfg.PurgeInterval, err = getDuration("PURGE_INTERVAL", time.Hour); err != nil {
+		return nil, err
+	}
+	if cfg.ScheduleInterval, err = getDuration("SCHEDULE_INTERVAL", time.Minute); err != nil {
+		return nil, err
+	}
+	if cfg.ReservationTTL, err = getDuration("RESERVATION_TTL", 15*time.Minute); err != nil {
+		return nil, err
+	}
+	if cfg.ReservationInterval, err = getDuration("RESERVATION_INTERVAL", time.Minute); err != nil {
+		return nil, err
+	}
+	if cfg.AlertInterval, err = getDuration("ALERT_INTERVAL", 5*time.Minute); err != nil {
+		return nil, err
 	}
 	if err := cfg.Validate(); err != nil {
 		return nil, err
@@ -52,6 +100,33 @@ func (c *Config) Validate() error {
 	if _, err := strconv.Atoi(c.DBPort); err != nil {
 		return errors.Join(ErrInvalidConfig, errors.New("DB_PORT must be a number"))
 	}
+	if c.TrashRetention <= 0 {
+		return errors.Join(ErrInvalidConfig, errors.New("TRASH_RETENTION must be positive"))
+	}
+	if c.PurgeInterval <= 0 {
+		return errors.Join(ErrInvalidConfig, errors.New("PURGE_INTERVAL must be positive"))
+	}
+	if c.ScheduleInterval <= 0 {
+		return errors.Join(ErrInvalidConfig, errors.New("SCHEDULE_INTERVAL must be positive"))
+	}
+	if c.ReservationTTL <= 0 {
+		return errors.Join(ErrInvalidConfig, errors.New("RESERVATION_TTL must be positive"))
+	}
+	if c.ReservationInterval <= 0 {
+		return errors.Join(ErrInvalidConfig, errors.New("RESERVATION_INTERVAL must be positive"))
+	}
+	if c.AlertInterval <= 0 {
+		return errors.Join(ErrInvalidConfig, errors.New("ALERT_INTERVAL must be positive"))
+	}
+	if c.AlertWebhookURL != "" {
+		u, err := url.Parse(c.AlertWebhookURL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return errors.Join(ErrInvalidConfig, errors.New("ALERT_WEBHOOK_URL must be an http or https URL"))
+		}
+	}
+	if c.CostMethod != "weighted_average" && c.CostMethod != "last_cost" {
+		return errors.Join(ErrInvalidConfig, errors.New("COST_METHOD must be weighted_average or last_cost"))
+	}
 	if c.ServerPort != "" && !strings.HasPrefix(c.ServerPort, ":") {
 		c.ServerPort = ":" + c.ServerPort
 	}
@@ -64,3 +139,15 @@ func getEnv(key, defaultVal string) string {
 	}
 	return defaultVal
 }
+
+func getDuration(key string, defaultVal time.Duration) (time.Duration, error) {
+	value, exists := os.LookupEnv(key)
+	if !exists || value == "" {
+		return defaultVal, nil
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, errors.Join(ErrInvalidConfig, fmt.Errorf("%s must be a duration such as 720h: %w", key, err))
+	}
+	return d, nil
+}
@@ -4,7 +4,11 @@ import (
 	"database/sql"
 	"embed"
 	"fmt"
+	"io/fs"
 	"log"
+	"path"
+	"sort"
+	"strings"
 
 	_ "github.com/lib/pq"
 )
@@ -35,14 +39,56 @@ func InitDB(user, password, dbname, host, port string) (*sql.DB, error) {
 	return db, nil
 }
 
+// applyMigrations runs the embedded migrations that have not been applied
+// yet, in file name order, each in its own transaction. An advisory lock
+// keeps concurrently starting processes from applying the same file twice.
 func applyMigrations(db *sql.DB) error {
-	sqlBytes, err := migrationsFS.ReadFile("migrations/init.sql")
+	_, err := db.Exec(`CREATE TABLE IF NOT EXISTS schema_migrations (
+		version TEXT PRIMARY KEY,
+		applied_at TIMESTAMPTZ NOT NULL DEFAULT now()
+	)`)
 	if err != nil {
-		return fmt.Errorf("read migration: %w", err)
+		return fmt.Errorf("create schema_migrations: %w", err)
 	}
-	_, err = db.Exec(string(sqlBytes))
+	files, err := fs.Glob(migrationsFS, "migrations/*.sql")
 	if err != nil {
-		return fmt.Errorf("apply migration: %w", err)
+		return fmt.Errorf("list migrations: %w", err)
+	}
+	sort.Strings(files)
+	for _, file := range files {
+		if err := applyMigration(db, file); err != nil {
+			return err
+		}
 	}
 	return nil
 }
+
+func applyMigration(db *sql.DB, file string) error {
+	version := strings.TrimSuffix(path.Base(file), ".sql")
+	sqlBytes, err := migrationsFS.ReadFile(file)
+	if err != nil {
+		return fmt.Errorf("read migration %s: %w", version, err)
+	}
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if _, err := tx.Exec(`SELECT pg_advisory_xact_lock(hashtext('schema_migrations'))`); err != nil {
+		return fmt.Errorf("lock migrations: %w", err)
+	}
+	var applied bool
+	if err := tx.QueryRow(`SELECT EXISTS (SELECT 1 FROM schema_migrations WHERE version = $1)`, version).Scan(&applied); err != nil {
+		return fmt.Errorf("check migration %s: %w", version, err)
+	}
+	if applied {
+		return nil
+	}
+	if _, err := tx.Exec(string(sqlBytes)); err != nil {
+		return fmt.Errorf("apply migration %s: %w", version, err)
+	}
+	if _, err := tx.Exec(`INSERT INTO schema_migrations (version) VALUES ($1)`, version); err != nil {
+		return fmt.Errorf("record migration %s: %w", version, err)
+	}
+	return tx.Commit()
+}
@@ -1,5 +1,3 @@
-DROP TABLE IF EXISTS products;
-
 CREATE TABLE IF NOT EXISTS products (
     id SERIAL PRIMARY KEY,
     name TEXT NOT NULL,
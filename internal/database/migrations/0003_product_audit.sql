@@ -0,0 +1,25 @@
+CREATE TABLE IF NOT EXISTS product_audit (
+    id BIGSERIAL PRIMARY KEY,
+    product_id INT NOT NULL,
+    action TEXT NOT NULL,
+    actor TEXT NOT NULL,
+    request_id TEXT,
+    changes JSONB NOT NULL DEFAULT '[]',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS product_audit_product_idx ON product_audit (product_id, id);
+CREATE INDEX IF NOT EXISTS product_audit_actor_idx ON product_audit (actor, id);
+CREATE INDEX IF NOT EXISTS product_audit_created_at_idx ON product_audit (created_at);
+
+-- The audit log is append-only: rows can be inserted but never changed.
+CREATE OR REPLACE FUNCTION product_audit_append_only() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'product_audit is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS product_audit_append_only ON product_audit;
+CREATE TRIGGER product_audit_append_only
+    BEFORE UPDATE OR DELETE ON product_audit
+    FOR EACH ROW EXECUTE FUNCTION product_audit_append_only();
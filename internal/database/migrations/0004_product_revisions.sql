@@ -0,0 +1,19 @@
+CREATE TABLE IF NOT EXISTS product_revisions (
+    product_id INT NOT NULL,
+    revision INT NOT NULL,
+    snapshot JSONB NOT NULL,
+    actor TEXT NOT NULL,
+    request_id TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (product_id, revision)
+);
+
+CREATE INDEX IF NOT EXISTS product_revisions_as_of_idx ON product_revisions (product_id, created_at);
+
+-- Existing products start their history with their current state.
+INSERT INTO product_revisions (product_id, revision, snapshot, actor)
+SELECT id, 1, jsonb_strip_nulls(jsonb_build_object(
+        'id', id, 'name', name, 'description', COALESCE(description, ''), 'price', price, 'deleted_at', deleted_at)),
+    'system'
+FROM products
+ON CONFLICT DO NOTHING;
@@ -0,0 +1,18 @@
+CREATE TABLE IF NOT EXISTS price_history (
+    id BIGSERIAL PRIMARY KEY,
+    product_id INT NOT NULL,
+    price INT NOT NULL,
+    effective_from TIMESTAMPTZ NOT NULL,
+    effective_to TIMESTAMPTZ,
+    CHECK (effective_to IS NULL OR effective_to > effective_from)
+);
+
+CREATE INDEX IF NOT EXISTS price_history_product_idx ON price_history (product_id, effective_from);
+-- At most one open period per product.
+CREATE UNIQUE INDEX IF NOT EXISTS price_history_current_idx ON price_history (product_id) WHERE effective_to IS NULL;
+
+-- Existing products get their current price as the first period.
+INSERT INTO price_history (product_id, price, effective_from)
+SELECT p.id, p.price, now()
+FROM products p
+WHERE NOT EXISTS (SELECT 1 FROM price_history h WHERE h.product_id = p.id);
@@ -0,0 +1,19 @@
+CREATE TABLE IF NOT EXISTS price_schedules (
+    id BIGSERIAL PRIMARY KEY,
+    product_id INT NOT NULL REFERENCES products (id) ON DELETE CASCADE,
+    price INT NOT NULL CHECK (price >= 0),
+    starts_at TIMESTAMPTZ NOT NULL,
+    -- NULL for a permanent price change, set for a time-boxed sale.
+    ends_at TIMESTAMPTZ,
+    status TEXT NOT NULL DEFAULT 'scheduled'
+        CHECK (status IN ('scheduled', 'active', 'expired', 'applied', 'cancelled')),
+    note TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    activated_at TIMESTAMPTZ,
+    finished_at TIMESTAMPTZ,
+    CHECK (ends_at IS NULL OR ends_at > starts_at)
+);
+
+CREATE INDEX IF NOT EXISTS price_schedules_product_idx ON price_schedules (product_id, starts_at);
+CREATE INDEX IF NOT EXISTS price_schedules_pending_idx ON price_schedules (starts_at)
+    WHERE status IN ('scheduled', 'active');
@@ -0,0 +1,25 @@
+-- Prices become amounts in the minor unit of an ISO 4217 currency. Prices
+-- stored so far had no currency and no fraction; they are taken to be whole
+-- euros and converted to cents.
+ALTER TABLE products
+    ALTER COLUMN price TYPE BIGINT USING price::BIGINT * 100,
+    ADD COLUMN IF NOT EXISTS currency TEXT NOT NULL DEFAULT 'EUR' CHECK (currency ~ '^[A-Z]{3}$');
+ALTER TABLE products ALTER COLUMN currency DROP DEFAULT;
+
+ALTER TABLE price_history
+    ALTER COLUMN price TYPE BIGINT USING price::BIGINT * 100,
+    ADD COLUMN IF NOT EXISTS currency TEXT NOT NULL DEFAULT 'EUR' CHECK (currency ~ '^[A-Z]{3}$');
+ALTER TABLE price_history ALTER COLUMN currency DROP DEFAULT;
+
+ALTER TABLE price_schedules
+    ALTER COLUMN price TYPE BIGINT USING price::BIGINT * 100,
+    ADD COLUMN IF NOT EXISTS currency TEXT NOT NULL DEFAULT 'EUR' CHECK (currency ~ '^[A-Z]{3}$');
+ALTER TABLE price_schedules ALTER COLUMN currency DROP DEFAULT;
+
+-- Revision snapshots are decoded into the product model, so their prices
+-- take the new shape as well.
+UPDATE product_revisions
+SET snapshot = jsonb_set(snapshot, '{price}', jsonb_build_object(
+    'amount', (snapshot->>'price')::numeric(20, 2)::text,
+    'currency', 'EUR'))
+WHERE jsonb_typeof(snapshot->'price') = 'number';
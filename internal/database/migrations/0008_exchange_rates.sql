@@ -0,0 +1,19 @@
+-- One unit of base buys rate units of quote.
+CREATE TABLE IF NOT EXISTS exchange_rates (
+    base TEXT NOT NULL CHECK (base ~ '^[A-Z]{3}$'),
+    quote TEXT NOT NULL CHECK (quote ~ '^[A-Z]{3}$'),
+    rate NUMERIC(24, 12) NOT NULL CHECK (rate > 0),
+    source TEXT NOT NULL DEFAULT 'manual',
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (base, quote),
+    CHECK (base <> quote)
+);
+
+-- Converted amounts in a currency are rounded to a multiple of increment
+-- minor units, e.g. 5 for CHF or 100 for whole tenge.
+CREATE TABLE IF NOT EXISTS currency_rounding (
+    currency TEXT PRIMARY KEY CHECK (currency ~ '^[A-Z]{3}$'),
+    mode TEXT NOT NULL CHECK (mode IN ('half_up', 'half_even', 'down', 'up')),
+    increment BIGINT NOT NULL DEFAULT 1 CHECK (increment > 0),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
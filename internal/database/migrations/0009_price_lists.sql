@@ -0,0 +1,24 @@
+CREATE TABLE IF NOT EXISTS price_lists (
+    id SERIAL PRIMARY KEY,
+    code TEXT NOT NULL UNIQUE,
+    name TEXT NOT NULL,
+    currency TEXT NOT NULL CHECK (currency ~ '^[A-Z]{3}$'),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+-- A tier sets the unit price of a product in a list from min_qty units on.
+CREATE TABLE IF NOT EXISTS price_list_tiers (
+    price_list_id INT NOT NULL REFERENCES price_lists (id) ON DELETE CASCADE,
+    product_id INT NOT NULL REFERENCES products (id) ON DELETE CASCADE,
+    min_qty INT NOT NULL CHECK (min_qty >= 1),
+    price BIGINT NOT NULL CHECK (price >= 0),
+    PRIMARY KEY (price_list_id, product_id, min_qty)
+);
+
+CREATE TABLE IF NOT EXISTS customer_groups (
+    id SERIAL PRIMARY KEY,
+    code TEXT NOT NULL UNIQUE,
+    name TEXT NOT NULL,
+    price_list_id INT REFERENCES price_lists (id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
@@ -0,0 +1,31 @@
+CREATE TABLE IF NOT EXISTS categories (
+    id SERIAL PRIMARY KEY,
+    parent_id INT REFERENCES categories (id),
+    name TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+-- Sibling names are unique regardless of case.
+CREATE UNIQUE INDEX IF NOT EXISTS categories_sibling_name_idx ON categories (COALESCE(parent_id, 0), lower(name));
+
+-- Every ancestor/descendant pair of the tree, including each category with
+-- itself at depth 0; kept in step with parent_id by the repository.
+CREATE TABLE IF NOT EXISTS category_closure (
+    ancestor_id INT NOT NULL REFERENCES categories (id) ON DELETE CASCADE,
+    descendant_id INT NOT NULL REFERENCES categories (id) ON DELETE CASCADE,
+    depth INT NOT NULL CHECK (depth >= 0),
+    PRIMARY KEY (ancestor_id, descendant_id)
+);
+
+CREATE INDEX IF NOT EXISTS category_closure_descendant_idx ON category_closure (descendant_id);
+
+CREATE TABLE IF NOT EXISTS product_categories (
+    product_id INT NOT NULL REFERENCES products (id) ON DELETE CASCADE,
+    category_id INT NOT NULL REFERENCES categories (id) ON DELETE CASCADE,
+    is_primary BOOLEAN NOT NULL DEFAULT false,
+    PRIMARY KEY (product_id, category_id)
+);
+
+CREATE INDEX IF NOT EXISTS product_categories_category_idx ON product_categories (category_id);
+-- At most one primary category per product.
+CREATE UNIQUE INDEX IF NOT EXISTS product_categories_primary_idx ON product_categories (product_id) WHERE is_primary;
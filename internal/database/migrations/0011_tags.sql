@@ -0,0 +1,14 @@
+-- Tag names are stored normalized: lower case, words joined by '-'.
+CREATE TABLE IF NOT EXISTS tags (
+    id SERIAL PRIMARY KEY,
+    name TEXT NOT NULL UNIQUE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE TABLE IF NOT EXISTS product_tags (
+    product_id INT NOT NULL REFERENCES products (id) ON DELETE CASCADE,
+    tag_id INT NOT NULL REFERENCES tags (id) ON DELETE CASCADE,
+    PRIMARY KEY (product_id, tag_id)
+);
+
+CREATE INDEX IF NOT EXISTS product_tags_tag_idx ON product_tags (tag_id);
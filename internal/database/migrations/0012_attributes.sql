@@ -0,0 +1,9 @@
+ALTER TABLE products ADD COLUMN IF NOT EXISTS attributes JSONB NOT NULL DEFAULT '{}';
+
+-- The default jsonb_ops class serves the jsonpath (@@) filters on
+-- attributes, indexing the equality conditions among them.
+CREATE INDEX IF NOT EXISTS products_attributes_idx ON products USING GIN (attributes);
+
+-- JSON Schema for the attributes of the products whose primary category
+-- is this category or, failing a closer one, one of its subcategories.
+ALTER TABLE categories ADD COLUMN IF NOT EXISTS attribute_schema JSONB;
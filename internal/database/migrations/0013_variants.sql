@@ -0,0 +1,26 @@
+-- Option axes of a product, such as size or colour, with their values in
+-- display order.
+CREATE TABLE IF NOT EXISTS product_options (
+    product_id INT NOT NULL REFERENCES products (id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    position INT NOT NULL,
+    "values" TEXT[] NOT NULL,
+    PRIMARY KEY (product_id, name)
+);
+
+CREATE TABLE IF NOT EXISTS product_variants (
+    id SERIAL PRIMARY KEY,
+    product_id INT NOT NULL REFERENCES products (id) ON DELETE CASCADE,
+    sku TEXT NOT NULL UNIQUE,
+    -- One value per option axis of the product, e.g. {"size": "M"}.
+    options JSONB NOT NULL,
+    -- Overrides the price of the product when set.
+    price BIGINT,
+    currency CHAR(3),
+    attributes JSONB NOT NULL DEFAULT '{}',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    CHECK ((price IS NULL) = (currency IS NULL))
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS product_variants_options_idx ON product_variants (product_id, options);
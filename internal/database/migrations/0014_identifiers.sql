@@ -0,0 +1,10 @@
+-- Alternative keys for scanners and storefront URLs. Trashed products keep
+-- theirs until they are purged, so that restoring one never clashes.
+ALTER TABLE products
+    ADD COLUMN IF NOT EXISTS sku TEXT,
+    ADD COLUMN IF NOT EXISTS barcode TEXT,
+    ADD COLUMN IF NOT EXISTS slug TEXT;
+
+CREATE UNIQUE INDEX IF NOT EXISTS products_sku_idx ON products (sku);
+CREATE UNIQUE INDEX IF NOT EXISTS products_barcode_idx ON products (barcode);
+CREATE UNIQUE INDEX IF NOT EXISTS products_slug_idx ON products (slug);
@@ -0,0 +1,36 @@
+-- Inventory settings of a product. The row is locked while a movement is
+-- recorded, so that concurrent movements see each other's balance.
+CREATE TABLE IF NOT EXISTS inventory_items (
+    product_id INT PRIMARY KEY REFERENCES products (id) ON DELETE CASCADE,
+    allow_backorder BOOLEAN NOT NULL DEFAULT false
+);
+
+-- The stock ledger. Stock is never stored as a counter: balance_after is
+-- the stock on hand once the movement is applied, so the latest movement of
+-- a product gives its current stock. Like the audit log, the ledger
+-- outlives purged products.
+CREATE TABLE IF NOT EXISTS stock_movements (
+    id BIGSERIAL PRIMARY KEY,
+    product_id INT NOT NULL,
+    type TEXT NOT NULL CHECK (type IN ('receipt', 'adjustment', 'sale', 'return')),
+    quantity BIGINT NOT NULL CHECK (quantity <> 0),
+    balance_after BIGINT NOT NULL,
+    reference TEXT NOT NULL DEFAULT '',
+    note TEXT NOT NULL DEFAULT '',
+    actor TEXT NOT NULL,
+    request_id TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS stock_movements_product_idx ON stock_movements (product_id, id);
+
+CREATE OR REPLACE FUNCTION stock_movements_append_only() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'stock_movements is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS stock_movements_append_only ON stock_movements;
+CREATE TRIGGER stock_movements_append_only
+    BEFORE UPDATE OR DELETE ON stock_movements
+    FOR EACH ROW EXECUTE FUNCTION stock_movements_append_only();
@@ -0,0 +1,53 @@
+CREATE TABLE IF NOT EXISTS warehouses (
+    id SERIAL PRIMARY KEY,
+    code TEXT NOT NULL UNIQUE,
+    name TEXT NOT NULL,
+    -- Movements recorded without a warehouse go to the default one.
+    is_default BOOLEAN NOT NULL DEFAULT false,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS warehouses_default_idx ON warehouses (is_default) WHERE is_default;
+
+INSERT INTO warehouses (code, name, is_default)
+SELECT 'main', 'Main warehouse', true WHERE NOT EXISTS (SELECT 1 FROM warehouses);
+
+-- Stock recorded before there were warehouses is in the default one, where
+-- the balances of the existing movements stay correct. This is the only
+-- time the ledger is ever rewritten.
+ALTER TABLE stock_movements ADD COLUMN IF NOT EXISTS warehouse_id INT REFERENCES warehouses (id);
+ALTER TABLE stock_movements DISABLE TRIGGER stock_movements_append_only;
+UPDATE stock_movements SET warehouse_id = (SELECT id FROM warehouses WHERE is_default) WHERE warehouse_id IS NULL;
+ALTER TABLE stock_movements ENABLE TRIGGER stock_movements_append_only;
+ALTER TABLE stock_movements ALTER COLUMN warehouse_id SET NOT NULL;
+
+ALTER TABLE stock_movements DROP CONSTRAINT IF EXISTS stock_movements_type_check;
+ALTER TABLE stock_movements ADD CONSTRAINT stock_movements_type_check
+    CHECK (type IN ('receipt', 'adjustment', 'sale', 'return', 'transfer_out', 'transfer_in'));
+
+-- balance_after is now the balance of the product in the warehouse.
+CREATE INDEX IF NOT EXISTS stock_movements_warehouse_idx ON stock_movements (product_id, warehouse_id, id);
+
+CREATE TABLE IF NOT EXISTS stock_transfers (
+    id BIGSERIAL PRIMARY KEY,
+    source_warehouse_id INT NOT NULL REFERENCES warehouses (id),
+    destination_warehouse_id INT NOT NULL REFERENCES warehouses (id),
+    status TEXT NOT NULL DEFAULT 'in_transit' CHECK (status IN ('in_transit', 'received', 'cancelled')),
+    note TEXT NOT NULL DEFAULT '',
+    actor TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    finished_at TIMESTAMPTZ,
+    CHECK (source_warehouse_id <> destination_warehouse_id)
+);
+
+CREATE INDEX IF NOT EXISTS stock_transfers_in_transit_idx ON stock_transfers (destination_warehouse_id) WHERE status = 'in_transit';
+
+-- Like the ledger, transfer lines outlive purged products.
+CREATE TABLE IF NOT EXISTS stock_transfer_lines (
+    transfer_id BIGINT NOT NULL REFERENCES stock_transfers (id) ON DELETE CASCADE,
+    product_id INT NOT NULL,
+    quantity BIGINT NOT NULL CHECK (quantity > 0),
+    PRIMARY KEY (transfer_id, product_id)
+);
+
+CREATE INDEX IF NOT EXISTS stock_transfer_lines_product_idx ON stock_transfer_lines (product_id);
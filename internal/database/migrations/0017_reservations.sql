@@ -0,0 +1,23 @@
+CREATE TABLE IF NOT EXISTS reservations (
+    id BIGSERIAL PRIMARY KEY,
+    warehouse_id INT NOT NULL REFERENCES warehouses (id),
+    status TEXT NOT NULL DEFAULT 'active' CHECK (status IN ('active', 'confirmed', 'released', 'expired')),
+    reference TEXT NOT NULL DEFAULT '',
+    actor TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    -- An active reservation stops holding stock at expires_at, even before
+    -- the expirer marks it expired.
+    expires_at TIMESTAMPTZ NOT NULL,
+    finished_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS reservations_active_idx ON reservations (expires_at) WHERE status = 'active';
+
+CREATE TABLE IF NOT EXISTS reservation_lines (
+    reservation_id BIGINT NOT NULL REFERENCES reservations (id) ON DELETE CASCADE,
+    product_id INT NOT NULL REFERENCES products (id) ON DELETE CASCADE,
+    quantity BIGINT NOT NULL CHECK (quantity > 0),
+    PRIMARY KEY (reservation_id, product_id)
+);
+
+CREATE INDEX IF NOT EXISTS reservation_lines_product_idx ON reservation_lines (product_id);
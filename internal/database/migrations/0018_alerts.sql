@@ -0,0 +1,41 @@
+ALTER TABLE inventory_items ADD COLUMN IF NOT EXISTS reorder_point BIGINT CHECK (reorder_point >= 0);
+ALTER TABLE inventory_items ADD COLUMN IF NOT EXISTS reorder_quantity BIGINT CHECK (reorder_quantity > 0);
+
+-- The stock position of each live product across all warehouses. Stock in
+-- transit between warehouses is still ours, so reordering counts it.
+CREATE OR REPLACE VIEW inventory_positions AS
+SELECT p.id AS product_id,
+    COALESCE((
+        SELECT sum(balance_after) FROM (
+            SELECT DISTINCT ON (warehouse_id) balance_after FROM stock_movements
+            WHERE product_id = p.id ORDER BY warehouse_id, id DESC
+        ) b
+    ), 0) AS on_hand,
+    COALESCE((
+        SELECT sum(l.quantity) FROM reservation_lines l
+        JOIN reservations r ON r.id = l.reservation_id
+        WHERE l.product_id = p.id AND r.status = 'active' AND r.expires_at > now()
+    ), 0) AS reserved,
+    COALESCE((
+        SELECT sum(l.quantity) FROM stock_transfer_lines l
+        JOIN stock_transfers t ON t.id = l.transfer_id
+        WHERE l.product_id = p.id AND t.status = 'in_transit'
+    ), 0) AS in_transit
+FROM products p
+WHERE p.deleted_at IS NULL;
+
+CREATE TABLE IF NOT EXISTS alerts (
+    id BIGSERIAL PRIMARY KEY,
+    product_id INT NOT NULL REFERENCES products (id) ON DELETE CASCADE,
+    type TEXT NOT NULL DEFAULT 'low_stock' CHECK (type IN ('low_stock')),
+    stock_level BIGINT NOT NULL,
+    reorder_point BIGINT NOT NULL,
+    reorder_quantity BIGINT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    resolved_at TIMESTAMPTZ,
+    notified_at TIMESTAMPTZ
+);
+
+-- A product has at most one open alert of a type.
+CREATE UNIQUE INDEX IF NOT EXISTS alerts_open_idx ON alerts (product_id, type) WHERE resolved_at IS NULL;
+CREATE INDEX IF NOT EXISTS alerts_unnotified_idx ON alerts (id) WHERE notified_at IS NULL AND resolved_at IS NULL;
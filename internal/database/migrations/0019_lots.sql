@@ -0,0 +1,53 @@
+-- How a stock movement splits across lots. Stock moved without a lot is
+-- untracked and has no rows here, so the balance of a lot is the sum of its
+-- rows and untracked stock is whatever the lots leave of the balance on
+-- hand. A lot number has one expiry date per product. Like the ledger, the
+-- rows are append-only and outlive purged products.
+CREATE TABLE IF NOT EXISTS stock_movement_lots (
+    id BIGSERIAL PRIMARY KEY,
+    movement_id BIGINT NOT NULL REFERENCES stock_movements (id),
+    product_id INT NOT NULL,
+    warehouse_id INT NOT NULL REFERENCES warehouses (id),
+    lot TEXT NOT NULL CHECK (lot <> ''),
+    expires_on DATE,
+    quantity BIGINT NOT NULL CHECK (quantity <> 0)
+);
+
+CREATE INDEX IF NOT EXISTS stock_movement_lots_movement_idx ON stock_movement_lots (movement_id);
+CREATE INDEX IF NOT EXISTS stock_movement_lots_product_idx ON stock_movement_lots (product_id, warehouse_id, lot);
+CREATE INDEX IF NOT EXISTS stock_movement_lots_expiry_idx ON stock_movement_lots (expires_on) WHERE expires_on IS NOT NULL;
+
+DROP TRIGGER IF EXISTS stock_movement_lots_append_only ON stock_movement_lots;
+CREATE TRIGGER stock_movement_lots_append_only
+    BEFORE UPDATE OR DELETE ON stock_movement_lots
+    FOR EACH ROW EXECUTE FUNCTION stock_movements_append_only();
+
+-- Stock of lots past their expiry date is still on hand but can no longer
+-- be sold, so it is not available to promise either.
+CREATE OR REPLACE VIEW inventory_positions AS
+SELECT p.id AS product_id,
+    COALESCE((
+        SELECT sum(balance_after) FROM (
+            SELECT DISTINCT ON (warehouse_id) balance_after FROM stock_movements
+            WHERE product_id = p.id ORDER BY warehouse_id, id DESC
+        ) b
+    ), 0) AS on_hand,
+    COALESCE((
+        SELECT sum(l.quantity) FROM reservation_lines l
+        JOIN reservations r ON r.id = l.reservation_id
+        WHERE l.product_id = p.id AND r.status = 'active' AND r.expires_at > now()
+    ), 0) AS reserved,
+    COALESCE((
+        SELECT sum(l.quantity) FROM stock_transfer_lines l
+        JOIN stock_transfers t ON t.id = l.transfer_id
+        WHERE l.product_id = p.id AND t.status = 'in_transit'
+    ), 0) AS in_transit,
+    COALESCE((
+        SELECT sum(balance) FROM (
+            SELECT sum(quantity) AS balance FROM stock_movement_lots
+            WHERE product_id = p.id AND expires_on < current_date
+            GROUP BY warehouse_id, lot
+        ) b WHERE balance > 0
+    ), 0) AS expired
+FROM products p
+WHERE p.deleted_at IS NULL;
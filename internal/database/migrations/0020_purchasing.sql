@@ -0,0 +1,60 @@
+-- The cost price of a product is what a unit of its stock cost to buy. It
+-- is set as purchase orders are received.
+ALTER TABLE inventory_items ADD COLUMN IF NOT EXISTS cost_price BIGINT CHECK (cost_price >= 0);
+ALTER TABLE inventory_items ADD COLUMN IF NOT EXISTS cost_currency TEXT;
+
+CREATE TABLE IF NOT EXISTS suppliers (
+    id SERIAL PRIMARY KEY,
+    code TEXT NOT NULL UNIQUE,
+    name TEXT NOT NULL,
+    email TEXT NOT NULL DEFAULT '',
+    phone TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+-- The products a supplier supplies, under the supplier's own SKU and at the
+-- supplier's cost price.
+CREATE TABLE IF NOT EXISTS supplier_products (
+    supplier_id INT NOT NULL REFERENCES suppliers (id) ON DELETE CASCADE,
+    product_id INT NOT NULL REFERENCES products (id) ON DELETE CASCADE,
+    supplier_sku TEXT NOT NULL DEFAULT '',
+    cost_price BIGINT CHECK (cost_price >= 0),
+    currency TEXT,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (supplier_id, product_id)
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS supplier_products_sku_idx ON supplier_products (supplier_id, supplier_sku) WHERE supplier_sku <> '';
+CREATE INDEX IF NOT EXISTS supplier_products_product_idx ON supplier_products (product_id);
+
+CREATE TABLE IF NOT EXISTS purchase_orders (
+    id BIGSERIAL PRIMARY KEY,
+    supplier_id INT NOT NULL REFERENCES suppliers (id),
+    warehouse_id INT NOT NULL REFERENCES warehouses (id),
+    status TEXT NOT NULL DEFAULT 'draft'
+        CHECK (status IN ('draft', 'sent', 'partially_received', 'received', 'cancelled')),
+    currency TEXT NOT NULL,
+    note TEXT NOT NULL DEFAULT '',
+    actor TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    sent_at TIMESTAMPTZ,
+    finished_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS purchase_orders_supplier_idx ON purchase_orders (supplier_id, id);
+CREATE INDEX IF NOT EXISTS purchase_orders_status_idx ON purchase_orders (status, id);
+
+-- Like the ledger, order lines outlive purged products.
+CREATE TABLE IF NOT EXISTS purchase_order_lines (
+    order_id BIGINT NOT NULL REFERENCES purchase_orders (id) ON DELETE CASCADE,
+    product_id INT NOT NULL,
+    supplier_sku TEXT NOT NULL DEFAULT '',
+    quantity BIGINT NOT NULL CHECK (quantity > 0),
+    received BIGINT NOT NULL DEFAULT 0 CHECK (received >= 0 AND received <= quantity),
+    unit_cost BIGINT NOT NULL CHECK (unit_cost >= 0),
+    PRIMARY KEY (order_id, product_id)
+);
+
+CREATE INDEX IF NOT EXISTS purchase_order_lines_product_idx ON purchase_order_lines (product_id);
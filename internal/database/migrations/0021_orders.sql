@@ -0,0 +1,34 @@
+-- Orders placed by customers. Their lines keep the name, SKU and price of
+-- each product as they were when the order was placed.
+CREATE TABLE IF NOT EXISTS orders (
+    id BIGSERIAL PRIMARY KEY,
+    warehouse_id INT NOT NULL REFERENCES warehouses (id),
+    status TEXT NOT NULL DEFAULT 'pending'
+        CHECK (status IN ('pending', 'paid', 'fulfilled', 'delivered', 'cancelled', 'refunded')),
+    currency TEXT NOT NULL,
+    customer TEXT NOT NULL DEFAULT '',
+    note TEXT NOT NULL DEFAULT '',
+    actor TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    paid_at TIMESTAMPTZ,
+    fulfilled_at TIMESTAMPTZ,
+    delivered_at TIMESTAMPTZ,
+    cancelled_at TIMESTAMPTZ,
+    refunded_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS orders_status_idx ON orders (status, id);
+
+-- Like the ledger, order lines outlive purged products.
+CREATE TABLE IF NOT EXISTS order_lines (
+    order_id BIGINT NOT NULL REFERENCES orders (id) ON DELETE CASCADE,
+    product_id INT NOT NULL,
+    name TEXT NOT NULL,
+    sku TEXT NOT NULL DEFAULT '',
+    quantity BIGINT NOT NULL CHECK (quantity > 0),
+    unit_price BIGINT NOT NULL CHECK (unit_price >= 0),
+    PRIMARY KEY (order_id, product_id)
+);
+
+CREATE INDEX IF NOT EXISTS order_lines_product_idx ON order_lines (product_id);
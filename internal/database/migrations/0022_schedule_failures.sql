@@ -0,0 +1,7 @@
+-- A permanent price change that cannot be applied, for example because the
+-- product no longer validates, fails with the reason instead of blocking
+-- the changes due after it.
+ALTER TABLE price_schedules DROP CONSTRAINT IF EXISTS price_schedules_status_check;
+ALTER TABLE price_schedules ADD CONSTRAINT price_schedules_status_check
+    CHECK (status IN ('scheduled', 'active', 'expired', 'applied', 'cancelled', 'failed'));
+ALTER TABLE price_schedules ADD COLUMN IF NOT EXISTS error TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,72 @@
+-- Variants are stocked, reserved, transferred, bought and sold on their
+-- own. Everything stock is kept of is keyed by product and variant, where
+-- variant_id is 0 for the product itself; that is how all stock recorded
+-- so far is kept. Like product_id in the ledger, variant_id outlives
+-- deleted variants, so it has no foreign key.
+ALTER TABLE inventory_items ADD COLUMN IF NOT EXISTS variant_id INT NOT NULL DEFAULT 0;
+ALTER TABLE inventory_items DROP CONSTRAINT IF EXISTS inventory_items_pkey;
+ALTER TABLE inventory_items ADD PRIMARY KEY (product_id, variant_id);
+
+ALTER TABLE stock_movements ADD COLUMN IF NOT EXISTS variant_id INT NOT NULL DEFAULT 0;
+DROP INDEX IF EXISTS stock_movements_warehouse_idx;
+CREATE INDEX IF NOT EXISTS stock_movements_item_idx ON stock_movements (product_id, variant_id, warehouse_id, id);
+
+ALTER TABLE stock_movement_lots ADD COLUMN IF NOT EXISTS variant_id INT NOT NULL DEFAULT 0;
+DROP INDEX IF EXISTS stock_movement_lots_product_idx;
+CREATE INDEX IF NOT EXISTS stock_movement_lots_item_idx ON stock_movement_lots (product_id, variant_id, warehouse_id, lot);
+
+ALTER TABLE reservation_lines ADD COLUMN IF NOT EXISTS variant_id INT NOT NULL DEFAULT 0;
+ALTER TABLE reservation_lines DROP CONSTRAINT IF EXISTS reservation_lines_pkey;
+ALTER TABLE reservation_lines ADD PRIMARY KEY (reservation_id, product_id, variant_id);
+
+ALTER TABLE stock_transfer_lines ADD COLUMN IF NOT EXISTS variant_id INT NOT NULL DEFAULT 0;
+ALTER TABLE stock_transfer_lines DROP CONSTRAINT IF EXISTS stock_transfer_lines_pkey;
+ALTER TABLE stock_transfer_lines ADD PRIMARY KEY (transfer_id, product_id, variant_id);
+
+ALTER TABLE purchase_order_lines ADD COLUMN IF NOT EXISTS variant_id INT NOT NULL DEFAULT 0;
+ALTER TABLE purchase_order_lines DROP CONSTRAINT IF EXISTS purchase_order_lines_pkey;
+ALTER TABLE purchase_order_lines ADD PRIMARY KEY (order_id, product_id, variant_id);
+
+ALTER TABLE order_lines ADD COLUMN IF NOT EXISTS variant_id INT NOT NULL DEFAULT 0;
+ALTER TABLE order_lines DROP CONSTRAINT IF EXISTS order_lines_pkey;
+ALTER TABLE order_lines ADD PRIMARY KEY (order_id, product_id, variant_id);
+
+ALTER TABLE alerts ADD COLUMN IF NOT EXISTS variant_id INT NOT NULL DEFAULT 0;
+DROP INDEX IF EXISTS alerts_open_idx;
+CREATE UNIQUE INDEX IF NOT EXISTS alerts_open_idx ON alerts (product_id, variant_id, type) WHERE resolved_at IS NULL;
+
+-- The stock position of each live product and each of its variants across
+-- all warehouses.
+DROP VIEW IF EXISTS inventory_positions;
+CREATE VIEW inventory_positions AS
+SELECT it.product_id, it.variant_id,
+    COALESCE((
+        SELECT sum(balance_after) FROM (
+            SELECT DISTINCT ON (warehouse_id) balance_after FROM stock_movements
+            WHERE product_id = it.product_id AND variant_id = it.variant_id ORDER BY warehouse_id, id DESC
+        ) b
+    ), 0) AS on_hand,
+    COALESCE((
+        SELECT sum(l.quantity) FROM reservation_lines l
+        JOIN reservations r ON r.id = l.reservation_id
+        WHERE l.product_id = it.product_id AND l.variant_id = it.variant_id
+          AND r.status = 'active' AND r.expires_at > now()
+    ), 0) AS reserved,
+    COALESCE((
+        SELECT sum(l.quantity) FROM stock_transfer_lines l
+        JOIN stock_transfers t ON t.id = l.transfer_id
+        WHERE l.product_id = it.product_id AND l.variant_id = it.variant_id AND t.status = 'in_transit'
+    ), 0) AS in_transit,
+    COALESCE((
+        SELECT sum(balance) FROM (
+            SELECT sum(quantity) AS balance FROM stock_movement_lots
+            WHERE product_id = it.product_id AND variant_id = it.variant_id AND expires_on < current_date
+            GROUP BY warehouse_id, lot
+        ) b WHERE balance > 0
+    ), 0) AS expired
+FROM (
+    SELECT id AS product_id, 0 AS variant_id FROM products WHERE deleted_at IS NULL
+    UNION ALL
+    SELECT v.product_id, v.id FROM product_variants v
+    JOIN products p ON p.id = v.product_id AND p.deleted_at IS NULL
+) it;
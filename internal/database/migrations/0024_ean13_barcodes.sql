@@ -0,0 +1,9 @@
+-- Barcodes are stored as EAN-13, so that the unique index also catches a
+-- UPC-A code and its EAN-13 with a leading zero. Where a product already
+-- holds the EAN-13, the one with the UPC-A loses its barcode rather than
+-- failing the migration.
+UPDATE products p SET barcode = NULL
+WHERE length(p.barcode) = 12
+  AND EXISTS (SELECT 1 FROM products o WHERE o.barcode = '0' || p.barcode);
+
+UPDATE products SET barcode = '0' || barcode WHERE length(barcode) = 12;
@@ -0,0 +1,22 @@
+-- Sales are recorded in the price history next to the product's own
+-- prices, so that the history shows every price that was charged. A sale
+-- period overlaps the product's period and is told apart by schedule_id;
+-- only the product's own prices are limited to one open period.
+ALTER TABLE price_history ADD COLUMN IF NOT EXISTS schedule_id BIGINT;
+
+DROP INDEX IF EXISTS price_history_current_idx;
+CREATE UNIQUE INDEX IF NOT EXISTS price_history_current_idx ON price_history (product_id)
+    WHERE effective_to IS NULL AND schedule_id IS NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS price_history_schedule_idx ON price_history (schedule_id);
+
+-- Sales cancelled after they had started used to vanish from the past as
+-- well; they ended when they were cancelled.
+UPDATE price_schedules SET status = 'expired', ends_at = finished_at
+WHERE status = 'cancelled' AND ends_at IS NOT NULL
+  AND starts_at < finished_at AND finished_at < ends_at;
+
+INSERT INTO price_history (product_id, price, currency, effective_from, effective_to, schedule_id)
+SELECT s.product_id, s.price, s.currency, s.starts_at, CASE WHEN s.status = 'active' THEN NULL ELSE s.ends_at END, s.id
+FROM price_schedules s
+WHERE s.ends_at IS NOT NULL AND s.status IN ('active', 'expired')
+  AND NOT EXISTS (SELECT 1 FROM price_history h WHERE h.schedule_id = s.id);
@@ -0,0 +1,156 @@
+// Package exchange reads exchange rate files: a plain CSV with one rate per
+// line and the daily reference rates XML published by the European Central
+// Bank.
+package exchange
+
+import (
+	"encoding/csv"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"product-test/internal/models"
+	"slices"
+	"strings"
+	"time"
+)
+
+type Format string
+
+const (
+	FormatCSV Format = "csv"
+	FormatECB Format = "ecb"
+)
+
+// ParseFormat accepts a format name, a file extension or a media type.
+func ParseFormat(s string) (Format, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if mt, _, ok := strings.Cut(s, ";"); ok {
+		s = strings.TrimSpace(mt)
+	}
+	switch s {
+	case "csv", "text/csv":
+		return FormatCSV, nil
+	case "ecb", "xml", "application/xml", "text/xml":
+		return FormatECB, nil
+	}
+	return "", fmt.Errorf("unsupported rates format %q; use csv or ecb", s)
+}
+
+// Parse reads rates in the given format.
+func Parse(f Format, r io.Reader) ([]models.ExchangeRate, error) {
+	switch f {
+	case FormatCSV:
+		return ParseCSV(r)
+	case FormatECB:
+		return ParseECB(r)
+	}
+	return nil, fmt.Errorf("unsupported rates format %q", f)
+}
+
+var csvColumns = []string{"base", "quote", "rate"}
+
+// ParseCSV reads a CSV file with the header base,quote,rate and an optional
+// updated_at column holding an RFC 3339 time or a date. Rates without a
+// time are stamped with the current time.
+func ParseCSV(r io.Reader) ([]models.ExchangeRate, error) {
+	cr := csv.NewReader(r)
+	cr.TrimLeadingSpace = true
+	header, err := cr.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, errors.New("rates file is empty")
+		}
+		return nil, err
+	}
+	for i := range header {
+		header[i] = strings.ToLower(strings.TrimSpace(header[i]))
+	}
+	index := make(map[string]int)
+	for _, col := range append(slices.Clip(csvColumns), "updated_at") {
+		index[col] = slices.Index(header, col)
+	}
+	for _, col := range csvColumns {
+		if index[col] < 0 {
+			return nil, fmt.Errorf("rates file has no %q column; expected header base,quote,rate[,updated_at]", col)
+		}
+	}
+
+	now := time.Now().UTC()
+	var rates []models.ExchangeRate
+	for {
+		rec, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			return rates, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		line, _ := cr.FieldPos(0)
+		rate := models.ExchangeRate{
+			Base:      strings.ToUpper(rec[index["base"]]),
+			Quote:     strings.ToUpper(rec[index["quote"]]),
+			Rate:      rec[index["rate"]],
+			Source:    "csv",
+			UpdatedAt: now,
+		}
+		if i := index["updated_at"]; i >= 0 && rec[i] != "" {
+			if rate.UpdatedAt, err = parseTime(rec[i]); err != nil {
+				return nil, fmt.Errorf("line %d: invalid updated_at %q", line, rec[i])
+			}
+		}
+		rates = append(rates, rate)
+	}
+}
+
+func parseTime(s string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	return time.Parse(time.DateOnly, s)
+}
+
+// ecbEnvelope matches eurofxref-daily.xml and the historical variants,
+// which hold one dated cube per day.
+type ecbEnvelope struct {
+	Days []struct {
+		Time  string `xml:"time,attr"`
+		Rates []struct {
+			Currency string `xml:"currency,attr"`
+			Rate     string `xml:"rate,attr"`
+		} `xml:"Cube"`
+	} `xml:"Cube>Cube"`
+}
+
+// ParseECB reads the ECB reference rates XML. All ECB rates are quoted
+// against EUR; only the most recent day of the file is used.
+func ParseECB(r io.Reader) ([]models.ExchangeRate, error) {
+	var env ecbEnvelope
+	if err := xml.NewDecoder(r).Decode(&env); err != nil {
+		return nil, fmt.Errorf("malformed ECB rates file: %w", err)
+	}
+	if len(env.Days) == 0 {
+		return nil, errors.New("ECB rates file contains no rates")
+	}
+	latest := env.Days[0]
+	for _, day := range env.Days[1:] {
+		if day.Time > latest.Time {
+			latest = day
+		}
+	}
+	at, err := time.Parse(time.DateOnly, latest.Time)
+	if err != nil {
+		return nil, fmt.Errorf("ECB rates file has an invalid date %q", latest.Time)
+	}
+	rates := make([]models.ExchangeRate, 0, len(latest.Rates))
+	for _, c := range latest.Rates {
+		rates = append(rates, models.ExchangeRate{
+			Base:      "EUR",
+			Quote:     strings.ToUpper(c.Currency),
+			Rate:      c.Rate,
+			Source:    "ecb",
+			UpdatedAt: at,
+		})
+	}
+	return rates, nil
+}
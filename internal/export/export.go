@@ -0,0 +1,137 @@
+// Package export writes product listings as downloadable files.
+package export
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"product-test/internal/models"
+	"strconv"
+	"strings"
+)
+
+type Format string
+
+const (
+	FormatCSV    Format = "csv"
+	FormatNDJSON Format = "ndjson"
+	FormatXLSX   Format = "xlsx"
+)
+
+func ParseFormat(s string) (Format, error) {
+	switch f := Format(strings.ToLower(s)); f {
+	case "":
+		return FormatCSV, nil
+	case FormatCSV, FormatNDJSON, FormatXLSX:
+		return f, nil
+	}
+	return "", fmt.Errorf("unsupported export format %q", s)
+}
+
+func (f Format) ContentType() string {
+	switch f {
+	case FormatNDJSON:
+		return "application/x-ndjson"
+	case FormatXLSX:
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	}
+	return "text/csv; charset=utf-8"
+}
+
+func (f Format) Filename() string {
+	return "products." + string(f)
+}
+
+// Writer encodes products one by one. Flush pushes buffered output to the
+// underlying writer; Close finishes the document and must always be called.
+type Writer interface {
+	Write(p *models.Product) error
+	Flush() error
+	Close() error
+}
+
+// NewWriter returns a writer for the given format that outputs the listed
+// fields of every product, or all of models.ProductFields if none are given.
+func NewWriter(f Format, w io.Writer, fields []string) (Writer, error) {
+	if len(fields) == 0 {
+		fields = models.ProductFields
+	}
+	switch f {
+	case FormatCSV:
+		return newCSVWriter(w, fields)
+	case FormatNDJSON:
+		return newNDJSONWriter(w, fields), nil
+	case FormatXLSX:
+		return newXLSXWriter(w, fields)
+	}
+	return nil, fmt.Errorf("unsupported export format %q", f)
+}
+
+type cell struct {
+	value   string
+	numeric bool
+}
+
+func cells(p *models.Product, fields []string) []cell {
+	row := make([]cell, len(fields))
+	for i, f := range fields {
+		switch v := p.Field(f).(type) {
+		case int:
+			row[i] = cell{value: strconv.Itoa(v), numeric: true}
+		default:
+			row[i] = cell{value: fmt.Sprint(v)}
+		}
+	}
+	return row
+}
+
+type csvWriter struct {
+	w      *csv.Writer
+	fields []string
+}
+
+func newCSVWriter(w io.Writer, fields []string) (*csvWriter, error) {
+	cw := &csvWriter{w: csv.NewWriter(w), fields: fields}
+	if err := cw.w.Write(fields); err != nil {
+		return nil, err
+	}
+	return cw, nil
+}
+
+func (c *csvWriter) Write(p *models.Product) error {
+	return c.w.Write(models.ProductView{Product: p, Fields: c.fields}.CSVRecord())
+}
+
+func (c *csvWriter) Flush() error {
+	c.w.Flush()
+	return c.w.Error()
+}
+
+func (c *csvWriter) Close() error {
+	return c.Flush()
+}
+
+type ndjsonWriter struct {
+	buf    *bufio.Writer
+	enc    *json.Encoder
+	fields []string
+}
+
+func newNDJSONWriter(w io.Writer, fields []string) *ndjsonWriter {
+	buf := bufio.NewWriter(w)
+	return &ndjsonWriter{buf: buf, enc: json.NewEncoder(buf), fields: fields}
+}
+
+func (n *ndjsonWriter) Write(p *models.Product) error {
+	return n.enc.Encode(models.ProductView{Product: p, Fields: n.fields})
+}
+
+func (n *ndjsonWriter) Flush() error {
+	return n.buf.Flush()
+}
+
+func (n *ndjsonWriter) Close() error {
+	return n.Flush()
+}
@@ -0,0 +1,129 @@
+package export
+
+import (
+	"archive/zip"
+	"bufio"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"product-test/internal/models"
+	"strconv"
+)
+
+// xlsxWriter streams a single-sheet workbook. The worksheet is the last
+// entry of the zip archive, so rows can be appended without buffering.
+type xlsxWriter struct {
+	zw     *zip.Writer
+	buf    *bufio.Writer
+	fields []string
+	row    int
+}
+
+const (
+	xlsxContentTypes = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+		`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+		`<Default Extension="xml" ContentType="application/xml"/>` +
+		`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+		`<Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>` +
+		`</Types>`
+	xlsxRootRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+		`</Relationships>`
+	xlsxWorkbook = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">` +
+		`<sheets><sheet name="Products" sheetId="1" r:id="rId1"/></sheets>` +
+		`</workbook>`
+	xlsxWorkbookRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/>` +
+		`</Relationships>`
+	xlsxSheetStart = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`
+	xlsxSheetEnd = `</sheetData></worksheet>`
+)
+
+func newXLSXWriter(w io.Writer, fields []string) (*xlsxWriter, error) {
+	zw := zip.NewWriter(w)
+	parts := []struct{ name, body string }{
+		{"[Content_Types].xml", xlsxContentTypes},
+		{"_rels/.rels", xlsxRootRels},
+		{"xl/workbook.xml", xlsxWorkbook},
+		{"xl/_rels/workbook.xml.rels", xlsxWorkbookRels},
+	}
+	for _, part := range parts {
+		f, err := zw.Create(part.name)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := io.WriteString(f, part.body); err != nil {
+			return nil, err
+		}
+	}
+	sheet, err := zw.Create("xl/worksheets/sheet1.xml")
+	if err != nil {
+		return nil, err
+	}
+	x := &xlsxWriter{zw: zw, buf: bufio.NewWriter(sheet), fields: fields}
+	if _, err := x.buf.WriteString(xlsxSheetStart); err != nil {
+		return nil, err
+	}
+	header := make([]cell, len(fields))
+	for i, c := range fields {
+		header[i] = cell{value: c}
+	}
+	if err := x.writeRow(header); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+func (x *xlsxWriter) Write(p *models.Product) error {
+	return x.writeRow(cells(p, x.fields))
+}
+
+func (x *xlsxWriter) writeRow(row []cell) error {
+	x.row++
+	fmt.Fprintf(x.buf, `<row r="%d">`, x.row)
+	for i, c := range row {
+		ref := columnName(i) + strconv.Itoa(x.row)
+		if c.numeric {
+			fmt.Fprintf(x.buf, `<c r="%s"><v>%s</v></c>`, ref, c.value)
+			continue
+		}
+		fmt.Fprintf(x.buf, `<c r="%s" t="inlineStr"><is><t xml:space="preserve">`, ref)
+		if err := xml.EscapeText(x.buf, []byte(c.value)); err != nil {
+			return err
+		}
+		x.buf.WriteString(`</t></is></c>`)
+	}
+	_, err := x.buf.WriteString(`</row>`)
+	return err
+}
+
+func (x *xlsxWriter) Flush() error {
+	if err := x.buf.Flush(); err != nil {
+		return err
+	}
+	return x.zw.Flush()
+}
+
+func (x *xlsxWriter) Close() error {
+	if _, err := x.buf.WriteString(xlsxSheetEnd); err != nil {
+		return err
+	}
+	if err := x.buf.Flush(); err != nil {
+		return err
+	}
+	return x.zw.Close()
+}
+
+// columnName converts a zero-based column index to a spreadsheet column name (A, B, ..., AA).
+func columnName(i int) string {
+	name := ""
+	for i++; i > 0; i = (i - 1) / 26 {
+		name = string(rune('A'+(i-1)%26)) + name
+	}
+	return name
+}
@@ -0,0 +1,50 @@
+package handlers
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"product-test/internal/apierr"
+	"product-test/internal/models"
+	"product-test/internal/service"
+)
+
+type AlertHandler struct {
+	service service.AlertService
+	log     *slog.Logger
+}
+
+func NewAlertHandler(svc service.AlertService, log *slog.Logger) *AlertHandler {
+	if log == nil {
+		log = slog.Default()
+	}
+	return &AlertHandler{service: svc, log: log}
+}
+
+func (h *AlertHandler) RegisterRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("GET /alerts", negotiated(h.list))
+}
+
+func (h *AlertHandler) list(w http.ResponseWriter, r *http.Request) {
+	filter := models.AlertFilter{Status: models.AlertStatus(r.URL.Query().Get("status"))}
+	filter.Limit, filter.Offset = parseLimitOffset(r)
+	alerts, total, err := h.service.ListAlerts(r.Context(), filter)
+	if err != nil {
+		if errors.Is(err, service.ErrValidation) {
+			badRequest(w, err)
+			return
+		}
+		h.log.Error("list alerts", "error", err)
+		apierr.Internal(w)
+		return
+	}
+	if alerts == nil {
+		alerts = []models.Alert{}
+	}
+	writePage(w, r, h.log, alerts, pageInfo{
+		Total:   total,
+		Limit:   filter.Limit,
+		Offset:  filter.Offset,
+		HasMore: filter.Offset+len(alerts) < total,
+	})
+}
@@ -0,0 +1,111 @@
+package handlers
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"product-test/internal/apierr"
+	"product-test/internal/models"
+	"product-test/internal/service"
+	"strconv"
+	"time"
+)
+
+type AuditHandler struct {
+	service service.AuditService
+	log     *slog.Logger
+}
+
+func NewAuditHandler(svc service.AuditService, log *slog.Logger) *AuditHandler {
+	if log == nil {
+		log = slog.Default()
+	}
+	return &AuditHandler{service: svc, log: log}
+}
+
+func (h *AuditHandler) RegisterRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("GET /audit", negotiated(h.list))
+	mux.HandleFunc("GET /products/{id}/history", negotiated(h.history))
+}
+
+func (h *AuditHandler) list(w http.ResponseWriter, r *http.Request) {
+	filter, ok := parseAuditFilter(w, r)
+	if !ok {
+		return
+	}
+	h.write(w, r, filter)
+}
+
+func (h *AuditHandler) history(w http.ResponseWriter, r *http.Request) {
+	id, ok := parseID(w, r, "id")
+	if !ok {
+		return
+	}
+	filter, ok := parseAuditFilter(w, r)
+	if !ok {
+		return
+	}
+	filter.ProductID = id
+	h.write(w, r, filter)
+}
+
+func (h *AuditHandler) write(w http.ResponseWriter, r *http.Request, filter models.AuditFilter) {
+	entries, total, err := h.service.ListAudit(r.Context(), filter)
+	if err != nil {
+		if errors.Is(err, service.ErrValidation) {
+			apierr.BadRequest(w, err.Error())
+			return
+		}
+		h.log.Error("list audit", "error", err)
+		apierr.Internal(w)
+		return
+	}
+	if entries == nil {
+		entries = []models.AuditEntry{}
+	}
+	writePage(w, r, h.log, entries, pageInfo{
+		Total:   total,
+		Limit:   filter.Limit,
+		Offset:  filter.Offset,
+		HasMore: filter.Offset+len(entries) < total,
+	})
+}
+
+func parseAuditFilter(w http.ResponseWriter, r *http.Request) (models.AuditFilter, bool) {
+	q := r.URL.Query()
+	filter := models.AuditFilter{
+		Actor:  q.Get("actor"),
+		Action: models.AuditAction(q.Get("action")),
+	}
+	filter.Limit, filter.Offset = parseLimitOffset(r)
+	if v := q.Get("product_id"); v != "" {
+		id, err := strconv.Atoi(v)
+		if err != nil || id <= 0 {
+			apierr.BadRequest(w, "invalid product_id")
+			return filter, false
+		}
+		filter.ProductID = id
+	}
+	var ok bool
+	if filter.From, ok = parseTimeParam(w, r, "from"); !ok {
+		return filter, false
+	}
+	if filter.To, ok = parseTimeParam(w, r, "to"); !ok {
+		return filter, false
+	}
+	return filter, true
+}
+
+// parseTimeParam reads an optional RFC 3339 timestamp from the query string.
+func parseTimeParam(w http.ResponseWriter, r *http.Request, name string) (time.Time, bool) {
+	v := r.URL.Query().Get(name)
+	if v == "" {
+		return time.Time{}, true
+	}
+	t, err := time.Parse(time.RFC3339, v)
+	if err != nil {
+		apierr.BadRequest(w, name+" must be an RFC 3339 timestamp")
+		return time.Time{}, false
+	}
+	return t, true
+}
@@ -0,0 +1,214 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"product-test/internal/apierr"
+	"product-test/internal/models"
+	"product-test/internal/service"
+)
+
+const maxCategoryBodyBytes = 64 << 10
+
+type CategoryHandler struct {
+	service service.CategoryService
+	log     *slog.Logger
+}
+
+func NewCategoryHandler(svc service.CategoryService, log *slog.Logger) *CategoryHandler {
+	if log == nil {
+		log = slog.Default()
+	}
+	return &CategoryHandler{service: svc, log: log}
+}
+
+// RegisterRoutes registers the category tree and product assignment
+// endpoints; the products of a category are listed by the ProductHandler.
+func (h *CategoryHandler) RegisterRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("GET /categories", negotiated(h.list))
+	mux.HandleFunc("POST /categories", negotiated(withBodyLimit(maxCategoryBodyBytes, h.create)))
+	mux.HandleFunc("GET /categories/{id}", negotiated(h.get))
+	mux.HandleFunc("PUT /categories/{id}", negotiated(withBodyLimit(maxCategoryBodyBytes, h.update)))
+	mux.HandleFunc("DELETE /categories/{id}", h.delete)
+	mux.HandleFunc("GET /categories/{id}/attribute-schema", h.getSchema)
+	mux.HandleFunc("PUT /categories/{id}/attribute-schema", h.setSchema)
+	mux.HandleFunc("DELETE /categories/{id}/attribute-schema", h.deleteSchema)
+	mux.HandleFunc("GET /products/{id}/categories", negotiated(h.productCategories))
+	mux.HandleFunc("PUT /products/{id}/categories", negotiated(withBodyLimit(maxCategoryBodyBytes, h.setProductCategories)))
+}
+
+func (h *CategoryHandler) list(w http.ResponseWriter, r *http.Request) {
+	categories, err := h.service.ListCategories(r.Context())
+	if err != nil {
+		h.fail(w, "list categories", err)
+		return
+	}
+	if categories == nil {
+		categories = []models.Category{}
+	}
+	respond(w, r, h.log, http.StatusOK, categories)
+}
+
+func (h *CategoryHandler) create(w http.ResponseWriter, r *http.Request) {
+	var in models.CategoryInput
+	if !decode(w, r, &in) {
+		return
+	}
+	c, err := h.service.CreateCategory(r.Context(), in)
+	if err != nil {
+		h.fail(w, "create category", err)
+		return
+	}
+	respond(w, r, h.log, http.StatusCreated, c)
+}
+
+func (h *CategoryHandler) get(w http.ResponseWriter, r *http.Request) {
+	id, ok := parsePathInt(w, r, "id", "category id")
+	if !ok {
+		return
+	}
+	c, err := h.service.GetCategory(r.Context(), id)
+	if err != nil {
+		h.fail(w, "get category", err)
+		return
+	}
+	respond(w, r, h.log, http.StatusOK, c)
+}
+
+func (h *CategoryHandler) update(w http.ResponseWriter, r *http.Request) {
+	id, ok := parsePathInt(w, r, "id", "category id")
+	if !ok {
+		return
+	}
+	var in models.CategoryInput
+	if !decode(w, r, &in) {
+		return
+	}
+	c, err := h.service.UpdateCategory(r.Context(), id, in)
+	if err != nil {
+		h.fail(w, "update category", err)
+		return
+	}
+	respond(w, r, h.log, http.StatusOK, c)
+}
+
+func (h *CategoryHandler) delete(w http.ResponseWriter, r *http.Request) {
+	id, ok := parsePathInt(w, r, "id", "category id")
+	if !ok {
+		return
+	}
+	if err := h.service.DeleteCategory(r.Context(), id); err != nil {
+		h.fail(w, "delete category", err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// getSchema and setSchema speak JSON only: a JSON Schema has no sensible
+// XML or CSV form.
+func (h *CategoryHandler) getSchema(w http.ResponseWriter, r *http.Request) {
+	id, ok := parsePathInt(w, r, "id", "category id")
+	if !ok {
+		return
+	}
+	schema, err := h.service.GetAttributeSchema(r.Context(), id)
+	if err != nil {
+		h.fail(w, "get attribute schema", err)
+		return
+	}
+	writeJSON(w, http.StatusOK, schema)
+}
+
+func (h *CategoryHandler) setSchema(w http.ResponseWriter, r *http.Request) {
+	id, ok := parsePathInt(w, r, "id", "category id")
+	if !ok {
+		return
+	}
+	if c, ok := codecs.ForContentType(r.Header.Get("Content-Type")); !ok || c.ContentType() != "application/json" {
+		apierr.UnsupportedMediaType(w, "attribute schemas must be sent as application/json")
+		return
+	}
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxCategoryBodyBytes))
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			apierr.PayloadTooLarge(w, fmt.Sprintf("request body must not exceed %d bytes", maxCategoryBodyBytes))
+			return
+		}
+		apierr.BadRequest(w, "could not read request body")
+		return
+	}
+	schema, err := h.service.SetAttributeSchema(r.Context(), id, body)
+	if err != nil {
+		h.fail(w, "set attribute schema", err)
+		return
+	}
+	writeJSON(w, http.StatusOK, schema)
+}
+
+func (h *CategoryHandler) deleteSchema(w http.ResponseWriter, r *http.Request) {
+	id, ok := parsePathInt(w, r, "id", "category id")
+	if !ok {
+		return
+	}
+	if err := h.service.DeleteAttributeSchema(r.Context(), id); err != nil {
+		h.fail(w, "delete attribute schema", err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func writeJSON(w http.ResponseWriter, status int, body []byte) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_, _ = w.Write(body)
+}
+
+func (h *CategoryHandler) productCategories(w http.ResponseWriter, r *http.Request) {
+	id, ok := parseID(w, r, "id")
+	if !ok {
+		return
+	}
+	categories, err := h.service.GetProductCategories(r.Context(), id)
+	if err != nil {
+		h.fail(w, "get product categories", err)
+		return
+	}
+	respond(w, r, h.log, http.StatusOK, models.ProductCategories{Categories: categories})
+}
+
+func (h *CategoryHandler) setProductCategories(w http.ResponseWriter, r *http.Request) {
+	id, ok := parseID(w, r, "id")
+	if !ok {
+		return
+	}
+	var in models.ProductCategories
+	if !decode(w, r, &in) {
+		return
+	}
+	categories, err := h.service.SetProductCategories(r.Context(), id, in.Categories)
+	if err != nil {
+		h.fail(w, "set product categories", err)
+		return
+	}
+	respond(w, r, h.log, http.StatusOK, models.ProductCategories{Categories: categories})
+}
+
+func (h *CategoryHandler) fail(w http.ResponseWriter, op string, err error) {
+	switch {
+	case errors.Is(err, service.ErrValidation):
+		badRequest(w, err)
+	case errors.Is(err, service.ErrCategoryNotFound):
+		apierr.NotFound(w, "category not found")
+	case errors.Is(err, service.ErrSchemaNotFound):
+		apierr.NotFound(w, "category has no attribute schema")
+	case errors.Is(err, service.ErrNotFound):
+		apierr.NotFound(w, "product not found")
+	default:
+		h.log.Error(op, "error", err)
+		apierr.Internal(w)
+	}
+}
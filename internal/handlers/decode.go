@@ -0,0 +1,123 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"product-test/internal/apierr"
+	"product-test/internal/codec"
+	"product-test/internal/models"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// defaultMaxBodyBytes applies to routes that do not set their own limit.
+const defaultMaxBodyBytes = 1 << 20
+
+type bodyLimitKey struct{}
+
+// withBodyLimit overrides the request body size limit for one route.
+func withBodyLimit(n int64, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		next(w, r.WithContext(context.WithValue(r.Context(), bodyLimitKey{}, n)))
+	}
+}
+
+func bodyLimit(r *http.Request) int64 {
+	if n, ok := r.Context().Value(bodyLimitKey{}).(int64); ok {
+		return n
+	}
+	return defaultMaxBodyBytes
+}
+
+// decode reads the request body into v and writes the error response itself
+// when that fails. The body must have a supported Content-Type, fit into the
+// route's size limit and contain exactly one value without unknown fields.
+func decode(w http.ResponseWriter, r *http.Request, v any) bool {
+	ct := r.Header.Get("Content-Type")
+	if strings.TrimSpace(ct) == "" {
+		apierr.UnsupportedMediaType(w, "Content-Type header is required; supported: "+strings.Join(codecs.Decodable(), ", "))
+		return false
+	}
+	c, ok := codecs.ForContentType(ct)
+	if !ok {
+		apierr.UnsupportedMediaType(w, fmt.Sprintf("unsupported Content-Type %q; supported: %s", ct, strings.Join(codecs.Decodable(), ", ")))
+		return false
+	}
+	limit := bodyLimit(r)
+	body := http.MaxBytesReader(w, r.Body, limit)
+	if err := c.Decoder.Decode(body, v); err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			apierr.PayloadTooLarge(w, fmt.Sprintf("request body must not exceed %d bytes", limit))
+			return false
+		}
+		apierr.BadRequest(w, describeDecodeError(err))
+		return false
+	}
+	return true
+}
+
+func describeDecodeError(err error) string {
+	var (
+		syntaxErr   *json.SyntaxError
+		typeErr     *json.UnmarshalTypeError
+		xmlErr      *xml.SyntaxError
+		trailingErr *codec.TrailingDataError
+		numErr      *strconv.NumError
+		moneyErr    *models.MoneyError
+	)
+	switch {
+	case errors.Is(err, io.EOF):
+		return "request body is empty"
+	case errors.Is(err, io.ErrUnexpectedEOF):
+		return "request body is truncated"
+	case errors.As(err, &syntaxErr):
+		return fmt.Sprintf("malformed body at offset %d: %s", syntaxErr.Offset, strings.TrimPrefix(syntaxErr.Error(), "json: "))
+	case errors.As(err, &typeErr):
+		if typeErr.Field == "" {
+			return fmt.Sprintf("body must be %s, got %s", typeName(typeErr.Type), typeErr.Value)
+		}
+		return fmt.Sprintf("field %q must be %s, got %s (offset %d)", typeErr.Field, typeName(typeErr.Type), typeErr.Value, typeErr.Offset)
+	case errors.As(err, &xmlErr):
+		return fmt.Sprintf("malformed XML at line %d: %s", xmlErr.Line, xmlErr.Msg)
+	case errors.As(err, &numErr):
+		return fmt.Sprintf("%q is not a valid number", numErr.Num)
+	case errors.As(err, &trailingErr):
+		return trailingErr.Error()
+	case errors.As(err, &moneyErr):
+		return moneyErr.Error()
+	case strings.HasPrefix(err.Error(), "json: unknown field "):
+		return strings.TrimPrefix(err.Error(), "json: ")
+	case strings.HasPrefix(err.Error(), "msgpack: unknown field "):
+		return strings.TrimPrefix(err.Error(), "msgpack: ")
+	}
+	return "invalid request body: " + err.Error()
+}
+
+// typeName describes a Go type in the terms of the wire format.
+func typeName(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.Pointer:
+		return typeName(t.Elem())
+	case reflect.Bool:
+		return "a boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "an integer"
+	case reflect.Float32, reflect.Float64:
+		return "a number"
+	case reflect.String:
+		return "a string"
+	case reflect.Slice, reflect.Array:
+		return "an array"
+	case reflect.Map, reflect.Struct:
+		return "an object"
+	}
+	return t.String()
+}
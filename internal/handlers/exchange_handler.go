@@ -0,0 +1,151 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"product-test/internal/apierr"
+	"product-test/internal/exchange"
+	"product-test/internal/models"
+	"product-test/internal/service"
+)
+
+// maxRatesFileBytes limits uploaded rate files; the ECB history files
+// carry many days of rates, of which only the latest is used.
+const maxRatesFileBytes = 16 << 20
+
+// ExchangeHandler serves the admin endpoints for exchange rates and
+// currency rounding rules. Access to /admin is restricted by the gateway.
+type ExchangeHandler struct {
+	service service.ExchangeService
+	log     *slog.Logger
+}
+
+func NewExchangeHandler(svc service.ExchangeService, log *slog.Logger) *ExchangeHandler {
+	if log == nil {
+		log = slog.Default()
+	}
+	return &ExchangeHandler{service: svc, log: log}
+}
+
+func (h *ExchangeHandler) RegisterRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("GET /admin/exchange-rates", negotiated(h.listRates))
+	mux.HandleFunc("POST /admin/exchange-rates/import", negotiated(h.importRates))
+	mux.HandleFunc("PUT /admin/exchange-rates/{base}/{quote}", negotiated(h.setRate))
+	mux.HandleFunc("DELETE /admin/exchange-rates/{base}/{quote}", h.deleteRate)
+	mux.HandleFunc("GET /admin/currency-rounding", negotiated(h.listRounding))
+	mux.HandleFunc("PUT /admin/currency-rounding/{currency}", negotiated(h.setRounding))
+	mux.HandleFunc("DELETE /admin/currency-rounding/{currency}", h.deleteRounding)
+}
+
+func (h *ExchangeHandler) listRates(w http.ResponseWriter, r *http.Request) {
+	rates, err := h.service.ListRates(r.Context())
+	if err != nil {
+		h.fail(w, "list exchange rates", err)
+		return
+	}
+	if rates == nil {
+		rates = []models.ExchangeRate{}
+	}
+	respond(w, r, h.log, http.StatusOK, rates)
+}
+
+func (h *ExchangeHandler) setRate(w http.ResponseWriter, r *http.Request) {
+	var in models.ExchangeRateInput
+	if !decode(w, r, &in) {
+		return
+	}
+	rate, err := h.service.SetRate(r.Context(), r.PathValue("base"), r.PathValue("quote"), in.Rate)
+	if err != nil {
+		h.fail(w, "set exchange rate", err)
+		return
+	}
+	respond(w, r, h.log, http.StatusOK, rate)
+}
+
+func (h *ExchangeHandler) deleteRate(w http.ResponseWriter, r *http.Request) {
+	if err := h.service.DeleteRate(r.Context(), r.PathValue("base"), r.PathValue("quote")); err != nil {
+		h.fail(w, "delete exchange rate", err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// importRates loads a rates file sent as the request body. The format
+// follows the Content-Type (text/csv or application/xml for ECB files)
+// unless ?format= names it.
+func (h *ExchangeHandler) importRates(w http.ResponseWriter, r *http.Request) {
+	name := r.URL.Query().Get("format")
+	if name == "" {
+		name = r.Header.Get("Content-Type")
+	}
+	format, err := exchange.ParseFormat(name)
+	if err != nil {
+		apierr.UnsupportedMediaType(w, err.Error())
+		return
+	}
+	rates, err := exchange.Parse(format, http.MaxBytesReader(w, r.Body, maxRatesFileBytes))
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			apierr.PayloadTooLarge(w, fmt.Sprintf("rates file must not exceed %d bytes", maxRatesFileBytes))
+			return
+		}
+		apierr.BadRequest(w, err.Error())
+		return
+	}
+	result, err := h.service.ImportRates(r.Context(), rates)
+	if err != nil {
+		h.fail(w, "import exchange rates", err)
+		return
+	}
+	respond(w, r, h.log, http.StatusOK, result)
+}
+
+func (h *ExchangeHandler) listRounding(w http.ResponseWriter, r *http.Request) {
+	rules, err := h.service.ListRoundingRules(r.Context())
+	if err != nil {
+		h.fail(w, "list rounding rules", err)
+		return
+	}
+	if rules == nil {
+		rules = []models.RoundingRule{}
+	}
+	respond(w, r, h.log, http.StatusOK, rules)
+}
+
+func (h *ExchangeHandler) setRounding(w http.ResponseWriter, r *http.Request) {
+	var in models.RoundingRuleInput
+	if !decode(w, r, &in) {
+		return
+	}
+	rule, err := h.service.SetRoundingRule(r.Context(), r.PathValue("currency"), in)
+	if err != nil {
+		h.fail(w, "set rounding rule", err)
+		return
+	}
+	respond(w, r, h.log, http.StatusOK, rule)
+}
+
+func (h *ExchangeHandler) deleteRounding(w http.ResponseWriter, r *http.Request) {
+	if err := h.service.DeleteRoundingRule(r.Context(), r.PathValue("currency")); err != nil {
+		h.fail(w, "delete rounding rule", err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *ExchangeHandler) fail(w http.ResponseWriter, op string, err error) {
+	switch {
+	case errors.Is(err, service.ErrValidation):
+		apierr.BadRequest(w, err.Error())
+	case errors.Is(err, service.ErrRateNotFound):
+		apierr.NotFound(w, "exchange rate not found")
+	case errors.Is(err, service.ErrRoundingNotFound):
+		apierr.NotFound(w, "rounding rule not found")
+	default:
+		h.log.Error(op, "error", err)
+		apierr.Internal(w)
+	}
+}
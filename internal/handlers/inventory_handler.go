@@ -0,0 +1,243 @@
+package handlers
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"product-test/internal/apierr"
+	"product-test/internal/models"
+	"product-test/internal/service"
+	"strconv"
+	"strings"
+)
+
+const maxInventoryBodyBytes = 8 << 10
+
+type InventoryHandler struct {
+	service service.InventoryService
+	log     *slog.Logger
+}
+
+func NewInventoryHandler(svc service.InventoryService, log *slog.Logger) *InventoryHandler {
+	if log == nil {
+		log = slog.Default()
+	}
+	return &InventoryHandler{service: svc, log: log}
+}
+
+func (h *InventoryHandler) RegisterRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("GET /products/{id}/stock", negotiated(h.stock))
+	mux.HandleFunc("PUT /products/{id}/stock", negotiated(withBodyLimit(maxInventoryBodyBytes, h.setSettings)))
+	mux.HandleFunc("GET /products/{id}/stock/movements", negotiated(h.movements))
+	mux.HandleFunc("POST /products/{id}/stock/movements", negotiated(withBodyLimit(maxInventoryBodyBytes, h.record)))
+	mux.HandleFunc("GET /products/{id}/stock/lots", negotiated(h.lots))
+	mux.HandleFunc("GET /products/{id}/availability", negotiated(h.availability))
+	mux.HandleFunc("GET /inventory/reorder-suggestions", negotiated(h.reorderSuggestions))
+	mux.HandleFunc("GET /inventory/expiring", negotiated(h.expiring))
+}
+
+// parseWarehouseParam reads the optional warehouse ID filter from the query
+// string.
+func parseWarehouseParam(w http.ResponseWriter, r *http.Request) (int, bool) {
+	v := r.URL.Query().Get("warehouse")
+	if v == "" {
+		return 0, true
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil || n <= 0 {
+		apierr.BadRequest(w, "invalid warehouse id")
+		return 0, false
+	}
+	return n, true
+}
+
+// parseStockKey reads the product ID from the path and the optional variant
+// ID from the query string.
+func parseStockKey(w http.ResponseWriter, r *http.Request) (models.StockKey, bool) {
+	id, ok := parseID(w, r, "id")
+	if !ok {
+		return models.StockKey{}, false
+	}
+	key := models.StockKey{ProductID: id}
+	if v := r.URL.Query().Get("variant"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			apierr.BadRequest(w, "invalid variant id")
+			return key, false
+		}
+		key.VariantID = n
+	}
+	return key, true
+}
+
+// parseDaysParam reads an optional number of days, such as 30 or 30d, from
+// the query string.
+func parseDaysParam(w http.ResponseWriter, r *http.Request, name string, def int) (int, bool) {
+	v := r.URL.Query().Get(name)
+	if v == "" {
+		return def, true
+	}
+	n, err := strconv.Atoi(strings.TrimSuffix(v, "d"))
+	if err != nil {
+		apierr.BadRequest(w, name+" must be a number of days")
+		return 0, false
+	}
+	return n, true
+}
+
+func (h *InventoryHandler) stock(w http.ResponseWriter, r *http.Request) {
+	key, ok := parseStockKey(w, r)
+	if !ok {
+		return
+	}
+	stock, err := h.service.GetStock(r.Context(), key)
+	if err != nil {
+		h.fail(w, "get stock", key.ProductID, err)
+		return
+	}
+	respond(w, r, h.log, http.StatusOK, stock)
+}
+
+func (h *InventoryHandler) setSettings(w http.ResponseWriter, r *http.Request) {
+	key, ok := parseStockKey(w, r)
+	if !ok {
+		return
+	}
+	var in models.StockSettings
+	if !decode(w, r, &in) {
+		return
+	}
+	stock, err := h.service.SetStockSettings(r.Context(), key, in)
+	if err != nil {
+		h.fail(w, "set stock settings", key.ProductID, err)
+		return
+	}
+	respond(w, r, h.log, http.StatusOK, stock)
+}
+
+func (h *InventoryHandler) movements(w http.ResponseWriter, r *http.Request) {
+	key, ok := parseStockKey(w, r)
+	if !ok {
+		return
+	}
+	filter := models.MovementFilter{ProductID: key.ProductID, VariantID: key.VariantID, Type: models.MovementType(r.URL.Query().Get("type"))}
+	if filter.WarehouseID, ok = parseWarehouseParam(w, r); !ok {
+		return
+	}
+	filter.Limit, filter.Offset = parseLimitOffset(r)
+	movements, total, err := h.service.ListMovements(r.Context(), filter)
+	if err != nil {
+		h.fail(w, "list stock movements", key.ProductID, err)
+		return
+	}
+	if movements == nil {
+		movements = []models.StockMovement{}
+	}
+	writePage(w, r, h.log, movements, pageInfo{
+		Total:   total,
+		Limit:   filter.Limit,
+		Offset:  filter.Offset,
+		HasMore: filter.Offset+len(movements) < total,
+	})
+}
+
+func (h *InventoryHandler) record(w http.ResponseWriter, r *http.Request) {
+	id, ok := parseID(w, r, "id")
+	if !ok {
+		return
+	}
+	var in models.StockMovementInput
+	if !decode(w, r, &in) {
+		return
+	}
+	m, err := h.service.RecordMovement(r.Context(), id, in)
+	if err != nil {
+		h.fail(w, "record stock movement", id, err)
+		return
+	}
+	respond(w, r, h.log, http.StatusCreated, m)
+}
+
+func (h *InventoryHandler) availability(w http.ResponseWriter, r *http.Request) {
+	key, ok := parseStockKey(w, r)
+	if !ok {
+		return
+	}
+	warehouseID, ok := parseWarehouseParam(w, r)
+	if !ok {
+		return
+	}
+	a, err := h.service.GetAvailability(r.Context(), key, warehouseID)
+	if err != nil {
+		h.fail(w, "get availability", key.ProductID, err)
+		return
+	}
+	respond(w, r, h.log, http.StatusOK, a)
+}
+
+func (h *InventoryHandler) reorderSuggestions(w http.ResponseWriter, r *http.Request) {
+	salesDays, ok := parseDaysParam(w, r, "days", 30)
+	if !ok {
+		return
+	}
+	coverDays, ok := parseDaysParam(w, r, "cover_days", 30)
+	if !ok {
+		return
+	}
+	suggestions, err := h.service.ReorderSuggestions(r.Context(), salesDays, coverDays)
+	if err != nil {
+		h.fail(w, "reorder suggestions", 0, err)
+		return
+	}
+	respond(w, r, h.log, http.StatusOK, suggestions)
+}
+
+func (h *InventoryHandler) lots(w http.ResponseWriter, r *http.Request) {
+	key, ok := parseStockKey(w, r)
+	if !ok {
+		return
+	}
+	warehouseID, ok := parseWarehouseParam(w, r)
+	if !ok {
+		return
+	}
+	lots, err := h.service.ListLots(r.Context(), key, warehouseID)
+	if err != nil {
+		h.fail(w, "list lots", key.ProductID, err)
+		return
+	}
+	respond(w, r, h.log, http.StatusOK, lots)
+}
+
+func (h *InventoryHandler) expiring(w http.ResponseWriter, r *http.Request) {
+	within, ok := parseDaysParam(w, r, "within", 30)
+	if !ok {
+		return
+	}
+	warehouseID, ok := parseWarehouseParam(w, r)
+	if !ok {
+		return
+	}
+	lots, err := h.service.ExpiringLots(r.Context(), within, warehouseID)
+	if err != nil {
+		h.fail(w, "list expiring lots", 0, err)
+		return
+	}
+	respond(w, r, h.log, http.StatusOK, lots)
+}
+
+func (h *InventoryHandler) fail(w http.ResponseWriter, op string, id int, err error) {
+	switch {
+	case errors.Is(err, service.ErrValidation):
+		badRequest(w, err)
+	case errors.Is(err, service.ErrInsufficientStock), errors.Is(err, service.ErrExpiredLot):
+		apierr.Conflict(w, err.Error())
+	case errors.Is(err, service.ErrNotFound):
+		apierr.NotFound(w, "product not found")
+	case errors.Is(err, service.ErrVariantNotFound):
+		apierr.NotFound(w, "variant not found")
+	default:
+		h.log.Error(op, "id", id, "error", err)
+		apierr.Internal(w)
+	}
+}
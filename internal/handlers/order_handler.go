@@ -0,0 +1,111 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"product-test/internal/apierr"
+	"product-test/internal/models"
+	"product-test/internal/service"
+)
+
+const maxOrderBodyBytes = 64 << 10
+
+type OrderHandler struct {
+	service service.OrderService
+	log     *slog.Logger
+}
+
+func NewOrderHandler(svc service.OrderService, log *slog.Logger) *OrderHandler {
+	if log == nil {
+		log = slog.Default()
+	}
+	return &OrderHandler{service: svc, log: log}
+}
+
+func (h *OrderHandler) RegisterRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("GET /orders", negotiated(h.list))
+	mux.HandleFunc("POST /orders", negotiated(withBodyLimit(maxOrderBodyBytes, h.create)))
+	mux.HandleFunc("GET /orders/{id}", negotiated(h.get))
+	mux.HandleFunc("POST /orders/{id}/pay", negotiated(h.transition("pay order", h.service.PayOrder)))
+	mux.HandleFunc("POST /orders/{id}/fulfill", negotiated(h.transition("fulfill order", h.service.FulfillOrder)))
+	mux.HandleFunc("POST /orders/{id}/deliver", negotiated(h.transition("deliver order", h.service.DeliverOrder)))
+	mux.HandleFunc("POST /orders/{id}/cancel", negotiated(h.transition("cancel order", h.service.CancelOrder)))
+	mux.HandleFunc("POST /orders/{id}/refund", negotiated(h.transition("refund order", h.service.RefundOrder)))
+}
+
+func (h *OrderHandler) list(w http.ResponseWriter, r *http.Request) {
+	filter := models.OrderFilter{Status: models.OrderStatus(r.URL.Query().Get("status"))}
+	filter.Limit, filter.Offset = parseLimitOffset(r)
+	orders, total, err := h.service.ListOrders(r.Context(), filter)
+	if err != nil {
+		h.fail(w, "list orders", 0, err)
+		return
+	}
+	if orders == nil {
+		orders = []models.Order{}
+	}
+	writePage(w, r, h.log, orders, pageInfo{
+		Total:   total,
+		Limit:   filter.Limit,
+		Offset:  filter.Offset,
+		HasMore: filter.Offset+len(orders) < total,
+	})
+}
+
+func (h *OrderHandler) create(w http.ResponseWriter, r *http.Request) {
+	var in models.OrderInput
+	if !decode(w, r, &in) {
+		return
+	}
+	o, err := h.service.CreateOrder(r.Context(), in)
+	if err != nil {
+		h.fail(w, "create order", 0, err)
+		return
+	}
+	respond(w, r, h.log, http.StatusCreated, o)
+}
+
+func (h *OrderHandler) get(w http.ResponseWriter, r *http.Request) {
+	id, ok := parsePathInt(w, r, "id", "order id")
+	if !ok {
+		return
+	}
+	o, err := h.service.GetOrder(r.Context(), int64(id))
+	if err != nil {
+		h.fail(w, "get order", id, err)
+		return
+	}
+	respond(w, r, h.log, http.StatusOK, o)
+}
+
+// transition handles a request moving an order to another status.
+func (h *OrderHandler) transition(op string, move func(context.Context, int64) (*models.Order, error)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, ok := parsePathInt(w, r, "id", "order id")
+		if !ok {
+			return
+		}
+		o, err := move(r.Context(), int64(id))
+		if err != nil {
+			h.fail(w, op, id, err)
+			return
+		}
+		respond(w, r, h.log, http.StatusOK, o)
+	}
+}
+
+func (h *OrderHandler) fail(w http.ResponseWriter, op string, id int, err error) {
+	switch {
+	case errors.Is(err, service.ErrValidation):
+		badRequest(w, err)
+	case errors.Is(err, service.ErrOrderState), errors.Is(err, service.ErrInsufficientStock):
+		apierr.Conflict(w, err.Error())
+	case errors.Is(err, service.ErrOrderNotFound):
+		apierr.NotFound(w, "order not found")
+	default:
+		h.log.Error(op, "id", id, "error", err)
+		apierr.Internal(w)
+	}
+}
@@ -0,0 +1,76 @@
+package handlers
+
+import (
+	"encoding/xml"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// pageInfo describes where a page sits in the whole result.
+type pageInfo struct {
+	Total          int  `json:"total" xml:"total"`
+	TotalEstimated bool `json:"total_estimated,omitempty" xml:"total_estimated,omitempty"`
+	Limit          int  `json:"limit" xml:"limit"`
+	Offset         int  `json:"offset" xml:"offset"`
+	HasMore        bool `json:"has_more" xml:"has_more"`
+}
+
+// envelope wraps a page of results when the client asks for ?envelope=true.
+type envelope struct {
+	XMLName xml.Name `json:"-" xml:"page"`
+	Data    any      `json:"data" xml:"data"`
+	pageInfo
+}
+
+// writePage writes a listing as a bare array with pagination headers, or
+// wrapped in an envelope when the client asks for ?envelope=true.
+func writePage(w http.ResponseWriter, r *http.Request, log *slog.Logger, data any, info pageInfo) {
+	setPaginationHeaders(w, r, info.Total, info.Limit, info.Offset, info.HasMore)
+	if info.TotalEstimated {
+		w.Header().Set("X-Total-Count-Estimated", "true")
+	}
+	if parseBool(r.URL.Query().Get("envelope")) {
+		respond(w, r, log, http.StatusOK, envelope{Data: data, pageInfo: info})
+		return
+	}
+	respond(w, r, log, http.StatusOK, data)
+}
+
+// setPaginationHeaders sets X-Total-Count and an RFC 8288 Link header with
+// first, prev, next and last relations built from the request URL.
+func setPaginationHeaders(w http.ResponseWriter, r *http.Request, total, limit, offset int, hasMore bool) {
+	w.Header().Set("X-Total-Count", strconv.Itoa(total))
+	if limit <= 0 {
+		return
+	}
+	links := []string{pageLink(r, "first", limit, 0)}
+	if offset > 0 {
+		links = append(links, pageLink(r, "prev", limit, max(offset-limit, 0)))
+	}
+	if hasMore {
+		links = append(links, pageLink(r, "next", limit, offset+limit))
+	}
+	last := 0
+	if total > 0 {
+		last = (total - 1) / limit * limit
+	}
+	links = append(links, pageLink(r, "last", limit, last))
+	w.Header().Set("Link", strings.Join(links, ", "))
+}
+
+func pageLink(r *http.Request, rel string, limit, offset int) string {
+	q := r.URL.Query()
+	q.Set("limit", strconv.Itoa(limit))
+	q.Set("offset", strconv.Itoa(offset))
+	u := url.URL{Path: r.URL.Path, RawQuery: q.Encode()}
+	return fmt.Sprintf(`<%s>; rel="%s"`, u.String(), rel)
+}
+
+func parseBool(v string) bool {
+	b, _ := strconv.ParseBool(v)
+	return b
+}
@@ -0,0 +1,87 @@
+package handlers
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"product-test/internal/apierr"
+	"product-test/internal/models"
+	"product-test/internal/service"
+	"time"
+)
+
+type PriceHandler struct {
+	service service.PriceService
+	log     *slog.Logger
+}
+
+func NewPriceHandler(svc service.PriceService, log *slog.Logger) *PriceHandler {
+	if log == nil {
+		log = slog.Default()
+	}
+	return &PriceHandler{service: svc, log: log}
+}
+
+func (h *PriceHandler) RegisterRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("GET /products/{id}/prices", negotiated(h.history))
+	mux.HandleFunc("GET /products/{id}/price", negotiated(h.at))
+}
+
+func (h *PriceHandler) history(w http.ResponseWriter, r *http.Request) {
+	id, ok := parseID(w, r, "id")
+	if !ok {
+		return
+	}
+	filter := models.PriceHistoryFilter{ProductID: id}
+	if filter.From, ok = parseTimeParam(w, r, "from"); !ok {
+		return
+	}
+	if filter.To, ok = parseTimeParam(w, r, "to"); !ok {
+		return
+	}
+	filter.Limit, filter.Offset = parseLimitOffset(r)
+	periods, total, err := h.service.GetPriceHistory(r.Context(), filter)
+	if err != nil {
+		if errors.Is(err, service.ErrValidation) {
+			apierr.BadRequest(w, err.Error())
+			return
+		}
+		h.log.Error("price history", "id", id, "error", err)
+		apierr.Internal(w)
+		return
+	}
+	if periods == nil {
+		periods = []models.PricePeriod{}
+	}
+	writePage(w, r, h.log, periods, pageInfo{
+		Total:   total,
+		Limit:   filter.Limit,
+		Offset:  filter.Offset,
+		HasMore: filter.Offset+len(periods) < total,
+	})
+}
+
+func (h *PriceHandler) at(w http.ResponseWriter, r *http.Request) {
+	id, ok := parseID(w, r, "id")
+	if !ok {
+		return
+	}
+	at, ok := parseTimeParam(w, r, "at")
+	if !ok {
+		return
+	}
+	if at.IsZero() {
+		at = time.Now()
+	}
+	p, err := h.service.GetPriceAt(r.Context(), id, at)
+	if err != nil {
+		if errors.Is(err, service.ErrNoPrice) {
+			apierr.NotFound(w, "no price in effect at that time")
+			return
+		}
+		h.log.Error("price at", "id", id, "error", err)
+		apierr.Internal(w)
+		return
+	}
+	respond(w, r, h.log, http.StatusOK, p)
+}
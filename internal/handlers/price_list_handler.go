@@ -0,0 +1,224 @@
+package handlers
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"product-test/internal/apierr"
+	"product-test/internal/models"
+	"product-test/internal/service"
+	"strconv"
+)
+
+const maxPriceListBodyBytes = 64 << 10
+
+type PriceListHandler struct {
+	service service.PriceListService
+	log     *slog.Logger
+}
+
+func NewPriceListHandler(svc service.PriceListService, log *slog.Logger) *PriceListHandler {
+	if log == nil {
+		log = slog.Default()
+	}
+	return &PriceListHandler{service: svc, log: log}
+}
+
+func (h *PriceListHandler) RegisterRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("GET /price-lists", negotiated(h.listPriceLists))
+	mux.HandleFunc("POST /price-lists", negotiated(withBodyLimit(maxPriceListBodyBytes, h.createPriceList)))
+	mux.HandleFunc("PUT /price-lists/{id}", negotiated(withBodyLimit(maxPriceListBodyBytes, h.updatePriceList)))
+	mux.HandleFunc("DELETE /price-lists/{id}", h.deletePriceList)
+	mux.HandleFunc("GET /price-lists/{id}/products/{product}/tiers", negotiated(h.getTiers))
+	mux.HandleFunc("PUT /price-lists/{id}/products/{product}/tiers", negotiated(withBodyLimit(maxPriceListBodyBytes, h.setTiers)))
+	mux.HandleFunc("GET /customer-groups", negotiated(h.listGroups))
+	mux.HandleFunc("POST /customer-groups", negotiated(withBodyLimit(maxPriceListBodyBytes, h.createGroup)))
+	mux.HandleFunc("PUT /customer-groups/{id}", negotiated(withBodyLimit(maxPriceListBodyBytes, h.updateGroup)))
+	mux.HandleFunc("DELETE /customer-groups/{id}", h.deleteGroup)
+	mux.HandleFunc("GET /products/{id}/quote", negotiated(h.quote))
+}
+
+func (h *PriceListHandler) listPriceLists(w http.ResponseWriter, r *http.Request) {
+	lists, err := h.service.ListPriceLists(r.Context())
+	if err != nil {
+		h.fail(w, "list price lists", err)
+		return
+	}
+	if lists == nil {
+		lists = []models.PriceList{}
+	}
+	respond(w, r, h.log, http.StatusOK, lists)
+}
+
+func (h *PriceListHandler) createPriceList(w http.ResponseWriter, r *http.Request) {
+	var in models.PriceListInput
+	if !decode(w, r, &in) {
+		return
+	}
+	l, err := h.service.CreatePriceList(r.Context(), in)
+	if err != nil {
+		h.fail(w, "create price list", err)
+		return
+	}
+	respond(w, r, h.log, http.StatusCreated, l)
+}
+
+func (h *PriceListHandler) updatePriceList(w http.ResponseWriter, r *http.Request) {
+	id, ok := parsePathInt(w, r, "id", "price list id")
+	if !ok {
+		return
+	}
+	var in models.PriceListInput
+	if !decode(w, r, &in) {
+		return
+	}
+	l, err := h.service.UpdatePriceList(r.Context(), id, in)
+	if err != nil {
+		h.fail(w, "update price list", err)
+		return
+	}
+	respond(w, r, h.log, http.StatusOK, l)
+}
+
+func (h *PriceListHandler) deletePriceList(w http.ResponseWriter, r *http.Request) {
+	id, ok := parsePathInt(w, r, "id", "price list id")
+	if !ok {
+		return
+	}
+	if err := h.service.DeletePriceList(r.Context(), id); err != nil {
+		h.fail(w, "delete price list", err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *PriceListHandler) getTiers(w http.ResponseWriter, r *http.Request) {
+	listID, ok := parsePathInt(w, r, "id", "price list id")
+	if !ok {
+		return
+	}
+	productID, ok := parseID(w, r, "product")
+	if !ok {
+		return
+	}
+	tiers, err := h.service.GetTiers(r.Context(), listID, productID)
+	if err != nil {
+		h.fail(w, "get price tiers", err)
+		return
+	}
+	respond(w, r, h.log, http.StatusOK, tiers)
+}
+
+func (h *PriceListHandler) setTiers(w http.ResponseWriter, r *http.Request) {
+	listID, ok := parsePathInt(w, r, "id", "price list id")
+	if !ok {
+		return
+	}
+	productID, ok := parseID(w, r, "product")
+	if !ok {
+		return
+	}
+	var in models.PriceTiers
+	if !decode(w, r, &in) {
+		return
+	}
+	tiers, err := h.service.SetTiers(r.Context(), listID, productID, in.Tiers)
+	if err != nil {
+		h.fail(w, "set price tiers", err)
+		return
+	}
+	respond(w, r, h.log, http.StatusOK, tiers)
+}
+
+func (h *PriceListHandler) listGroups(w http.ResponseWriter, r *http.Request) {
+	groups, err := h.service.ListGroups(r.Context())
+	if err != nil {
+		h.fail(w, "list customer groups", err)
+		return
+	}
+	if groups == nil {
+		groups = []models.CustomerGroup{}
+	}
+	respond(w, r, h.log, http.StatusOK, groups)
+}
+
+func (h *PriceListHandler) createGroup(w http.ResponseWriter, r *http.Request) {
+	var in models.CustomerGroupInput
+	if !decode(w, r, &in) {
+		return
+	}
+	g, err := h.service.CreateGroup(r.Context(), in)
+	if err != nil {
+		h.fail(w, "create customer group", err)
+		return
+	}
+	respond(w, r, h.log, http.StatusCreated, g)
+}
+
+func (h *PriceListHandler) updateGroup(w http.ResponseWriter, r *http.Request) {
+	id, ok := parsePathInt(w, r, "id", "customer group id")
+	if !ok {
+		return
+	}
+	var in models.CustomerGroupInput
+	if !decode(w, r, &in) {
+		return
+	}
+	g, err := h.service.UpdateGroup(r.Context(), id, in)
+	if err != nil {
+		h.fail(w, "update customer group", err)
+		return
+	}
+	respond(w, r, h.log, http.StatusOK, g)
+}
+
+func (h *PriceListHandler) deleteGroup(w http.ResponseWriter, r *http.Request) {
+	id, ok := parsePathInt(w, r, "id", "customer group id")
+	if !ok {
+		return
+	}
+	if err := h.service.DeleteGroup(r.Context(), id); err != nil {
+		h.fail(w, "delete customer group", err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *PriceListHandler) quote(w http.ResponseWriter, r *http.Request) {
+	id, ok := parseID(w, r, "id")
+	if !ok {
+		return
+	}
+	q := r.URL.Query()
+	qty := 1
+	if s := q.Get("qty"); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil {
+			apierr.BadRequest(w, "qty must be an integer")
+			return
+		}
+		qty = n
+	}
+	quote, err := h.service.Quote(r.Context(), id, qty, q.Get("price_list"), q.Get("customer_group"))
+	if err != nil {
+		h.fail(w, "quote product", err)
+		return
+	}
+	respond(w, r, h.log, http.StatusOK, quote)
+}
+
+func (h *PriceListHandler) fail(w http.ResponseWriter, op string, err error) {
+	switch {
+	case errors.Is(err, service.ErrValidation):
+		apierr.BadRequest(w, err.Error())
+	case errors.Is(err, service.ErrPriceListNotFound):
+		apierr.NotFound(w, "price list not found")
+	case errors.Is(err, service.ErrGroupNotFound):
+		apierr.NotFound(w, "customer group not found")
+	case errors.Is(err, service.ErrNotFound):
+		apierr.NotFound(w, "product not found")
+	default:
+		h.log.Error(op, "error", err)
+		apierr.Internal(w)
+	}
+}
@@ -0,0 +1,116 @@
+package handlers
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"product-test/internal/apierr"
+	"product-test/internal/models"
+	"product-test/internal/service"
+)
+
+const maxScheduleBodyBytes = 8 << 10
+
+type PriceScheduleHandler struct {
+	service service.PriceScheduleService
+	log     *slog.Logger
+}
+
+func NewPriceScheduleHandler(svc service.PriceScheduleService, log *slog.Logger) *PriceScheduleHandler {
+	if log == nil {
+		log = slog.Default()
+	}
+	return &PriceScheduleHandler{service: svc, log: log}
+}
+
+func (h *PriceScheduleHandler) RegisterRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("GET /products/{id}/price-schedules", negotiated(h.list))
+	mux.HandleFunc("POST /products/{id}/price-schedules", negotiated(withBodyLimit(maxScheduleBodyBytes, h.create)))
+	mux.HandleFunc("PUT /products/{id}/price-schedules/{sid}", negotiated(withBodyLimit(maxScheduleBodyBytes, h.update)))
+	mux.HandleFunc("DELETE /products/{id}/price-schedules/{sid}", h.cancel)
+}
+
+func (h *PriceScheduleHandler) list(w http.ResponseWriter, r *http.Request) {
+	id, ok := parseID(w, r, "id")
+	if !ok {
+		return
+	}
+	status := models.ScheduleStatus(r.URL.Query().Get("status"))
+	schedules, err := h.service.ListSchedules(r.Context(), id, status)
+	if err != nil {
+		h.fail(w, "list price schedules", id, err)
+		return
+	}
+	if schedules == nil {
+		schedules = []models.PriceSchedule{}
+	}
+	respond(w, r, h.log, http.StatusOK, schedules)
+}
+
+func (h *PriceScheduleHandler) create(w http.ResponseWriter, r *http.Request) {
+	id, ok := parseID(w, r, "id")
+	if !ok {
+		return
+	}
+	var in models.PriceScheduleInput
+	if !decode(w, r, &in) {
+		return
+	}
+	sched, err := h.service.CreateSchedule(r.Context(), id, in)
+	if err != nil {
+		h.fail(w, "create price schedule", id, err)
+		return
+	}
+	respond(w, r, h.log, http.StatusCreated, sched)
+}
+
+func (h *PriceScheduleHandler) update(w http.ResponseWriter, r *http.Request) {
+	id, ok := parseID(w, r, "id")
+	if !ok {
+		return
+	}
+	sid, ok := parsePathInt(w, r, "sid", "schedule id")
+	if !ok {
+		return
+	}
+	var in models.PriceScheduleInput
+	if !decode(w, r, &in) {
+		return
+	}
+	sched, err := h.service.UpdateSchedule(r.Context(), id, int64(sid), in)
+	if err != nil {
+		h.fail(w, "update price schedule", id, err)
+		return
+	}
+	respond(w, r, h.log, http.StatusOK, sched)
+}
+
+func (h *PriceScheduleHandler) cancel(w http.ResponseWriter, r *http.Request) {
+	id, ok := parseID(w, r, "id")
+	if !ok {
+		return
+	}
+	sid, ok := parsePathInt(w, r, "sid", "schedule id")
+	if !ok {
+		return
+	}
+	if err := h.service.CancelSchedule(r.Context(), id, int64(sid)); err != nil {
+		h.fail(w, "cancel price schedule", id, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *PriceScheduleHandler) fail(w http.ResponseWriter, op string, id int, err error) {
+	switch {
+	case errors.Is(err, service.ErrValidation), errors.Is(err, service.ErrScheduleState):
+		apierr.BadRequest(w, err.Error())
+	case errors.Is(err, service.ErrScheduleNotFound):
+		apierr.NotFound(w, "price schedule not found")
+	case errors.Is(err, service.ErrNotFound):
+		apierr.NotFound(w, "product not found")
+	default:
+		h.log.Error(op, "id", id, "error", err)
+		apierr.Internal(w)
+	}
+}
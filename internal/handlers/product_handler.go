@@ -1,64 +1,208 @@
 package handlers
 
 import (
-	"encoding/json"
+	"context"
 	"errors"
 	"log/slog"
+	"maps"
 	"net/http"
+	"net/url"
 	"product-test/internal/apierr"
+	"product-test/internal/export"
 	"product-test/internal/models"
 	"product-test/internal/service"
+	"slices"
 	"strconv"
+	"strings"
 )
 
+// maxProductBodyBytes limits create and update bodies; a product is a few
+// short strings and a number.
+const maxProductBodyBytes = 64 << 10
+
 type ProductHandler struct {
-	service service.ProductService
-	log     *slog.Logger
+	service  service.ProductService
+	exchange service.ExchangeService
+	log      *slog.Logger
 }
 
-func NewProductHandler(svc service.ProductService, log *slog.Logger) *ProductHandler {
+func NewProductHandler(svc service.ProductService, exchange service.ExchangeService, log *slog.Logger) *ProductHandler {
 	if log == nil {
 		log = slog.Default()
 	}
-	return &ProductHandler{service: svc, log: log}
+	return &ProductHandler{service: svc, exchange: exchange, log: log}
 }
 
 func (h *ProductHandler) RegisterRoutes(mux *http.ServeMux) {
-	mux.HandleFunc("GET /products", h.getAll)
-	mux.HandleFunc("POST /products", h.create)
-	mux.HandleFunc("GET /products/{id}", h.getByID)
-	mux.HandleFunc("PUT /products/{id}", h.update)
+	mux.HandleFunc("GET /products", negotiated(h.getAll))
+	mux.HandleFunc("POST /products", negotiated(withBodyLimit(maxProductBodyBytes, h.create)))
+	mux.HandleFunc("GET /products/export", h.export)
+	mux.HandleFunc("GET /products/trash", negotiated(h.trash))
+	mux.HandleFunc("POST /products/{id}/restore", negotiated(h.restore))
+	mux.HandleFunc("GET /products/{id}", negotiated(h.getByID))
+	mux.HandleFunc("PUT /products/{id}", negotiated(withBodyLimit(maxProductBodyBytes, h.update)))
 	mux.HandleFunc("DELETE /products/{id}", h.delete)
+	mux.HandleFunc("GET /categories/{id}/products", negotiated(h.byCategory))
 }
 
 func (h *ProductHandler) getAll(w http.ResponseWriter, r *http.Request) {
-	limit, offset := parseLimitOffset(r)
-	products, err := h.service.GetAllProducts(r.Context(), limit, offset)
+	filter, ok := parseProductFilter(w, r)
+	if !ok {
+		return
+	}
+	filter.Limit, filter.Offset = parseLimitOffset(r)
+	h.list(w, r, filter)
+}
+
+func (h *ProductHandler) trash(w http.ResponseWriter, r *http.Request) {
+	filter, ok := parseProductFilter(w, r)
+	if !ok {
+		return
+	}
+	filter.Limit, filter.Offset = parseLimitOffset(r)
+	filter.Trashed = true
+	h.list(w, r, filter)
+}
+
+// byCategory lists the products of a category, the same as
+// GET /products?category={id}.
+func (h *ProductHandler) byCategory(w http.ResponseWriter, r *http.Request) {
+	id, ok := parsePathInt(w, r, "id", "category id")
+	if !ok {
+		return
+	}
+	filter, ok := parseProductFilter(w, r)
+	if !ok {
+		return
+	}
+	filter.Limit, filter.Offset = parseLimitOffset(r)
+	filter.CategoryID = id
+	h.list(w, r, filter)
+}
+
+func (h *ProductHandler) list(w http.ResponseWriter, r *http.Request, filter models.ProductFilter) {
+	page, err := h.service.ListProducts(r.Context(), filter, r.URL.Query().Get("count") == "estimated")
 	if err != nil {
-		h.log.Error("get all products", "error", err)
+		if errors.Is(err, service.ErrValidation) {
+			apierr.BadRequest(w, err.Error())
+			return
+		}
+		if errors.Is(err, service.ErrCategoryNotFound) {
+			apierr.NotFound(w, "category not found")
+			return
+		}
+		h.log.Error("list products", "trashed", filter.Trashed, "error", err)
 		apierr.Internal(w)
 		return
 	}
-	h.writeJSON(w, http.StatusOK, products)
+	currency := r.URL.Query().Get("currency")
+	if !h.convert(w, r, page.Items, currency) {
+		return
+	}
+
+	var data any = page.Items
+	if len(filter.Fields) > 0 {
+		fields := filter.Fields
+		if currency != "" {
+			fields = append(slices.Clip(fields), "converted_price")
+		}
+		views := make([]models.ProductView, len(page.Items))
+		for i := range page.Items {
+			views[i] = models.ProductView{Product: &page.Items[i], Fields: fields}
+		}
+		data = views
+	}
+	writePage(w, r, h.log, data, pageInfo{
+		Total:          page.Total,
+		TotalEstimated: page.TotalEstimated,
+		Limit:          page.Limit,
+		Offset:         page.Offset,
+		HasMore:        page.HasMore,
+	})
+}
+
+func (h *ProductHandler) export(w http.ResponseWriter, r *http.Request) {
+	format, err := export.ParseFormat(r.URL.Query().Get("format"))
+	if err != nil {
+		apierr.BadRequest(w, err.Error())
+		return
+	}
+	filter, ok := parseProductFilter(w, r)
+	if !ok {
+		return
+	}
+	filter.Limit, filter.Offset = parseExportRange(r)
+
+	// The response is started lazily so that a failing query can still be
+	// reported as a regular API error.
+	var ew export.Writer
+	start := func() (err error) {
+		w.Header().Set("Content-Type", format.ContentType())
+		w.Header().Set("Content-Disposition", `attachment; filename="`+format.Filename()+`"`)
+		ew, err = export.NewWriter(format, w, filter.Fields)
+		return err
+	}
+	rc := http.NewResponseController(w)
+	err = h.service.ExportProducts(r.Context(), filter, func(batch []models.Product) error {
+		if ew == nil {
+			if err := start(); err != nil {
+				return err
+			}
+		}
+		for i := range batch {
+			if err := ew.Write(&batch[i]); err != nil {
+				return err
+			}
+		}
+		if err := ew.Flush(); err != nil {
+			return err
+		}
+		_ = rc.Flush()
+		return nil
+	})
+	if err == nil && ew == nil {
+		err = start()
+	}
+	if err != nil {
+		if ew == nil && errors.Is(err, service.ErrValidation) {
+			apierr.BadRequest(w, err.Error())
+			return
+		}
+		if ew == nil && errors.Is(err, service.ErrCategoryNotFound) {
+			apierr.NotFound(w, "category not found")
+			return
+		}
+		h.log.Error("export products", "error", err)
+		if ew == nil {
+			apierr.Internal(w)
+		}
+		return
+	}
+	if err := ew.Close(); err != nil {
+		h.log.Error("export products: close writer", "error", err)
+	}
 }
 
 func (h *ProductHandler) create(w http.ResponseWriter, r *http.Request) {
 	var p models.Product
-	if err := json.NewDecoder(r.Body).Decode(&p); err != nil {
-		apierr.BadRequest(w, "invalid JSON")
+	if !decode(w, r, &p) {
 		return
 	}
 	p.ID = 0
 	if err := h.service.CreateProduct(r.Context(), &p); err != nil {
 		if errors.Is(err, service.ErrValidation) {
-			apierr.BadRequest(w, err.Error())
+			badRequest(w, err)
+			return
+		}
+		if errors.Is(err, service.ErrConflict) {
+			apierr.Conflict(w, err.Error())
 			return
 		}
 		h.log.Error("create product", "error", err)
 		apierr.Internal(w)
 		return
 	}
-	h.writeJSON(w, http.StatusCreated, p)
+	h.write(w, r, http.StatusCreated, p)
 }
 
 func (h *ProductHandler) getByID(w http.ResponseWriter, r *http.Request) {
@@ -66,7 +210,19 @@ func (h *ProductHandler) getByID(w http.ResponseWriter, r *http.Request) {
 	if !ok {
 		return
 	}
-	p, err := h.service.GetProductByID(r.Context(), id)
+	asOf, ok := parseTimeParam(w, r, "as_of")
+	if !ok {
+		return
+	}
+	var (
+		p   *models.Product
+		err error
+	)
+	if asOf.IsZero() {
+		p, err = h.service.GetProductByID(r.Context(), id)
+	} else {
+		p, err = h.service.GetProductAsOf(r.Context(), id, asOf)
+	}
 	if err != nil {
 		if errors.Is(err, service.ErrNotFound) {
 			apierr.NotFound(w, "product not found")
@@ -76,7 +232,69 @@ func (h *ProductHandler) getByID(w http.ResponseWriter, r *http.Request) {
 		apierr.Internal(w)
 		return
 	}
-	h.writeJSON(w, http.StatusOK, p)
+	products := []models.Product{*p}
+	if !h.convert(w, r, products, r.URL.Query().Get("currency")) {
+		return
+	}
+	h.write(w, r, http.StatusOK, &products[0])
+}
+
+// Lookups serves GET /products/by-sku/{sku}, /products/by-barcode/{code}
+// and /products/by-slug/{slug} in front of next. They cannot be registered
+// on the same ServeMux as the /products/{id}/... routes, which match the
+// same paths: /products/by-sku/tags is either.
+func (h *ProductHandler) Lookups(next http.Handler) http.Handler {
+	lookups := http.NewServeMux()
+	lookups.HandleFunc("GET /products/by-sku/{sku}", negotiated(h.lookup("sku", h.service.GetProductBySKU)))
+	lookups.HandleFunc("GET /products/by-barcode/{code}", negotiated(h.lookup("code", h.service.GetProductByBarcode)))
+	lookups.HandleFunc("GET /products/by-slug/{slug}", negotiated(h.lookup("slug", h.service.GetProductBySlug)))
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, pattern := lookups.Handler(r); pattern != "" {
+			lookups.ServeHTTP(w, r)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (h *ProductHandler) lookup(param string, find func(context.Context, string) (*models.Product, error)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		key := r.PathValue(param)
+		p, err := find(r.Context(), key)
+		if err != nil {
+			if errors.Is(err, service.ErrNotFound) {
+				apierr.NotFound(w, "product not found")
+				return
+			}
+			h.log.Error("look up product", param, key, "error", err)
+			apierr.Internal(w)
+			return
+		}
+		products := []models.Product{*p}
+		if !h.convert(w, r, products, r.URL.Query().Get("currency")) {
+			return
+		}
+		h.write(w, r, http.StatusOK, &products[0])
+	}
+}
+
+// convert fills in the converted prices when a currency was requested and
+// writes the error response itself when that fails.
+func (h *ProductHandler) convert(w http.ResponseWriter, r *http.Request, products []models.Product, currency string) bool {
+	if currency == "" {
+		return true
+	}
+	err := h.exchange.ConvertPrices(r.Context(), products, currency)
+	switch {
+	case err == nil:
+		return true
+	case errors.Is(err, service.ErrValidation):
+		apierr.BadRequest(w, err.Error())
+	default:
+		h.log.Error("convert prices", "currency", currency, "error", err)
+		apierr.Internal(w)
+	}
+	return false
 }
 
 func (h *ProductHandler) update(w http.ResponseWriter, r *http.Request) {
@@ -85,14 +303,17 @@ func (h *ProductHandler) update(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	var p models.Product
-	if err := json.NewDecoder(r.Body).Decode(&p); err != nil {
-		apierr.BadRequest(w, "invalid JSON")
+	if !decode(w, r, &p) {
 		return
 	}
 	p.ID = id
 	if err := h.service.UpdateProduct(r.Context(), &p); err != nil {
 		if errors.Is(err, service.ErrValidation) {
-			apierr.BadRequest(w, err.Error())
+			badRequest(w, err)
+			return
+		}
+		if errors.Is(err, service.ErrConflict) {
+			apierr.Conflict(w, err.Error())
 			return
 		}
 		if errors.Is(err, service.ErrNotFound) {
@@ -103,7 +324,7 @@ func (h *ProductHandler) update(w http.ResponseWriter, r *http.Request) {
 		apierr.Internal(w)
 		return
 	}
-	h.writeJSON(w, http.StatusOK, p)
+	h.write(w, r, http.StatusOK, p)
 }
 
 func (h *ProductHandler) delete(w http.ResponseWriter, r *http.Request) {
@@ -123,6 +344,24 @@ func (h *ProductHandler) delete(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusNoContent)
 }
 
+func (h *ProductHandler) restore(w http.ResponseWriter, r *http.Request) {
+	id, ok := parseID(w, r, "id")
+	if !ok {
+		return
+	}
+	p, err := h.service.RestoreProduct(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, service.ErrNotFound) {
+			apierr.NotFound(w, "product not found in trash")
+			return
+		}
+		h.log.Error("restore product", "id", id, "error", err)
+		apierr.Internal(w)
+		return
+	}
+	h.write(w, r, http.StatusOK, p)
+}
+
 func parseLimitOffset(r *http.Request) (limit, offset int) {
 	limit = 100
 	offset = 0
@@ -142,24 +381,110 @@ func parseLimitOffset(r *http.Request) (limit, offset int) {
 	return limit, offset
 }
 
-func parseID(w http.ResponseWriter, r *http.Request, param string) (int, bool) {
-	idStr := r.PathValue(param)
-	if idStr == "" {
-		apierr.BadRequest(w, "invalid product id")
-		return 0, false
+// parseProductFilter reads the query parameters shared by listing and
+// export and writes the error response itself for malformed values.
+func parseProductFilter(w http.ResponseWriter, r *http.Request) (models.ProductFilter, bool) {
+	q := r.URL.Query()
+	filter := models.ProductFilter{
+		Fields:  parseList(q.Get("fields")),
+		Include: parseList(q.Get("include")),
 	}
-	id, err := strconv.Atoi(idStr)
-	if err != nil || id <= 0 {
-		apierr.BadRequest(w, "invalid product id")
-		return 0, false
+	if v := q.Get("category"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			apierr.BadRequest(w, "category must be a positive integer")
+			return filter, false
+		}
+		filter.CategoryID = n
 	}
-	return id, true
+	if v := q.Get("include_descendants"); v != "" {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			apierr.BadRequest(w, "include_descendants must be true or false")
+			return filter, false
+		}
+		filter.IncludeDescendants = b
+	}
+	filter.Tags = parseList(q.Get("tags"))
+	filter.TagMode = models.TagMode(q.Get("tag_mode"))
+	filter.Attributes = parseAttributeFilters(q)
+	return filter, true
+}
+
+// attributeOpSuffixes are checked in order, so that _gte wins over _gt.
+var attributeOpSuffixes = []models.AttributeOp{models.AttributeGte, models.AttributeLte, models.AttributeGt, models.AttributeLt}
+
+// parseAttributeFilters reads the attr.<name>[_gt|_gte|_lt|_lte] parameters
+// in name order. Repeated and comma-separated values of an equality are
+// alternatives.
+func parseAttributeFilters(q url.Values) []models.AttributeFilter {
+	var filters []models.AttributeFilter
+	for _, key := range slices.Sorted(maps.Keys(q)) {
+		name, ok := strings.CutPrefix(key, "attr.")
+		if !ok {
+			continue
+		}
+		f := models.AttributeFilter{Name: name, Op: models.AttributeEq}
+		for _, op := range attributeOpSuffixes {
+			if base, ok := strings.CutSuffix(name, "_"+string(op)); ok {
+				f.Name, f.Op = base, op
+				break
+			}
+		}
+		for _, v := range q[key] {
+			if f.Op == models.AttributeEq {
+				f.Values = append(f.Values, parseList(v)...)
+			} else {
+				f.Values = append(f.Values, strings.TrimSpace(v))
+			}
+		}
+		filters = append(filters, f)
+	}
+	return filters
+}
+
+// parseList splits a comma-separated parameter, dropping blanks and duplicates.
+func parseList(v string) []string {
+	var items []string
+	for _, item := range strings.Split(v, ",") {
+		item = strings.TrimSpace(item)
+		if item != "" && !slices.Contains(items, item) {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// parseExportRange is like parseLimitOffset, but exports are unbounded by default.
+func parseExportRange(r *http.Request) (limit, offset int) {
+	if v := r.URL.Query().Get("limit"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 {
+			limit = n
+		}
+	}
+	if v := r.URL.Query().Get("offset"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n >= 0 {
+			offset = n
+		}
+	}
+	return limit, offset
+}
+
+func parseID(w http.ResponseWriter, r *http.Request, param string) (int, bool) {
+	return parsePathInt(w, r, param, "product id")
 }
 
-func (h *ProductHandler) writeJSON(w http.ResponseWriter, status int, v any) {
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(status)
-	if err := json.NewEncoder(w).Encode(v); err != nil {
-		h.log.Error("encode response", "error", err)
+// parsePathInt reads a positive integer path parameter; label names it in
+// the error message.
+func parsePathInt(w http.ResponseWriter, r *http.Request, param, label string) (int, bool) {
+	n, err := strconv.Atoi(r.PathValue(param))
+	if err != nil || n <= 0 {
+		apierr.BadRequest(w, "invalid "+label)
+		return 0, false
 	}
+	return n, true
+}
+
+func (h *ProductHandler) write(w http.ResponseWriter, r *http.Request, status int, v any) {
+	respond(w, r, h.log, status, v)
 }
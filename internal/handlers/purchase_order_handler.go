@@ -0,0 +1,162 @@
+package handlers
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"product-test/internal/apierr"
+	"product-test/internal/models"
+	"product-test/internal/service"
+	"strconv"
+)
+
+const maxPurchaseOrderBodyBytes = 64 << 10
+
+type PurchaseOrderHandler struct {
+	service service.PurchaseOrderService
+	log     *slog.Logger
+}
+
+func NewPurchaseOrderHandler(svc service.PurchaseOrderService, log *slog.Logger) *PurchaseOrderHandler {
+	if log == nil {
+		log = slog.Default()
+	}
+	return &PurchaseOrderHandler{service: svc, log: log}
+}
+
+func (h *PurchaseOrderHandler) RegisterRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("GET /purchase-orders", negotiated(h.list))
+	mux.HandleFunc("POST /purchase-orders", negotiated(withBodyLimit(maxPurchaseOrderBodyBytes, h.create)))
+	mux.HandleFunc("GET /purchase-orders/{id}", negotiated(h.get))
+	mux.HandleFunc("PUT /purchase-orders/{id}", negotiated(withBodyLimit(maxPurchaseOrderBodyBytes, h.update)))
+	mux.HandleFunc("POST /purchase-orders/{id}/send", negotiated(h.send))
+	mux.HandleFunc("POST /purchase-orders/{id}/cancel", negotiated(h.cancel))
+	mux.HandleFunc("POST /purchase-orders/{id}/receive", negotiated(withBodyLimit(maxPurchaseOrderBodyBytes, h.receive)))
+}
+
+func (h *PurchaseOrderHandler) list(w http.ResponseWriter, r *http.Request) {
+	filter := models.PurchaseOrderFilter{Status: models.PurchaseOrderStatus(r.URL.Query().Get("status"))}
+	if v := r.URL.Query().Get("supplier"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			apierr.BadRequest(w, "invalid supplier id")
+			return
+		}
+		filter.SupplierID = n
+	}
+	filter.Limit, filter.Offset = parseLimitOffset(r)
+	orders, total, err := h.service.ListPurchaseOrders(r.Context(), filter)
+	if err != nil {
+		h.fail(w, "list purchase orders", 0, err)
+		return
+	}
+	if orders == nil {
+		orders = []models.PurchaseOrder{}
+	}
+	writePage(w, r, h.log, orders, pageInfo{
+		Total:   total,
+		Limit:   filter.Limit,
+		Offset:  filter.Offset,
+		HasMore: filter.Offset+len(orders) < total,
+	})
+}
+
+func (h *PurchaseOrderHandler) create(w http.ResponseWriter, r *http.Request) {
+	var in models.PurchaseOrderInput
+	if !decode(w, r, &in) {
+		return
+	}
+	o, err := h.service.CreatePurchaseOrder(r.Context(), in)
+	if err != nil {
+		h.fail(w, "create purchase order", 0, err)
+		return
+	}
+	respond(w, r, h.log, http.StatusCreated, o)
+}
+
+func (h *PurchaseOrderHandler) get(w http.ResponseWriter, r *http.Request) {
+	id, ok := parsePathInt(w, r, "id", "purchase order id")
+	if !ok {
+		return
+	}
+	o, err := h.service.GetPurchaseOrder(r.Context(), int64(id))
+	if err != nil {
+		h.fail(w, "get purchase order", id, err)
+		return
+	}
+	respond(w, r, h.log, http.StatusOK, o)
+}
+
+func (h *PurchaseOrderHandler) update(w http.ResponseWriter, r *http.Request) {
+	id, ok := parsePathInt(w, r, "id", "purchase order id")
+	if !ok {
+		return
+	}
+	var in models.PurchaseOrderInput
+	if !decode(w, r, &in) {
+		return
+	}
+	o, err := h.service.UpdatePurchaseOrder(r.Context(), int64(id), in)
+	if err != nil {
+		h.fail(w, "update purchase order", id, err)
+		return
+	}
+	respond(w, r, h.log, http.StatusOK, o)
+}
+
+func (h *PurchaseOrderHandler) send(w http.ResponseWriter, r *http.Request) {
+	id, ok := parsePathInt(w, r, "id", "purchase order id")
+	if !ok {
+		return
+	}
+	o, err := h.service.SendPurchaseOrder(r.Context(), int64(id))
+	if err != nil {
+		h.fail(w, "send purchase order", id, err)
+		return
+	}
+	respond(w, r, h.log, http.StatusOK, o)
+}
+
+func (h *PurchaseOrderHandler) cancel(w http.ResponseWriter, r *http.Request) {
+	id, ok := parsePathInt(w, r, "id", "purchase order id")
+	if !ok {
+		return
+	}
+	o, err := h.service.CancelPurchaseOrder(r.Context(), int64(id))
+	if err != nil {
+		h.fail(w, "cancel purchase order", id, err)
+		return
+	}
+	respond(w, r, h.log, http.StatusOK, o)
+}
+
+func (h *PurchaseOrderHandler) receive(w http.ResponseWriter, r *http.Request) {
+	id, ok := parsePathInt(w, r, "id", "purchase order id")
+	if !ok {
+		return
+	}
+	var in models.ReceiptInput
+	if !decode(w, r, &in) {
+		return
+	}
+	o, err := h.service.ReceivePurchaseOrder(r.Context(), int64(id), in)
+	if err != nil {
+		h.fail(w, "receive purchase order", id, err)
+		return
+	}
+	respond(w, r, h.log, http.StatusOK, o)
+}
+
+func (h *PurchaseOrderHandler) fail(w http.ResponseWriter, op string, id int, err error) {
+	switch {
+	case errors.Is(err, service.ErrValidation):
+		badRequest(w, err)
+	case errors.Is(err, service.ErrPurchaseOrderState):
+		apierr.Conflict(w, err.Error())
+	case errors.Is(err, service.ErrPurchaseOrderNotFound):
+		apierr.NotFound(w, "purchase order not found")
+	default:
+		h.log.Error(op, "id", id, "error", err)
+		apierr.Internal(w)
+	}
+}
@@ -0,0 +1,95 @@
+package handlers
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"product-test/internal/apierr"
+	"product-test/internal/models"
+	"product-test/internal/service"
+)
+
+type ReservationHandler struct {
+	service service.ReservationService
+	log     *slog.Logger
+}
+
+func NewReservationHandler(svc service.ReservationService, log *slog.Logger) *ReservationHandler {
+	if log == nil {
+		log = slog.Default()
+	}
+	return &ReservationHandler{service: svc, log: log}
+}
+
+func (h *ReservationHandler) RegisterRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("POST /reservations", negotiated(withBodyLimit(maxTransferBodyBytes, h.create)))
+	mux.HandleFunc("GET /reservations/{id}", negotiated(h.get))
+	mux.HandleFunc("POST /reservations/{id}/confirm", negotiated(h.confirm))
+	mux.HandleFunc("POST /reservations/{id}/release", negotiated(h.release))
+}
+
+func (h *ReservationHandler) create(w http.ResponseWriter, r *http.Request) {
+	var in models.ReservationInput
+	if !decode(w, r, &in) {
+		return
+	}
+	res, err := h.service.CreateReservation(r.Context(), in)
+	if err != nil {
+		h.fail(w, "create reservation", 0, err)
+		return
+	}
+	respond(w, r, h.log, http.StatusCreated, res)
+}
+
+func (h *ReservationHandler) get(w http.ResponseWriter, r *http.Request) {
+	id, ok := parsePathInt(w, r, "id", "reservation id")
+	if !ok {
+		return
+	}
+	res, err := h.service.GetReservation(r.Context(), int64(id))
+	if err != nil {
+		h.fail(w, "get reservation", id, err)
+		return
+	}
+	respond(w, r, h.log, http.StatusOK, res)
+}
+
+func (h *ReservationHandler) confirm(w http.ResponseWriter, r *http.Request) {
+	id, ok := parsePathInt(w, r, "id", "reservation id")
+	if !ok {
+		return
+	}
+	res, err := h.service.ConfirmReservation(r.Context(), int64(id))
+	if err != nil {
+		h.fail(w, "confirm reservation", id, err)
+		return
+	}
+	respond(w, r, h.log, http.StatusOK, res)
+}
+
+func (h *ReservationHandler) release(w http.ResponseWriter, r *http.Request) {
+	id, ok := parsePathInt(w, r, "id", "reservation id")
+	if !ok {
+		return
+	}
+	res, err := h.service.ReleaseReservation(r.Context(), int64(id))
+	if err != nil {
+		h.fail(w, "release reservation", id, err)
+		return
+	}
+	respond(w, r, h.log, http.StatusOK, res)
+}
+
+func (h *ReservationHandler) fail(w http.ResponseWriter, op string, id int, err error) {
+	switch {
+	case errors.Is(err, service.ErrValidation):
+		badRequest(w, err)
+	case errors.Is(err, service.ErrInsufficientStock), errors.Is(err, service.ErrReservationState):
+		apierr.Conflict(w, err.Error())
+	case errors.Is(err, service.ErrReservationNotFound):
+		apierr.NotFound(w, "reservation not found")
+	default:
+		h.log.Error(op, "id", id, "error", err)
+		apierr.Internal(w)
+	}
+}
@@ -0,0 +1,71 @@
+package handlers
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"net/http"
+	"product-test/internal/apierr"
+	"product-test/internal/codec"
+	"product-test/internal/service"
+	"strings"
+)
+
+var codecs = codec.Default()
+
+// negotiated rejects requests whose Accept header matches none of the
+// registered encoders before the handler has a chance to change anything.
+func negotiated(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if _, ok := codecs.Negotiate(r.Header.Get("Accept")); !ok {
+			notAcceptable(w)
+			return
+		}
+		next(w, r)
+	}
+}
+
+// respond encodes v in the format selected by the Accept header. The body
+// is buffered so that an encoding failure can still become an error response.
+func respond(w http.ResponseWriter, r *http.Request, log *slog.Logger, status int, v any) {
+	c, ok := codecs.Negotiate(r.Header.Get("Accept"))
+	if !ok {
+		notAcceptable(w)
+		return
+	}
+	var buf bytes.Buffer
+	if err := c.Encoder.Encode(&buf, v); err != nil {
+		if errors.Is(err, codec.ErrUnsupported) {
+			apierr.NotAcceptable(w, c.ContentType()+" is not available for this resource")
+			return
+		}
+		log.Error("encode response", "content_type", c.ContentType(), "error", err)
+		apierr.Internal(w)
+		return
+	}
+	w.Header().Set("Content-Type", c.ContentType())
+	w.Header().Add("Vary", "Accept")
+	w.WriteHeader(status)
+	if _, err := w.Write(buf.Bytes()); err != nil {
+		log.Error("write response", "error", err)
+	}
+}
+
+func notAcceptable(w http.ResponseWriter) {
+	apierr.NotAcceptable(w, "supported media types: "+strings.Join(codecs.Encodable(), ", "))
+}
+
+// badRequest writes a validation error, listing the offending fields when
+// the service named them.
+func badRequest(w http.ResponseWriter, err error) {
+	var verr *service.ValidationError
+	if !errors.As(err, &verr) {
+		apierr.BadRequest(w, err.Error())
+		return
+	}
+	fields := make([]apierr.FieldError, len(verr.Fields))
+	for i, f := range verr.Fields {
+		fields[i] = apierr.FieldError{Field: f.Field, Message: f.Message}
+	}
+	apierr.InvalidFields(w, err.Error(), fields)
+}
@@ -0,0 +1,102 @@
+package handlers
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"product-test/internal/apierr"
+	"product-test/internal/models"
+	"product-test/internal/service"
+)
+
+type RevisionHandler struct {
+	service service.RevisionService
+	log     *slog.Logger
+}
+
+func NewRevisionHandler(svc service.RevisionService, log *slog.Logger) *RevisionHandler {
+	if log == nil {
+		log = slog.Default()
+	}
+	return &RevisionHandler{service: svc, log: log}
+}
+
+func (h *RevisionHandler) RegisterRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("GET /products/{id}/revisions", negotiated(h.list))
+	mux.HandleFunc("GET /products/{id}/revisions/{a}/diff/{b}", negotiated(h.diff))
+	mux.HandleFunc("POST /products/{id}/revisions/{n}/revert", negotiated(h.revert))
+}
+
+func (h *RevisionHandler) list(w http.ResponseWriter, r *http.Request) {
+	id, ok := parseID(w, r, "id")
+	if !ok {
+		return
+	}
+	limit, offset := parseLimitOffset(r)
+	revs, total, err := h.service.ListRevisions(r.Context(), id, limit, offset)
+	if err != nil {
+		h.fail(w, "list revisions", id, err)
+		return
+	}
+	if revs == nil {
+		revs = []models.ProductRevision{}
+	}
+	writePage(w, r, h.log, revs, pageInfo{
+		Total:   total,
+		Limit:   limit,
+		Offset:  offset,
+		HasMore: offset+len(revs) < total,
+	})
+}
+
+func (h *RevisionHandler) diff(w http.ResponseWriter, r *http.Request) {
+	id, ok := parseID(w, r, "id")
+	if !ok {
+		return
+	}
+	from, ok := parsePathInt(w, r, "a", "revision")
+	if !ok {
+		return
+	}
+	to, ok := parsePathInt(w, r, "b", "revision")
+	if !ok {
+		return
+	}
+	d, err := h.service.DiffRevisions(r.Context(), id, from, to)
+	if err != nil {
+		h.fail(w, "diff revisions", id, err)
+		return
+	}
+	respond(w, r, h.log, http.StatusOK, d)
+}
+
+func (h *RevisionHandler) revert(w http.ResponseWriter, r *http.Request) {
+	id, ok := parseID(w, r, "id")
+	if !ok {
+		return
+	}
+	n, ok := parsePathInt(w, r, "n", "revision")
+	if !ok {
+		return
+	}
+	p, err := h.service.RevertToRevision(r.Context(), id, n)
+	if err != nil {
+		h.fail(w, "revert product", id, err)
+		return
+	}
+	respond(w, r, h.log, http.StatusOK, p)
+}
+
+func (h *RevisionHandler) fail(w http.ResponseWriter, op string, id int, err error) {
+	switch {
+	case errors.Is(err, service.ErrValidation):
+		apierr.BadRequest(w, err.Error())
+	case errors.Is(err, service.ErrRevisionNotFound):
+		apierr.NotFound(w, "revision not found")
+	case errors.Is(err, service.ErrNotFound):
+		apierr.NotFound(w, "product not found")
+	default:
+		h.log.Error(op, "id", id, "error", err)
+		apierr.Internal(w)
+	}
+}
@@ -0,0 +1,171 @@
+package handlers
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"product-test/internal/apierr"
+	"product-test/internal/models"
+	"product-test/internal/service"
+)
+
+type SupplierHandler struct {
+	service service.SupplierService
+	log     *slog.Logger
+}
+
+func NewSupplierHandler(svc service.SupplierService, log *slog.Logger) *SupplierHandler {
+	if log == nil {
+		log = slog.Default()
+	}
+	return &SupplierHandler{service: svc, log: log}
+}
+
+func (h *SupplierHandler) RegisterRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("GET /suppliers", negotiated(h.list))
+	mux.HandleFunc("POST /suppliers", negotiated(withBodyLimit(maxInventoryBodyBytes, h.create)))
+	mux.HandleFunc("GET /suppliers/{id}", negotiated(h.get))
+	mux.HandleFunc("PUT /suppliers/{id}", negotiated(withBodyLimit(maxInventoryBodyBytes, h.update)))
+	mux.HandleFunc("DELETE /suppliers/{id}", h.delete)
+	mux.HandleFunc("GET /suppliers/{id}/products", negotiated(h.products))
+	mux.HandleFunc("PUT /suppliers/{id}/products/{product_id}", negotiated(withBodyLimit(maxInventoryBodyBytes, h.setProduct)))
+	mux.HandleFunc("DELETE /suppliers/{id}/products/{product_id}", h.deleteProduct)
+}
+
+func (h *SupplierHandler) list(w http.ResponseWriter, r *http.Request) {
+	suppliers, err := h.service.ListSuppliers(r.Context())
+	if err != nil {
+		h.fail(w, "list suppliers", err)
+		return
+	}
+	if suppliers == nil {
+		suppliers = []models.Supplier{}
+	}
+	respond(w, r, h.log, http.StatusOK, suppliers)
+}
+
+func (h *SupplierHandler) create(w http.ResponseWriter, r *http.Request) {
+	var in models.SupplierInput
+	if !decode(w, r, &in) {
+		return
+	}
+	s, err := h.service.CreateSupplier(r.Context(), in)
+	if err != nil {
+		h.fail(w, "create supplier", err)
+		return
+	}
+	respond(w, r, h.log, http.StatusCreated, s)
+}
+
+func (h *SupplierHandler) get(w http.ResponseWriter, r *http.Request) {
+	id, ok := parsePathInt(w, r, "id", "supplier id")
+	if !ok {
+		return
+	}
+	s, err := h.service.GetSupplier(r.Context(), id)
+	if err != nil {
+		h.fail(w, "get supplier", err)
+		return
+	}
+	respond(w, r, h.log, http.StatusOK, s)
+}
+
+func (h *SupplierHandler) update(w http.ResponseWriter, r *http.Request) {
+	id, ok := parsePathInt(w, r, "id", "supplier id")
+	if !ok {
+		return
+	}
+	var in models.SupplierInput
+	if !decode(w, r, &in) {
+		return
+	}
+	s, err := h.service.UpdateSupplier(r.Context(), id, in)
+	if err != nil {
+		h.fail(w, "update supplier", err)
+		return
+	}
+	respond(w, r, h.log, http.StatusOK, s)
+}
+
+func (h *SupplierHandler) delete(w http.ResponseWriter, r *http.Request) {
+	id, ok := parsePathInt(w, r, "id", "supplier id")
+	if !ok {
+		return
+	}
+	if err := h.service.DeleteSupplier(r.Context(), id); err != nil {
+		h.fail(w, "delete supplier", err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *SupplierHandler) products(w http.ResponseWriter, r *http.Request) {
+	id, ok := parsePathInt(w, r, "id", "supplier id")
+	if !ok {
+		return
+	}
+	products, err := h.service.ListSupplierProducts(r.Context(), id)
+	if err != nil {
+		h.fail(w, "list supplier products", err)
+		return
+	}
+	if products == nil {
+		products = []models.SupplierProduct{}
+	}
+	respond(w, r, h.log, http.StatusOK, products)
+}
+
+func (h *SupplierHandler) setProduct(w http.ResponseWriter, r *http.Request) {
+	id, ok := parsePathInt(w, r, "id", "supplier id")
+	if !ok {
+		return
+	}
+	productID, ok := parsePathInt(w, r, "product_id", "product id")
+	if !ok {
+		return
+	}
+	var in models.SupplierProductInput
+	if !decode(w, r, &in) {
+		return
+	}
+	p, err := h.service.SetSupplierProduct(r.Context(), id, productID, in)
+	if err != nil {
+		h.fail(w, "set supplier product", err)
+		return
+	}
+	respond(w, r, h.log, http.StatusOK, p)
+}
+
+func (h *SupplierHandler) deleteProduct(w http.ResponseWriter, r *http.Request) {
+	id, ok := parsePathInt(w, r, "id", "supplier id")
+	if !ok {
+		return
+	}
+	productID, ok := parsePathInt(w, r, "product_id", "product id")
+	if !ok {
+		return
+	}
+	if err := h.service.DeleteSupplierProduct(r.Context(), id, productID); err != nil {
+		h.fail(w, "delete supplier product", err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *SupplierHandler) fail(w http.ResponseWriter, op string, err error) {
+	switch {
+	case errors.Is(err, service.ErrValidation):
+		badRequest(w, err)
+	case errors.Is(err, service.ErrConflict):
+		apierr.Conflict(w, err.Error())
+	case errors.Is(err, service.ErrSupplierNotFound):
+		apierr.NotFound(w, "supplier not found")
+	case errors.Is(err, service.ErrSupplierProductNotFound):
+		apierr.NotFound(w, err.Error())
+	case errors.Is(err, service.ErrNotFound):
+		apierr.NotFound(w, "product not found")
+	default:
+		h.log.Error(op, "error", err)
+		apierr.Internal(w)
+	}
+}
@@ -0,0 +1,108 @@
+package handlers
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"product-test/internal/apierr"
+	"product-test/internal/models"
+	"product-test/internal/service"
+	"strconv"
+)
+
+const maxTagsBodyBytes = 64 << 10
+
+type TagHandler struct {
+	service service.TagService
+	log     *slog.Logger
+}
+
+func NewTagHandler(svc service.TagService, log *slog.Logger) *TagHandler {
+	if log == nil {
+		log = slog.Default()
+	}
+	return &TagHandler{service: svc, log: log}
+}
+
+func (h *TagHandler) RegisterRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("GET /tags", negotiated(h.list))
+	mux.HandleFunc("GET /products/{id}/tags", negotiated(h.productTags))
+	mux.HandleFunc("POST /products/{id}/tags", negotiated(withBodyLimit(maxTagsBodyBytes, h.add)))
+	mux.HandleFunc("DELETE /products/{id}/tags", h.remove)
+}
+
+func (h *TagHandler) list(w http.ResponseWriter, r *http.Request) {
+	limit := 0
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			apierr.BadRequest(w, "limit must be a positive integer")
+			return
+		}
+		limit = n
+	}
+	tags, err := h.service.ListTags(r.Context(), limit)
+	if err != nil {
+		h.fail(w, "list tags", err)
+		return
+	}
+	if tags == nil {
+		tags = []models.Tag{}
+	}
+	respond(w, r, h.log, http.StatusOK, tags)
+}
+
+func (h *TagHandler) productTags(w http.ResponseWriter, r *http.Request) {
+	id, ok := parseID(w, r, "id")
+	if !ok {
+		return
+	}
+	tags, err := h.service.GetProductTags(r.Context(), id)
+	if err != nil {
+		h.fail(w, "get product tags", err)
+		return
+	}
+	respond(w, r, h.log, http.StatusOK, models.ProductTags{Tags: tags})
+}
+
+func (h *TagHandler) add(w http.ResponseWriter, r *http.Request) {
+	id, ok := parseID(w, r, "id")
+	if !ok {
+		return
+	}
+	var in models.ProductTags
+	if !decode(w, r, &in) {
+		return
+	}
+	tags, err := h.service.AddProductTags(r.Context(), id, in.Tags)
+	if err != nil {
+		h.fail(w, "add product tags", err)
+		return
+	}
+	respond(w, r, h.log, http.StatusOK, models.ProductTags{Tags: tags})
+}
+
+// remove takes the tags named in ?tags= off a product.
+func (h *TagHandler) remove(w http.ResponseWriter, r *http.Request) {
+	id, ok := parseID(w, r, "id")
+	if !ok {
+		return
+	}
+	if err := h.service.RemoveProductTags(r.Context(), id, parseList(r.URL.Query().Get("tags"))); err != nil {
+		h.fail(w, "remove product tags", err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *TagHandler) fail(w http.ResponseWriter, op string, err error) {
+	switch {
+	case errors.Is(err, service.ErrValidation):
+		apierr.BadRequest(w, err.Error())
+	case errors.Is(err, service.ErrNotFound):
+		apierr.NotFound(w, "product not found")
+	default:
+		h.log.Error(op, "error", err)
+		apierr.Internal(w)
+	}
+}
@@ -0,0 +1,121 @@
+package handlers
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"product-test/internal/apierr"
+	"product-test/internal/models"
+	"product-test/internal/service"
+)
+
+const maxTransferBodyBytes = 64 << 10
+
+type TransferHandler struct {
+	service service.TransferService
+	log     *slog.Logger
+}
+
+func NewTransferHandler(svc service.TransferService, log *slog.Logger) *TransferHandler {
+	if log == nil {
+		log = slog.Default()
+	}
+	return &TransferHandler{service: svc, log: log}
+}
+
+func (h *TransferHandler) RegisterRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("GET /transfers", negotiated(h.list))
+	mux.HandleFunc("POST /transfers", negotiated(withBodyLimit(maxTransferBodyBytes, h.create)))
+	mux.HandleFunc("GET /transfers/{id}", negotiated(h.get))
+	mux.HandleFunc("POST /transfers/{id}/receive", negotiated(h.receive))
+	mux.HandleFunc("POST /transfers/{id}/cancel", negotiated(h.cancel))
+}
+
+func (h *TransferHandler) list(w http.ResponseWriter, r *http.Request) {
+	filter := models.TransferFilter{Status: models.TransferStatus(r.URL.Query().Get("status"))}
+	var ok bool
+	if filter.WarehouseID, ok = parseWarehouseParam(w, r); !ok {
+		return
+	}
+	filter.Limit, filter.Offset = parseLimitOffset(r)
+	transfers, total, err := h.service.ListTransfers(r.Context(), filter)
+	if err != nil {
+		h.fail(w, "list transfers", 0, err)
+		return
+	}
+	if transfers == nil {
+		transfers = []models.Transfer{}
+	}
+	writePage(w, r, h.log, transfers, pageInfo{
+		Total:   total,
+		Limit:   filter.Limit,
+		Offset:  filter.Offset,
+		HasMore: filter.Offset+len(transfers) < total,
+	})
+}
+
+func (h *TransferHandler) create(w http.ResponseWriter, r *http.Request) {
+	var in models.TransferInput
+	if !decode(w, r, &in) {
+		return
+	}
+	t, err := h.service.CreateTransfer(r.Context(), in)
+	if err != nil {
+		h.fail(w, "create transfer", 0, err)
+		return
+	}
+	respond(w, r, h.log, http.StatusCreated, t)
+}
+
+func (h *TransferHandler) get(w http.ResponseWriter, r *http.Request) {
+	id, ok := parsePathInt(w, r, "id", "transfer id")
+	if !ok {
+		return
+	}
+	t, err := h.service.GetTransfer(r.Context(), int64(id))
+	if err != nil {
+		h.fail(w, "get transfer", id, err)
+		return
+	}
+	respond(w, r, h.log, http.StatusOK, t)
+}
+
+func (h *TransferHandler) receive(w http.ResponseWriter, r *http.Request) {
+	id, ok := parsePathInt(w, r, "id", "transfer id")
+	if !ok {
+		return
+	}
+	t, err := h.service.ReceiveTransfer(r.Context(), int64(id))
+	if err != nil {
+		h.fail(w, "receive transfer", id, err)
+		return
+	}
+	respond(w, r, h.log, http.StatusOK, t)
+}
+
+func (h *TransferHandler) cancel(w http.ResponseWriter, r *http.Request) {
+	id, ok := parsePathInt(w, r, "id", "transfer id")
+	if !ok {
+		return
+	}
+	t, err := h.service.CancelTransfer(r.Context(), int64(id))
+	if err != nil {
+		h.fail(w, "cancel transfer", id, err)
+		return
+	}
+	respond(w, r, h.log, http.StatusOK, t)
+}
+
+func (h *TransferHandler) fail(w http.ResponseWriter, op string, id int, err error) {
+	switch {
+	case errors.Is(err, service.ErrValidation):
+		badRequest(w, err)
+	case errors.Is(err, service.ErrInsufficientStock), errors.Is(err, service.ErrTransferState):
+		apierr.Conflict(w, err.Error())
+	case errors.Is(err, service.ErrTransferNotFound):
+		apierr.NotFound(w, "transfer not found")
+	default:
+		h.log.Error(op, "id", id, "error", err)
+		apierr.Internal(w)
+	}
+}
@@ -0,0 +1,170 @@
+package handlers
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"product-test/internal/apierr"
+	"product-test/internal/models"
+	"product-test/internal/service"
+)
+
+const maxVariantBodyBytes = 64 << 10
+
+type VariantHandler struct {
+	service service.VariantService
+	log     *slog.Logger
+}
+
+func NewVariantHandler(svc service.VariantService, log *slog.Logger) *VariantHandler {
+	if log == nil {
+		log = slog.Default()
+	}
+	return &VariantHandler{service: svc, log: log}
+}
+
+func (h *VariantHandler) RegisterRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("GET /products/{id}/options", negotiated(h.options))
+	mux.HandleFunc("PUT /products/{id}/options", negotiated(withBodyLimit(maxVariantBodyBytes, h.setOptions)))
+	mux.HandleFunc("GET /products/{id}/variants", negotiated(h.list))
+	mux.HandleFunc("POST /products/{id}/variants", negotiated(withBodyLimit(maxVariantBodyBytes, h.create)))
+	mux.HandleFunc("POST /products/{id}/variants/generate", negotiated(withBodyLimit(maxVariantBodyBytes, h.generate)))
+	mux.HandleFunc("PUT /products/{id}/variants/{vid}", negotiated(withBodyLimit(maxVariantBodyBytes, h.update)))
+	mux.HandleFunc("DELETE /products/{id}/variants/{vid}", h.delete)
+}
+
+func (h *VariantHandler) options(w http.ResponseWriter, r *http.Request) {
+	id, ok := parseID(w, r, "id")
+	if !ok {
+		return
+	}
+	options, err := h.service.GetOptions(r.Context(), id)
+	if err != nil {
+		h.fail(w, "get options", id, err)
+		return
+	}
+	respond(w, r, h.log, http.StatusOK, models.ProductOptions{Options: options})
+}
+
+func (h *VariantHandler) setOptions(w http.ResponseWriter, r *http.Request) {
+	id, ok := parseID(w, r, "id")
+	if !ok {
+		return
+	}
+	var in models.ProductOptions
+	if !decode(w, r, &in) {
+		return
+	}
+	options, err := h.service.SetOptions(r.Context(), id, in.Options)
+	if err != nil {
+		h.fail(w, "set options", id, err)
+		return
+	}
+	respond(w, r, h.log, http.StatusOK, models.ProductOptions{Options: options})
+}
+
+func (h *VariantHandler) list(w http.ResponseWriter, r *http.Request) {
+	id, ok := parseID(w, r, "id")
+	if !ok {
+		return
+	}
+	variants, err := h.service.ListVariants(r.Context(), id)
+	if err != nil {
+		h.fail(w, "list variants", id, err)
+		return
+	}
+	respond(w, r, h.log, http.StatusOK, models.Variants{Variants: variants})
+}
+
+func (h *VariantHandler) create(w http.ResponseWriter, r *http.Request) {
+	id, ok := parseID(w, r, "id")
+	if !ok {
+		return
+	}
+	var in models.VariantInput
+	if !decode(w, r, &in) {
+		return
+	}
+	v, err := h.service.CreateVariant(r.Context(), id, in)
+	if err != nil {
+		h.fail(w, "create variant", id, err)
+		return
+	}
+	respond(w, r, h.log, http.StatusCreated, v)
+}
+
+// generate answers 201 when it created variants and 200 when every
+// combination already had one.
+func (h *VariantHandler) generate(w http.ResponseWriter, r *http.Request) {
+	id, ok := parseID(w, r, "id")
+	if !ok {
+		return
+	}
+	var in models.VariantMatrix
+	if r.ContentLength != 0 && !decode(w, r, &in) {
+		return
+	}
+	variants, err := h.service.GenerateVariants(r.Context(), id, in)
+	if err != nil {
+		h.fail(w, "generate variants", id, err)
+		return
+	}
+	status := http.StatusCreated
+	if len(variants) == 0 {
+		status = http.StatusOK
+	}
+	respond(w, r, h.log, status, models.Variants{Variants: variants})
+}
+
+func (h *VariantHandler) update(w http.ResponseWriter, r *http.Request) {
+	id, ok := parseID(w, r, "id")
+	if !ok {
+		return
+	}
+	vid, ok := parsePathInt(w, r, "vid", "variant id")
+	if !ok {
+		return
+	}
+	var in models.VariantInput
+	if !decode(w, r, &in) {
+		return
+	}
+	v, err := h.service.UpdateVariant(r.Context(), id, vid, in)
+	if err != nil {
+		h.fail(w, "update variant", id, err)
+		return
+	}
+	respond(w, r, h.log, http.StatusOK, v)
+}
+
+func (h *VariantHandler) delete(w http.ResponseWriter, r *http.Request) {
+	id, ok := parseID(w, r, "id")
+	if !ok {
+		return
+	}
+	vid, ok := parsePathInt(w, r, "vid", "variant id")
+	if !ok {
+		return
+	}
+	if err := h.service.DeleteVariant(r.Context(), id, vid); err != nil {
+		h.fail(w, "delete variant", id, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *VariantHandler) fail(w http.ResponseWriter, op string, id int, err error) {
+	switch {
+	case errors.Is(err, service.ErrValidation):
+		badRequest(w, err)
+	case errors.Is(err, service.ErrConflict):
+		apierr.Conflict(w, err.Error())
+	case errors.Is(err, service.ErrVariantNotFound):
+		apierr.NotFound(w, "variant not found")
+	case errors.Is(err, service.ErrNotFound):
+		apierr.NotFound(w, "product not found")
+	default:
+		h.log.Error(op, "id", id, "error", err)
+		apierr.Internal(w)
+	}
+}
@@ -0,0 +1,111 @@
+package handlers
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"product-test/internal/apierr"
+	"product-test/internal/models"
+	"product-test/internal/service"
+)
+
+type WarehouseHandler struct {
+	service service.WarehouseService
+	log     *slog.Logger
+}
+
+func NewWarehouseHandler(svc service.WarehouseService, log *slog.Logger) *WarehouseHandler {
+	if log == nil {
+		log = slog.Default()
+	}
+	return &WarehouseHandler{service: svc, log: log}
+}
+
+func (h *WarehouseHandler) RegisterRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("GET /warehouses", negotiated(h.list))
+	mux.HandleFunc("POST /warehouses", negotiated(withBodyLimit(maxInventoryBodyBytes, h.create)))
+	mux.HandleFunc("GET /warehouses/{id}", negotiated(h.get))
+	mux.HandleFunc("PUT /warehouses/{id}", negotiated(withBodyLimit(maxInventoryBodyBytes, h.update)))
+	mux.HandleFunc("DELETE /warehouses/{id}", h.delete)
+}
+
+func (h *WarehouseHandler) list(w http.ResponseWriter, r *http.Request) {
+	warehouses, err := h.service.ListWarehouses(r.Context())
+	if err != nil {
+		h.fail(w, "list warehouses", err)
+		return
+	}
+	if warehouses == nil {
+		warehouses = []models.Warehouse{}
+	}
+	respond(w, r, h.log, http.StatusOK, warehouses)
+}
+
+func (h *WarehouseHandler) create(w http.ResponseWriter, r *http.Request) {
+	var in models.WarehouseInput
+	if !decode(w, r, &in) {
+		return
+	}
+	wh, err := h.service.CreateWarehouse(r.Context(), in)
+	if err != nil {
+		h.fail(w, "create warehouse", err)
+		return
+	}
+	respond(w, r, h.log, http.StatusCreated, wh)
+}
+
+func (h *WarehouseHandler) get(w http.ResponseWriter, r *http.Request) {
+	id, ok := parsePathInt(w, r, "id", "warehouse id")
+	if !ok {
+		return
+	}
+	wh, err := h.service.GetWarehouse(r.Context(), id)
+	if err != nil {
+		h.fail(w, "get warehouse", err)
+		return
+	}
+	respond(w, r, h.log, http.StatusOK, wh)
+}
+
+func (h *WarehouseHandler) update(w http.ResponseWriter, r *http.Request) {
+	id, ok := parsePathInt(w, r, "id", "warehouse id")
+	if !ok {
+		return
+	}
+	var in models.WarehouseInput
+	if !decode(w, r, &in) {
+		return
+	}
+	wh, err := h.service.UpdateWarehouse(r.Context(), id, in)
+	if err != nil {
+		h.fail(w, "update warehouse", err)
+		return
+	}
+	respond(w, r, h.log, http.StatusOK, wh)
+}
+
+func (h *WarehouseHandler) delete(w http.ResponseWriter, r *http.Request) {
+	id, ok := parsePathInt(w, r, "id", "warehouse id")
+	if !ok {
+		return
+	}
+	if err := h.service.DeleteWarehouse(r.Context(), id); err != nil {
+		h.fail(w, "delete warehouse", err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *WarehouseHandler) fail(w http.ResponseWriter, op string, err error) {
+	switch {
+	case errors.Is(err, service.ErrValidation):
+		badRequest(w, err)
+	case errors.Is(err, service.ErrConflict):
+		apierr.Conflict(w, err.Error())
+	case errors.Is(err, service.ErrWarehouseNotFound):
+		apierr.NotFound(w, "warehouse not found")
+	default:
+		h.log.Error(op, "error", err)
+		apierr.Internal(w)
+	}
+}
@@ -0,0 +1,47 @@
+// Package jobs runs periodic background work inside the server process.
+package jobs
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+type Runner struct {
+	log *slog.Logger
+	wg  sync.WaitGroup
+}
+
+func NewRunner(log *slog.Logger) *Runner {
+	if log == nil {
+		log = slog.Default()
+	}
+	return &Runner{log: log}
+}
+
+// Every runs fn now and then once per interval until ctx is cancelled.
+// Errors are logged and do not stop the job.
+func (r *Runner) Every(ctx context.Context, name string, interval time.Duration, fn func(ctx context.Context) error) {
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			if err := fn(ctx); err != nil && ctx.Err() == nil {
+				r.log.Error("background job failed", "job", name, "error", err)
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Wait blocks until every job has returned after its context was cancelled.
+func (r *Runner) Wait() {
+	r.wg.Wait()
+}
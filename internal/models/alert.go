@@ -0,0 +1,43 @@
+package models
+
+import (
+	"encoding/xml"
+	"time"
+)
+
+type AlertType string
+
+const AlertLowStock AlertType = "low_stock"
+
+// Alert reports a product, or a variant of it when VariantID is set, whose
+// stock level fell to its reorder point. It
+// stays open until the stock level rises above the reorder point again,
+// the reorder point is removed or the product is trashed.
+type Alert struct {
+	XMLName         xml.Name   `json:"-" xml:"alert"`
+	ID              int64      `json:"id" xml:"id"`
+	ProductID       int        `json:"product_id" xml:"product_id"`
+	VariantID       int        `json:"variant_id,omitempty" xml:"variant_id,omitempty"`
+	ProductName     string     `json:"product_name" xml:"product_name"`
+	Type            AlertType  `json:"type" xml:"type"`
+	StockLevel      int64      `json:"stock_level" xml:"stock_level"`
+	ReorderPoint    int64      `json:"reorder_point" xml:"reorder_point"`
+	ReorderQuantity *int64     `json:"reorder_quantity,omitempty" xml:"reorder_quantity,omitempty"`
+	CreatedAt       time.Time  `json:"created_at" xml:"created_at"`
+	ResolvedAt      *time.Time `json:"resolved_at,omitempty" xml:"resolved_at,omitempty"`
+	NotifiedAt      *time.Time `json:"notified_at,omitempty" xml:"notified_at,omitempty"`
+}
+
+type AlertStatus string
+
+const (
+	AlertOpen     AlertStatus = "open"
+	AlertResolved AlertStatus = "resolved"
+)
+
+// AlertFilter selects alerts, newest first; a zero Status does not filter.
+type AlertFilter struct {
+	Status AlertStatus
+	Limit  int
+	Offset int
+}
@@ -0,0 +1,284 @@
+package models
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/vmihailenco/msgpack/v5"
+	"github.com/vmihailenco/msgpack/v5/msgpcode"
+)
+
+// Attributes are the custom fields of a product, such as a shoe size or
+// the RAM of a laptop. Values are strings, booleans, json.Number or arrays
+// of those; numbers stay json.Number so that integers survive unchanged.
+type Attributes map[string]any
+
+func (a *Attributes) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		*a = nil
+		return nil
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var m map[string]any
+	if err := dec.Decode(&m); err != nil {
+		return err
+	}
+	*a = m
+	return nil
+}
+
+// EncodeMsgpack writes numbers as msgpack integers or floats rather than
+// the strings json.Number would otherwise become.
+func (a Attributes) EncodeMsgpack(enc *msgpack.Encoder) error {
+	if a == nil {
+		return enc.EncodeNil()
+	}
+	return encodeAttributeValue(enc, map[string]any(a))
+}
+
+func encodeAttributeValue(enc *msgpack.Encoder, v any) error {
+	switch v := v.(type) {
+	case json.Number:
+		if n, err := v.Int64(); err == nil {
+			return enc.EncodeInt(n)
+		}
+		if n, err := strconv.ParseUint(v.String(), 10, 64); err == nil {
+			return enc.EncodeUint(n)
+		}
+		f, err := v.Float64()
+		if err != nil {
+			return err
+		}
+		return enc.EncodeFloat64(f)
+	case []any:
+		if err := enc.EncodeArrayLen(len(v)); err != nil {
+			return err
+		}
+		for _, item := range v {
+			if err := encodeAttributeValue(enc, item); err != nil {
+				return err
+			}
+		}
+		return nil
+	case map[string]any:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		if err := enc.EncodeMapLen(len(v)); err != nil {
+			return err
+		}
+		for _, k := range keys {
+			if err := enc.EncodeString(k); err != nil {
+				return err
+			}
+			if err := encodeAttributeValue(enc, v[k]); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return enc.Encode(v)
+}
+
+// DecodeMsgpack turns numbers into json.Number, as UnmarshalJSON does.
+func (a *Attributes) DecodeMsgpack(dec *msgpack.Decoder) error {
+	v, err := decodeAttributeValue(dec)
+	if err != nil {
+		return err
+	}
+	switch v := v.(type) {
+	case nil:
+		*a = nil
+	case map[string]any:
+		*a = v
+	default:
+		return fmt.Errorf("attributes must be a map, got %T", v)
+	}
+	return nil
+}
+
+func decodeAttributeValue(dec *msgpack.Decoder) (any, error) {
+	c, err := dec.PeekCode()
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case msgpcode.IsFixedArray(c) || c == msgpcode.Array16 || c == msgpcode.Array32:
+		n, err := dec.DecodeArrayLen()
+		if err != nil {
+			return nil, err
+		}
+		items := make([]any, 0, min(n, 1024))
+		for i := 0; i < n; i++ {
+			item, err := decodeAttributeValue(dec)
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, item)
+		}
+		return items, nil
+	case msgpcode.IsFixedMap(c) || c == msgpcode.Map16 || c == msgpcode.Map32:
+		n, err := dec.DecodeMapLen()
+		if err != nil {
+			return nil, err
+		}
+		m := make(map[string]any, min(n, 1024))
+		for i := 0; i < n; i++ {
+			k, err := dec.DecodeString()
+			if err != nil {
+				return nil, err
+			}
+			if m[k], err = decodeAttributeValue(dec); err != nil {
+				return nil, err
+			}
+		}
+		return m, nil
+	}
+	v, err := dec.DecodeInterfaceLoose()
+	if err != nil {
+		return nil, err
+	}
+	switch v := v.(type) {
+	case int64:
+		return json.Number(strconv.FormatInt(v, 10)), nil
+	case uint64:
+		return json.Number(strconv.FormatUint(v, 10)), nil
+	case float64:
+		b, err := json.Marshal(v)
+		if err != nil {
+			return nil, err
+		}
+		return json.Number(b), nil
+	case []byte:
+		return string(v), nil
+	}
+	return v, nil
+}
+
+// String returns the attributes as a JSON object with sorted keys, or ""
+// when there are none; it is how they appear in CSV.
+func (a Attributes) String() string {
+	if len(a) == 0 {
+		return ""
+	}
+	b, err := json.Marshal(map[string]any(a))
+	if err != nil {
+		return fmt.Sprint(map[string]any(a))
+	}
+	return string(b)
+}
+
+// MarshalXML writes one <attribute name="..."> element per attribute, in
+// key order, holding the JSON encoding of the value. Strings are written
+// unquoted unless they would read back as another JSON value.
+func (a Attributes) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	if err := e.EncodeToken(start); err != nil {
+		return err
+	}
+	keys := make([]string, 0, len(a))
+	for k := range a {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		text, err := xmlAttributeText(a[k])
+		if err != nil {
+			return err
+		}
+		el := xml.StartElement{Name: xml.Name{Local: "attribute"}, Attr: []xml.Attr{{Name: xml.Name{Local: "name"}, Value: k}}}
+		if err := e.EncodeElement(text, el); err != nil {
+			return err
+		}
+	}
+	return e.EncodeToken(start.End())
+}
+
+func xmlAttributeText(v any) (string, error) {
+	if s, ok := v.(string); ok && !json.Valid([]byte(s)) {
+		return s, nil
+	}
+	b, err := json.Marshal(v)
+	return string(b), err
+}
+
+func (a *Attributes) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	var doc struct {
+		Items []struct {
+			Name  string `xml:"name,attr"`
+			Value string `xml:",chardata"`
+		} `xml:"attribute"`
+	}
+	if err := d.DecodeElement(&doc, &start); err != nil {
+		return err
+	}
+	m := make(Attributes, len(doc.Items))
+	for _, item := range doc.Items {
+		if _, dup := m[item.Name]; dup {
+			return fmt.Errorf("attribute %q is given twice", item.Name)
+		}
+		dec := json.NewDecoder(bytes.NewReader([]byte(item.Value)))
+		dec.UseNumber()
+		var v any
+		if err := dec.Decode(&v); err != nil || dec.More() {
+			v = item.Value
+		}
+		m[item.Name] = v
+	}
+	*a = m
+	return nil
+}
+
+func (a Attributes) Value() (driver.Value, error) {
+	if a == nil {
+		return []byte("{}"), nil
+	}
+	return json.Marshal(map[string]any(a))
+}
+
+func (a *Attributes) Scan(src any) error {
+	switch src := src.(type) {
+	case nil:
+		*a = nil
+		return nil
+	case []byte:
+		return a.UnmarshalJSON(src)
+	case string:
+		return a.UnmarshalJSON([]byte(src))
+	}
+	return errors.New("attributes: unsupported column type")
+}
+
+// AttributeOp compares an attribute in a product filter.
+type AttributeOp string
+
+const (
+	AttributeEq  AttributeOp = "eq"
+	AttributeGt  AttributeOp = "gt"
+	AttributeGte AttributeOp = "gte"
+	AttributeLt  AttributeOp = "lt"
+	AttributeLte AttributeOp = "lte"
+)
+
+// AttributeFilter is a condition on one attribute, from a query parameter
+// such as attr.color=red,blue or attr.ram_gb_gte=16. An equality matches
+// any of the values; array attributes match when any item does.
+type AttributeFilter struct {
+	Name   string
+	Op     AttributeOp
+	Values []string
+}
+
+// FieldError points at one invalid field of a request body.
+type FieldError struct {
+	Field   string `json:"field" xml:"field"`
+	Message string `json:"message" xml:"message"`
+}
@@ -0,0 +1,67 @@
+package models
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"strconv"
+	"time"
+)
+
+type AuditAction string
+
+const (
+	AuditCreate  AuditAction = "create"
+	AuditUpdate  AuditAction = "update"
+	AuditDelete  AuditAction = "delete"
+	AuditRestore AuditAction = "restore"
+	AuditPurge   AuditAction = "purge"
+)
+
+// AuditEntry records one change to a product: who made it, in which
+// request, and the old and new value of every field that changed.
+type AuditEntry struct {
+	XMLName   xml.Name      `json:"-" xml:"audit_entry"`
+	ID        int64         `json:"id" xml:"id"`
+	ProductID int           `json:"product_id" xml:"product_id"`
+	Action    AuditAction   `json:"action" xml:"action"`
+	Actor     string        `json:"actor" xml:"actor"`
+	RequestID string        `json:"request_id,omitempty" xml:"request_id,omitempty"`
+	Changes   []FieldChange `json:"changes" xml:"changes>change"`
+	CreatedAt time.Time     `json:"created_at" xml:"created_at"`
+}
+
+// FieldChange is one entry of a diff. Old is nil for created fields and
+// New is nil for removed ones.
+type FieldChange struct {
+	Field string `json:"field" xml:"field"`
+	Old   any    `json:"old" xml:"old,omitempty"`
+	New   any    `json:"new" xml:"new,omitempty"`
+}
+
+func (e AuditEntry) CSVHeader() []string {
+	return []string{"id", "product_id", "action", "actor", "request_id", "changes", "created_at"}
+}
+
+func (e AuditEntry) CSVRecord() []string {
+	changes, _ := json.Marshal(e.Changes)
+	return []string{
+		strconv.FormatInt(e.ID, 10),
+		strconv.Itoa(e.ProductID),
+		string(e.Action),
+		e.Actor,
+		e.RequestID,
+		string(changes),
+		e.CreatedAt.Format(time.RFC3339),
+	}
+}
+
+// AuditFilter selects audit entries; zero values do not filter.
+type AuditFilter struct {
+	ProductID int
+	Actor     string
+	Action    AuditAction
+	From      time.Time
+	To        time.Time
+	Limit     int
+	Offset    int
+}
@@ -0,0 +1,49 @@
+package models
+
+import (
+	"encoding/xml"
+	"time"
+)
+
+// Category is a node of the category tree. Path runs from the root down to
+// the category itself.
+type Category struct {
+	XMLName   xml.Name      `json:"-" xml:"category"`
+	ID        int           `json:"id" xml:"id"`
+	ParentID  *int          `json:"parent_id" xml:"parent_id,omitempty"`
+	Name      string        `json:"name" xml:"name"`
+	Depth     int           `json:"depth" xml:"depth"`
+	Path      []CategoryRef `json:"path,omitempty" xml:"path>category,omitempty"`
+	CreatedAt time.Time     `json:"created_at" xml:"created_at"`
+}
+
+// CategoryRef names a category in breadcrumbs and paths.
+type CategoryRef struct {
+	ID   int    `json:"id" xml:"id"`
+	Name string `json:"name" xml:"name"`
+}
+
+// CategoryInput is the request body for creating a category or renaming
+// and moving one. A nil ParentID makes it a root category.
+type CategoryInput struct {
+	XMLName  xml.Name `json:"-" xml:"category"`
+	Name     string   `json:"name" xml:"name"`
+	ParentID *int     `json:"parent_id" xml:"parent_id,omitempty"`
+}
+
+// ProductCategory is a category a product is assigned to. The primary
+// category provides the product's breadcrumbs.
+type ProductCategory struct {
+	XMLName   xml.Name      `json:"-" xml:"category"`
+	ID        int           `json:"id" xml:"id"`
+	Name      string        `json:"name,omitempty" xml:"name,omitempty"`
+	IsPrimary bool          `json:"is_primary" xml:"is_primary"`
+	Path      []CategoryRef `json:"path,omitempty" xml:"path>category,omitempty"`
+}
+
+// ProductCategories is the request body that replaces the categories of a
+// product.
+type ProductCategories struct {
+	XMLName    xml.Name          `json:"-" xml:"categories"`
+	Categories []ProductCategory `json:"categories" xml:"category"`
+}
@@ -0,0 +1,70 @@
+package models
+
+import (
+	"encoding/xml"
+	"time"
+)
+
+// ExchangeRate says that one unit of Base buys Rate units of Quote. Rate
+// is a decimal string so that it is never rounded through a float.
+type ExchangeRate struct {
+	XMLName   xml.Name  `json:"-" xml:"exchange_rate"`
+	Base      string    `json:"base" xml:"base"`
+	Quote     string    `json:"quote" xml:"quote"`
+	Rate      string    `json:"rate" xml:"rate"`
+	Source    string    `json:"source" xml:"source"`
+	UpdatedAt time.Time `json:"updated_at" xml:"updated_at"`
+}
+
+// RateImport is the outcome of loading a rates file.
+type RateImport struct {
+	XMLName  xml.Name `json:"-" xml:"rate_import"`
+	Imported int      `json:"imported" xml:"imported"`
+	Skipped  []string `json:"skipped" xml:"skipped>currency"`
+}
+
+type RoundingMode string
+
+const (
+	RoundHalfUp   RoundingMode = "half_up"
+	RoundHalfEven RoundingMode = "half_even"
+	RoundDown     RoundingMode = "down"
+	RoundUp       RoundingMode = "up"
+)
+
+// RoundingRule controls how converted amounts in Currency are rounded:
+// to a multiple of Increment minor units, using Mode. Currencies without a
+// rule round half up to the minor unit.
+type RoundingRule struct {
+	XMLName   xml.Name     `json:"-" xml:"rounding_rule"`
+	Currency  string       `json:"currency" xml:"currency"`
+	Mode      RoundingMode `json:"mode" xml:"mode"`
+	Increment Money        `json:"increment" xml:"increment"`
+	UpdatedAt time.Time    `json:"updated_at" xml:"updated_at"`
+}
+
+// RoundingRuleInput is the request body for setting a rounding rule; the
+// increment is a decimal amount in the rule's currency, e.g. "0.05".
+type RoundingRuleInput struct {
+	XMLName   xml.Name     `json:"-" xml:"rounding_rule"`
+	Mode      RoundingMode `json:"mode" xml:"mode"`
+	Increment string       `json:"increment" xml:"increment"`
+}
+
+// ExchangeRateInput is the request body for setting one rate.
+type ExchangeRateInput struct {
+	XMLName xml.Name `json:"-" xml:"exchange_rate"`
+	Rate    string   `json:"rate" xml:"rate"`
+}
+
+// ConvertedPrice is a price expressed in another currency, with the rate
+// and the time of the rate used for the conversion.
+type ConvertedPrice struct {
+	Price  Money      `json:"price" xml:"price"`
+	Rate   string     `json:"rate" xml:"rate"`
+	RateAt *time.Time `json:"rate_at,omitempty" xml:"rate_at,omitempty"`
+}
+
+func (c ConvertedPrice) String() string {
+	return c.Price.String()
+}
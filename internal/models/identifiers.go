@@ -0,0 +1,97 @@
+package models
+
+import (
+	"strings"
+	"unicode"
+)
+
+// MaxSlugLength bounds generated and given slugs.
+const MaxSlugLength = 200
+
+// ValidBarcode reports whether code is an EAN-13, UPC-A or EAN-8 barcode
+// with a correct check digit.
+func ValidBarcode(code string) bool {
+	switch len(code) {
+	case 8, 12, 13:
+	default:
+		return false
+	}
+	sum := 0
+	for i := len(code) - 1; i >= 0; i-- {
+		c := code[i]
+		if c < '0' || c > '9' {
+			return false
+		}
+		d := int(c - '0')
+		if i == len(code)-1 {
+			continue
+		}
+		// Digits are weighted 3, 1, 3, ... from the right, starting next
+		// to the check digit.
+		if (len(code)-1-i)%2 == 1 {
+			d *= 3
+		}
+		sum += d
+	}
+	return (10-sum%10)%10 == int(code[len(code)-1]-'0')
+}
+
+// NormalizeBarcode returns the form barcodes are stored and looked up in: a
+// UPC-A code is the EAN-13 with a leading zero, so both are kept as that.
+func NormalizeBarcode(code string) string {
+	if len(code) == 12 {
+		return "0" + code
+	}
+	return code
+}
+
+// cyrillic transliterates Russian, Ukrainian and Kazakh letters.
+var cyrillic = map[rune]string{
+	'а': "a", 'б': "b", 'в': "v", 'г': "g", 'д': "d", 'е': "e", 'ё': "e",
+	'ж': "zh", 'з': "z", 'и': "i", 'й': "y", 'к': "k", 'л': "l", 'м': "m",
+	'н': "n", 'о': "o", 'п': "p", 'р': "r", 'с': "s", 'т': "t", 'у': "u",
+	'ф': "f", 'х': "kh", 'ц': "ts", 'ч': "ch", 'ш': "sh", 'щ': "shch",
+	'ъ': "", 'ы': "y", 'ь': "", 'э': "e", 'ю': "yu", 'я': "ya",
+	'і': "i", 'ї': "yi", 'є': "ye", 'ґ': "g",
+	'ә': "a", 'ғ': "g", 'қ': "q", 'ң': "n", 'ө': "o", 'ұ': "u", 'ү': "u", 'һ': "h",
+}
+
+// Slugify turns a product name into a URL slug: lowercase ASCII letters
+// and digits in words joined by '-', with Cyrillic transliterated. Other
+// characters separate words. It returns "" if nothing is left.
+func Slugify(name string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(name) {
+		s, ok := cyrillic[r]
+		switch {
+		case ok:
+		case r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)):
+			s = string(r)
+		default:
+			dash = true
+			continue
+		}
+		if s == "" {
+			continue
+		}
+		if dash && b.Len() > 0 {
+			b.WriteByte('-')
+		}
+		dash = false
+		b.WriteString(s)
+	}
+	slug := b.String()
+	if len(slug) > MaxSlugLength {
+		slug = slug[:MaxSlugLength]
+		if i := strings.LastIndexByte(slug, '-'); i > 0 {
+			slug = slug[:i]
+		}
+	}
+	return slug
+}
+
+// ValidSlug reports whether s is a slug as Slugify makes them.
+func ValidSlug(s string) bool {
+	return s != "" && len(s) <= MaxSlugLength && Slugify(s) == s
+}
@@ -0,0 +1,104 @@
+package models
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidBarcode(t *testing.T) {
+	tests := []struct {
+		code string
+		want bool
+	}{
+		{"96385074", true},       // EAN-8
+		{"96385075", false},      // EAN-8, wrong check digit
+		{"036000291452", true},   // UPC-A
+		{"012345678905", true},   // UPC-A
+		{"012345678906", false},  // UPC-A, wrong check digit
+		{"4006381333931", true},  // EAN-13
+		{"0012345678905", true},  // EAN-13 of a UPC-A
+		{"4006381333932", false}, // EAN-13, wrong check digit
+		{"", false},
+		{"1234567", false},
+		{"40063813339310", false},
+		{"40063813339a1", false},
+		{" 4006381333931", false},
+	}
+	for _, tt := range tests {
+		if got := ValidBarcode(tt.code); got != tt.want {
+			t.Errorf("ValidBarcode(%q) = %v, want %v", tt.code, got, tt.want)
+		}
+	}
+}
+
+func TestNormalizeBarcode(t *testing.T) {
+	tests := []struct {
+		code, want string
+	}{
+		{"012345678905", "0012345678905"},
+		{"0012345678905", "0012345678905"},
+		{"4006381333931", "4006381333931"},
+		{"96385074", "96385074"},
+	}
+	for _, tt := range tests {
+		got := NormalizeBarcode(tt.code)
+		if got != tt.want {
+			t.Errorf("NormalizeBarcode(%q) = %q, want %q", tt.code, got, tt.want)
+		}
+		if !ValidBarcode(got) {
+			t.Errorf("NormalizeBarcode(%q) = %q, which is not a valid barcode", tt.code, got)
+		}
+	}
+}
+
+func TestSlugify(t *testing.T) {
+	long := strings.Repeat("a", MaxSlugLength-1)
+	tests := []struct {
+		name, in, want string
+	}{
+		{"ascii", "Blue T-Shirt, XL", "blue-t-shirt-xl"},
+		{"russian", "Футболка «Щука»", "futbolka-shchuka"},
+		{"yo and hard sign", "Ёлка подъезд", "elka-podezd"},
+		{"ukrainian", "Їжак Євген", "yizhak-yevgen"},
+		{"kazakh", "Әсем қағаз өңір ұлы үй һ", "asem-qagaz-onir-uly-uy-h"},
+		{"digits", "Чай №1 100 г", "chay-1-100-g"},
+		{"trims separators", "  --Hello--  ", "hello"},
+		{"drops other scripts", "東京 Tokyo", "tokyo"},
+		{"nothing left", "«—»", ""},
+		{"truncates at a word", long + " bb", long},
+		{"cuts one long word", strings.Repeat("b", MaxSlugLength+10), strings.Repeat("b", MaxSlugLength)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Slugify(tt.in)
+			if got != tt.want {
+				t.Errorf("Slugify(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+			if len(got) > MaxSlugLength {
+				t.Errorf("Slugify(%q) is %d bytes, longer than %d", tt.in, len(got), MaxSlugLength)
+			}
+		})
+	}
+}
+
+func TestValidSlug(t *testing.T) {
+	tests := []struct {
+		slug string
+		want bool
+	}{
+		{"futbolka-shchuka", true},
+		{"a", true},
+		{"", false},
+		{"Futbolka", false},
+		{"-futbolka", false},
+		{"futbolka--shchuka", false},
+		{"футболка", false},
+		{strings.Repeat("a", MaxSlugLength), true},
+		{strings.Repeat("a", MaxSlugLength+1), false},
+	}
+	for _, tt := range tests {
+		if got := ValidSlug(tt.slug); got != tt.want {
+			t.Errorf("ValidSlug(%q) = %v, want %v", tt.slug, got, tt.want)
+		}
+	}
+}
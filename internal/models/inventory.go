@@ -0,0 +1,304 @@
+package models
+
+import (
+	"cmp"
+	"encoding/xml"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// MovementType is the kind of a stock movement.
+type MovementType string
+
+const (
+	MovementReceipt    MovementType = "receipt"
+	MovementAdjustment MovementType = "adjustment"
+	MovementSale       MovementType = "sale"
+	MovementReturn     MovementType = "return"
+	// Transfers between warehouses move stock out of the source when they
+	// are shipped and into the destination when they are received.
+	MovementTransferOut MovementType = "transfer_out"
+	MovementTransferIn  MovementType = "transfer_in"
+)
+
+// StockKey identifies what stock is kept of: a product, or one of its
+// variants when VariantID is not zero.
+type StockKey struct {
+	ProductID int
+	VariantID int
+}
+
+func (k StockKey) String() string {
+	if k.VariantID == 0 {
+		return "product " + strconv.Itoa(k.ProductID)
+	}
+	return "variant " + strconv.Itoa(k.VariantID) + " of product " + strconv.Itoa(k.ProductID)
+}
+
+// Compare orders keys by product, then variant.
+func (k StockKey) Compare(o StockKey) int {
+	if k.ProductID != o.ProductID {
+		return cmp.Compare(k.ProductID, o.ProductID)
+	}
+	return cmp.Compare(k.VariantID, o.VariantID)
+}
+
+// StockMovement is one entry of the stock ledger. Quantity is positive for
+// stock coming in and negative for stock going out; BalanceAfter is the
+// stock of the product or variant in the warehouse once the movement is
+// applied. Lots is the part of the quantity that went into or out of lots;
+// the rest is untracked stock.
+type StockMovement struct {
+	XMLName      xml.Name      `json:"-" xml:"movement"`
+	ID           int64         `json:"id" xml:"id"`
+	ProductID    int           `json:"product_id" xml:"product_id"`
+	VariantID    int           `json:"variant_id,omitempty" xml:"variant_id,omitempty"`
+	WarehouseID  int           `json:"warehouse_id" xml:"warehouse_id"`
+	Type         MovementType  `json:"type" xml:"type"`
+	Quantity     int64         `json:"quantity" xml:"quantity"`
+	BalanceAfter int64         `json:"balance_after" xml:"balance_after"`
+	Lots         []LotQuantity `json:"lots,omitempty" xml:"lots>lot,omitempty"`
+	Reference    string        `json:"reference,omitempty" xml:"reference,omitempty"`
+	Note         string        `json:"note,omitempty" xml:"note,omitempty"`
+	Actor        string        `json:"actor" xml:"actor"`
+	RequestID    string        `json:"request_id,omitempty" xml:"request_id,omitempty"`
+	CreatedAt    time.Time     `json:"created_at" xml:"created_at"`
+}
+
+func (m StockMovement) Key() StockKey {
+	return StockKey{ProductID: m.ProductID, VariantID: m.VariantID}
+}
+
+// LotQuantity is the quantity of a movement in one lot. ExpiresOn is a date
+// in the form 2006-01-02, empty for lots that do not expire.
+type LotQuantity struct {
+	Lot       string `json:"lot" xml:"lot"`
+	ExpiresOn string `json:"expires_on,omitempty" xml:"expires_on,omitempty"`
+	Quantity  int64  `json:"quantity" xml:"quantity"`
+}
+
+func (m StockMovement) CSVHeader() []string {
+	return []string{"id", "product_id", "variant_id", "warehouse_id", "type", "quantity", "balance_after", "lots", "reference", "note", "actor", "request_id", "created_at"}
+}
+
+func (m StockMovement) CSVRecord() []string {
+	lots := make([]string, len(m.Lots))
+	for i, l := range m.Lots {
+		lots[i] = l.Lot + ":" + strconv.FormatInt(l.Quantity, 10)
+	}
+	return []string{
+		strconv.FormatInt(m.ID, 10),
+		strconv.Itoa(m.ProductID),
+		strconv.Itoa(m.VariantID),
+		strconv.Itoa(m.WarehouseID),
+		string(m.Type),
+		strconv.FormatInt(m.Quantity, 10),
+		strconv.FormatInt(m.BalanceAfter, 10),
+		strings.Join(lots, ";"),
+		m.Reference,
+		m.Note,
+		m.Actor,
+		m.RequestID,
+		m.CreatedAt.Format(time.RFC3339),
+	}
+}
+
+// StockMovementInput is the request body for recording a movement. The
+// quantity of a receipt, sale or return is a positive number of units and
+// its direction follows from the type; an adjustment is signed. A zero
+// WarehouseID stands for the default warehouse, a zero VariantID for the
+// product itself. Stock coming in goes into Lot, if given, which expires on
+// ExpiresOn; stock going out comes out of Lot, or else out of the first
+// lots to expire.
+type StockMovementInput struct {
+	XMLName     xml.Name     `json:"-" xml:"movement"`
+	VariantID   int          `json:"variant_id" xml:"variant_id,omitempty"`
+	WarehouseID int          `json:"warehouse_id" xml:"warehouse_id,omitempty"`
+	Type        MovementType `json:"type" xml:"type"`
+	Quantity    int64        `json:"quantity" xml:"quantity"`
+	Lot         string       `json:"lot" xml:"lot,omitempty"`
+	ExpiresOn   string       `json:"expires_on" xml:"expires_on,omitempty"`
+	Reference   string       `json:"reference" xml:"reference,omitempty"`
+	Note        string       `json:"note" xml:"note,omitempty"`
+}
+
+// Stock is the inventory position of a product, or of one of its variants
+// when VariantID is set, across all warehouses and per warehouse that
+// holds or ever held it. Expired is the stock on hand in
+// lots past their expiry date. Available, the stock that can still be
+// promised, is OnHand minus Expired and Reserved. UpdatedAt is the time of
+// the last movement, if any. CostPrice, what a unit cost to buy, is set
+// as purchase orders are received.
+type Stock struct {
+	XMLName         xml.Name         `json:"-" xml:"stock"`
+	ProductID       int              `json:"product_id" xml:"product_id"`
+	VariantID       int              `json:"variant_id,omitempty" xml:"variant_id,omitempty"`
+	OnHand          int64            `json:"on_hand" xml:"on_hand"`
+	Expired         int64            `json:"expired" xml:"expired"`
+	Reserved        int64            `json:"reserved" xml:"reserved"`
+	Available       int64            `json:"available" xml:"available"`
+	AllowBackorder  bool             `json:"allow_backorder" xml:"allow_backorder"`
+	ReorderPoint    *int64           `json:"reorder_point,omitempty" xml:"reorder_point,omitempty"`
+	ReorderQuantity *int64           `json:"reorder_quantity,omitempty" xml:"reorder_quantity,omitempty"`
+	CostPrice       *Money           `json:"cost_price,omitempty" xml:"cost_price,omitempty"`
+	UpdatedAt       *time.Time       `json:"updated_at,omitempty" xml:"updated_at,omitempty"`
+	Warehouses      []WarehouseStock `json:"warehouses" xml:"warehouses>warehouse"`
+}
+
+// WarehouseStock is the stock of a product in one warehouse.
+type WarehouseStock struct {
+	WarehouseID int    `json:"warehouse_id" xml:"warehouse_id"`
+	Code        string `json:"code" xml:"code"`
+	OnHand      int64  `json:"on_hand" xml:"on_hand"`
+	Expired     int64  `json:"expired" xml:"expired"`
+	Reserved    int64  `json:"reserved" xml:"reserved"`
+	Available   int64  `json:"available" xml:"available"`
+}
+
+// InWarehouse returns the stock in one warehouse.
+func (s *Stock) InWarehouse(id int) WarehouseStock {
+	for _, w := range s.Warehouses {
+		if w.WarehouseID == id {
+			return w
+		}
+	}
+	return WarehouseStock{WarehouseID: id}
+}
+
+// Availability is what a product or variant has on hand, expired,
+// reserved, available to promise and in transit, across all warehouses or
+// in one of them.
+type Availability struct {
+	XMLName    xml.Name                `json:"-" xml:"availability"`
+	ProductID  int                     `json:"product_id" xml:"product_id"`
+	VariantID  int                     `json:"variant_id,omitempty" xml:"variant_id,omitempty"`
+	OnHand     int64                   `json:"on_hand" xml:"on_hand"`
+	Expired    int64                   `json:"expired" xml:"expired"`
+	Reserved   int64                   `json:"reserved" xml:"reserved"`
+	Available  int64                   `json:"available" xml:"available"`
+	InTransit  int64                   `json:"in_transit" xml:"in_transit"`
+	Warehouses []WarehouseAvailability `json:"warehouses" xml:"warehouses>warehouse"`
+}
+
+// WarehouseAvailability is what a warehouse has of a product; Incoming is
+// stock in transit to it.
+type WarehouseAvailability struct {
+	WarehouseID int    `json:"warehouse_id" xml:"warehouse_id"`
+	Code        string `json:"code" xml:"code"`
+	Name        string `json:"name" xml:"name"`
+	OnHand      int64  `json:"on_hand" xml:"on_hand"`
+	Expired     int64  `json:"expired" xml:"expired"`
+	Reserved    int64  `json:"reserved" xml:"reserved"`
+	Available   int64  `json:"available" xml:"available"`
+	Incoming    int64  `json:"incoming" xml:"incoming"`
+}
+
+// StockSettings is the request body for changing the inventory settings of
+// a product or variant; it replaces all of them. An item whose stock falls
+// to its ReorderPoint raises a low-stock alert, and ReorderQuantity is the
+// least it is reordered in.
+type StockSettings struct {
+	XMLName         xml.Name `json:"-" xml:"stock"`
+	AllowBackorder  bool     `json:"allow_backorder" xml:"allow_backorder"`
+	ReorderPoint    *int64   `json:"reorder_point" xml:"reorder_point,omitempty"`
+	ReorderQuantity *int64   `json:"reorder_quantity" xml:"reorder_quantity,omitempty"`
+}
+
+// ReorderSuggestion proposes how much of a product or variant to reorder.
+// StockLevel is the stock available to promise across all warehouses plus
+// stock in transit between them; NetSales is the units sold less units
+// returned over the sales window, and DailySales their average per day.
+type ReorderSuggestion struct {
+	XMLName           xml.Name `json:"-" xml:"suggestion"`
+	ProductID         int      `json:"product_id" xml:"product_id"`
+	VariantID         int      `json:"variant_id,omitempty" xml:"variant_id,omitempty"`
+	Name              string   `json:"name" xml:"name"`
+	SKU               string   `json:"sku,omitempty" xml:"sku,omitempty"`
+	StockLevel        int64    `json:"stock_level" xml:"stock_level"`
+	ReorderPoint      *int64   `json:"reorder_point,omitempty" xml:"reorder_point,omitempty"`
+	ReorderQuantity   *int64   `json:"reorder_quantity,omitempty" xml:"reorder_quantity,omitempty"`
+	NetSales          int64    `json:"net_sales" xml:"net_sales"`
+	DailySales        float64  `json:"daily_sales" xml:"daily_sales"`
+	DaysOfCover       *float64 `json:"days_of_cover,omitempty" xml:"days_of_cover,omitempty"`
+	SuggestedQuantity int64    `json:"suggested_quantity" xml:"suggested_quantity"`
+}
+
+func (s ReorderSuggestion) CSVHeader() []string {
+	return []string{"product_id", "variant_id", "name", "sku", "stock_level", "reorder_point", "reorder_quantity",
+		"net_sales", "daily_sales", "days_of_cover", "suggested_quantity"}
+}
+
+func (s ReorderSuggestion) CSVRecord() []string {
+	optional := func(n *int64) string {
+		if n == nil {
+			return ""
+		}
+		return strconv.FormatInt(*n, 10)
+	}
+	cover := ""
+	if s.DaysOfCover != nil {
+		cover = strconv.FormatFloat(*s.DaysOfCover, 'f', 1, 64)
+	}
+	return []string{
+		strconv.Itoa(s.ProductID),
+		strconv.Itoa(s.VariantID),
+		s.Name,
+		s.SKU,
+		strconv.FormatInt(s.StockLevel, 10),
+		optional(s.ReorderPoint),
+		optional(s.ReorderQuantity),
+		strconv.FormatInt(s.NetSales, 10),
+		strconv.FormatFloat(s.DailySales, 'f', 2, 64),
+		cover,
+		strconv.FormatInt(s.SuggestedQuantity, 10),
+	}
+}
+
+// Lot is the stock of a product or variant in one lot in one warehouse.
+// Expired lots cannot be sold; their stock can only leave by an adjustment
+// that names the lot. ProductName, SKU and WarehouseCode are only filled in
+// when lots of many products are listed.
+type Lot struct {
+	XMLName       xml.Name `json:"-" xml:"lot"`
+	ProductID     int      `json:"product_id" xml:"product_id"`
+	VariantID     int      `json:"variant_id,omitempty" xml:"variant_id,omitempty"`
+	ProductName   string   `json:"product_name,omitempty" xml:"product_name,omitempty"`
+	SKU           string   `json:"sku,omitempty" xml:"sku,omitempty"`
+	WarehouseID   int      `json:"warehouse_id" xml:"warehouse_id"`
+	WarehouseCode string   `json:"warehouse_code,omitempty" xml:"warehouse_code,omitempty"`
+	Lot           string   `json:"lot" xml:"number"`
+	ExpiresOn     string   `json:"expires_on,omitempty" xml:"expires_on,omitempty"`
+	Quantity      int64    `json:"quantity" xml:"quantity"`
+	Expired       bool     `json:"expired" xml:"expired"`
+}
+
+func (l Lot) CSVHeader() []string {
+	return []string{"product_id", "variant_id", "product_name", "sku", "warehouse_id", "warehouse_code", "lot", "expires_on", "quantity", "expired"}
+}
+
+func (l Lot) CSVRecord() []string {
+	return []string{
+		strconv.Itoa(l.ProductID),
+		strconv.Itoa(l.VariantID),
+		l.ProductName,
+		l.SKU,
+		strconv.Itoa(l.WarehouseID),
+		l.WarehouseCode,
+		l.Lot,
+		l.ExpiresOn,
+		strconv.FormatInt(l.Quantity, 10),
+		strconv.FormatBool(l.Expired),
+	}
+}
+
+// MovementFilter selects movements of a product; a zero VariantID,
+// WarehouseID or Type does not filter.
+type MovementFilter struct {
+	ProductID   int
+	VariantID   int
+	WarehouseID int
+	Type        MovementType
+	Limit       int
+	Offset      int
+}
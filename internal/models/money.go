@@ -0,0 +1,224 @@
+package models
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/vmihailenco/msgpack/v5"
+	"github.com/vmihailenco/msgpack/v5/msgpcode"
+)
+
+// currencyExponents lists the supported ISO 4217 currencies and the number
+// of decimal places of their minor unit.
+var currencyExponents = map[string]int{
+	"EUR": 2,
+	"USD": 2,
+	"KZT": 2,
+	"GBP": 2,
+	"CHF": 2,
+	"RUB": 2,
+	"JPY": 0,
+	"KWD": 3,
+}
+
+// CurrencyExponent returns the number of minor-unit digits of a supported
+// currency.
+func CurrencyExponent(currency string) (int, bool) {
+	exp, ok := currencyExponents[currency]
+	return exp, ok
+}
+
+// Money is an amount in the minor unit of its ISO 4217 currency, e.g.
+// cents for EUR. On the wire the amount is a decimal string so that it
+// never passes through a float: {"amount":"12.34","currency":"EUR"}.
+type Money struct {
+	Amount   int64
+	Currency string
+}
+
+// MoneyError reports an amount or currency that cannot be represented.
+type MoneyError struct {
+	Msg string
+}
+
+func (e *MoneyError) Error() string {
+	return e.Msg
+}
+
+func moneyErrorf(format string, args ...any) error {
+	return &MoneyError{Msg: fmt.Sprintf(format, args...)}
+}
+
+// ParseMoney parses a decimal amount such as "12.34" or "-0.5" in the given
+// currency. The amount may not have more decimal places than the currency's
+// minor unit.
+func ParseMoney(amount, currency string) (Money, error) {
+	if currency == "" {
+		return Money{}, moneyErrorf("currency is required")
+	}
+	exp, ok := CurrencyExponent(currency)
+	if !ok {
+		return Money{}, moneyErrorf("unsupported currency %q", currency)
+	}
+	s, neg := amount, false
+	if rest, ok := strings.CutPrefix(s, "-"); ok {
+		s, neg = rest, true
+	}
+	whole, frac, hasPoint := strings.Cut(s, ".")
+	if whole == "" || (hasPoint && frac == "") || !isDigits(whole) || !isDigits(frac) {
+		return Money{}, moneyErrorf("invalid amount %q: must be a decimal number such as \"12.34\"", amount)
+	}
+	if len(frac) > exp {
+		if exp == 0 {
+			return Money{}, moneyErrorf("invalid amount %q: %s has no minor unit", amount, currency)
+		}
+		return Money{}, moneyErrorf("invalid amount %q: %s allows at most %d decimal places", amount, currency, exp)
+	}
+	digits := whole + frac + strings.Repeat("0", exp-len(frac))
+	n, err := strconv.ParseInt(digits, 10, 64)
+	if err != nil {
+		return Money{}, moneyErrorf("invalid amount %q: out of range", amount)
+	}
+	if neg {
+		n = -n
+	}
+	return Money{Amount: n, Currency: currency}, nil
+}
+
+func isDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// Decimal formats the amount with exactly as many decimal places as the
+// currency's minor unit has.
+func (m Money) Decimal() string {
+	exp := currencyExponents[m.Currency]
+	n := m.Amount
+	sign := ""
+	if n < 0 {
+		sign, n = "-", -n
+	}
+	s := strconv.FormatInt(n, 10)
+	if exp == 0 {
+		return sign + s
+	}
+	if len(s) <= exp {
+		s = strings.Repeat("0", exp-len(s)+1) + s
+	}
+	return sign + s[:len(s)-exp] + "." + s[len(s)-exp:]
+}
+
+func (m Money) String() string {
+	return m.Decimal() + " " + m.Currency
+}
+
+func (m Money) IsZero() bool {
+	return m == Money{}
+}
+
+type moneyWire struct {
+	Amount   string `json:"amount" xml:"amount"`
+	Currency string `json:"currency" xml:"currency"`
+}
+
+func (m Money) MarshalJSON() ([]byte, error) {
+	return json.Marshal(moneyWire{Amount: m.Decimal(), Currency: m.Currency})
+}
+
+func (m *Money) UnmarshalJSON(data []byte) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	var w moneyWire
+	if err := dec.Decode(&w); err != nil {
+		var typeErr *json.UnmarshalTypeError
+		if errors.As(err, &typeErr) {
+			return moneyErrorf(`money must be an object such as {"amount":"12.34","currency":"EUR"} with a string amount`)
+		}
+		return err
+	}
+	return m.set(w)
+}
+
+// EncodeMsgpack writes the map MarshalJSON writes.
+func (m Money) EncodeMsgpack(enc *msgpack.Encoder) error {
+	if err := enc.EncodeMapLen(2); err != nil {
+		return err
+	}
+	for _, s := range []string{"amount", m.Decimal(), "currency", m.Currency} {
+		if err := enc.EncodeString(s); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *Money) DecodeMsgpack(dec *msgpack.Decoder) error {
+	errShape := moneyErrorf(`money must be a map such as {"amount":"12.34","currency":"EUR"} with a string amount`)
+	c, err := dec.PeekCode()
+	if err != nil {
+		return err
+	}
+	if !msgpcode.IsFixedMap(c) && c != msgpcode.Map16 && c != msgpcode.Map32 {
+		return errShape
+	}
+	n, err := dec.DecodeMapLen()
+	if err != nil {
+		return err
+	}
+	var w moneyWire
+	for i := 0; i < n; i++ {
+		key, err := dec.DecodeString()
+		if err != nil {
+			return err
+		}
+		var field *string
+		switch key {
+		case "amount":
+			field = &w.Amount
+		case "currency":
+			field = &w.Currency
+		default:
+			return fmt.Errorf("msgpack: unknown field %q", key)
+		}
+		if c, err := dec.PeekCode(); err != nil {
+			return err
+		} else if !msgpcode.IsString(c) {
+			return errShape
+		}
+		if *field, err = dec.DecodeString(); err != nil {
+			return err
+		}
+	}
+	return m.set(w)
+}
+
+func (m Money) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	return e.EncodeElement(moneyWire{Amount: m.Decimal(), Currency: m.Currency}, start)
+}
+
+func (m *Money) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	var w moneyWire
+	if err := d.DecodeElement(&w, &start); err != nil {
+		return err
+	}
+	return m.set(w)
+}
+
+func (m *Money) set(w moneyWire) error {
+	parsed, err := ParseMoney(strings.TrimSpace(w.Amount), strings.ToUpper(strings.TrimSpace(w.Currency)))
+	if err != nil {
+		return err
+	}
+	*m = parsed
+	return nil
+}
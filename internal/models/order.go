@@ -0,0 +1,86 @@
+package models
+
+import (
+	"encoding/xml"
+	"time"
+)
+
+type OrderStatus string
+
+const (
+	OrderPending   OrderStatus = "pending"
+	OrderPaid      OrderStatus = "paid"
+	OrderFulfilled OrderStatus = "fulfilled"
+	OrderDelivered OrderStatus = "delivered"
+	OrderCancelled OrderStatus = "cancelled"
+	OrderRefunded  OrderStatus = "refunded"
+)
+
+// Order is a customer's order for products shipped from a warehouse. It
+// goes from pending to paid, fulfilled and delivered; a pending order can
+// be cancelled and a paid one refunded. Its stock leaves the warehouse when
+// the order is placed and comes back when it is cancelled or refunded.
+// Total is the price of all the lines.
+type Order struct {
+	XMLName     xml.Name    `json:"-" xml:"order"`
+	ID          int64       `json:"id" xml:"id"`
+	WarehouseID int         `json:"warehouse_id" xml:"warehouse_id"`
+	Status      OrderStatus `json:"status" xml:"status"`
+	Currency    string      `json:"currency" xml:"currency"`
+	Customer    string      `json:"customer,omitempty" xml:"customer,omitempty"`
+	Lines       []OrderLine `json:"lines" xml:"lines>line"`
+	Total       Money       `json:"total" xml:"total"`
+	Note        string      `json:"note,omitempty" xml:"note,omitempty"`
+	Actor       string      `json:"actor" xml:"actor"`
+	CreatedAt   time.Time   `json:"created_at" xml:"created_at"`
+	UpdatedAt   time.Time   `json:"updated_at" xml:"updated_at"`
+	PaidAt      *time.Time  `json:"paid_at,omitempty" xml:"paid_at,omitempty"`
+	FulfilledAt *time.Time  `json:"fulfilled_at,omitempty" xml:"fulfilled_at,omitempty"`
+	DeliveredAt *time.Time  `json:"delivered_at,omitempty" xml:"delivered_at,omitempty"`
+	CancelledAt *time.Time  `json:"cancelled_at,omitempty" xml:"cancelled_at,omitempty"`
+	RefundedAt  *time.Time  `json:"refunded_at,omitempty" xml:"refunded_at,omitempty"`
+}
+
+// OrderLine is a product, or one of its variants when VariantID is set, on
+// an order. Name, SKU and UnitPrice are those of the product or variant
+// when the order was placed.
+type OrderLine struct {
+	ProductID int    `json:"product_id" xml:"product_id"`
+	VariantID int    `json:"variant_id,omitempty" xml:"variant_id,omitempty"`
+	Name      string `json:"name" xml:"name"`
+	SKU       string `json:"sku,omitempty" xml:"sku,omitempty"`
+	Quantity  int64  `json:"quantity" xml:"quantity"`
+	UnitPrice Money  `json:"unit_price" xml:"unit_price"`
+	Total     Money  `json:"total" xml:"total"`
+}
+
+func (l OrderLine) Key() StockKey {
+	return StockKey{ProductID: l.ProductID, VariantID: l.VariantID}
+}
+
+// OrderInput is the request body for placing an order. A zero WarehouseID
+// stands for the default warehouse.
+type OrderInput struct {
+	XMLName     xml.Name         `json:"-" xml:"order"`
+	WarehouseID int              `json:"warehouse_id" xml:"warehouse_id,omitempty"`
+	Customer    string           `json:"customer" xml:"customer,omitempty"`
+	Lines       []OrderLineInput `json:"lines" xml:"lines>line"`
+	Note        string           `json:"note" xml:"note,omitempty"`
+}
+
+type OrderLineInput struct {
+	ProductID int   `json:"product_id" xml:"product_id"`
+	VariantID int   `json:"variant_id" xml:"variant_id,omitempty"`
+	Quantity  int64 `json:"quantity" xml:"quantity"`
+}
+
+func (l OrderLineInput) Key() StockKey {
+	return StockKey{ProductID: l.ProductID, VariantID: l.VariantID}
+}
+
+// OrderFilter selects orders; zero values do not filter.
+type OrderFilter struct {
+	Status OrderStatus
+	Limit  int
+	Offset int
+}
@@ -0,0 +1,28 @@
+package models
+
+import (
+	"encoding/xml"
+	"time"
+)
+
+// PricePeriod is a price that was in effect from EffectiveFrom until
+// EffectiveTo, or is still in effect when EffectiveTo is nil. ScheduleID is
+// set when the price came from a price schedule rather than the product.
+type PricePeriod struct {
+	XMLName       xml.Name   `json:"-" xml:"price_period"`
+	ProductID     int        `json:"product_id" xml:"product_id"`
+	Price         Money      `json:"price" xml:"price"`
+	EffectiveFrom time.Time  `json:"effective_from" xml:"effective_from"`
+	EffectiveTo   *time.Time `json:"effective_to" xml:"effective_to,omitempty"`
+	ScheduleID    *int64     `json:"schedule_id,omitempty" xml:"schedule_id,omitempty"`
+}
+
+// PriceHistoryFilter selects the periods of one product that overlap
+// [From, To); zero bounds are open.
+type PriceHistoryFilter struct {
+	ProductID int
+	From      time.Time
+	To        time.Time
+	Limit     int
+	Offset    int
+}
@@ -0,0 +1,84 @@
+package models
+
+import (
+	"encoding/xml"
+	"time"
+)
+
+// PriceList holds negotiated prices, e.g. for wholesale customers, in one
+// currency. Its prices are set per product as quantity tiers.
+type PriceList struct {
+	XMLName   xml.Name  `json:"-" xml:"price_list"`
+	ID        int       `json:"id" xml:"id"`
+	Code      string    `json:"code" xml:"code"`
+	Name      string    `json:"name" xml:"name"`
+	Currency  string    `json:"currency" xml:"currency"`
+	CreatedAt time.Time `json:"created_at" xml:"created_at"`
+}
+
+// PriceTier is the unit price of a product from MinQuantity units on.
+type PriceTier struct {
+	XMLName     xml.Name `json:"-" xml:"tier"`
+	MinQuantity int      `json:"min_qty" xml:"min_qty"`
+	Price       Money    `json:"price" xml:"price"`
+}
+
+// CustomerGroup buys at the prices of its price list, if it has one.
+type CustomerGroup struct {
+	XMLName     xml.Name  `json:"-" xml:"customer_group"`
+	ID          int       `json:"id" xml:"id"`
+	Code        string    `json:"code" xml:"code"`
+	Name        string    `json:"name" xml:"name"`
+	PriceListID *int      `json:"price_list_id" xml:"price_list_id,omitempty"`
+	CreatedAt   time.Time `json:"created_at" xml:"created_at"`
+}
+
+type QuoteSource string
+
+const (
+	QuoteBase      QuoteSource = "base"
+	QuoteSale      QuoteSource = "sale"
+	QuotePriceList QuoteSource = "price_list"
+)
+
+// Quote is the price of a quantity of a product. Source tells whether the
+// unit price comes from the product, a running price schedule, or a tier
+// of the price list; MinQuantity is the break of that tier.
+type Quote struct {
+	XMLName     xml.Name    `json:"-" xml:"quote"`
+	ProductID   int         `json:"product_id" xml:"product_id"`
+	Quantity    int         `json:"qty" xml:"qty"`
+	PriceList   string      `json:"price_list,omitempty" xml:"price_list,omitempty"`
+	Source      QuoteSource `json:"source" xml:"source"`
+	MinQuantity int         `json:"min_qty,omitempty" xml:"min_qty,omitempty"`
+	BasePrice   Money       `json:"base_price" xml:"base_price"`
+	UnitPrice   Money       `json:"unit_price" xml:"unit_price"`
+	Total       Money       `json:"total" xml:"total"`
+}
+
+// PriceListInput is the request body for creating or changing a price
+// list. The currency cannot change once the list exists.
+type PriceListInput struct {
+	XMLName  xml.Name `json:"-" xml:"price_list"`
+	Code     string   `json:"code" xml:"code"`
+	Name     string   `json:"name" xml:"name"`
+	Currency string   `json:"currency" xml:"currency"`
+}
+
+// CustomerGroupInput is the request body for creating or changing a
+// customer group.
+type CustomerGroupInput struct {
+	XMLName     xml.Name `json:"-" xml:"customer_group"`
+	Code        string   `json:"code" xml:"code"`
+	Name        string   `json:"name" xml:"name"`
+	PriceListID *int     `json:"price_list_id" xml:"price_list_id,omitempty"`
+}
+
+// PriceTiers are the tiers of one product in one price list, ordered by
+// quantity break.
+type PriceTiers struct {
+	XMLName     xml.Name    `json:"-" xml:"tiers"`
+	PriceListID int         `json:"price_list_id" xml:"price_list_id"`
+	ProductID   int         `json:"product_id" xml:"product_id"`
+	Tiers       []PriceTier `json:"tiers" xml:"tier"`
+}
@@ -0,0 +1,50 @@
+package models
+
+import (
+	"encoding/xml"
+	"time"
+)
+
+type ScheduleStatus string
+
+const (
+	ScheduleScheduled ScheduleStatus = "scheduled"
+	ScheduleActive    ScheduleStatus = "active"
+	ScheduleExpired   ScheduleStatus = "expired"
+	ScheduleApplied   ScheduleStatus = "applied"
+	ScheduleCancelled ScheduleStatus = "cancelled"
+	ScheduleFailed    ScheduleStatus = "failed"
+)
+
+// PriceSchedule changes a product's price at StartsAt. With EndsAt set it
+// is a sale that overrides the price until EndsAt; without it the new price
+// replaces the product's price for good once the scheduler applies it, or
+// fails with an Error when the product cannot take it.
+type PriceSchedule struct {
+	XMLName     xml.Name       `json:"-" xml:"price_schedule"`
+	ID          int64          `json:"id" xml:"id"`
+	ProductID   int            `json:"product_id" xml:"product_id"`
+	Price       Money          `json:"price" xml:"price"`
+	StartsAt    time.Time      `json:"starts_at" xml:"starts_at"`
+	EndsAt      *time.Time     `json:"ends_at,omitempty" xml:"ends_at,omitempty"`
+	Status      ScheduleStatus `json:"status" xml:"status"`
+	Note        string         `json:"note" xml:"note"`
+	CreatedAt   time.Time      `json:"created_at" xml:"created_at"`
+	ActivatedAt *time.Time     `json:"activated_at,omitempty" xml:"activated_at,omitempty"`
+	FinishedAt  *time.Time     `json:"finished_at,omitempty" xml:"finished_at,omitempty"`
+	Error       string         `json:"error,omitempty" xml:"error,omitempty"`
+}
+
+// IsSale reports whether the schedule is time-boxed.
+func (s *PriceSchedule) IsSale() bool {
+	return s.EndsAt != nil
+}
+
+// PriceScheduleInput is the request body for creating or changing a schedule.
+type PriceScheduleInput struct {
+	XMLName  xml.Name   `json:"-" xml:"price_schedule"`
+	Price    Money      `json:"price" xml:"price"`
+	StartsAt time.Time  `json:"starts_at" xml:"starts_at"`
+	EndsAt   *time.Time `json:"ends_at" xml:"ends_at,omitempty"`
+	Note     string     `json:"note" xml:"note"`
+}
@@ -1,8 +1,197 @@
 package models
 
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"time"
+
+	"github.com/vmihailenco/msgpack/v5"
+)
+
 type Product struct {
-	ID          int    `json:"id"`
-	Name        string `json:"name"`
-	Description string `json:"description"`
-	Price       int    `json:"price"`
+	XMLName     xml.Name   `json:"-" xml:"product"`
+	ID          int        `json:"id" xml:"id"`
+	Name        string     `json:"name" xml:"name"`
+	Description string     `json:"description" xml:"description"`
+	Price       Money      `json:"price" xml:"price"`
+	Attributes  Attributes `json:"attributes,omitempty" xml:"attributes,omitempty"`
+	SKU         string     `json:"sku,omitempty" xml:"sku,omitempty"`
+	Barcode     string     `json:"barcode,omitempty" xml:"barcode,omitempty"`
+	Slug        string     `json:"slug,omitempty" xml:"slug,omitempty"`
+	DeletedAt   *time.Time `json:"deleted_at,omitempty" xml:"deleted_at,omitempty"`
+
+	// EffectivePrice is what the product sells for, which differs from
+	// Price while a price schedule applies. It is only set on reads.
+	EffectivePrice *Money `json:"effective_price,omitempty" xml:"effective_price,omitempty"`
+	// ConvertedPrice is the effective price in the currency requested with
+	// ?currency=, if any. It stays unset when there is no rate for it.
+	ConvertedPrice *ConvertedPrice `json:"converted_price,omitempty" xml:"converted_price,omitempty"`
+	// Breadcrumbs is the path from the root to the product's primary
+	// category. It is only set on reads.
+	Breadcrumbs []CategoryRef `json:"breadcrumbs,omitempty" xml:"breadcrumbs>category,omitempty"`
+	// Tags is only set on reads.
+	Tags []string `json:"tags,omitempty" xml:"tags>tag,omitempty"`
+	// Options and Variants are only embedded when a single product is read.
+	Options  []ProductOption `json:"options,omitempty" xml:"options>option,omitempty"`
+	Variants []Variant       `json:"variants,omitempty" xml:"variants>variant,omitempty"`
+	// Available is the stock that can still be promised: on hand across
+	// all warehouses minus active reservations, of the product and its
+	// variants together. It is only set on reads.
+	Available *int64 `json:"available,omitempty" xml:"available,omitempty"`
+}
+
+func (p Product) CSVHeader() []string {
+	return ProductFields
+}
+
+func (p Product) CSVRecord() []string {
+	return p.record(ProductFields)
+}
+
+// ProductFields lists the fields that can be selected with ?fields=.
+var ProductFields = []string{"id", "name", "description", "price", "attributes", "sku", "barcode", "slug"}
+
+// ProductIncludes lists the relations that can be embedded with ?include=.
+var ProductIncludes = []string{}
+
+// Field returns the value of a field from ProductFields (or deleted_at and
+// converted_price), or nil for an unknown name.
+func (p *Product) Field(name string) any {
+	switch name {
+	case "id":
+		return p.ID
+	case "name":
+		return p.Name
+	case "description":
+		return p.Description
+	case "price":
+		return p.Price
+	case "attributes":
+		return p.Attributes
+	case "sku":
+		return p.SKU
+	case "barcode":
+		return p.Barcode
+	case "slug":
+		return p.Slug
+	case "deleted_at":
+		return p.DeletedAt
+	case "converted_price":
+		if p.ConvertedPrice == nil {
+			return nil
+		}
+		return p.ConvertedPrice
+	}
+	return nil
+}
+
+func (p *Product) record(fields []string) []string {
+	rec := make([]string, len(fields))
+	for i, f := range fields {
+		if v := p.Field(f); v != nil {
+			rec[i] = fmt.Sprint(v)
+		}
+	}
+	return rec
+}
+
+// ProductFilter narrows down product listings and exports.
+// A zero Limit means no limit; empty Fields means all fields. Trashed
+// selects soft-deleted products instead of live ones. CategoryID selects
+// the products of a category, and of its subcategories with
+// IncludeDescendants. Tags selects the products carrying all or, with
+// TagModeAny, any of the given normalized tags. Attributes must all hold.
+type ProductFilter struct {
+	Limit              int
+	Offset             int
+	Fields             []string
+	Include            []string
+	Trashed            bool
+	CategoryID         int
+	IncludeDescendants bool
+	Tags               []string
+	TagMode            TagMode
+	Attributes         []AttributeFilter
+}
+
+// Narrowed reports whether the filter selects only part of the live
+// products, so that table-wide statistics do not describe the result.
+func (f ProductFilter) Narrowed() bool {
+	return f.Trashed || f.CategoryID != 0 || len(f.Tags) > 0 || len(f.Attributes) > 0
+}
+
+// ProductPage is one page of a product listing together with the size of
+// the whole result. Total is approximate when TotalEstimated is set.
+type ProductPage struct {
+	Items          []Product
+	Total          int
+	TotalEstimated bool
+	Limit          int
+	Offset         int
+	HasMore        bool
+}
+
+// ProductView is a product restricted to a subset of its fields, in the
+// given order. It encodes to JSON, XML and CSV like Product does.
+type ProductView struct {
+	Product *Product
+	Fields  []string
+}
+
+func (v ProductView) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, f := range v.Fields {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		key, _ := json.Marshal(f)
+		val, err := json.Marshal(v.Product.Field(f))
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(val)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+func (v ProductView) EncodeMsgpack(enc *msgpack.Encoder) error {
+	if err := enc.EncodeMapLen(len(v.Fields)); err != nil {
+		return err
+	}
+	for _, f := range v.Fields {
+		if err := enc.EncodeString(f); err != nil {
+			return err
+		}
+		if err := enc.Encode(v.Product.Field(f)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (v ProductView) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	start = xml.StartElement{Name: xml.Name{Local: "product"}}
+	if err := e.EncodeToken(start); err != nil {
+		return err
+	}
+	for _, f := range v.Fields {
+		if err := e.EncodeElement(v.Product.Field(f), xml.StartElement{Name: xml.Name{Local: f}}); err != nil {
+			return err
+		}
+	}
+	return e.EncodeToken(start.End())
+}
+
+func (v ProductView) CSVHeader() []string {
+	return v.Fields
+}
+
+func (v ProductView) CSVRecord() []string {
+	return v.Product.record(v.Fields)
 }
@@ -0,0 +1,107 @@
+package models
+
+import (
+	"encoding/xml"
+	"time"
+)
+
+type PurchaseOrderStatus string
+
+const (
+	PurchaseOrderDraft             PurchaseOrderStatus = "draft"
+	PurchaseOrderSent              PurchaseOrderStatus = "sent"
+	PurchaseOrderPartiallyReceived PurchaseOrderStatus = "partially_received"
+	PurchaseOrderReceived          PurchaseOrderStatus = "received"
+	PurchaseOrderCancelled         PurchaseOrderStatus = "cancelled"
+)
+
+// PurchaseOrder orders stock from a supplier into a warehouse. A draft can
+// be changed until it is sent to the supplier; a sent order is received
+// into stock, in one go or in parts, or cancelled while nothing of it has
+// been received. Total is the cost of all the lines.
+type PurchaseOrder struct {
+	XMLName     xml.Name            `json:"-" xml:"purchase_order"`
+	ID          int64               `json:"id" xml:"id"`
+	SupplierID  int                 `json:"supplier_id" xml:"supplier_id"`
+	WarehouseID int                 `json:"warehouse_id" xml:"warehouse_id"`
+	Status      PurchaseOrderStatus `json:"status" xml:"status"`
+	Currency    string              `json:"currency" xml:"currency"`
+	Lines       []PurchaseOrderLine `json:"lines" xml:"lines>line"`
+	Total       Money               `json:"total" xml:"total"`
+	Note        string              `json:"note,omitempty" xml:"note,omitempty"`
+	Actor       string              `json:"actor" xml:"actor"`
+	CreatedAt   time.Time           `json:"created_at" xml:"created_at"`
+	UpdatedAt   time.Time           `json:"updated_at" xml:"updated_at"`
+	SentAt      *time.Time          `json:"sent_at,omitempty" xml:"sent_at,omitempty"`
+	FinishedAt  *time.Time          `json:"finished_at,omitempty" xml:"finished_at,omitempty"`
+}
+
+// PurchaseOrderLine is a product, or one of its variants when VariantID is
+// set, on a purchase order. SupplierSKU is the supplier's code for the
+// product when the order was drafted.
+type PurchaseOrderLine struct {
+	ProductID   int    `json:"product_id" xml:"product_id"`
+	VariantID   int    `json:"variant_id,omitempty" xml:"variant_id,omitempty"`
+	SupplierSKU string `json:"supplier_sku,omitempty" xml:"supplier_sku,omitempty"`
+	Quantity    int64  `json:"quantity" xml:"quantity"`
+	Received    int64  `json:"received" xml:"received"`
+	UnitCost    Money  `json:"unit_cost" xml:"unit_cost"`
+}
+
+func (l PurchaseOrderLine) Key() StockKey {
+	return StockKey{ProductID: l.ProductID, VariantID: l.VariantID}
+}
+
+// PurchaseOrderInput is the request body for drafting a purchase order or
+// changing a draft. A zero WarehouseID stands for the default warehouse.
+type PurchaseOrderInput struct {
+	XMLName     xml.Name                 `json:"-" xml:"purchase_order"`
+	SupplierID  int                      `json:"supplier_id" xml:"supplier_id"`
+	WarehouseID int                      `json:"warehouse_id" xml:"warehouse_id,omitempty"`
+	Currency    string                   `json:"currency" xml:"currency"`
+	Lines       []PurchaseOrderLineInput `json:"lines" xml:"lines>line"`
+	Note        string                   `json:"note" xml:"note,omitempty"`
+}
+
+// PurchaseOrderLineInput is a line of a PurchaseOrderInput. Without a
+// UnitCost the line costs the supplier's cost price of the product.
+type PurchaseOrderLineInput struct {
+	ProductID int    `json:"product_id" xml:"product_id"`
+	VariantID int    `json:"variant_id" xml:"variant_id,omitempty"`
+	Quantity  int64  `json:"quantity" xml:"quantity"`
+	UnitCost  *Money `json:"unit_cost" xml:"unit_cost,omitempty"`
+}
+
+func (l PurchaseOrderLineInput) Key() StockKey {
+	return StockKey{ProductID: l.ProductID, VariantID: l.VariantID}
+}
+
+// ReceiptInput is the request body for receiving the stock of a purchase
+// order that has arrived.
+type ReceiptInput struct {
+	XMLName xml.Name      `json:"-" xml:"receipt"`
+	Lines   []ReceiptLine `json:"lines" xml:"lines>line"`
+	Note    string        `json:"note" xml:"note,omitempty"`
+}
+
+// ReceiptLine is the stock of one product or variant that arrived,
+// optionally in a lot that expires on ExpiresOn.
+type ReceiptLine struct {
+	ProductID int    `json:"product_id" xml:"product_id"`
+	VariantID int    `json:"variant_id" xml:"variant_id,omitempty"`
+	Quantity  int64  `json:"quantity" xml:"quantity"`
+	Lot       string `json:"lot" xml:"lot,omitempty"`
+	ExpiresOn string `json:"expires_on" xml:"expires_on,omitempty"`
+}
+
+func (l ReceiptLine) Key() StockKey {
+	return StockKey{ProductID: l.ProductID, VariantID: l.VariantID}
+}
+
+// PurchaseOrderFilter selects purchase orders; zero values do not filter.
+type PurchaseOrderFilter struct {
+	Status     PurchaseOrderStatus
+	SupplierID int
+	Limit      int
+	Offset     int
+}
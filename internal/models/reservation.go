@@ -0,0 +1,52 @@
+package models
+
+import (
+	"encoding/xml"
+	"time"
+)
+
+type ReservationStatus string
+
+const (
+	ReservationActive    ReservationStatus = "active"
+	ReservationConfirmed ReservationStatus = "confirmed"
+	ReservationReleased  ReservationStatus = "released"
+	ReservationExpired   ReservationStatus = "expired"
+)
+
+// Reservation holds stock in a warehouse for a cart or checkout until it
+// expires. Confirming it sells the stock; releasing it or letting it
+// expire frees the stock again.
+type Reservation struct {
+	XMLName     xml.Name          `json:"-" xml:"reservation"`
+	ID          int64             `json:"id" xml:"id"`
+	WarehouseID int               `json:"warehouse_id" xml:"warehouse_id"`
+	Status      ReservationStatus `json:"status" xml:"status"`
+	Lines       []ReservationLine `json:"lines" xml:"lines>line"`
+	Reference   string            `json:"reference,omitempty" xml:"reference,omitempty"`
+	Actor       string            `json:"actor" xml:"actor"`
+	CreatedAt   time.Time         `json:"created_at" xml:"created_at"`
+	ExpiresAt   time.Time         `json:"expires_at" xml:"expires_at"`
+	FinishedAt  *time.Time        `json:"finished_at,omitempty" xml:"finished_at,omitempty"`
+}
+
+// ReservationLine holds stock of a product, or of one of its variants when
+// VariantID is set.
+type ReservationLine struct {
+	ProductID int   `json:"product_id" xml:"product_id"`
+	VariantID int   `json:"variant_id,omitempty" xml:"variant_id,omitempty"`
+	Quantity  int64 `json:"quantity" xml:"quantity"`
+}
+
+func (l ReservationLine) Key() StockKey {
+	return StockKey{ProductID: l.ProductID, VariantID: l.VariantID}
+}
+
+// ReservationInput is the request body for creating a reservation. A zero
+// WarehouseID stands for the default warehouse.
+type ReservationInput struct {
+	XMLName     xml.Name          `json:"-" xml:"reservation"`
+	WarehouseID int               `json:"warehouse_id" xml:"warehouse_id,omitempty"`
+	Lines       []ReservationLine `json:"lines" xml:"lines>line"`
+	Reference   string            `json:"reference" xml:"reference,omitempty"`
+}
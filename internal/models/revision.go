@@ -0,0 +1,27 @@
+package models
+
+import (
+	"encoding/xml"
+	"time"
+)
+
+// ProductRevision is a full snapshot of a product taken after each change.
+// Revisions are numbered from 1 per product.
+type ProductRevision struct {
+	XMLName   xml.Name  `json:"-" xml:"revision"`
+	ProductID int       `json:"product_id" xml:"product_id"`
+	Revision  int       `json:"revision" xml:"number"`
+	Snapshot  Product   `json:"snapshot" xml:"snapshot"`
+	Actor     string    `json:"actor" xml:"actor"`
+	RequestID string    `json:"request_id,omitempty" xml:"request_id,omitempty"`
+	CreatedAt time.Time `json:"created_at" xml:"created_at"`
+}
+
+// RevisionDiff lists the changes needed to go from revision From to To.
+type RevisionDiff struct {
+	XMLName   xml.Name      `json:"-" xml:"revision_diff"`
+	ProductID int           `json:"product_id" xml:"product_id"`
+	From      int           `json:"from" xml:"from"`
+	To        int           `json:"to" xml:"to"`
+	Changes   []FieldChange `json:"changes" xml:"changes>change"`
+}
@@ -0,0 +1,47 @@
+package models
+
+import (
+	"encoding/xml"
+	"time"
+)
+
+type Supplier struct {
+	XMLName   xml.Name  `json:"-" xml:"supplier"`
+	ID        int       `json:"id" xml:"id"`
+	Code      string    `json:"code" xml:"code"`
+	Name      string    `json:"name" xml:"name"`
+	Email     string    `json:"email,omitempty" xml:"email,omitempty"`
+	Phone     string    `json:"phone,omitempty" xml:"phone,omitempty"`
+	CreatedAt time.Time `json:"created_at" xml:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" xml:"updated_at"`
+}
+
+// SupplierInput is the request body for creating or updating a supplier.
+type SupplierInput struct {
+	XMLName xml.Name `json:"-" xml:"supplier"`
+	Code    string   `json:"code" xml:"code"`
+	Name    string   `json:"name" xml:"name"`
+	Email   string   `json:"email" xml:"email,omitempty"`
+	Phone   string   `json:"phone" xml:"phone,omitempty"`
+}
+
+// SupplierProduct is a product a supplier supplies. SupplierSKU is the
+// supplier's own code for it and CostPrice what the supplier charges per
+// unit, which purchase orders default to.
+type SupplierProduct struct {
+	XMLName     xml.Name  `json:"-" xml:"product"`
+	SupplierID  int       `json:"supplier_id" xml:"supplier_id"`
+	ProductID   int       `json:"product_id" xml:"product_id"`
+	ProductName string    `json:"product_name" xml:"product_name"`
+	SupplierSKU string    `json:"supplier_sku,omitempty" xml:"supplier_sku,omitempty"`
+	CostPrice   *Money    `json:"cost_price,omitempty" xml:"cost_price,omitempty"`
+	UpdatedAt   time.Time `json:"updated_at" xml:"updated_at"`
+}
+
+// SupplierProductInput is the request body for adding a product to a
+// supplier or changing its terms.
+type SupplierProductInput struct {
+	XMLName     xml.Name `json:"-" xml:"product"`
+	SupplierSKU string   `json:"supplier_sku" xml:"supplier_sku,omitempty"`
+	CostPrice   *Money   `json:"cost_price" xml:"cost_price,omitempty"`
+}
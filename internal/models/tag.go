@@ -0,0 +1,35 @@
+package models
+
+import (
+	"encoding/xml"
+	"strings"
+)
+
+// TagMode tells whether a product must carry all or any of the tags of a
+// filter.
+type TagMode string
+
+const (
+	TagModeAll TagMode = "all"
+	TagModeAny TagMode = "any"
+)
+
+// Tag is a tag with the number of live products that carry it.
+type Tag struct {
+	XMLName xml.Name `json:"-" xml:"tag"`
+	Name    string   `json:"name" xml:"name"`
+	Count   int      `json:"count" xml:"count"`
+}
+
+// ProductTags is the list of tags of a product, and the request body for
+// adding tags to one.
+type ProductTags struct {
+	XMLName xml.Name `json:"-" xml:"tags"`
+	Tags    []string `json:"tags" xml:"tag"`
+}
+
+// NormalizeTag lower-cases a tag name and joins its words with '-', so
+// that "New Arrival" and " new-arrival" name the same tag.
+func NormalizeTag(name string) string {
+	return strings.Join(strings.Fields(strings.ToLower(name)), "-")
+}
@@ -0,0 +1,118 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// ProductOption is an axis along which the variants of a product differ.
+type ProductOption struct {
+	XMLName xml.Name `json:"-" xml:"option"`
+	Name    string   `json:"name" xml:"name"`
+	Values  []string `json:"values" xml:"value"`
+}
+
+// ProductOptions is the list of option axes of a product, and the request
+// body that replaces them.
+type ProductOptions struct {
+	XMLName xml.Name        `json:"-" xml:"options"`
+	Options []ProductOption `json:"options" xml:"option"`
+}
+
+// OptionValues picks one value per option axis, e.g. size M and colour red.
+type OptionValues map[string]string
+
+// MarshalXML writes one <option name="..."> element per axis, in name order.
+func (o OptionValues) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	if err := e.EncodeToken(start); err != nil {
+		return err
+	}
+	names := make([]string, 0, len(o))
+	for name := range o {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		el := xml.StartElement{Name: xml.Name{Local: "option"}, Attr: []xml.Attr{{Name: xml.Name{Local: "name"}, Value: name}}}
+		if err := e.EncodeElement(o[name], el); err != nil {
+			return err
+		}
+	}
+	return e.EncodeToken(start.End())
+}
+
+func (o *OptionValues) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	var doc struct {
+		Items []struct {
+			Name  string `xml:"name,attr"`
+			Value string `xml:",chardata"`
+		} `xml:"option"`
+	}
+	if err := d.DecodeElement(&doc, &start); err != nil {
+		return err
+	}
+	m := make(OptionValues, len(doc.Items))
+	for _, item := range doc.Items {
+		if _, dup := m[item.Name]; dup {
+			return fmt.Errorf("option %q is given twice", item.Name)
+		}
+		m[item.Name] = item.Value
+	}
+	*o = m
+	return nil
+}
+
+func (o OptionValues) Value() (driver.Value, error) {
+	return json.Marshal(map[string]string(o))
+}
+
+func (o *OptionValues) Scan(src any) error {
+	b, ok := src.([]byte)
+	if !ok {
+		return errors.New("option values: unsupported column type")
+	}
+	return json.Unmarshal(b, (*map[string]string)(o))
+}
+
+// Variant is a sellable version of a product for one combination of its
+// options. Price overrides the product's price when set; EffectivePrice,
+// only set on reads, is what the variant sells for.
+type Variant struct {
+	XMLName        xml.Name     `json:"-" xml:"variant"`
+	ID             int          `json:"id" xml:"id"`
+	ProductID      int          `json:"product_id" xml:"product_id"`
+	SKU            string       `json:"sku" xml:"sku"`
+	Options        OptionValues `json:"options" xml:"options"`
+	Price          *Money       `json:"price,omitempty" xml:"price,omitempty"`
+	EffectivePrice *Money       `json:"effective_price,omitempty" xml:"effective_price,omitempty"`
+	Attributes     Attributes   `json:"attributes,omitempty" xml:"attributes,omitempty"`
+	CreatedAt      time.Time    `json:"created_at" xml:"created_at"`
+	UpdatedAt      time.Time    `json:"updated_at" xml:"updated_at"`
+}
+
+// VariantInput is the request body for creating or updating a variant.
+type VariantInput struct {
+	XMLName    xml.Name     `json:"-" xml:"variant"`
+	SKU        string       `json:"sku" xml:"sku"`
+	Options    OptionValues `json:"options" xml:"options"`
+	Price      *Money       `json:"price" xml:"price,omitempty"`
+	Attributes Attributes   `json:"attributes" xml:"attributes,omitempty"`
+}
+
+// Variants is a list of variants as returned by the variant endpoints.
+type Variants struct {
+	XMLName  xml.Name  `json:"-" xml:"variants"`
+	Variants []Variant `json:"variants" xml:"variant"`
+}
+
+// VariantMatrix is the request body for generating the missing variants of
+// a product; their SKUs start with SKUPrefix.
+type VariantMatrix struct {
+	XMLName   xml.Name `json:"-" xml:"matrix"`
+	SKUPrefix string   `json:"sku_prefix" xml:"sku_prefix"`
+}
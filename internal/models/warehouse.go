@@ -0,0 +1,78 @@
+package models
+
+import (
+	"encoding/xml"
+	"time"
+)
+
+type Warehouse struct {
+	XMLName   xml.Name  `json:"-" xml:"warehouse"`
+	ID        int       `json:"id" xml:"id"`
+	Code      string    `json:"code" xml:"code"`
+	Name      string    `json:"name" xml:"name"`
+	IsDefault bool      `json:"is_default" xml:"is_default"`
+	CreatedAt time.Time `json:"created_at" xml:"created_at"`
+}
+
+// WarehouseInput is the request body for creating or updating a warehouse.
+// Making a warehouse the default one takes that role from the previous
+// default.
+type WarehouseInput struct {
+	XMLName   xml.Name `json:"-" xml:"warehouse"`
+	Code      string   `json:"code" xml:"code"`
+	Name      string   `json:"name" xml:"name"`
+	IsDefault bool     `json:"is_default" xml:"is_default"`
+}
+
+type TransferStatus string
+
+const (
+	TransferInTransit TransferStatus = "in_transit"
+	TransferReceived  TransferStatus = "received"
+	TransferCancelled TransferStatus = "cancelled"
+)
+
+// Transfer moves stock between warehouses. Creating it takes the stock out
+// of the source; receiving it puts the stock into the destination, and
+// cancelling it puts the stock back into the source.
+type Transfer struct {
+	XMLName       xml.Name       `json:"-" xml:"transfer"`
+	ID            int64          `json:"id" xml:"id"`
+	SourceID      int            `json:"source_warehouse_id" xml:"source_warehouse_id"`
+	DestinationID int            `json:"destination_warehouse_id" xml:"destination_warehouse_id"`
+	Status        TransferStatus `json:"status" xml:"status"`
+	Lines         []TransferLine `json:"lines" xml:"lines>line"`
+	Note          string         `json:"note,omitempty" xml:"note,omitempty"`
+	Actor         string         `json:"actor" xml:"actor"`
+	CreatedAt     time.Time      `json:"created_at" xml:"created_at"`
+	FinishedAt    *time.Time     `json:"finished_at,omitempty" xml:"finished_at,omitempty"`
+}
+
+// TransferLine moves stock of a product, or of one of its variants when
+// VariantID is set.
+type TransferLine struct {
+	ProductID int   `json:"product_id" xml:"product_id"`
+	VariantID int   `json:"variant_id,omitempty" xml:"variant_id,omitempty"`
+	Quantity  int64 `json:"quantity" xml:"quantity"`
+}
+
+func (l TransferLine) Key() StockKey {
+	return StockKey{ProductID: l.ProductID, VariantID: l.VariantID}
+}
+
+// TransferInput is the request body for creating a transfer.
+type TransferInput struct {
+	XMLName       xml.Name       `json:"-" xml:"transfer"`
+	SourceID      int            `json:"source_warehouse_id" xml:"source_warehouse_id"`
+	DestinationID int            `json:"destination_warehouse_id" xml:"destination_warehouse_id"`
+	Lines         []TransferLine `json:"lines" xml:"lines>line"`
+	Note          string         `json:"note" xml:"note,omitempty"`
+}
+
+// TransferFilter selects transfers; zero values do not filter.
+type TransferFilter struct {
+	Status      TransferStatus
+	WarehouseID int
+	Limit       int
+	Offset      int
+}
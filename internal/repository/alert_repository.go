@@ -0,0 +1,121 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"product-test/internal/models"
+
+	"github.com/lib/pq"
+)
+
+type AlertRepository interface {
+	List(ctx context.Context, filter models.AlertFilter) ([]models.Alert, error)
+	Count(ctx context.Context, filter models.AlertFilter) (int, error)
+	// Raise opens a low-stock alert for each live product or variant whose
+	// stock level is at or below its reorder point and that has no open
+	// alert yet, and returns how many it opened.
+	Raise(ctx context.Context) (int64, error)
+	// Resolve closes the open alerts of products and variants that no
+	// longer need reordering, and returns how many it closed.
+	Resolve(ctx context.Context) (int64, error)
+	// Unnotified returns open alerts that have not been delivered yet,
+	// oldest first.
+	Unnotified(ctx context.Context, limit int) ([]models.Alert, error)
+	MarkNotified(ctx context.Context, ids []int64) error
+}
+
+type alertRepo struct {
+	db *sql.DB
+}
+
+func NewAlertRepository(db *sql.DB) AlertRepository {
+	return &alertRepo{db: db}
+}
+
+const alertSelect = `SELECT a.id, a.product_id, a.variant_id, p.name, a.type, a.stock_level, a.reorder_point, a.reorder_quantity,
+	a.created_at, a.resolved_at, a.notified_at
+	FROM alerts a JOIN products p ON p.id = a.product_id`
+
+// lowStock selects the live products and variants at or below their
+// reorder point.
+const lowStock = `
+	SELECT pos.product_id, pos.variant_id, pos.on_hand - pos.expired - pos.reserved + pos.in_transit AS stock_level, i.reorder_point, i.reorder_quantity
+	FROM inventory_positions pos
+	JOIN inventory_items i ON i.product_id = pos.product_id AND i.variant_id = pos.variant_id
+	WHERE i.reorder_point IS NOT NULL AND pos.on_hand - pos.expired - pos.reserved + pos.in_transit <= i.reorder_point`
+
+func (r *alertRepo) query(ctx context.Context, query string, args ...any) ([]models.Alert, error) {
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var alerts []models.Alert
+	for rows.Next() {
+		var a models.Alert
+		err := rows.Scan(&a.ID, &a.ProductID, &a.VariantID, &a.ProductName, &a.Type, &a.StockLevel, &a.ReorderPoint, &a.ReorderQuantity,
+			&a.CreatedAt, &a.ResolvedAt, &a.NotifiedAt)
+		if err != nil {
+			return nil, err
+		}
+		alerts = append(alerts, a)
+	}
+	return alerts, rows.Err()
+}
+
+func alertCondition(f models.AlertFilter) string {
+	switch f.Status {
+	case models.AlertOpen:
+		return "a.resolved_at IS NULL"
+	case models.AlertResolved:
+		return "a.resolved_at IS NOT NULL"
+	}
+	return "TRUE"
+}
+
+func (r *alertRepo) List(ctx context.Context, filter models.AlertFilter) ([]models.Alert, error) {
+	if filter.Limit <= 0 {
+		filter.Limit = 100
+	}
+	query := fmt.Sprintf(alertSelect+` WHERE %s ORDER BY a.id DESC LIMIT $1 OFFSET $2`, alertCondition(filter))
+	return r.query(ctx, query, filter.Limit, filter.Offset)
+}
+
+func (r *alertRepo) Count(ctx context.Context, filter models.AlertFilter) (int, error) {
+	var n int
+	err := conn(ctx, r.db).QueryRowContext(ctx, `SELECT count(*) FROM alerts a WHERE `+alertCondition(filter)).Scan(&n)
+	return n, err
+}
+
+func (r *alertRepo) Raise(ctx context.Context) (int64, error) {
+	res, err := conn(ctx, r.db).ExecContext(ctx, `
+		INSERT INTO alerts (product_id, variant_id, type, stock_level, reorder_point, reorder_quantity)
+		SELECT product_id, variant_id, 'low_stock', stock_level, reorder_point, reorder_quantity FROM (`+lowStock+`) l
+		ON CONFLICT (product_id, variant_id, type) WHERE resolved_at IS NULL DO NOTHING`)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+func (r *alertRepo) Resolve(ctx context.Context) (int64, error) {
+	res, err := conn(ctx, r.db).ExecContext(ctx, `
+		UPDATE alerts SET resolved_at = now()
+		WHERE resolved_at IS NULL AND (product_id, variant_id) NOT IN (SELECT product_id, variant_id FROM (`+lowStock+`) l)`)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+func (r *alertRepo) Unnotified(ctx context.Context, limit int) ([]models.Alert, error) {
+	return r.query(ctx, alertSelect+` WHERE a.notified_at IS NULL AND a.resolved_at IS NULL ORDER BY a.id LIMIT $1`, limit)
+}
+
+func (r *alertRepo) MarkNotified(ctx context.Context, ids []int64) error {
+	_, err := conn(ctx, r.db).ExecContext(ctx,
+		`UPDATE alerts SET notified_at = now() WHERE id = ANY($1)`, pq.Int64Array(ids))
+	return err
+}
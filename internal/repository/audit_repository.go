@@ -0,0 +1,100 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"product-test/internal/models"
+	"strings"
+)
+
+type AuditRepository interface {
+	Insert(ctx context.Context, entry *models.AuditEntry) error
+	List(ctx context.Context, filter models.AuditFilter) ([]models.AuditEntry, error)
+	Count(ctx context.Context, filter models.AuditFilter) (int, error)
+}
+
+type auditRepo struct {
+	db *sql.DB
+}
+
+func NewAuditRepository(db *sql.DB) AuditRepository {
+	return &auditRepo{db: db}
+}
+
+// Insert appends an entry. Call it with the context of the transaction that
+// makes the change so that both are committed together.
+func (r *auditRepo) Insert(ctx context.Context, e *models.AuditEntry) error {
+	changes, err := json.Marshal(e.Changes)
+	if err != nil {
+		return fmt.Errorf("encode audit changes: %w", err)
+	}
+	query := `INSERT INTO product_audit (product_id, action, actor, request_id, changes)
+		VALUES ($1, $2, $3, NULLIF($4, ''), $5) RETURNING id, created_at`
+	return conn(ctx, r.db).QueryRowContext(ctx, query, e.ProductID, e.Action, e.Actor, e.RequestID, changes).
+		Scan(&e.ID, &e.CreatedAt)
+}
+
+func (r *auditRepo) List(ctx context.Context, filter models.AuditFilter) ([]models.AuditEntry, error) {
+	if filter.Limit <= 0 {
+		filter.Limit = 100
+	}
+	where, args := auditConditions(filter)
+	args = append(args, filter.Limit, filter.Offset)
+	query := fmt.Sprintf(`SELECT id, product_id, action, actor, COALESCE(request_id, ''), changes, created_at
+		FROM product_audit WHERE %s ORDER BY id DESC LIMIT $%d OFFSET $%d`, where, len(args)-1, len(args))
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []models.AuditEntry
+	for rows.Next() {
+		var (
+			e       models.AuditEntry
+			changes []byte
+		)
+		if err := rows.Scan(&e.ID, &e.ProductID, &e.Action, &e.Actor, &e.RequestID, &changes, &e.CreatedAt); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(changes, &e.Changes); err != nil {
+			return nil, fmt.Errorf("decode audit changes of entry %d: %w", e.ID, err)
+		}
+		entries = append(entries, e)
+	}
+	return entries, rows.Err()
+}
+
+func (r *auditRepo) Count(ctx context.Context, filter models.AuditFilter) (int, error) {
+	where, args := auditConditions(filter)
+	var n int
+	err := conn(ctx, r.db).QueryRowContext(ctx, `SELECT count(*) FROM product_audit WHERE `+where, args...).Scan(&n)
+	return n, err
+}
+
+func auditConditions(f models.AuditFilter) (string, []any) {
+	conds := []string{"TRUE"}
+	var args []any
+	add := func(cond string, arg any) {
+		args = append(args, arg)
+		conds = append(conds, fmt.Sprintf(cond, len(args)))
+	}
+	if f.ProductID > 0 {
+		add("product_id = $%d", f.ProductID)
+	}
+	if f.Actor != "" {
+		add("actor = $%d", f.Actor)
+	}
+	if f.Action != "" {
+		add("action = $%d", f.Action)
+	}
+	if !f.From.IsZero() {
+		add("created_at >= $%d", f.From)
+	}
+	if !f.To.IsZero() {
+		add("created_at < $%d", f.To)
+	}
+	return strings.Join(conds, " AND "), args
+}
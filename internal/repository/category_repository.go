@@ -0,0 +1,364 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"product-test/internal/models"
+
+	"github.com/lib/pq"
+)
+
+// ErrCategoryCycle is returned when a category would be moved below itself.
+var ErrCategoryCycle = errors.New("category cannot be moved into its own subtree")
+
+// ErrCategoryNotEmpty is returned when deleting a category with children.
+var ErrCategoryNotEmpty = errors.New("category has subcategories")
+
+// CategoryRepository keeps the parent_id adjacency list and the closure
+// table of the category tree in step; writes must run within a transaction.
+type CategoryRepository interface {
+	List(ctx context.Context) ([]models.Category, error)
+	GetByID(ctx context.Context, id int) (*models.Category, error)
+	// Path returns the ancestors of a category from the root down,
+	// ending with the category itself.
+	Path(ctx context.Context, id int) ([]models.CategoryRef, error)
+	Create(ctx context.Context, c *models.Category) error
+	Rename(ctx context.Context, id int, name string) error
+	// Move reattaches a category and its subtree below parentID, or at
+	// the root when parentID is nil.
+	Move(ctx context.Context, id int, parentID *int) error
+	Delete(ctx context.Context, id int) error
+
+	// AttributeSchema returns the schema set on the category itself, or
+	// nil when it has none.
+	AttributeSchema(ctx context.Context, id int) (json.RawMessage, error)
+	// SetAttributeSchema sets the schema of a category; nil removes it.
+	SetAttributeSchema(ctx context.Context, id int, schema json.RawMessage) error
+	// EffectiveSchema returns the schema of the category or of its nearest
+	// ancestor that has one, or nil.
+	EffectiveSchema(ctx context.Context, id int) (json.RawMessage, error)
+	// ProductSchema returns the effective schema of a product's primary
+	// category, or nil.
+	ProductSchema(ctx context.Context, productID int) (json.RawMessage, error)
+
+	GetProductCategories(ctx context.Context, productID int) ([]models.ProductCategory, error)
+	ReplaceProductCategories(ctx context.Context, productID int, categories []models.ProductCategory) error
+	// Breadcrumbs returns the path to the primary category of each
+	// product that has one.
+	Breadcrumbs(ctx context.Context, productIDs []int) (map[int][]models.CategoryRef, error)
+}
+
+type categoryRepo struct {
+	db *sql.DB
+}
+
+func NewCategoryRepository(db *sql.DB) CategoryRepository {
+	return &categoryRepo{db: db}
+}
+
+const categorySelect = `SELECT c.id, c.parent_id, c.name, c.created_at,
+	(SELECT max(depth) FROM category_closure WHERE descendant_id = c.id)
+	FROM categories c`
+
+func scanCategory(row interface{ Scan(dest ...any) error }, c *models.Category) error {
+	var parentID sql.NullInt64
+	err := row.Scan(&c.ID, &parentID, &c.Name, &c.CreatedAt, &c.Depth)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrNotFound
+	}
+	if parentID.Valid {
+		id := int(parentID.Int64)
+		c.ParentID = &id
+	}
+	return err
+}
+
+func (r *categoryRepo) List(ctx context.Context) ([]models.Category, error) {
+	rows, err := conn(ctx, r.db).QueryContext(ctx, categorySelect+` ORDER BY 5, lower(c.name), c.id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var categories []models.Category
+	for rows.Next() {
+		var c models.Category
+		if err := scanCategory(rows, &c); err != nil {
+			return nil, err
+		}
+		categories = append(categories, c)
+	}
+	return categories, rows.Err()
+}
+
+func (r *categoryRepo) GetByID(ctx context.Context, id int) (*models.Category, error) {
+	var c models.Category
+	row := conn(ctx, r.db).QueryRowContext(ctx, categorySelect+` WHERE c.id = $1`, id)
+	if err := scanCategory(row, &c); err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+func (r *categoryRepo) Path(ctx context.Context, id int) ([]models.CategoryRef, error) {
+	rows, err := conn(ctx, r.db).QueryContext(ctx, `
+		SELECT c.id, c.name FROM category_closure cc
+		JOIN categories c ON c.id = cc.ancestor_id
+		WHERE cc.descendant_id = $1
+		ORDER BY cc.depth DESC`, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var path []models.CategoryRef
+	for rows.Next() {
+		var ref models.CategoryRef
+		if err := rows.Scan(&ref.ID, &ref.Name); err != nil {
+			return nil, err
+		}
+		path = append(path, ref)
+	}
+	return path, rows.Err()
+}
+
+// lockTree serialises changes to the shape of the tree for the rest of the
+// transaction, so that two concurrent moves cannot together form a cycle
+// that neither would form alone.
+func lockTree(ctx context.Context, db DBTX) error {
+	_, err := db.ExecContext(ctx, `SELECT pg_advisory_xact_lock(hashtext('category_tree'))`)
+	return err
+}
+
+func (r *categoryRepo) Create(ctx context.Context, c *models.Category) error {
+	db := conn(ctx, r.db)
+	if err := lockTree(ctx, db); err != nil {
+		return err
+	}
+	err := db.QueryRowContext(ctx,
+		`INSERT INTO categories (parent_id, name) VALUES ($1, $2) RETURNING id, created_at`,
+		c.ParentID, c.Name).Scan(&c.ID, &c.CreatedAt)
+	if err != nil {
+		return uniqueViolation(err)
+	}
+	_, err = db.ExecContext(ctx, `
+		INSERT INTO category_closure (ancestor_id, descendant_id, depth)
+		SELECT ancestor_id, $1, depth + 1 FROM category_closure WHERE descendant_id = $2
+		UNION ALL SELECT $1, $1, 0`, c.ID, c.ParentID)
+	return err
+}
+
+func (r *categoryRepo) Rename(ctx context.Context, id int, name string) error {
+	res, err := conn(ctx, r.db).ExecContext(ctx, `UPDATE categories SET name = $1 WHERE id = $2`, name, id)
+	if err != nil {
+		return uniqueViolation(err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *categoryRepo) Move(ctx context.Context, id int, parentID *int) error {
+	db := conn(ctx, r.db)
+	if err := lockTree(ctx, db); err != nil {
+		return err
+	}
+	if parentID != nil {
+		var cycle bool
+		err := db.QueryRowContext(ctx,
+			`SELECT EXISTS (SELECT 1 FROM category_closure WHERE ancestor_id = $1 AND descendant_id = $2)`,
+			id, *parentID).Scan(&cycle)
+		if err != nil {
+			return err
+		}
+		if cycle {
+			return ErrCategoryCycle
+		}
+	}
+	res, err := db.ExecContext(ctx, `UPDATE categories SET parent_id = $1 WHERE id = $2`, parentID, id)
+	if err != nil {
+		return uniqueViolation(err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrNotFound
+	}
+	// Cut the subtree loose from its old ancestors, then link it to the
+	// new ones.
+	_, err = db.ExecContext(ctx, `
+		DELETE FROM category_closure
+		WHERE descendant_id IN (SELECT descendant_id FROM category_closure WHERE ancestor_id = $1)
+		AND ancestor_id NOT IN (SELECT descendant_id FROM category_closure WHERE ancestor_id = $1)`, id)
+	if err != nil {
+		return err
+	}
+	if parentID == nil {
+		return nil
+	}
+	_, err = db.ExecContext(ctx, `
+		INSERT INTO category_closure (ancestor_id, descendant_id, depth)
+		SELECT up.ancestor_id, down.descendant_id, up.depth + down.depth + 1
+		FROM category_closure up CROSS JOIN category_closure down
+		WHERE up.descendant_id = $1 AND down.ancestor_id = $2`, *parentID, id)
+	return err
+}
+
+// Delete removes a leaf category. Products that had it as their primary
+// category fall back to another of their categories.
+func (r *categoryRepo) Delete(ctx context.Context, id int) error {
+	db := conn(ctx, r.db)
+	if err := lockTree(ctx, db); err != nil {
+		return err
+	}
+	var children bool
+	if err := db.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM categories WHERE parent_id = $1)`, id).Scan(&children); err != nil {
+		return err
+	}
+	if children {
+		return ErrCategoryNotEmpty
+	}
+	var orphaned pq.Int64Array
+	err := db.QueryRowContext(ctx, `
+		WITH removed AS (
+			DELETE FROM product_categories WHERE category_id = $1 RETURNING product_id, is_primary
+		)
+		SELECT COALESCE(array_agg(product_id), '{}') FROM removed WHERE is_primary`, id).Scan(&orphaned)
+	if err != nil {
+		return err
+	}
+	res, err := db.ExecContext(ctx, `DELETE FROM categories WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrNotFound
+	}
+	if len(orphaned) == 0 {
+		return nil
+	}
+	_, err = db.ExecContext(ctx, `
+		UPDATE product_categories SET is_primary = true
+		WHERE (product_id, category_id) IN (
+			SELECT product_id, min(category_id) FROM product_categories
+			WHERE product_id = ANY($1) GROUP BY product_id
+		)`, orphaned)
+	return err
+}
+
+func (r *categoryRepo) AttributeSchema(ctx context.Context, id int) (json.RawMessage, error) {
+	var schema []byte
+	err := conn(ctx, r.db).QueryRowContext(ctx, `SELECT attribute_schema FROM categories WHERE id = $1`, id).Scan(&schema)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	return schema, err
+}
+
+func (r *categoryRepo) SetAttributeSchema(ctx context.Context, id int, schema json.RawMessage) error {
+	var value any
+	if schema != nil {
+		value = []byte(schema)
+	}
+	res, err := conn(ctx, r.db).ExecContext(ctx, `UPDATE categories SET attribute_schema = $1 WHERE id = $2`, value, id)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *categoryRepo) EffectiveSchema(ctx context.Context, id int) (json.RawMessage, error) {
+	return r.nearestSchema(ctx, `SELECT $1::int`, id)
+}
+
+func (r *categoryRepo) ProductSchema(ctx context.Context, productID int) (json.RawMessage, error) {
+	return r.nearestSchema(ctx, `SELECT category_id FROM product_categories WHERE product_id = $1 AND is_primary`, productID)
+}
+
+// nearestSchema finds the closest schema up the tree from the category
+// selected by the given query.
+func (r *categoryRepo) nearestSchema(ctx context.Context, category string, arg int) (json.RawMessage, error) {
+	var schema []byte
+	err := conn(ctx, r.db).QueryRowContext(ctx, `
+		SELECT c.attribute_schema FROM category_closure cc
+		JOIN categories c ON c.id = cc.ancestor_id
+		WHERE cc.descendant_id = (`+category+`) AND c.attribute_schema IS NOT NULL
+		ORDER BY cc.depth
+		LIMIT 1`, arg).Scan(&schema)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	return schema, err
+}
+
+func (r *categoryRepo) GetProductCategories(ctx context.Context, productID int) ([]models.ProductCategory, error) {
+	rows, err := conn(ctx, r.db).QueryContext(ctx, `
+		SELECT pc.category_id, c.name, pc.is_primary FROM product_categories pc
+		JOIN categories c ON c.id = pc.category_id
+		WHERE pc.product_id = $1
+		ORDER BY pc.is_primary DESC, lower(c.name), c.id`, productID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var categories []models.ProductCategory
+	for rows.Next() {
+		var c models.ProductCategory
+		if err := rows.Scan(&c.ID, &c.Name, &c.IsPrimary); err != nil {
+			return nil, err
+		}
+		categories = append(categories, c)
+	}
+	return categories, rows.Err()
+}
+
+func (r *categoryRepo) ReplaceProductCategories(ctx context.Context, productID int, categories []models.ProductCategory) error {
+	db := conn(ctx, r.db)
+	if _, err := db.ExecContext(ctx, `DELETE FROM product_categories WHERE product_id = $1`, productID); err != nil {
+		return err
+	}
+	for _, c := range categories {
+		_, err := db.ExecContext(ctx,
+			`INSERT INTO product_categories (product_id, category_id, is_primary) VALUES ($1, $2, $3)`,
+			productID, c.ID, c.IsPrimary)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *categoryRepo) Breadcrumbs(ctx context.Context, productIDs []int) (map[int][]models.CategoryRef, error) {
+	ids := make(pq.Int64Array, len(productIDs))
+	for i, id := range productIDs {
+		ids[i] = int64(id)
+	}
+	rows, err := conn(ctx, r.db).QueryContext(ctx, `
+		SELECT pc.product_id, c.id, c.name FROM product_categories pc
+		JOIN category_closure cc ON cc.descendant_id = pc.category_id
+		JOIN categories c ON c.id = cc.ancestor_id
+		WHERE pc.product_id = ANY($1) AND pc.is_primary
+		ORDER BY pc.product_id, cc.depth DESC`, ids)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	crumbs := make(map[int][]models.CategoryRef)
+	for rows.Next() {
+		var (
+			productID int
+			ref       models.CategoryRef
+		)
+		if err := rows.Scan(&productID, &ref.ID, &ref.Name); err != nil {
+			return nil, err
+		}
+		crumbs[productID] = append(crumbs[productID], ref)
+	}
+	return crumbs, rows.Err()
+}
@@ -0,0 +1,134 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"product-test/internal/models"
+	"strings"
+)
+
+type ExchangeRepository interface {
+	ListRates(ctx context.Context) ([]models.ExchangeRate, error)
+	UpsertRate(ctx context.Context, rate *models.ExchangeRate) error
+	DeleteRate(ctx context.Context, base, quote string) error
+	ListRounding(ctx context.Context) ([]models.RoundingRule, error)
+	GetRounding(ctx context.Context, currency string) (*models.RoundingRule, error)
+	UpsertRounding(ctx context.Context, rule *models.RoundingRule) error
+	DeleteRounding(ctx context.Context, currency string) error
+}
+
+type exchangeRepo struct {
+	db *sql.DB
+}
+
+func NewExchangeRepository(db *sql.DB) ExchangeRepository {
+	return &exchangeRepo{db: db}
+}
+
+func (r *exchangeRepo) ListRates(ctx context.Context) ([]models.ExchangeRate, error) {
+	rows, err := conn(ctx, r.db).QueryContext(ctx,
+		`SELECT base, quote, rate::text, source, updated_at FROM exchange_rates ORDER BY base, quote`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var rates []models.ExchangeRate
+	for rows.Next() {
+		var rate models.ExchangeRate
+		if err := rows.Scan(&rate.Base, &rate.Quote, &rate.Rate, &rate.Source, &rate.UpdatedAt); err != nil {
+			return nil, err
+		}
+		rate.Rate = trimDecimal(rate.Rate)
+		rates = append(rates, rate)
+	}
+	return rates, rows.Err()
+}
+
+// UpsertRate stores a rate, replacing the previous rate of the pair.
+func (r *exchangeRepo) UpsertRate(ctx context.Context, rate *models.ExchangeRate) error {
+	query := `INSERT INTO exchange_rates (base, quote, rate, source, updated_at)
+		VALUES ($1, $2, $3::numeric, $4, $5)
+		ON CONFLICT (base, quote) DO UPDATE
+		SET rate = EXCLUDED.rate, source = EXCLUDED.source, updated_at = EXCLUDED.updated_at`
+	_, err := conn(ctx, r.db).ExecContext(ctx, query, rate.Base, rate.Quote, rate.Rate, rate.Source, rate.UpdatedAt)
+	return err
+}
+
+func (r *exchangeRepo) DeleteRate(ctx context.Context, base, quote string) error {
+	res, err := conn(ctx, r.db).ExecContext(ctx, `DELETE FROM exchange_rates WHERE base = $1 AND quote = $2`, base, quote)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *exchangeRepo) ListRounding(ctx context.Context) ([]models.RoundingRule, error) {
+	rows, err := conn(ctx, r.db).QueryContext(ctx,
+		`SELECT currency, mode, increment, updated_at FROM currency_rounding ORDER BY currency`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var rules []models.RoundingRule
+	for rows.Next() {
+		var rule models.RoundingRule
+		if err := scanRounding(rows, &rule); err != nil {
+			return nil, err
+		}
+		rules = append(rules, rule)
+	}
+	return rules, rows.Err()
+}
+
+func (r *exchangeRepo) GetRounding(ctx context.Context, currency string) (*models.RoundingRule, error) {
+	var rule models.RoundingRule
+	row := conn(ctx, r.db).QueryRowContext(ctx,
+		`SELECT currency, mode, increment, updated_at FROM currency_rounding WHERE currency = $1`, currency)
+	if err := scanRounding(row, &rule); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return &rule, nil
+}
+
+func (r *exchangeRepo) UpsertRounding(ctx context.Context, rule *models.RoundingRule) error {
+	query := `INSERT INTO currency_rounding (currency, mode, increment) VALUES ($1, $2, $3)
+		ON CONFLICT (currency) DO UPDATE SET mode = EXCLUDED.mode, increment = EXCLUDED.increment, updated_at = now()
+		RETURNING updated_at`
+	return conn(ctx, r.db).QueryRowContext(ctx, query, rule.Currency, rule.Mode, rule.Increment.Amount).Scan(&rule.UpdatedAt)
+}
+
+func (r *exchangeRepo) DeleteRounding(ctx context.Context, currency string) error {
+	res, err := conn(ctx, r.db).ExecContext(ctx, `DELETE FROM currency_rounding WHERE currency = $1`, currency)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func scanRounding(row interface{ Scan(dest ...any) error }, rule *models.RoundingRule) error {
+	if err := row.Scan(&rule.Currency, &rule.Mode, &rule.Increment.Amount, &rule.UpdatedAt); err != nil {
+		return err
+	}
+	rule.Increment.Currency = rule.Currency
+	return nil
+}
+
+// trimDecimal drops the padding zeros of a fixed-scale NUMERIC.
+func trimDecimal(s string) string {
+	if !strings.Contains(s, ".") {
+		return s
+	}
+	return strings.TrimSuffix(strings.TrimRight(s, "0"), ".")
+}
@@ -0,0 +1,432 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"product-test/internal/models"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+type InventoryRepository interface {
+	Stock(ctx context.Context, key models.StockKey) (*models.Stock, error)
+	// Lock locks the inventory row of a product or variant, creating it if
+	// needed, and returns the stock as of that moment. Movements must only
+	// be appended under this lock, inside the same transaction.
+	Lock(ctx context.Context, key models.StockKey) (*models.Stock, error)
+	SetSettings(ctx context.Context, key models.StockKey, settings models.StockSettings) error
+	// SetCostPrice changes the cost price of a product or variant whose
+	// inventory row is locked.
+	SetCostPrice(ctx context.Context, key models.StockKey, cost models.Money) error
+	Append(ctx context.Context, m *models.StockMovement) error
+	Movements(ctx context.Context, filter models.MovementFilter) ([]models.StockMovement, error)
+	CountMovements(ctx context.Context, filter models.MovementFilter) (int, error)
+	// Availability returns the stock of a product or variant on hand in and
+	// in transit to each warehouse, or to one when warehouseID is not zero.
+	Availability(ctx context.Context, key models.StockKey, warehouseID int) ([]models.WarehouseAvailability, error)
+	// Available returns the stock available to promise of each product and
+	// its variants together, across all warehouses.
+	Available(ctx context.Context, productIDs []int) (map[int]int64, error)
+	// Lots returns every lot a product or variant ever had in each
+	// warehouse, with its balance, by expiry date with lots that do not
+	// expire last.
+	Lots(ctx context.Context, key models.StockKey) ([]models.Lot, error)
+	// ExpiringLots returns the lots of live products and variants with
+	// stock that expire on or before the given date, in one warehouse when
+	// warehouseID is not zero, by expiry date.
+	ExpiringLots(ctx context.Context, before time.Time, warehouseID int) ([]models.Lot, error)
+	// ByReference returns the movements with a reference in ledger order.
+	ByReference(ctx context.Context, reference string) ([]models.StockMovement, error)
+	// ReorderCandidates returns the live products and variants that have a
+	// reorder point or sales since the given time, with their stock level,
+	// reorder settings and net sales since then. Nothing is suggested yet.
+	ReorderCandidates(ctx context.Context, since time.Time) ([]models.ReorderSuggestion, error)
+}
+
+// activeReserved sums the stock held by active reservations of the product
+// in $1 and variant in $2 at warehouse w.
+const activeReserved = `
+	SELECT sum(l.quantity) FROM reservation_lines l
+	JOIN reservations r ON r.id = l.reservation_id
+	WHERE l.product_id = $1 AND l.variant_id = $2 AND r.warehouse_id = w.id AND r.status = 'active' AND r.expires_at > now()`
+
+// expiredStock sums the stock of the product in $1 and variant in $2 at
+// warehouse w in lots past their expiry date.
+const expiredStock = `
+	SELECT sum(balance) FROM (
+		SELECT sum(quantity) AS balance FROM stock_movement_lots
+		WHERE product_id = $1 AND variant_id = $2 AND warehouse_id = w.id AND expires_on < current_date
+		GROUP BY lot
+	) b WHERE balance > 0`
+
+// lotExpiry formats the expiry date of a lot as text.
+const lotExpiry = `COALESCE(to_char(expires_on, 'YYYY-MM-DD'), '')`
+
+type inventoryRepo struct {
+	db *sql.DB
+}
+
+func NewInventoryRepository(db *sql.DB) InventoryRepository {
+	return &inventoryRepo{db: db}
+}
+
+// Stock reads the settings of a product or variant, the balance of its
+// latest movement in each warehouse, what of it has expired and what
+// active reservations hold there; an item without any has no stock.
+func (r *inventoryRepo) Stock(ctx context.Context, key models.StockKey) (*models.Stock, error) {
+	db := conn(ctx, r.db)
+	s := &models.Stock{ProductID: key.ProductID, VariantID: key.VariantID, Warehouses: []models.WarehouseStock{}}
+	var (
+		cost     sql.NullInt64
+		currency sql.NullString
+	)
+	err := db.QueryRowContext(ctx, `
+		SELECT COALESCE(i.allow_backorder, false), i.reorder_point, i.reorder_quantity, i.cost_price, i.cost_currency
+		FROM (SELECT $1::int AS product_id, $2::int AS variant_id) k
+		LEFT JOIN inventory_items i ON i.product_id = k.product_id AND i.variant_id = k.variant_id`,
+		key.ProductID, key.VariantID).Scan(&s.AllowBackorder, &s.ReorderPoint, &s.ReorderQuantity, &cost, &currency)
+	if err != nil {
+		return nil, err
+	}
+	if cost.Valid {
+		s.CostPrice = &models.Money{Amount: cost.Int64, Currency: currency.String}
+	}
+	rows, err := db.QueryContext(ctx, `
+		SELECT w.id, w.code, COALESCE(m.balance_after, 0), COALESCE((`+expiredStock+`), 0), COALESCE(h.reserved, 0), m.created_at
+		FROM warehouses w
+		LEFT JOIN LATERAL (
+			SELECT balance_after, created_at FROM stock_movements
+			WHERE product_id = $1 AND variant_id = $2 AND warehouse_id = w.id ORDER BY id DESC LIMIT 1
+		) m ON true
+		LEFT JOIN LATERAL (`+activeReserved+`) h(reserved) ON true
+		WHERE m.balance_after IS NOT NULL OR h.reserved IS NOT NULL
+		ORDER BY w.id`, key.ProductID, key.VariantID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			ws models.WarehouseStock
+			at sql.NullTime
+		)
+		if err := rows.Scan(&ws.WarehouseID, &ws.Code, &ws.OnHand, &ws.Expired, &ws.Reserved, &at); err != nil {
+			return nil, err
+		}
+		ws.Available = ws.OnHand - ws.Expired - ws.Reserved
+		s.OnHand += ws.OnHand
+		s.Expired += ws.Expired
+		s.Reserved += ws.Reserved
+		if at.Valid && (s.UpdatedAt == nil || at.Time.After(*s.UpdatedAt)) {
+			s.UpdatedAt = &at.Time
+		}
+		s.Warehouses = append(s.Warehouses, ws)
+	}
+	s.Available = s.OnHand - s.Expired - s.Reserved
+	return s, rows.Err()
+}
+
+func (r *inventoryRepo) Lock(ctx context.Context, key models.StockKey) (*models.Stock, error) {
+	db := conn(ctx, r.db)
+	// A purged product has no inventory row to lock; transfers that were
+	// in transit can still post its movements.
+	_, err := db.ExecContext(ctx, `
+		INSERT INTO inventory_items (product_id, variant_id)
+		SELECT id, $2 FROM products WHERE id = $1
+		ON CONFLICT DO NOTHING`, key.ProductID, key.VariantID)
+	if err != nil {
+		return nil, err
+	}
+	_, err = db.ExecContext(ctx, `SELECT 1 FROM inventory_items WHERE product_id = $1 AND variant_id = $2 FOR UPDATE`,
+		key.ProductID, key.VariantID)
+	if err != nil {
+		return nil, err
+	}
+	return r.Stock(ctx, key)
+}
+
+func (r *inventoryRepo) SetSettings(ctx context.Context, key models.StockKey, settings models.StockSettings) error {
+	_, err := conn(ctx, r.db).ExecContext(ctx, `
+		INSERT INTO inventory_items (product_id, variant_id, allow_backorder, reorder_point, reorder_quantity)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (product_id, variant_id) DO UPDATE SET allow_backorder = EXCLUDED.allow_backorder,
+			reorder_point = EXCLUDED.reorder_point, reorder_quantity = EXCLUDED.reorder_quantity`,
+		key.ProductID, key.VariantID, settings.AllowBackorder, settings.ReorderPoint, settings.ReorderQuantity)
+	return err
+}
+
+func (r *inventoryRepo) SetCostPrice(ctx context.Context, key models.StockKey, cost models.Money) error {
+	_, err := conn(ctx, r.db).ExecContext(ctx,
+		`UPDATE inventory_items SET cost_price = $3, cost_currency = $4 WHERE product_id = $1 AND variant_id = $2`,
+		key.ProductID, key.VariantID, cost.Amount, cost.Currency)
+	return err
+}
+
+func (r *inventoryRepo) Append(ctx context.Context, m *models.StockMovement) error {
+	db := conn(ctx, r.db)
+	query := `INSERT INTO stock_movements (product_id, variant_id, warehouse_id, type, quantity, balance_after, reference, note, actor, request_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, NULLIF($10, '')) RETURNING id, created_at`
+	err := db.QueryRowContext(ctx, query,
+		m.ProductID, m.VariantID, m.WarehouseID, m.Type, m.Quantity, m.BalanceAfter, m.Reference, m.Note, m.Actor, m.RequestID).
+		Scan(&m.ID, &m.CreatedAt)
+	if err != nil {
+		return err
+	}
+	for _, l := range m.Lots {
+		_, err := db.ExecContext(ctx, `
+			INSERT INTO stock_movement_lots (movement_id, product_id, variant_id, warehouse_id, lot, expires_on, quantity)
+			VALUES ($1, $2, $3, $4, $5, NULLIF($6, '')::date, $7)`,
+			m.ID, m.ProductID, m.VariantID, m.WarehouseID, l.Lot, l.ExpiresOn, l.Quantity)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// attachLots reads the lots of movements.
+func (r *inventoryRepo) attachLots(ctx context.Context, movements []models.StockMovement) error {
+	if len(movements) == 0 {
+		return nil
+	}
+	ids := make(pq.Int64Array, len(movements))
+	index := make(map[int64]int, len(movements))
+	for i, m := range movements {
+		ids[i] = m.ID
+		index[m.ID] = i
+	}
+	rows, err := conn(ctx, r.db).QueryContext(ctx, `
+		SELECT movement_id, lot, `+lotExpiry+`, quantity FROM stock_movement_lots
+		WHERE movement_id = ANY($1) ORDER BY id`, ids)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			id int64
+			l  models.LotQuantity
+		)
+		if err := rows.Scan(&id, &l.Lot, &l.ExpiresOn, &l.Quantity); err != nil {
+			return err
+		}
+		m := &movements[index[id]]
+		m.Lots = append(m.Lots, l)
+	}
+	return rows.Err()
+}
+
+func (r *inventoryRepo) Movements(ctx context.Context, filter models.MovementFilter) ([]models.StockMovement, error) {
+	if filter.Limit <= 0 {
+		filter.Limit = 100
+	}
+	where, args := movementConditions(filter)
+	args = append(args, filter.Limit, filter.Offset)
+	query := fmt.Sprintf(`SELECT `+movementColumns+` FROM stock_movements WHERE %s ORDER BY id DESC LIMIT $%d OFFSET $%d`,
+		where, len(args)-1, len(args))
+	return r.movements(ctx, query, args...)
+}
+
+func (r *inventoryRepo) ByReference(ctx context.Context, reference string) ([]models.StockMovement, error) {
+	return r.movements(ctx, `SELECT `+movementColumns+` FROM stock_movements WHERE reference = $1 ORDER BY id`, reference)
+}
+
+const movementColumns = `id, product_id, variant_id, warehouse_id, type, quantity, balance_after, reference, note, actor, COALESCE(request_id, ''), created_at`
+
+func (r *inventoryRepo) movements(ctx context.Context, query string, args ...any) ([]models.StockMovement, error) {
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var movements []models.StockMovement
+	for rows.Next() {
+		var m models.StockMovement
+		err := rows.Scan(&m.ID, &m.ProductID, &m.VariantID, &m.WarehouseID, &m.Type, &m.Quantity, &m.BalanceAfter, &m.Reference, &m.Note, &m.Actor, &m.RequestID, &m.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		movements = append(movements, m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	rows.Close()
+	if err := r.attachLots(ctx, movements); err != nil {
+		return nil, err
+	}
+	return movements, nil
+}
+
+func (r *inventoryRepo) CountMovements(ctx context.Context, filter models.MovementFilter) (int, error) {
+	where, args := movementConditions(filter)
+	var n int
+	err := conn(ctx, r.db).QueryRowContext(ctx, `SELECT count(*) FROM stock_movements WHERE `+where, args...).Scan(&n)
+	return n, err
+}
+
+func movementConditions(f models.MovementFilter) (string, []any) {
+	conds := []string{"TRUE"}
+	var args []any
+	add := func(cond string, arg any) {
+		args = append(args, arg)
+		conds = append(conds, fmt.Sprintf(cond, len(args)))
+	}
+	if f.ProductID != 0 {
+		add("product_id = $%d", f.ProductID)
+	}
+	if f.VariantID != 0 {
+		add("variant_id = $%d", f.VariantID)
+	}
+	if f.WarehouseID != 0 {
+		add("warehouse_id = $%d", f.WarehouseID)
+	}
+	if f.Type != "" {
+		add("type = $%d", f.Type)
+	}
+	return strings.Join(conds, " AND "), args
+}
+
+func (r *inventoryRepo) Availability(ctx context.Context, key models.StockKey, warehouseID int) ([]models.WarehouseAvailability, error) {
+	rows, err := conn(ctx, r.db).QueryContext(ctx, `
+		SELECT w.id, w.code, w.name,
+			COALESCE((
+				SELECT balance_after FROM stock_movements
+				WHERE product_id = $1 AND variant_id = $2 AND warehouse_id = w.id ORDER BY id DESC LIMIT 1
+			), 0),
+			COALESCE((`+expiredStock+`), 0),
+			COALESCE((`+activeReserved+`), 0),
+			COALESCE((
+				SELECT sum(l.quantity) FROM stock_transfer_lines l
+				JOIN stock_transfers t ON t.id = l.transfer_id
+				WHERE l.product_id = $1 AND l.variant_id = $2 AND t.destination_warehouse_id = w.id AND t.status = 'in_transit'
+			), 0)
+		FROM warehouses w
+		WHERE $3 = 0 OR w.id = $3
+		ORDER BY w.id`, key.ProductID, key.VariantID, warehouseID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var warehouses []models.WarehouseAvailability
+	for rows.Next() {
+		var a models.WarehouseAvailability
+		if err := rows.Scan(&a.WarehouseID, &a.Code, &a.Name, &a.OnHand, &a.Expired, &a.Reserved, &a.Incoming); err != nil {
+			return nil, err
+		}
+		a.Available = a.OnHand - a.Expired - a.Reserved
+		warehouses = append(warehouses, a)
+	}
+	return warehouses, rows.Err()
+}
+
+func (r *inventoryRepo) Available(ctx context.Context, productIDs []int) (map[int]int64, error) {
+	ids := make(pq.Int64Array, len(productIDs))
+	for i, id := range productIDs {
+		ids[i] = int64(id)
+	}
+	rows, err := conn(ctx, r.db).QueryContext(ctx, `
+		SELECT product_id, sum(on_hand - expired - reserved) FROM inventory_positions
+		WHERE product_id = ANY($1::int[])
+		GROUP BY product_id`, ids)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	available := make(map[int]int64, len(productIDs))
+	for rows.Next() {
+		var (
+			id int
+			n  int64
+		)
+		if err := rows.Scan(&id, &n); err != nil {
+			return nil, err
+		}
+		available[id] = n
+	}
+	return available, rows.Err()
+}
+
+func (r *inventoryRepo) ReorderCandidates(ctx context.Context, since time.Time) ([]models.ReorderSuggestion, error) {
+	rows, err := conn(ctx, r.db).QueryContext(ctx, `
+		SELECT p.id, pos.variant_id, p.name, COALESCE(v.sku, p.sku, ''), pos.on_hand - pos.expired - pos.reserved + pos.in_transit,
+			i.reorder_point, i.reorder_quantity, COALESCE(s.net_sales, 0)
+		FROM inventory_positions pos
+		JOIN products p ON p.id = pos.product_id
+		LEFT JOIN product_variants v ON v.id = pos.variant_id
+		LEFT JOIN inventory_items i ON i.product_id = pos.product_id AND i.variant_id = pos.variant_id
+		LEFT JOIN (
+			SELECT product_id, variant_id, -sum(quantity) AS net_sales FROM stock_movements
+			WHERE type IN ('sale', 'return') AND created_at >= $1
+			GROUP BY product_id, variant_id
+		) s ON s.product_id = pos.product_id AND s.variant_id = pos.variant_id
+		WHERE i.reorder_point IS NOT NULL OR s.product_id IS NOT NULL
+		ORDER BY p.id, pos.variant_id`, since)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var candidates []models.ReorderSuggestion
+	for rows.Next() {
+		var c models.ReorderSuggestion
+		err := rows.Scan(&c.ProductID, &c.VariantID, &c.Name, &c.SKU, &c.StockLevel, &c.ReorderPoint, &c.ReorderQuantity, &c.NetSales)
+		if err != nil {
+			return nil, err
+		}
+		candidates = append(candidates, c)
+	}
+	return candidates, rows.Err()
+}
+
+// lotBalances sums the movements of each lot of a product or variant in a
+// warehouse.
+const lotBalances = `
+	SELECT product_id, variant_id, warehouse_id, lot, expires_on, sum(quantity) AS quantity
+	FROM stock_movement_lots GROUP BY product_id, variant_id, warehouse_id, lot, expires_on`
+
+func (r *inventoryRepo) Lots(ctx context.Context, key models.StockKey) ([]models.Lot, error) {
+	return r.lots(ctx, `
+		SELECT b.product_id, b.variant_id, '', '', b.warehouse_id, '', b.lot, `+lotExpiry+`, b.quantity, COALESCE(expires_on < current_date, false)
+		FROM (`+lotBalances+`) b
+		WHERE b.product_id = $1 AND b.variant_id = $2
+		ORDER BY b.expires_on NULLS LAST, b.lot, b.warehouse_id`, key.ProductID, key.VariantID)
+}
+
+func (r *inventoryRepo) ExpiringLots(ctx context.Context, before time.Time, warehouseID int) ([]models.Lot, error) {
+	return r.lots(ctx, `
+		SELECT b.product_id, b.variant_id, p.name, COALESCE(v.sku, p.sku, ''), b.warehouse_id, w.code, b.lot, `+lotExpiry+`, b.quantity, expires_on < current_date
+		FROM (`+lotBalances+`) b
+		JOIN products p ON p.id = b.product_id AND p.deleted_at IS NULL
+		LEFT JOIN product_variants v ON v.id = b.variant_id
+		JOIN warehouses w ON w.id = b.warehouse_id
+		WHERE b.quantity > 0 AND b.expires_on <= $1::date AND ($2 = 0 OR b.warehouse_id = $2)
+		ORDER BY b.expires_on, b.product_id, b.variant_id, b.lot, b.warehouse_id`, before.Format(time.DateOnly), warehouseID)
+}
+
+func (r *inventoryRepo) lots(ctx context.Context, query string, args ...any) ([]models.Lot, error) {
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var lots []models.Lot
+	for rows.Next() {
+		var l models.Lot
+		err := rows.Scan(&l.ProductID, &l.VariantID, &l.ProductName, &l.SKU, &l.WarehouseID, &l.WarehouseCode, &l.Lot, &l.ExpiresOn, &l.Quantity, &l.Expired)
+		if err != nil {
+			return nil, err
+		}
+		lots = append(lots, l)
+	}
+	return lots, rows.Err()
+}
@@ -0,0 +1,199 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"product-test/internal/models"
+	"strings"
+
+	"github.com/lib/pq"
+)
+
+type OrderRepository interface {
+	GetByID(ctx context.Context, id int64) (*models.Order, error)
+	// Lock reads an order and locks it until the end of the transaction.
+	Lock(ctx context.Context, id int64) (*models.Order, error)
+	List(ctx context.Context, filter models.OrderFilter) ([]models.Order, error)
+	Count(ctx context.Context, filter models.OrderFilter) (int, error)
+	Create(ctx context.Context, o *models.Order) error
+	// SetStatus moves an order to a status, stamping when it got there.
+	SetStatus(ctx context.Context, o *models.Order, status models.OrderStatus) error
+}
+
+type orderRepo struct {
+	db *sql.DB
+}
+
+func NewOrderRepository(db *sql.DB) OrderRepository {
+	return &orderRepo{db: db}
+}
+
+const orderSelect = `SELECT id, warehouse_id, status, currency, customer, note, actor, created_at, updated_at,
+		paid_at, fulfilled_at, delivered_at, cancelled_at, refunded_at
+	FROM orders`
+
+func scanOrder(row rowScanner) (*models.Order, error) {
+	var o models.Order
+	err := row.Scan(&o.ID, &o.WarehouseID, &o.Status, &o.Currency, &o.Customer, &o.Note, &o.Actor, &o.CreatedAt, &o.UpdatedAt,
+		&o.PaidAt, &o.FulfilledAt, &o.DeliveredAt, &o.CancelledAt, &o.RefundedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &o, nil
+}
+
+func (r *orderRepo) get(ctx context.Context, id int64, lock string) (*models.Order, error) {
+	o, err := scanOrder(conn(ctx, r.db).QueryRowContext(ctx, orderSelect+` WHERE id = $1`+lock, id))
+	if err != nil {
+		return nil, err
+	}
+	if err := r.loadLines(ctx, []*models.Order{o}); err != nil {
+		return nil, err
+	}
+	return o, nil
+}
+
+func (r *orderRepo) GetByID(ctx context.Context, id int64) (*models.Order, error) {
+	return r.get(ctx, id, "")
+}
+
+func (r *orderRepo) Lock(ctx context.Context, id int64) (*models.Order, error) {
+	return r.get(ctx, id, " FOR UPDATE")
+}
+
+// loadLines fills in the lines of orders, ordered by product, and their
+// totals.
+func (r *orderRepo) loadLines(ctx context.Context, orders []*models.Order) error {
+	if len(orders) == 0 {
+		return nil
+	}
+	byID := make(map[int64]*models.Order, len(orders))
+	ids := make(pq.Int64Array, 0, len(orders))
+	for _, o := range orders {
+		byID[o.ID] = o
+		o.Lines = []models.OrderLine{}
+		o.Total = models.Money{Currency: o.Currency}
+		ids = append(ids, o.ID)
+	}
+	rows, err := conn(ctx, r.db).QueryContext(ctx, `
+		SELECT order_id, product_id, variant_id, name, sku, quantity, unit_price FROM order_lines
+		WHERE order_id = ANY($1) ORDER BY order_id, product_id, variant_id`, ids)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			id int64
+			l  models.OrderLine
+		)
+		if err := rows.Scan(&id, &l.ProductID, &l.VariantID, &l.Name, &l.SKU, &l.Quantity, &l.UnitPrice.Amount); err != nil {
+			return err
+		}
+		o := byID[id]
+		l.UnitPrice.Currency = o.Currency
+		l.Total = models.Money{Amount: l.Quantity * l.UnitPrice.Amount, Currency: o.Currency}
+		o.Total.Amount += l.Total.Amount
+		o.Lines = append(o.Lines, l)
+	}
+	return rows.Err()
+}
+
+func (r *orderRepo) List(ctx context.Context, filter models.OrderFilter) ([]models.Order, error) {
+	if filter.Limit <= 0 {
+		filter.Limit = 100
+	}
+	where, args := orderConditions(filter)
+	args = append(args, filter.Limit, filter.Offset)
+	query := fmt.Sprintf(orderSelect+` WHERE %s ORDER BY id DESC LIMIT $%d OFFSET $%d`, where, len(args)-1, len(args))
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var orders []*models.Order
+	for rows.Next() {
+		o, err := scanOrder(rows)
+		if err != nil {
+			return nil, err
+		}
+		orders = append(orders, o)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	rows.Close()
+	if err := r.loadLines(ctx, orders); err != nil {
+		return nil, err
+	}
+	list := make([]models.Order, len(orders))
+	for i, o := range orders {
+		list[i] = *o
+	}
+	return list, nil
+}
+
+func (r *orderRepo) Count(ctx context.Context, filter models.OrderFilter) (int, error) {
+	where, args := orderConditions(filter)
+	var n int
+	err := conn(ctx, r.db).QueryRowContext(ctx, `SELECT count(*) FROM orders WHERE `+where, args...).Scan(&n)
+	return n, err
+}
+
+func orderConditions(f models.OrderFilter) (string, []any) {
+	conds := []string{"TRUE"}
+	var args []any
+	if f.Status != "" {
+		args = append(args, f.Status)
+		conds = append(conds, fmt.Sprintf("status = $%d", len(args)))
+	}
+	return strings.Join(conds, " AND "), args
+}
+
+func (r *orderRepo) Create(ctx context.Context, o *models.Order) error {
+	db := conn(ctx, r.db)
+	err := db.QueryRowContext(ctx, `
+		INSERT INTO orders (warehouse_id, status, currency, customer, note, actor)
+		VALUES ($1, $2, $3, $4, $5, $6) RETURNING id, created_at, updated_at`,
+		o.WarehouseID, o.Status, o.Currency, o.Customer, o.Note, o.Actor).Scan(&o.ID, &o.CreatedAt, &o.UpdatedAt)
+	if err != nil {
+		return err
+	}
+	for _, l := range o.Lines {
+		_, err := db.ExecContext(ctx, `
+			INSERT INTO order_lines (order_id, product_id, variant_id, name, sku, quantity, unit_price)
+			VALUES ($1, $2, $3, $4, $5, $6, $7)`,
+			o.ID, l.ProductID, l.VariantID, l.Name, l.SKU, l.Quantity, l.UnitPrice.Amount)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *orderRepo) SetStatus(ctx context.Context, o *models.Order, status models.OrderStatus) error {
+	err := conn(ctx, r.db).QueryRowContext(ctx, `
+		UPDATE orders SET status = $2, updated_at = now(),
+			paid_at = CASE WHEN $2 = 'paid' THEN now() ELSE paid_at END,
+			fulfilled_at = CASE WHEN $2 = 'fulfilled' THEN now() ELSE fulfilled_at END,
+			delivered_at = CASE WHEN $2 = 'delivered' THEN now() ELSE delivered_at END,
+			cancelled_at = CASE WHEN $2 = 'cancelled' THEN now() ELSE cancelled_at END,
+			refunded_at = CASE WHEN $2 = 'refunded' THEN now() ELSE refunded_at END
+		WHERE id = $1 RETURNING updated_at, paid_at, fulfilled_at, delivered_at, cancelled_at, refunded_at`,
+		o.ID, status).Scan(&o.UpdatedAt, &o.PaidAt, &o.FulfilledAt, &o.DeliveredAt, &o.CancelledAt, &o.RefundedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrNotFound
+	}
+	if err != nil {
+		return err
+	}
+	o.Status = status
+	return nil
+}
@@ -0,0 +1,225 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"product-test/internal/models"
+)
+
+type PriceListRepository interface {
+	ListPriceLists(ctx context.Context) ([]models.PriceList, error)
+	GetPriceList(ctx context.Context, id int) (*models.PriceList, error)
+	GetPriceListByCode(ctx context.Context, code string) (*models.PriceList, error)
+	CreatePriceList(ctx context.Context, l *models.PriceList) error
+	UpdatePriceList(ctx context.Context, l *models.PriceList) error
+	DeletePriceList(ctx context.Context, id int) error
+
+	GetTiers(ctx context.Context, listID, productID int) ([]models.PriceTier, error)
+	ReplaceTiers(ctx context.Context, listID, productID int, tiers []models.PriceTier) error
+	// FindTier returns the tier with the largest break not above qty.
+	FindTier(ctx context.Context, listID, productID, qty int) (*models.PriceTier, error)
+
+	ListGroups(ctx context.Context) ([]models.CustomerGroup, error)
+	GetGroup(ctx context.Context, id int) (*models.CustomerGroup, error)
+	GetGroupByCode(ctx context.Context, code string) (*models.CustomerGroup, error)
+	CreateGroup(ctx context.Context, g *models.CustomerGroup) error
+	UpdateGroup(ctx context.Context, g *models.CustomerGroup) error
+	DeleteGroup(ctx context.Context, id int) error
+}
+
+type priceListRepo struct {
+	db *sql.DB
+}
+
+func NewPriceListRepository(db *sql.DB) PriceListRepository {
+	return &priceListRepo{db: db}
+}
+
+const priceListColumns = `id, code, name, currency, created_at`
+
+func scanPriceList(row interface{ Scan(dest ...any) error }, l *models.PriceList) error {
+	err := row.Scan(&l.ID, &l.Code, &l.Name, &l.Currency, &l.CreatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrNotFound
+	}
+	return err
+}
+
+func (r *priceListRepo) ListPriceLists(ctx context.Context) ([]models.PriceList, error) {
+	rows, err := conn(ctx, r.db).QueryContext(ctx, `SELECT `+priceListColumns+` FROM price_lists ORDER BY code`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var lists []models.PriceList
+	for rows.Next() {
+		var l models.PriceList
+		if err := scanPriceList(rows, &l); err != nil {
+			return nil, err
+		}
+		lists = append(lists, l)
+	}
+	return lists, rows.Err()
+}
+
+func (r *priceListRepo) GetPriceList(ctx context.Context, id int) (*models.PriceList, error) {
+	var l models.PriceList
+	row := conn(ctx, r.db).QueryRowContext(ctx, `SELECT `+priceListColumns+` FROM price_lists WHERE id = $1`, id)
+	if err := scanPriceList(row, &l); err != nil {
+		return nil, err
+	}
+	return &l, nil
+}
+
+func (r *priceListRepo) GetPriceListByCode(ctx context.Context, code string) (*models.PriceList, error) {
+	var l models.PriceList
+	row := conn(ctx, r.db).QueryRowContext(ctx, `SELECT `+priceListColumns+` FROM price_lists WHERE code = $1`, code)
+	if err := scanPriceList(row, &l); err != nil {
+		return nil, err
+	}
+	return &l, nil
+}
+
+func (r *priceListRepo) CreatePriceList(ctx context.Context, l *models.PriceList) error {
+	query := `INSERT INTO price_lists (code, name, currency) VALUES ($1, $2, $3) RETURNING id, created_at`
+	err := conn(ctx, r.db).QueryRowContext(ctx, query, l.Code, l.Name, l.Currency).Scan(&l.ID, &l.CreatedAt)
+	return uniqueViolation(err)
+}
+
+func (r *priceListRepo) UpdatePriceList(ctx context.Context, l *models.PriceList) error {
+	query := `UPDATE price_lists SET code = $1, name = $2 WHERE id = $3 RETURNING ` + priceListColumns
+	err := scanPriceList(conn(ctx, r.db).QueryRowContext(ctx, query, l.Code, l.Name, l.ID), l)
+	return uniqueViolation(err)
+}
+
+func (r *priceListRepo) DeletePriceList(ctx context.Context, id int) error {
+	res, err := conn(ctx, r.db).ExecContext(ctx, `DELETE FROM price_lists WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *priceListRepo) GetTiers(ctx context.Context, listID, productID int) ([]models.PriceTier, error) {
+	query := `SELECT t.min_qty, t.price, l.currency FROM price_list_tiers t
+		JOIN price_lists l ON l.id = t.price_list_id
+		WHERE t.price_list_id = $1 AND t.product_id = $2 ORDER BY t.min_qty`
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, listID, productID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tiers []models.PriceTier
+	for rows.Next() {
+		var t models.PriceTier
+		if err := rows.Scan(&t.MinQuantity, &t.Price.Amount, &t.Price.Currency); err != nil {
+			return nil, err
+		}
+		tiers = append(tiers, t)
+	}
+	return tiers, rows.Err()
+}
+
+func (r *priceListRepo) ReplaceTiers(ctx context.Context, listID, productID int, tiers []models.PriceTier) error {
+	c := conn(ctx, r.db)
+	if _, err := c.ExecContext(ctx, `DELETE FROM price_list_tiers WHERE price_list_id = $1 AND product_id = $2`, listID, productID); err != nil {
+		return err
+	}
+	for _, t := range tiers {
+		_, err := c.ExecContext(ctx, `INSERT INTO price_list_tiers (price_list_id, product_id, min_qty, price)
+			VALUES ($1, $2, $3, $4)`, listID, productID, t.MinQuantity, t.Price.Amount)
+		if err != nil {
+			return uniqueViolation(err)
+		}
+	}
+	return nil
+}
+
+func (r *priceListRepo) FindTier(ctx context.Context, listID, productID, qty int) (*models.PriceTier, error) {
+	query := `SELECT t.min_qty, t.price, l.currency FROM price_list_tiers t
+		JOIN price_lists l ON l.id = t.price_list_id
+		WHERE t.price_list_id = $1 AND t.product_id = $2 AND t.min_qty <= $3
+		ORDER BY t.min_qty DESC LIMIT 1`
+	var t models.PriceTier
+	err := conn(ctx, r.db).QueryRowContext(ctx, query, listID, productID, qty).Scan(&t.MinQuantity, &t.Price.Amount, &t.Price.Currency)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return &t, nil
+}
+
+const groupColumns = `id, code, name, price_list_id, created_at`
+
+func scanGroup(row interface{ Scan(dest ...any) error }, g *models.CustomerGroup) error {
+	err := row.Scan(&g.ID, &g.Code, &g.Name, &g.PriceListID, &g.CreatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrNotFound
+	}
+	return err
+}
+
+func (r *priceListRepo) ListGroups(ctx context.Context) ([]models.CustomerGroup, error) {
+	rows, err := conn(ctx, r.db).QueryContext(ctx, `SELECT `+groupColumns+` FROM customer_groups ORDER BY code`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var groups []models.CustomerGroup
+	for rows.Next() {
+		var g models.CustomerGroup
+		if err := scanGroup(rows, &g); err != nil {
+			return nil, err
+		}
+		groups = append(groups, g)
+	}
+	return groups, rows.Err()
+}
+
+func (r *priceListRepo) GetGroup(ctx context.Context, id int) (*models.CustomerGroup, error) {
+	var g models.CustomerGroup
+	if err := scanGroup(conn(ctx, r.db).QueryRowContext(ctx, `SELECT `+groupColumns+` FROM customer_groups WHERE id = $1`, id), &g); err != nil {
+		return nil, err
+	}
+	return &g, nil
+}
+
+func (r *priceListRepo) GetGroupByCode(ctx context.Context, code string) (*models.CustomerGroup, error) {
+	var g models.CustomerGroup
+	if err := scanGroup(conn(ctx, r.db).QueryRowContext(ctx, `SELECT `+groupColumns+` FROM customer_groups WHERE code = $1`, code), &g); err != nil {
+		return nil, err
+	}
+	return &g, nil
+}
+
+func (r *priceListRepo) CreateGroup(ctx context.Context, g *models.CustomerGroup) error {
+	query := `INSERT INTO customer_groups (code, name, price_list_id) VALUES ($1, $2, $3) RETURNING id, created_at`
+	err := conn(ctx, r.db).QueryRowContext(ctx, query, g.Code, g.Name, g.PriceListID).Scan(&g.ID, &g.CreatedAt)
+	return uniqueViolation(err)
+}
+
+func (r *priceListRepo) UpdateGroup(ctx context.Context, g *models.CustomerGroup) error {
+	query := `UPDATE customer_groups SET code = $1, name = $2, price_list_id = $3 WHERE id = $4 RETURNING ` + groupColumns
+	err := scanGroup(conn(ctx, r.db).QueryRowContext(ctx, query, g.Code, g.Name, g.PriceListID, g.ID), g)
+	return uniqueViolation(err)
+}
+
+func (r *priceListRepo) DeleteGroup(ctx context.Context, id int) error {
+	res, err := conn(ctx, r.db).ExecContext(ctx, `DELETE FROM customer_groups WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
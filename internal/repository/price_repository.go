@@ -0,0 +1,116 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"product-test/internal/models"
+	"strings"
+	"time"
+)
+
+type PriceRepository interface {
+	// SetPrice closes the current period of the product's own price, if
+	// any, and opens a new one starting when the previous one ends.
+	SetPrice(ctx context.Context, productID int, price models.Money) error
+	// List and Count cover the product's own prices and its sales.
+	List(ctx context.Context, filter models.PriceHistoryFilter) ([]models.PricePeriod, error)
+	Count(ctx context.Context, filter models.PriceHistoryFilter) (int, error)
+	// At returns the period of the product's own price at the instant.
+	At(ctx context.Context, productID int, at time.Time) (*models.PricePeriod, error)
+}
+
+type priceRepo struct {
+	db *sql.DB
+}
+
+func NewPriceRepository(db *sql.DB) PriceRepository {
+	return &priceRepo{db: db}
+}
+
+// SetPrice runs under the product's row lock. The switch is stamped with
+// the clock at that point rather than the transaction start, which may
+// precede the period a transaction committed while this one waited for the
+// lock; it is kept after the start of the period it closes all the same.
+func (r *priceRepo) SetPrice(ctx context.Context, productID int, price models.Money) error {
+	c := conn(ctx, r.db)
+	var at time.Time
+	err := c.QueryRowContext(ctx, `SELECT GREATEST(clock_timestamp(), max(effective_from) + interval '1 microsecond')
+		FROM price_history WHERE product_id = $1 AND effective_to IS NULL AND schedule_id IS NULL`, productID).Scan(&at)
+	if err != nil {
+		return fmt.Errorf("close price period: %w", err)
+	}
+	_, err = c.ExecContext(ctx, `UPDATE price_history SET effective_to = $2
+		WHERE product_id = $1 AND effective_to IS NULL AND schedule_id IS NULL`, productID, at)
+	if err != nil {
+		return fmt.Errorf("close price period: %w", err)
+	}
+	_, err = c.ExecContext(ctx, `INSERT INTO price_history (product_id, price, currency, effective_from)
+		VALUES ($1, $2, $3, $4)`, productID, price.Amount, price.Currency, at)
+	if err != nil {
+		return fmt.Errorf("open price period: %w", err)
+	}
+	return nil
+}
+
+func (r *priceRepo) List(ctx context.Context, filter models.PriceHistoryFilter) ([]models.PricePeriod, error) {
+	if filter.Limit <= 0 {
+		filter.Limit = 100
+	}
+	where, args := priceConditions(filter)
+	args = append(args, filter.Limit, filter.Offset)
+	query := fmt.Sprintf(`SELECT product_id, price, currency, effective_from, effective_to, schedule_id FROM price_history
+		WHERE %s ORDER BY effective_from, id LIMIT $%d OFFSET $%d`, where, len(args)-1, len(args))
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var periods []models.PricePeriod
+	for rows.Next() {
+		var p models.PricePeriod
+		if err := rows.Scan(&p.ProductID, &p.Price.Amount, &p.Price.Currency, &p.EffectiveFrom, &p.EffectiveTo, &p.ScheduleID); err != nil {
+			return nil, err
+		}
+		periods = append(periods, p)
+	}
+	return periods, rows.Err()
+}
+
+func (r *priceRepo) Count(ctx context.Context, filter models.PriceHistoryFilter) (int, error) {
+	where, args := priceConditions(filter)
+	var n int
+	err := conn(ctx, r.db).QueryRowContext(ctx, `SELECT count(*) FROM price_history WHERE `+where, args...).Scan(&n)
+	return n, err
+}
+
+func (r *priceRepo) At(ctx context.Context, productID int, at time.Time) (*models.PricePeriod, error) {
+	query := `SELECT product_id, price, currency, effective_from, effective_to FROM price_history
+		WHERE product_id = $1 AND schedule_id IS NULL AND effective_from <= $2 AND (effective_to IS NULL OR effective_to > $2)`
+	var p models.PricePeriod
+	err := conn(ctx, r.db).QueryRowContext(ctx, query, productID, at).
+		Scan(&p.ProductID, &p.Price.Amount, &p.Price.Currency, &p.EffectiveFrom, &p.EffectiveTo)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return &p, nil
+}
+
+func priceConditions(f models.PriceHistoryFilter) (string, []any) {
+	conds := []string{"product_id = $1"}
+	args := []any{f.ProductID}
+	if !f.From.IsZero() {
+		args = append(args, f.From)
+		conds = append(conds, fmt.Sprintf("(effective_to IS NULL OR effective_to > $%d)", len(args)))
+	}
+	if !f.To.IsZero() {
+		args = append(args, f.To)
+		conds = append(conds, fmt.Sprintf("effective_from < $%d", len(args)))
+	}
+	return strings.Join(conds, " AND "), args
+}
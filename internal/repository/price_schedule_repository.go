@@ -0,0 +1,217 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"product-test/internal/models"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+type PriceScheduleRepository interface {
+	Create(ctx context.Context, s *models.PriceSchedule) error
+	Update(ctx context.Context, s *models.PriceSchedule) error
+	GetForUpdate(ctx context.Context, productID int, id int64) (*models.PriceSchedule, error)
+	ListByProduct(ctx context.Context, productID int, status models.ScheduleStatus) ([]models.PriceSchedule, error)
+	SetStatus(ctx context.Context, id int64, status models.ScheduleStatus) error
+	// Cancel withdraws a schedule that has not started. One that has is
+	// ended now and expires instead, so that the price it set stays on
+	// record. It returns the status the schedule ends up in.
+	Cancel(ctx context.Context, id int64) (models.ScheduleStatus, error)
+	// EffectivePrices returns, per product, the schedule that sets its price
+	// at the given instant, if any.
+	EffectivePrices(ctx context.Context, productIDs []int, at time.Time) (map[int]models.PriceSchedule, error)
+	// Activate and Expire move sales whose window has opened or closed by
+	// now, open and close their periods in the price history, and report
+	// how many sales changed.
+	Activate(ctx context.Context) (int64, error)
+	Expire(ctx context.Context) (int64, error)
+	// ClaimDueChange locks one permanent change that is due, skipping rows
+	// locked by other schedulers. It returns ErrNotFound when none is due.
+	ClaimDueChange(ctx context.Context) (*models.PriceSchedule, error)
+	// Fail marks a permanent change that is still scheduled as failed with
+	// the reason.
+	Fail(ctx context.Context, id int64, reason string) error
+}
+
+type priceScheduleRepo struct {
+	db *sql.DB
+}
+
+func NewPriceScheduleRepository(db *sql.DB) PriceScheduleRepository {
+	return &priceScheduleRepo{db: db}
+}
+
+const scheduleColumns = `id, product_id, price, currency, starts_at, ends_at, status, note, created_at, activated_at, finished_at, error`
+
+func (r *priceScheduleRepo) Create(ctx context.Context, s *models.PriceSchedule) error {
+	query := `INSERT INTO price_schedules (product_id, price, currency, starts_at, ends_at, note)
+		VALUES ($1, $2, $3, $4, $5, $6) RETURNING ` + scheduleColumns
+	row := conn(ctx, r.db).QueryRowContext(ctx, query, s.ProductID, s.Price.Amount, s.Price.Currency, s.StartsAt, s.EndsAt, s.Note)
+	return scanSchedule(row, s)
+}
+
+func (r *priceScheduleRepo) Update(ctx context.Context, s *models.PriceSchedule) error {
+	query := `UPDATE price_schedules SET price = $1, currency = $2, starts_at = $3, ends_at = $4, note = $5
+		WHERE id = $6 RETURNING ` + scheduleColumns
+	row := conn(ctx, r.db).QueryRowContext(ctx, query, s.Price.Amount, s.Price.Currency, s.StartsAt, s.EndsAt, s.Note, s.ID)
+	return scanSchedule(row, s)
+}
+
+func (r *priceScheduleRepo) GetForUpdate(ctx context.Context, productID int, id int64) (*models.PriceSchedule, error) {
+	query := `SELECT ` + scheduleColumns + ` FROM price_schedules WHERE id = $1 AND product_id = $2 FOR UPDATE`
+	var s models.PriceSchedule
+	if err := scanSchedule(conn(ctx, r.db).QueryRowContext(ctx, query, id, productID), &s); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+func (r *priceScheduleRepo) ListByProduct(ctx context.Context, productID int, status models.ScheduleStatus) ([]models.PriceSchedule, error) {
+	query := `SELECT ` + scheduleColumns + ` FROM price_schedules
+		WHERE product_id = $1 AND ($2 = '' OR status = $2) ORDER BY starts_at, id`
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, productID, status)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var schedules []models.PriceSchedule
+	for rows.Next() {
+		var s models.PriceSchedule
+		if err := scanSchedule(rows, &s); err != nil {
+			return nil, err
+		}
+		schedules = append(schedules, s)
+	}
+	return schedules, rows.Err()
+}
+
+func (r *priceScheduleRepo) SetStatus(ctx context.Context, id int64, status models.ScheduleStatus) error {
+	query := `UPDATE price_schedules SET status = $1,
+		finished_at = CASE WHEN $1 IN ('expired', 'applied', 'cancelled') THEN now() ELSE finished_at END
+		WHERE id = $2`
+	_, err := conn(ctx, r.db).ExecContext(ctx, query, status, id)
+	return err
+}
+
+func (r *priceScheduleRepo) Cancel(ctx context.Context, id int64) (models.ScheduleStatus, error) {
+	c := conn(ctx, r.db)
+	query := `UPDATE price_schedules SET
+		status = CASE WHEN starts_at < now() THEN 'expired' ELSE 'cancelled' END,
+		ends_at = CASE WHEN starts_at < now() THEN LEAST(ends_at, now()) ELSE ends_at END,
+		finished_at = now()
+		WHERE id = $1 RETURNING status`
+	var status models.ScheduleStatus
+	err := c.QueryRowContext(ctx, query, id).Scan(&status)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", ErrNotFound
+	}
+	if err != nil || status != models.ScheduleExpired {
+		return status, err
+	}
+	// Close the period of the sale, or record the whole of it if the
+	// scheduler has not opened it yet.
+	_, err = c.ExecContext(ctx, `WITH closed AS (
+			UPDATE price_history h SET effective_to = s.ends_at FROM price_schedules s
+			WHERE s.id = $1 AND h.schedule_id = s.id AND h.effective_to IS NULL
+		)
+		INSERT INTO price_history (product_id, price, currency, effective_from, effective_to, schedule_id)
+		SELECT product_id, price, currency, starts_at, ends_at, id FROM price_schedules s
+		WHERE s.id = $1 AND NOT EXISTS (SELECT 1 FROM price_history h WHERE h.schedule_id = s.id)`, id)
+	return status, err
+}
+
+// EffectivePrices considers sales whose window contains at, and permanent
+// changes that are due but not applied yet. Applied changes are already
+// part of the product's own price. The most recently started one wins.
+func (r *priceScheduleRepo) EffectivePrices(ctx context.Context, productIDs []int, at time.Time) (map[int]models.PriceSchedule, error) {
+	result := make(map[int]models.PriceSchedule)
+	if len(productIDs) == 0 {
+		return result, nil
+	}
+	query := `SELECT DISTINCT ON (product_id) ` + scheduleColumns + ` FROM price_schedules
+		WHERE product_id = ANY($1) AND starts_at <= $2
+		  AND ((ends_at IS NOT NULL AND ends_at > $2 AND status <> 'cancelled')
+		    OR (ends_at IS NULL AND status = 'scheduled'))
+		ORDER BY product_id, starts_at DESC, id DESC`
+	ids := make(pq.Int64Array, len(productIDs))
+	for i, id := range productIDs {
+		ids[i] = int64(id)
+	}
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, ids, at)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var s models.PriceSchedule
+		if err := scanSchedule(rows, &s); err != nil {
+			return nil, err
+		}
+		result[s.ProductID] = s
+	}
+	return result, rows.Err()
+}
+
+func (r *priceScheduleRepo) Activate(ctx context.Context) (int64, error) {
+	res, err := conn(ctx, r.db).ExecContext(ctx, `WITH activated AS (
+			UPDATE price_schedules SET status = 'active', activated_at = now()
+			WHERE status = 'scheduled' AND ends_at IS NOT NULL AND starts_at <= now() AND ends_at > now()
+			RETURNING id, product_id, price, currency, starts_at
+		)
+		INSERT INTO price_history (product_id, price, currency, effective_from, schedule_id)
+		SELECT product_id, price, currency, starts_at, id FROM activated`)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+// Expire closes the periods of the sales it ends. A sale whose whole window
+// passed between two runs was never opened and is recorded in one go.
+func (r *priceScheduleRepo) Expire(ctx context.Context) (int64, error) {
+	var n int64
+	err := conn(ctx, r.db).QueryRowContext(ctx, `WITH expired AS (
+			UPDATE price_schedules SET status = 'expired', finished_at = now()
+			WHERE status IN ('scheduled', 'active') AND ends_at <= now()
+			RETURNING id, product_id, price, currency, starts_at, ends_at
+		), closed AS (
+			UPDATE price_history h SET effective_to = e.ends_at FROM expired e
+			WHERE h.schedule_id = e.id AND h.effective_to IS NULL
+		), recorded AS (
+			INSERT INTO price_history (product_id, price, currency, effective_from, effective_to, schedule_id)
+			SELECT product_id, price, currency, starts_at, ends_at, id FROM expired e
+			WHERE NOT EXISTS (SELECT 1 FROM price_history h WHERE h.schedule_id = e.id)
+		)
+		SELECT count(*) FROM expired`).Scan(&n)
+	return n, err
+}
+
+func (r *priceScheduleRepo) ClaimDueChange(ctx context.Context) (*models.PriceSchedule, error) {
+	query := `SELECT ` + scheduleColumns + ` FROM price_schedules
+		WHERE status = 'scheduled' AND ends_at IS NULL AND starts_at <= now()
+		ORDER BY starts_at, id LIMIT 1 FOR UPDATE SKIP LOCKED`
+	var s models.PriceSchedule
+	if err := scanSchedule(conn(ctx, r.db).QueryRowContext(ctx, query), &s); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+func (r *priceScheduleRepo) Fail(ctx context.Context, id int64, reason string) error {
+	_, err := conn(ctx, r.db).ExecContext(ctx, `UPDATE price_schedules SET status = 'failed', error = $2, finished_at = now()
+		WHERE id = $1 AND status = 'scheduled'`, id, reason)
+	return err
+}
+
+func scanSchedule(row interface{ Scan(dest ...any) error }, s *models.PriceSchedule) error {
+	err := row.Scan(&s.ID, &s.ProductID, &s.Price.Amount, &s.Price.Currency, &s.StartsAt, &s.EndsAt, &s.Status, &s.Note,
+		&s.CreatedAt, &s.ActivatedAt, &s.FinishedAt, &s.Error)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrNotFound
+	}
+	return err
+}
@@ -3,18 +3,101 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"product-test/internal/attrschema"
 	"product-test/internal/models"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
 )
 
 var ErrNotFound = errors.New("product not found")
 
+// ErrDuplicate is returned when a write would break a uniqueness constraint.
+var ErrDuplicate = errors.New("duplicate key")
+
+// DuplicateError is an ErrDuplicate that names the column whose value is
+// already taken.
+type DuplicateError struct {
+	Column string
+}
+
+func (e *DuplicateError) Error() string {
+	return "duplicate " + e.Column
+}
+
+func (e *DuplicateError) Is(target error) bool {
+	return target == ErrDuplicate
+}
+
+// productUniqueColumns maps the unique indexes of products to their columns.
+var productUniqueColumns = map[string]string{
+	"products_sku_idx":     "sku",
+	"products_barcode_idx": "barcode",
+	"products_slug_idx":    "slug",
+}
+
+// productDuplicate is uniqueViolation naming the column that clashed.
+func productDuplicate(err error) error {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+		if column, ok := productUniqueColumns[pqErr.Constraint]; ok {
+			return &DuplicateError{Column: column}
+		}
+	}
+	return uniqueViolation(err)
+}
+
+const streamBatchSize = 500
+
+// productColumns maps the fields of models.ProductFields to their columns
+// and scan destinations, so that sparse fieldsets only read what they need.
+var productColumns = map[string]struct {
+	columns []string
+	dest    func(p *models.Product) []any
+}{
+	"id":          {[]string{"id"}, func(p *models.Product) []any { return []any{&p.ID} }},
+	"name":        {[]string{"name"}, func(p *models.Product) []any { return []any{&p.Name} }},
+	"description": {[]string{"description"}, func(p *models.Product) []any { return []any{&p.Description} }},
+	"price":       {[]string{"price", "currency"}, func(p *models.Product) []any { return []any{&p.Price.Amount, &p.Price.Currency} }},
+	"attributes":  {[]string{"attributes"}, func(p *models.Product) []any { return []any{&p.Attributes} }},
+	"sku":         {[]string{"COALESCE(sku, '')"}, func(p *models.Product) []any { return []any{&p.SKU} }},
+	"barcode":     {[]string{"COALESCE(barcode, '')"}, func(p *models.Product) []any { return []any{&p.Barcode} }},
+	"slug":        {[]string{"COALESCE(slug, '')"}, func(p *models.Product) []any { return []any{&p.Slug} }},
+	"deleted_at":  {[]string{"deleted_at"}, func(p *models.Product) []any { return []any{&p.DeletedAt} }},
+}
+
 type ProductRepository interface {
-	GetAll(ctx context.Context, limit, offset int) ([]models.Product, error)
+	GetAll(ctx context.Context, filter models.ProductFilter) ([]models.Product, error)
+	Stream(ctx context.Context, filter models.ProductFilter, fn func([]models.Product) error) error
+	Count(ctx context.Context, filter models.ProductFilter) (int, error)
+	EstimateCount(ctx context.Context) (int, error)
 	GetByID(ctx context.Context, id int) (*models.Product, error)
+	GetForUpdate(ctx context.Context, id int) (*models.Product, error)
+	// FindBySKU returns the id of the live product that has the SKU itself
+	// or through one of its variants.
+	FindBySKU(ctx context.Context, sku string) (int, error)
+	FindByBarcode(ctx context.Context, code string) (int, error)
+	FindBySlug(ctx context.Context, slug string) (int, error)
+	// SlugsLike returns the slugs of other products that equal base or
+	// extend it with a '-' suffix.
+	SlugsLike(ctx context.Context, base string, exceptID int) ([]string, error)
+	// WithoutSlug returns the ids of the products, trashed or not, that
+	// have no slug.
+	WithoutSlug(ctx context.Context) ([]int, error)
+	SetSlug(ctx context.Context, id int, slug string) error
+	// ClaimSKU locks a SKU for the rest of the transaction and fails with a
+	// DuplicateError if a variant already has it.
+	ClaimSKU(ctx context.Context, sku string) error
 	Create(ctx context.Context, product *models.Product) error
 	Update(ctx context.Context, product *models.Product) error
 	Delete(ctx context.Context, id int) error
+	Restore(ctx context.Context, id int) error
+	Purge(ctx context.Context, deletedBefore time.Time) ([]models.Product, error)
 }
 
 type productRepo struct {
@@ -25,40 +108,212 @@ func NewProductRepository(db *sql.DB) ProductRepository {
 	return &productRepo{db: db}
 }
 
-func (r *productRepo) GetAll(ctx context.Context, limit, offset int) ([]models.Product, error) {
-	if limit <= 0 {
-		limit = 100
-	}
-	if offset < 0 {
-		offset = 0
+func (r *productRepo) GetAll(ctx context.Context, filter models.ProductFilter) ([]models.Product, error) {
+	if filter.Limit <= 0 {
+		filter.Limit = 100
 	}
-	query := `SELECT id, name, description, price FROM products ORDER BY id LIMIT $1 OFFSET $2`
-	rows, err := r.db.QueryContext(ctx, query, limit, offset)
+	query, args, fields := selectProducts(filter)
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
+	return scanProducts(rows, fields)
+}
+
+// Stream reads the filtered products through a server-side cursor and hands
+// them to fn in batches, so the full result set is never held in memory.
+func (r *productRepo) Stream(ctx context.Context, filter models.ProductFilter, fn func([]models.Product) error) error {
+	tx, err := r.db.BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
 
+	query, args, fields := selectProducts(filter)
+	if _, err := tx.ExecContext(ctx, "DECLARE products_cursor NO SCROLL CURSOR FOR "+query, args...); err != nil {
+		return fmt.Errorf("declare cursor: %w", err)
+	}
+	fetch := fmt.Sprintf("FETCH FORWARD %d FROM products_cursor", streamBatchSize)
+	for {
+		rows, err := tx.QueryContext(ctx, fetch)
+		if err != nil {
+			return fmt.Errorf("fetch cursor: %w", err)
+		}
+		batch, err := scanProducts(rows, fields)
+		rows.Close()
+		if err != nil {
+			return err
+		}
+		if len(batch) == 0 {
+			break
+		}
+		if err := fn(batch); err != nil {
+			return err
+		}
+	}
+	if _, err := tx.ExecContext(ctx, "CLOSE products_cursor"); err != nil {
+		return fmt.Errorf("close cursor: %w", err)
+	}
+	return tx.Commit()
+}
+
+func (r *productRepo) Count(ctx context.Context, filter models.ProductFilter) (int, error) {
+	where, args := productConditions(filter)
+	var n int
+	err := conn(ctx, r.db).QueryRowContext(ctx, `SELECT count(*) FROM products WHERE `+where, args...).Scan(&n)
+	return n, err
+}
+
+// EstimateCount returns the planner's row estimate for the table, which is
+// cheap on large tables but only as fresh as the last VACUUM or ANALYZE.
+// It returns -1 when the table has never been analyzed.
+func (r *productRepo) EstimateCount(ctx context.Context) (int, error) {
+	var n float64
+	err := conn(ctx, r.db).QueryRowContext(ctx, `SELECT reltuples FROM pg_class WHERE oid = 'products'::regclass`).Scan(&n)
+	if err != nil {
+		return 0, err
+	}
+	if n < 0 {
+		return -1, nil
+	}
+	return int(n), nil
+}
+
+func selectProducts(filter models.ProductFilter) (string, []any, []string) {
+	fields := filter.Fields
+	if len(fields) == 0 {
+		fields = models.ProductFields
+	}
+	order := "id"
+	if filter.Trashed {
+		fields = append(slices.Clip(fields), "deleted_at")
+		order = "deleted_at DESC, id"
+	}
+	var columns []string
+	for _, f := range fields {
+		columns = append(columns, productColumns[f].columns...)
+	}
+	where, args := productConditions(filter)
+	query := `SELECT ` + strings.Join(columns, ", ") + ` FROM products WHERE ` + where + ` ORDER BY ` + order
+	if filter.Limit > 0 {
+		args = append(args, filter.Limit)
+		query += fmt.Sprintf(" LIMIT $%d", len(args))
+	}
+	if filter.Offset > 0 {
+		args = append(args, filter.Offset)
+		query += fmt.Sprintf(" OFFSET $%d", len(args))
+	}
+	return query, args, fields
+}
+
+// productConditions builds the WHERE clause shared by listing, export and counting.
+func productConditions(filter models.ProductFilter) (string, []any) {
+	conds := []string{"deleted_at IS NULL"}
+	if filter.Trashed {
+		conds[0] = "deleted_at IS NOT NULL"
+	}
+	var args []any
+	if filter.CategoryID != 0 {
+		args = append(args, filter.CategoryID)
+		depth := " AND cc.depth = 0"
+		if filter.IncludeDescendants {
+			depth = ""
+		}
+		conds = append(conds, fmt.Sprintf(`id IN (SELECT pc.product_id FROM product_categories pc
+			JOIN category_closure cc ON cc.descendant_id = pc.category_id
+			WHERE cc.ancestor_id = $%d%s)`, len(args), depth))
+	}
+	if len(filter.Tags) > 0 {
+		args = append(args, pq.StringArray(filter.Tags))
+		tags := fmt.Sprintf(`SELECT pt.product_id FROM product_tags pt
+			JOIN tags t ON t.id = pt.tag_id
+			WHERE t.name = ANY($%d)`, len(args))
+		if filter.TagMode != models.TagModeAny {
+			args = append(args, len(filter.Tags))
+			tags += fmt.Sprintf(" GROUP BY pt.product_id HAVING count(*) = $%d", len(args))
+		}
+		conds = append(conds, "id IN ("+tags+")")
+	}
+	for _, f := range filter.Attributes {
+		args = append(args, attributePath(f))
+		conds = append(conds, fmt.Sprintf("attributes @@ $%d::jsonpath", len(args)))
+	}
+	return strings.Join(conds, " AND "), args
+}
+
+var attributeOps = map[models.AttributeOp]string{
+	models.AttributeGt:  ">",
+	models.AttributeGte: ">=",
+	models.AttributeLt:  "<",
+	models.AttributeLte: "<=",
+}
+
+// attributePath turns a validated attribute filter into a jsonpath
+// predicate. Equality with a value that reads as a number or boolean also
+// matches that JSON value, since query parameters carry no types.
+func attributePath(f models.AttributeFilter) string {
+	// Keys are quoted so that names such as size or type are not read as
+	// jsonpath keywords; valid names need no escaping.
+	key := `$."` + f.Name + `"`
+	if op, ok := attributeOps[f.Op]; ok {
+		return key + " " + op + " " + f.Values[0]
+	}
+	var terms []string
+	for _, v := range f.Values {
+		quoted, _ := json.Marshal(v)
+		terms = append(terms, key+" == "+string(quoted))
+		if attrschema.IsNumber(v) || v == "true" || v == "false" {
+			terms = append(terms, key+" == "+v)
+		}
+	}
+	return strings.Join(terms, " || ")
+}
+
+func scanProducts(rows *sql.Rows, fields []string) ([]models.Product, error) {
 	var products []models.Product
 	for rows.Next() {
 		var p models.Product
-		if err := rows.Scan(&p.ID, &p.Name, &p.Description, &p.Price); err != nil {
+		var dest []any
+		for _, f := range fields {
+			dest = append(dest, productColumns[f].dest(&p)...)
+		}
+		if err := rows.Scan(dest...); err != nil {
 			return nil, err
 		}
 		products = append(products, p)
 	}
-	return products, nil
+	return products, rows.Err()
 }
 
 func (r *productRepo) Create(ctx context.Context, p *models.Product) error {
-	query := `INSERT INTO products (name, description, price) VALUES ($1, $2, $3) RETURNING id`
-	return r.db.QueryRowContext(ctx, query, p.Name, p.Description, p.Price).Scan(&p.ID)
+	query := `INSERT INTO products (name, description, price, currency, attributes, sku, barcode, slug)
+		VALUES ($1, $2, $3, $4, $5, NULLIF($6, ''), NULLIF($7, ''), NULLIF($8, '')) RETURNING id`
+	err := conn(ctx, r.db).QueryRowContext(ctx, query, p.Name, p.Description, p.Price.Amount, p.Price.Currency, p.Attributes, p.SKU, p.Barcode, p.Slug).Scan(&p.ID)
+	return productDuplicate(err)
 }
 
 func (r *productRepo) GetByID(ctx context.Context, id int) (*models.Product, error) {
-	query := `SELECT id, name, description, price FROM products WHERE id = $1`
+	query := `SELECT id, name, description, price, currency, attributes, COALESCE(sku, ''), COALESCE(barcode, ''), COALESCE(slug, '')
+		FROM products WHERE id = $1 AND deleted_at IS NULL`
+	var p models.Product
+	err := conn(ctx, r.db).QueryRowContext(ctx, query, id).Scan(&p.ID, &p.Name, &p.Description, &p.Price.Amount, &p.Price.Currency, &p.Attributes, &p.SKU, &p.Barcode, &p.Slug)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return &p, nil
+}
+
+// GetForUpdate reads a product, trashed or not, and locks its row until the
+// end of the transaction carried by ctx.
+func (r *productRepo) GetForUpdate(ctx context.Context, id int) (*models.Product, error) {
+	query := `SELECT id, name, description, price, currency, attributes, COALESCE(sku, ''), COALESCE(barcode, ''), COALESCE(slug, ''), deleted_at
+		FROM products WHERE id = $1 FOR UPDATE`
 	var p models.Product
-	err := r.db.QueryRowContext(ctx, query, id).Scan(&p.ID, &p.Name, &p.Description, &p.Price)
+	err := conn(ctx, r.db).QueryRowContext(ctx, query, id).Scan(&p.ID, &p.Name, &p.Description, &p.Price.Amount, &p.Price.Currency, &p.Attributes, &p.SKU, &p.Barcode, &p.Slug, &p.DeletedAt)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, ErrNotFound
@@ -69,10 +324,11 @@ func (r *productRepo) GetByID(ctx context.Context, id int) (*models.Product, err
 }
 
 func (r *productRepo) Update(ctx context.Context, p *models.Product) error {
-	query := `UPDATE products SET name=$1, description=$2, price=$3 WHERE id=$4`
-	res, err := r.db.ExecContext(ctx, query, p.Name, p.Description, p.Price, p.ID)
+	query := `UPDATE products SET name=$1, description=$2, price=$3, currency=$4, attributes=$5,
+		sku=NULLIF($6, ''), barcode=NULLIF($7, ''), slug=NULLIF($8, '') WHERE id=$9 AND deleted_at IS NULL`
+	res, err := conn(ctx, r.db).ExecContext(ctx, query, p.Name, p.Description, p.Price.Amount, p.Price.Currency, p.Attributes, p.SKU, p.Barcode, p.Slug, p.ID)
 	if err != nil {
-		return err
+		return productDuplicate(err)
 	}
 	rows, _ := res.RowsAffected()
 	if rows == 0 {
@@ -81,9 +337,23 @@ func (r *productRepo) Update(ctx context.Context, p *models.Product) error {
 	return nil
 }
 
+// Delete moves the product to the trash; Purge removes it for good.
 func (r *productRepo) Delete(ctx context.Context, id int) error {
-	query := `DELETE FROM products WHERE id = $1`
-	res, err := r.db.ExecContext(ctx, query, id)
+	query := `UPDATE products SET deleted_at = now() WHERE id = $1 AND deleted_at IS NULL`
+	res, err := conn(ctx, r.db).ExecContext(ctx, query, id)
+	if err != nil {
+		return err
+	}
+	rows, _ := res.RowsAffected()
+	if rows == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *productRepo) Restore(ctx context.Context, id int) error {
+	query := `UPDATE products SET deleted_at = NULL WHERE id = $1 AND deleted_at IS NOT NULL`
+	res, err := conn(ctx, r.db).ExecContext(ctx, query, id)
 	if err != nil {
 		return err
 	}
@@ -93,3 +363,108 @@ func (r *productRepo) Delete(ctx context.Context, id int) error {
 	}
 	return nil
 }
+
+// Purge permanently removes products trashed before deletedBefore and
+// returns them.
+func (r *productRepo) Purge(ctx context.Context, deletedBefore time.Time) ([]models.Product, error) {
+	query := `DELETE FROM products WHERE deleted_at < $1 RETURNING id, name, description, price, currency, attributes,
+		COALESCE(sku, ''), COALESCE(barcode, ''), COALESCE(slug, ''), deleted_at`
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, deletedBefore)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return scanProducts(rows, append(slices.Clip(models.ProductFields), "deleted_at"))
+}
+
+func (r *productRepo) FindBySKU(ctx context.Context, sku string) (int, error) {
+	return r.findID(ctx, `
+		SELECT id FROM products WHERE sku = $1 AND deleted_at IS NULL
+		UNION ALL
+		SELECT p.id FROM product_variants v
+		JOIN products p ON p.id = v.product_id AND p.deleted_at IS NULL
+		WHERE v.sku = $1`, sku)
+}
+
+func (r *productRepo) FindByBarcode(ctx context.Context, code string) (int, error) {
+	return r.findID(ctx, `SELECT id FROM products WHERE barcode = $1 AND deleted_at IS NULL`, code)
+}
+
+func (r *productRepo) FindBySlug(ctx context.Context, slug string) (int, error) {
+	return r.findID(ctx, `SELECT id FROM products WHERE slug = $1 AND deleted_at IS NULL`, slug)
+}
+
+func (r *productRepo) findID(ctx context.Context, query string, arg any) (int, error) {
+	var id int
+	err := conn(ctx, r.db).QueryRowContext(ctx, query+` LIMIT 1`, arg).Scan(&id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, ErrNotFound
+	}
+	return id, err
+}
+
+func (r *productRepo) SlugsLike(ctx context.Context, base string, exceptID int) ([]string, error) {
+	// Slugs hold no LIKE wildcards, so base needs no escaping.
+	rows, err := conn(ctx, r.db).QueryContext(ctx,
+		`SELECT slug FROM products WHERE (slug = $1 OR slug LIKE $1 || '-%') AND id <> $2`, base, exceptID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var slugs []string
+	for rows.Next() {
+		var slug string
+		if err := rows.Scan(&slug); err != nil {
+			return nil, err
+		}
+		slugs = append(slugs, slug)
+	}
+	return slugs, rows.Err()
+}
+
+func (r *productRepo) WithoutSlug(ctx context.Context) ([]int, error) {
+	rows, err := conn(ctx, r.db).QueryContext(ctx, `SELECT id FROM products WHERE slug IS NULL ORDER BY id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+func (r *productRepo) SetSlug(ctx context.Context, id int, slug string) error {
+	res, err := conn(ctx, r.db).ExecContext(ctx, `UPDATE products SET slug = $1 WHERE id = $2`, slug, id)
+	if err != nil {
+		return productDuplicate(err)
+	}
+	rows, _ := res.RowsAffected()
+	if rows == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *productRepo) ClaimSKU(ctx context.Context, sku string) error {
+	db := conn(ctx, r.db)
+	if err := lockSKU(ctx, db, sku); err != nil {
+		return err
+	}
+	var taken bool
+	err := db.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM product_variants WHERE sku = $1)`, sku).Scan(&taken)
+	if err != nil {
+		return err
+	}
+	if taken {
+		return &DuplicateError{Column: "sku"}
+	}
+	return nil
+}
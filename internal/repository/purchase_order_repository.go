@@ -0,0 +1,242 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"product-test/internal/models"
+	"strings"
+
+	"github.com/lib/pq"
+)
+
+type PurchaseOrderRepository interface {
+	GetByID(ctx context.Context, id int64) (*models.PurchaseOrder, error)
+	// Lock reads a purchase order and locks it until the end of the
+	// transaction.
+	Lock(ctx context.Context, id int64) (*models.PurchaseOrder, error)
+	List(ctx context.Context, filter models.PurchaseOrderFilter) ([]models.PurchaseOrder, error)
+	Count(ctx context.Context, filter models.PurchaseOrderFilter) (int, error)
+	Create(ctx context.Context, o *models.PurchaseOrder) error
+	// Update replaces the supplier, warehouse, currency, note and lines of
+	// a draft.
+	Update(ctx context.Context, o *models.PurchaseOrder) error
+	// SetStatus moves a purchase order to a status, stamping when it was
+	// sent or finished.
+	SetStatus(ctx context.Context, o *models.PurchaseOrder, status models.PurchaseOrderStatus) error
+	// Receive adds to the quantity received of a line.
+	Receive(ctx context.Context, orderID int64, key models.StockKey, quantity int64) error
+}
+
+type purchaseOrderRepo struct {
+	db *sql.DB
+}
+
+func NewPurchaseOrderRepository(db *sql.DB) PurchaseOrderRepository {
+	return &purchaseOrderRepo{db: db}
+}
+
+const purchaseOrderSelect = `SELECT id, supplier_id, warehouse_id, status, currency, note, actor, created_at, updated_at, sent_at, finished_at
+	FROM purchase_orders`
+
+func scanPurchaseOrder(row rowScanner) (*models.PurchaseOrder, error) {
+	var o models.PurchaseOrder
+	err := row.Scan(&o.ID, &o.SupplierID, &o.WarehouseID, &o.Status, &o.Currency, &o.Note, &o.Actor,
+		&o.CreatedAt, &o.UpdatedAt, &o.SentAt, &o.FinishedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &o, nil
+}
+
+func (r *purchaseOrderRepo) get(ctx context.Context, id int64, lock string) (*models.PurchaseOrder, error) {
+	o, err := scanPurchaseOrder(conn(ctx, r.db).QueryRowContext(ctx, purchaseOrderSelect+` WHERE id = $1`+lock, id))
+	if err != nil {
+		return nil, err
+	}
+	if err := r.loadLines(ctx, []*models.PurchaseOrder{o}); err != nil {
+		return nil, err
+	}
+	return o, nil
+}
+
+func (r *purchaseOrderRepo) GetByID(ctx context.Context, id int64) (*models.PurchaseOrder, error) {
+	return r.get(ctx, id, "")
+}
+
+func (r *purchaseOrderRepo) Lock(ctx context.Context, id int64) (*models.PurchaseOrder, error) {
+	return r.get(ctx, id, " FOR UPDATE")
+}
+
+// loadLines fills in the lines of purchase orders, ordered by product, and
+// their totals.
+func (r *purchaseOrderRepo) loadLines(ctx context.Context, orders []*models.PurchaseOrder) error {
+	if len(orders) == 0 {
+		return nil
+	}
+	byID := make(map[int64]*models.PurchaseOrder, len(orders))
+	ids := make(pq.Int64Array, 0, len(orders))
+	for _, o := range orders {
+		byID[o.ID] = o
+		o.Lines = []models.PurchaseOrderLine{}
+		o.Total = models.Money{Currency: o.Currency}
+		ids = append(ids, o.ID)
+	}
+	rows, err := conn(ctx, r.db).QueryContext(ctx, `
+		SELECT order_id, product_id, variant_id, supplier_sku, quantity, received, unit_cost FROM purchase_order_lines
+		WHERE order_id = ANY($1) ORDER BY order_id, product_id, variant_id`, ids)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			id int64
+			l  models.PurchaseOrderLine
+		)
+		if err := rows.Scan(&id, &l.ProductID, &l.VariantID, &l.SupplierSKU, &l.Quantity, &l.Received, &l.UnitCost.Amount); err != nil {
+			return err
+		}
+		o := byID[id]
+		l.UnitCost.Currency = o.Currency
+		o.Total.Amount += l.Quantity * l.UnitCost.Amount
+		o.Lines = append(o.Lines, l)
+	}
+	return rows.Err()
+}
+
+func (r *purchaseOrderRepo) List(ctx context.Context, filter models.PurchaseOrderFilter) ([]models.PurchaseOrder, error) {
+	if filter.Limit <= 0 {
+		filter.Limit = 100
+	}
+	where, args := purchaseOrderConditions(filter)
+	args = append(args, filter.Limit, filter.Offset)
+	query := fmt.Sprintf(purchaseOrderSelect+` WHERE %s ORDER BY id DESC LIMIT $%d OFFSET $%d`, where, len(args)-1, len(args))
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var orders []*models.PurchaseOrder
+	for rows.Next() {
+		o, err := scanPurchaseOrder(rows)
+		if err != nil {
+			return nil, err
+		}
+		orders = append(orders, o)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	rows.Close()
+	if err := r.loadLines(ctx, orders); err != nil {
+		return nil, err
+	}
+	list := make([]models.PurchaseOrder, len(orders))
+	for i, o := range orders {
+		list[i] = *o
+	}
+	return list, nil
+}
+
+func (r *purchaseOrderRepo) Count(ctx context.Context, filter models.PurchaseOrderFilter) (int, error) {
+	where, args := purchaseOrderConditions(filter)
+	var n int
+	err := conn(ctx, r.db).QueryRowContext(ctx, `SELECT count(*) FROM purchase_orders WHERE `+where, args...).Scan(&n)
+	return n, err
+}
+
+func purchaseOrderConditions(f models.PurchaseOrderFilter) (string, []any) {
+	conds := []string{"TRUE"}
+	var args []any
+	add := func(cond string, arg any) {
+		args = append(args, arg)
+		conds = append(conds, fmt.Sprintf(cond, len(args)))
+	}
+	if f.Status != "" {
+		add("status = $%d", f.Status)
+	}
+	if f.SupplierID != 0 {
+		add("supplier_id = $%d", f.SupplierID)
+	}
+	return strings.Join(conds, " AND "), args
+}
+
+func (r *purchaseOrderRepo) insertLines(ctx context.Context, db DBTX, o *models.PurchaseOrder) error {
+	for _, l := range o.Lines {
+		_, err := db.ExecContext(ctx, `
+			INSERT INTO purchase_order_lines (order_id, product_id, variant_id, supplier_sku, quantity, received, unit_cost)
+			VALUES ($1, $2, $3, $4, $5, $6, $7)`,
+			o.ID, l.ProductID, l.VariantID, l.SupplierSKU, l.Quantity, l.Received, l.UnitCost.Amount)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *purchaseOrderRepo) Create(ctx context.Context, o *models.PurchaseOrder) error {
+	db := conn(ctx, r.db)
+	err := db.QueryRowContext(ctx, `
+		INSERT INTO purchase_orders (supplier_id, warehouse_id, status, currency, note, actor)
+		VALUES ($1, $2, $3, $4, $5, $6) RETURNING id, created_at, updated_at`,
+		o.SupplierID, o.WarehouseID, o.Status, o.Currency, o.Note, o.Actor).Scan(&o.ID, &o.CreatedAt, &o.UpdatedAt)
+	if err != nil {
+		return err
+	}
+	return r.insertLines(ctx, db, o)
+}
+
+func (r *purchaseOrderRepo) Update(ctx context.Context, o *models.PurchaseOrder) error {
+	db := conn(ctx, r.db)
+	err := db.QueryRowContext(ctx, `
+		UPDATE purchase_orders SET supplier_id = $2, warehouse_id = $3, currency = $4, note = $5, updated_at = now()
+		WHERE id = $1 AND status = 'draft' RETURNING updated_at`,
+		o.ID, o.SupplierID, o.WarehouseID, o.Currency, o.Note).Scan(&o.UpdatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrNotFound
+	}
+	if err != nil {
+		return err
+	}
+	if _, err := db.ExecContext(ctx, `DELETE FROM purchase_order_lines WHERE order_id = $1`, o.ID); err != nil {
+		return err
+	}
+	return r.insertLines(ctx, db, o)
+}
+
+func (r *purchaseOrderRepo) SetStatus(ctx context.Context, o *models.PurchaseOrder, status models.PurchaseOrderStatus) error {
+	err := conn(ctx, r.db).QueryRowContext(ctx, `
+		UPDATE purchase_orders SET status = $2, updated_at = now(),
+			sent_at = CASE WHEN $2 = 'sent' THEN now() ELSE sent_at END,
+			finished_at = CASE WHEN $2 IN ('received', 'cancelled') THEN now() ELSE finished_at END
+		WHERE id = $1 RETURNING updated_at, sent_at, finished_at`,
+		o.ID, status).Scan(&o.UpdatedAt, &o.SentAt, &o.FinishedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrNotFound
+	}
+	if err != nil {
+		return err
+	}
+	o.Status = status
+	return nil
+}
+
+func (r *purchaseOrderRepo) Receive(ctx context.Context, orderID int64, key models.StockKey, quantity int64) error {
+	res, err := conn(ctx, r.db).ExecContext(ctx,
+		`UPDATE purchase_order_lines SET received = received + $4 WHERE order_id = $1 AND product_id = $2 AND variant_id = $3`,
+		orderID, key.ProductID, key.VariantID, quantity)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
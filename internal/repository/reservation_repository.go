@@ -0,0 +1,116 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"product-test/internal/models"
+
+	"github.com/lib/pq"
+)
+
+type ReservationRepository interface {
+	GetByID(ctx context.Context, id int64) (*models.Reservation, error)
+	// Lock reads a reservation and locks it until the end of the
+	// transaction.
+	Lock(ctx context.Context, id int64) (*models.Reservation, error)
+	Create(ctx context.Context, res *models.Reservation) error
+	// Finish moves an active reservation to its final status.
+	Finish(ctx context.Context, res *models.Reservation, status models.ReservationStatus) error
+	// Expire marks the active reservations past their expiry as expired
+	// and returns how many there were.
+	Expire(ctx context.Context) (int64, error)
+}
+
+type reservationRepo struct {
+	db *sql.DB
+}
+
+func NewReservationRepository(db *sql.DB) ReservationRepository {
+	return &reservationRepo{db: db}
+}
+
+const reservationSelect = `SELECT id, warehouse_id, status, reference, actor, created_at, expires_at, finished_at
+	FROM reservations`
+
+func (r *reservationRepo) get(ctx context.Context, id int64, lock string) (*models.Reservation, error) {
+	db := conn(ctx, r.db)
+	var res models.Reservation
+	err := db.QueryRowContext(ctx, reservationSelect+` WHERE id = $1`+lock, id).Scan(
+		&res.ID, &res.WarehouseID, &res.Status, &res.Reference, &res.Actor, &res.CreatedAt, &res.ExpiresAt, &res.FinishedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	rows, err := db.QueryContext(ctx,
+		`SELECT product_id, variant_id, quantity FROM reservation_lines WHERE reservation_id = $1 ORDER BY product_id, variant_id`, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	res.Lines = []models.ReservationLine{}
+	for rows.Next() {
+		var l models.ReservationLine
+		if err := rows.Scan(&l.ProductID, &l.VariantID, &l.Quantity); err != nil {
+			return nil, err
+		}
+		res.Lines = append(res.Lines, l)
+	}
+	return &res, rows.Err()
+}
+
+func (r *reservationRepo) GetByID(ctx context.Context, id int64) (*models.Reservation, error) {
+	return r.get(ctx, id, "")
+}
+
+func (r *reservationRepo) Lock(ctx context.Context, id int64) (*models.Reservation, error) {
+	return r.get(ctx, id, " FOR UPDATE")
+}
+
+func (r *reservationRepo) Create(ctx context.Context, res *models.Reservation) error {
+	db := conn(ctx, r.db)
+	err := db.QueryRowContext(ctx, `
+		INSERT INTO reservations (warehouse_id, status, reference, actor, expires_at)
+		VALUES ($1, $2, $3, $4, $5) RETURNING id, created_at`,
+		res.WarehouseID, res.Status, res.Reference, res.Actor, res.ExpiresAt).Scan(&res.ID, &res.CreatedAt)
+	if err != nil {
+		return err
+	}
+	products := make(pq.Int64Array, len(res.Lines))
+	variants := make(pq.Int64Array, len(res.Lines))
+	quantities := make(pq.Int64Array, len(res.Lines))
+	for i, l := range res.Lines {
+		products[i], variants[i], quantities[i] = int64(l.ProductID), int64(l.VariantID), l.Quantity
+	}
+	_, err = db.ExecContext(ctx, `
+		INSERT INTO reservation_lines (reservation_id, product_id, variant_id, quantity)
+		SELECT $1, unnest($2::int[]), unnest($3::int[]), unnest($4::bigint[])`, res.ID, products, variants, quantities)
+	return err
+}
+
+func (r *reservationRepo) Finish(ctx context.Context, res *models.Reservation, status models.ReservationStatus) error {
+	err := conn(ctx, r.db).QueryRowContext(ctx, `
+		UPDATE reservations SET status = $2, finished_at = now()
+		WHERE id = $1 AND status = 'active' RETURNING finished_at`, res.ID, status).Scan(&res.FinishedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrNotFound
+	}
+	if err != nil {
+		return err
+	}
+	res.Status = status
+	return nil
+}
+
+func (r *reservationRepo) Expire(ctx context.Context) (int64, error) {
+	res, err := conn(ctx, r.db).ExecContext(ctx, `
+		UPDATE reservations SET status = 'expired', finished_at = expires_at
+		WHERE status = 'active' AND expires_at <= now()`)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
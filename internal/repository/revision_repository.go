@@ -0,0 +1,103 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"product-test/internal/models"
+	"time"
+)
+
+type RevisionRepository interface {
+	Insert(ctx context.Context, rev *models.ProductRevision) error
+	List(ctx context.Context, productID, limit, offset int) ([]models.ProductRevision, error)
+	Count(ctx context.Context, productID int) (int, error)
+	Get(ctx context.Context, productID, revision int) (*models.ProductRevision, error)
+	AsOf(ctx context.Context, productID int, at time.Time) (*models.ProductRevision, error)
+}
+
+type revisionRepo struct {
+	db *sql.DB
+}
+
+func NewRevisionRepository(db *sql.DB) RevisionRepository {
+	return &revisionRepo{db: db}
+}
+
+const revisionColumns = `product_id, revision, snapshot, actor, COALESCE(request_id, ''), created_at`
+
+// Insert stores the next revision of a product. The caller must hold the
+// product's row lock so that revision numbers do not race.
+func (r *revisionRepo) Insert(ctx context.Context, rev *models.ProductRevision) error {
+	snapshot, err := json.Marshal(rev.Snapshot)
+	if err != nil {
+		return fmt.Errorf("encode snapshot: %w", err)
+	}
+	query := `INSERT INTO product_revisions (product_id, revision, snapshot, actor, request_id)
+		SELECT $1, COALESCE(MAX(revision), 0) + 1, $2, $3, NULLIF($4, '')
+		FROM product_revisions WHERE product_id = $1
+		RETURNING revision, created_at`
+	return conn(ctx, r.db).QueryRowContext(ctx, query, rev.ProductID, snapshot, rev.Actor, rev.RequestID).
+		Scan(&rev.Revision, &rev.CreatedAt)
+}
+
+func (r *revisionRepo) List(ctx context.Context, productID, limit, offset int) ([]models.ProductRevision, error) {
+	if limit <= 0 {
+		limit = 100
+	}
+	query := `SELECT ` + revisionColumns + ` FROM product_revisions
+		WHERE product_id = $1 ORDER BY revision DESC LIMIT $2 OFFSET $3`
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, productID, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var revs []models.ProductRevision
+	for rows.Next() {
+		rev, err := scanRevision(rows)
+		if err != nil {
+			return nil, err
+		}
+		revs = append(revs, *rev)
+	}
+	return revs, rows.Err()
+}
+
+func (r *revisionRepo) Count(ctx context.Context, productID int) (int, error) {
+	var n int
+	err := conn(ctx, r.db).QueryRowContext(ctx, `SELECT count(*) FROM product_revisions WHERE product_id = $1`, productID).Scan(&n)
+	return n, err
+}
+
+func (r *revisionRepo) Get(ctx context.Context, productID, revision int) (*models.ProductRevision, error) {
+	query := `SELECT ` + revisionColumns + ` FROM product_revisions WHERE product_id = $1 AND revision = $2`
+	return scanRevision(conn(ctx, r.db).QueryRowContext(ctx, query, productID, revision))
+}
+
+// AsOf returns the revision that was current at the given instant.
+func (r *revisionRepo) AsOf(ctx context.Context, productID int, at time.Time) (*models.ProductRevision, error) {
+	query := `SELECT ` + revisionColumns + ` FROM product_revisions
+		WHERE product_id = $1 AND created_at <= $2 ORDER BY revision DESC LIMIT 1`
+	return scanRevision(conn(ctx, r.db).QueryRowContext(ctx, query, productID, at))
+}
+
+func scanRevision(row interface{ Scan(dest ...any) error }) (*models.ProductRevision, error) {
+	var (
+		rev      models.ProductRevision
+		snapshot []byte
+	)
+	err := row.Scan(&rev.ProductID, &rev.Revision, &snapshot, &rev.Actor, &rev.RequestID, &rev.CreatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	if err := json.Unmarshal(snapshot, &rev.Snapshot); err != nil {
+		return nil, fmt.Errorf("decode snapshot of product %d revision %d: %w", rev.ProductID, rev.Revision, err)
+	}
+	return &rev, nil
+}
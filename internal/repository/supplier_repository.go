@@ -0,0 +1,180 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"product-test/internal/models"
+)
+
+// ErrSupplierInUse is returned when deleting a supplier that has purchase
+// orders.
+var ErrSupplierInUse = errors.New("supplier has purchase orders")
+
+type SupplierRepository interface {
+	List(ctx context.Context) ([]models.Supplier, error)
+	GetByID(ctx context.Context, id int) (*models.Supplier, error)
+	Create(ctx context.Context, s *models.Supplier) error
+	Update(ctx context.Context, s *models.Supplier) error
+	Delete(ctx context.Context, id int) error
+	// Products returns the products a supplier supplies, by product.
+	Products(ctx context.Context, supplierID int) ([]models.SupplierProduct, error)
+	Product(ctx context.Context, supplierID, productID int) (*models.SupplierProduct, error)
+	// SetProduct adds a product to a supplier or replaces its terms.
+	SetProduct(ctx context.Context, p *models.SupplierProduct) error
+	DeleteProduct(ctx context.Context, supplierID, productID int) error
+}
+
+type supplierRepo struct {
+	db *sql.DB
+}
+
+func NewSupplierRepository(db *sql.DB) SupplierRepository {
+	return &supplierRepo{db: db}
+}
+
+const supplierSelect = `SELECT id, code, name, email, phone, created_at, updated_at FROM suppliers`
+
+func scanSupplier(row rowScanner) (*models.Supplier, error) {
+	var s models.Supplier
+	err := row.Scan(&s.ID, &s.Code, &s.Name, &s.Email, &s.Phone, &s.CreatedAt, &s.UpdatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+func (r *supplierRepo) List(ctx context.Context) ([]models.Supplier, error) {
+	rows, err := conn(ctx, r.db).QueryContext(ctx, supplierSelect+` ORDER BY id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var suppliers []models.Supplier
+	for rows.Next() {
+		s, err := scanSupplier(rows)
+		if err != nil {
+			return nil, err
+		}
+		suppliers = append(suppliers, *s)
+	}
+	return suppliers, rows.Err()
+}
+
+func (r *supplierRepo) GetByID(ctx context.Context, id int) (*models.Supplier, error) {
+	return scanSupplier(conn(ctx, r.db).QueryRowContext(ctx, supplierSelect+` WHERE id = $1`, id))
+}
+
+func (r *supplierRepo) Create(ctx context.Context, s *models.Supplier) error {
+	err := conn(ctx, r.db).QueryRowContext(ctx,
+		`INSERT INTO suppliers (code, name, email, phone) VALUES ($1, $2, $3, $4) RETURNING id, created_at, updated_at`,
+		s.Code, s.Name, s.Email, s.Phone).Scan(&s.ID, &s.CreatedAt, &s.UpdatedAt)
+	return uniqueViolation(err)
+}
+
+func (r *supplierRepo) Update(ctx context.Context, s *models.Supplier) error {
+	err := conn(ctx, r.db).QueryRowContext(ctx, `
+		UPDATE suppliers SET code = $2, name = $3, email = $4, phone = $5, updated_at = now()
+		WHERE id = $1 RETURNING created_at, updated_at`,
+		s.ID, s.Code, s.Name, s.Email, s.Phone).Scan(&s.CreatedAt, &s.UpdatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrNotFound
+	}
+	return uniqueViolation(err)
+}
+
+func (r *supplierRepo) Delete(ctx context.Context, id int) error {
+	db := conn(ctx, r.db)
+	var used bool
+	err := db.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM purchase_orders WHERE supplier_id = $1)`, id).Scan(&used)
+	if err != nil {
+		return err
+	}
+	if used {
+		return ErrSupplierInUse
+	}
+	res, err := db.ExecContext(ctx, `DELETE FROM suppliers WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+const supplierProductSelect = `
+	SELECT sp.supplier_id, sp.product_id, p.name, sp.supplier_sku, sp.cost_price, sp.currency, sp.updated_at
+	FROM supplier_products sp
+	JOIN products p ON p.id = sp.product_id`
+
+func scanSupplierProduct(row rowScanner) (*models.SupplierProduct, error) {
+	var (
+		p        models.SupplierProduct
+		cost     sql.NullInt64
+		currency sql.NullString
+	)
+	err := row.Scan(&p.SupplierID, &p.ProductID, &p.ProductName, &p.SupplierSKU, &cost, &currency, &p.UpdatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if cost.Valid {
+		p.CostPrice = &models.Money{Amount: cost.Int64, Currency: currency.String}
+	}
+	return &p, nil
+}
+
+func (r *supplierRepo) Products(ctx context.Context, supplierID int) ([]models.SupplierProduct, error) {
+	rows, err := conn(ctx, r.db).QueryContext(ctx,
+		supplierProductSelect+` WHERE sp.supplier_id = $1 ORDER BY sp.product_id`, supplierID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var products []models.SupplierProduct
+	for rows.Next() {
+		p, err := scanSupplierProduct(rows)
+		if err != nil {
+			return nil, err
+		}
+		products = append(products, *p)
+	}
+	return products, rows.Err()
+}
+
+func (r *supplierRepo) Product(ctx context.Context, supplierID, productID int) (*models.SupplierProduct, error) {
+	return scanSupplierProduct(conn(ctx, r.db).QueryRowContext(ctx,
+		supplierProductSelect+` WHERE sp.supplier_id = $1 AND sp.product_id = $2`, supplierID, productID))
+}
+
+func (r *supplierRepo) SetProduct(ctx context.Context, p *models.SupplierProduct) error {
+	cost, currency := priceArgs(p.CostPrice)
+	err := conn(ctx, r.db).QueryRowContext(ctx, `
+		INSERT INTO supplier_products (supplier_id, product_id, supplier_sku, cost_price, currency)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (supplier_id, product_id) DO UPDATE SET supplier_sku = EXCLUDED.supplier_sku,
+			cost_price = EXCLUDED.cost_price, currency = EXCLUDED.currency, updated_at = now()
+		RETURNING updated_at`,
+		p.SupplierID, p.ProductID, p.SupplierSKU, cost, currency).Scan(&p.UpdatedAt)
+	return uniqueViolation(err)
+}
+
+func (r *supplierRepo) DeleteProduct(ctx context.Context, supplierID, productID int) error {
+	res, err := conn(ctx, r.db).ExecContext(ctx,
+		`DELETE FROM supplier_products WHERE supplier_id = $1 AND product_id = $2`, supplierID, productID)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
@@ -0,0 +1,104 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"product-test/internal/models"
+
+	"github.com/lib/pq"
+)
+
+type TagRepository interface {
+	// List returns the tags carried by live products, most used first.
+	List(ctx context.Context, limit int) ([]models.Tag, error)
+	// ForProducts returns the tags of each product that has any.
+	ForProducts(ctx context.Context, productIDs []int) (map[int][]string, error)
+	Add(ctx context.Context, productID int, names []string) error
+	Remove(ctx context.Context, productID int, names []string) error
+}
+
+type tagRepo struct {
+	db *sql.DB
+}
+
+func NewTagRepository(db *sql.DB) TagRepository {
+	return &tagRepo{db: db}
+}
+
+func (r *tagRepo) List(ctx context.Context, limit int) ([]models.Tag, error) {
+	rows, err := conn(ctx, r.db).QueryContext(ctx, `
+		SELECT t.name, count(*) FROM tags t
+		JOIN product_tags pt ON pt.tag_id = t.id
+		JOIN products p ON p.id = pt.product_id AND p.deleted_at IS NULL
+		GROUP BY t.name
+		ORDER BY count(*) DESC, t.name
+		LIMIT $1`, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tags []models.Tag
+	for rows.Next() {
+		var t models.Tag
+		if err := rows.Scan(&t.Name, &t.Count); err != nil {
+			return nil, err
+		}
+		tags = append(tags, t)
+	}
+	return tags, rows.Err()
+}
+
+func (r *tagRepo) ForProducts(ctx context.Context, productIDs []int) (map[int][]string, error) {
+	ids := make(pq.Int64Array, len(productIDs))
+	for i, id := range productIDs {
+		ids[i] = int64(id)
+	}
+	rows, err := conn(ctx, r.db).QueryContext(ctx, `
+		SELECT pt.product_id, t.name FROM product_tags pt
+		JOIN tags t ON t.id = pt.tag_id
+		WHERE pt.product_id = ANY($1)
+		ORDER BY pt.product_id, t.name`, ids)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tags := make(map[int][]string)
+	for rows.Next() {
+		var (
+			productID int
+			name      string
+		)
+		if err := rows.Scan(&productID, &name); err != nil {
+			return nil, err
+		}
+		tags[productID] = append(tags[productID], name)
+	}
+	return tags, rows.Err()
+}
+
+// Add creates the tags that do not exist yet and assigns them; tags the
+// product already carries are left alone.
+func (r *tagRepo) Add(ctx context.Context, productID int, names []string) error {
+	db := conn(ctx, r.db)
+	_, err := db.ExecContext(ctx,
+		`INSERT INTO tags (name) SELECT unnest($1::text[]) ON CONFLICT (name) DO NOTHING`,
+		pq.StringArray(names))
+	if err != nil {
+		return err
+	}
+	_, err = db.ExecContext(ctx, `
+		INSERT INTO product_tags (product_id, tag_id)
+		SELECT $1, id FROM tags WHERE name = ANY($2)
+		ON CONFLICT DO NOTHING`, productID, pq.StringArray(names))
+	return err
+}
+
+func (r *tagRepo) Remove(ctx context.Context, productID int, names []string) error {
+	_, err := conn(ctx, r.db).ExecContext(ctx, `
+		DELETE FROM product_tags
+		WHERE product_id = $1 AND tag_id IN (SELECT id FROM tags WHERE name = ANY($2))`,
+		productID, pq.StringArray(names))
+	return err
+}
@@ -0,0 +1,191 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"product-test/internal/models"
+	"strings"
+
+	"github.com/lib/pq"
+)
+
+type TransferRepository interface {
+	GetByID(ctx context.Context, id int64) (*models.Transfer, error)
+	// Lock reads a transfer and locks it until the end of the transaction.
+	Lock(ctx context.Context, id int64) (*models.Transfer, error)
+	List(ctx context.Context, filter models.TransferFilter) ([]models.Transfer, error)
+	Count(ctx context.Context, filter models.TransferFilter) (int, error)
+	Create(ctx context.Context, t *models.Transfer) error
+	// Finish moves an in-transit transfer to its final status.
+	Finish(ctx context.Context, t *models.Transfer, status models.TransferStatus) error
+}
+
+type transferRepo struct {
+	db *sql.DB
+}
+
+func NewTransferRepository(db *sql.DB) TransferRepository {
+	return &transferRepo{db: db}
+}
+
+const transferSelect = `SELECT id, source_warehouse_id, destination_warehouse_id, status, note, actor, created_at, finished_at
+	FROM stock_transfers`
+
+func scanTransfer(row rowScanner) (*models.Transfer, error) {
+	var t models.Transfer
+	err := row.Scan(&t.ID, &t.SourceID, &t.DestinationID, &t.Status, &t.Note, &t.Actor, &t.CreatedAt, &t.FinishedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+func (r *transferRepo) get(ctx context.Context, id int64, lock string) (*models.Transfer, error) {
+	db := conn(ctx, r.db)
+	t, err := scanTransfer(db.QueryRowContext(ctx, transferSelect+` WHERE id = $1`+lock, id))
+	if err != nil {
+		return nil, err
+	}
+	if err := r.loadLines(ctx, []*models.Transfer{t}); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+func (r *transferRepo) GetByID(ctx context.Context, id int64) (*models.Transfer, error) {
+	return r.get(ctx, id, "")
+}
+
+func (r *transferRepo) Lock(ctx context.Context, id int64) (*models.Transfer, error) {
+	return r.get(ctx, id, " FOR UPDATE")
+}
+
+// loadLines fills in the lines of transfers, ordered by product.
+func (r *transferRepo) loadLines(ctx context.Context, transfers []*models.Transfer) error {
+	if len(transfers) == 0 {
+		return nil
+	}
+	byID := make(map[int64]*models.Transfer, len(transfers))
+	ids := make(pq.Int64Array, 0, len(transfers))
+	for _, t := range transfers {
+		byID[t.ID] = t
+		t.Lines = []models.TransferLine{}
+		ids = append(ids, t.ID)
+	}
+	rows, err := conn(ctx, r.db).QueryContext(ctx, `
+		SELECT transfer_id, product_id, variant_id, quantity FROM stock_transfer_lines
+		WHERE transfer_id = ANY($1) ORDER BY transfer_id, product_id, variant_id`, ids)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			id int64
+			l  models.TransferLine
+		)
+		if err := rows.Scan(&id, &l.ProductID, &l.VariantID, &l.Quantity); err != nil {
+			return err
+		}
+		byID[id].Lines = append(byID[id].Lines, l)
+	}
+	return rows.Err()
+}
+
+func (r *transferRepo) List(ctx context.Context, filter models.TransferFilter) ([]models.Transfer, error) {
+	if filter.Limit <= 0 {
+		filter.Limit = 100
+	}
+	where, args := transferConditions(filter)
+	args = append(args, filter.Limit, filter.Offset)
+	query := fmt.Sprintf(transferSelect+` WHERE %s ORDER BY id DESC LIMIT $%d OFFSET $%d`, where, len(args)-1, len(args))
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var transfers []*models.Transfer
+	for rows.Next() {
+		t, err := scanTransfer(rows)
+		if err != nil {
+			return nil, err
+		}
+		transfers = append(transfers, t)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	rows.Close()
+	if err := r.loadLines(ctx, transfers); err != nil {
+		return nil, err
+	}
+	list := make([]models.Transfer, len(transfers))
+	for i, t := range transfers {
+		list[i] = *t
+	}
+	return list, nil
+}
+
+func (r *transferRepo) Count(ctx context.Context, filter models.TransferFilter) (int, error) {
+	where, args := transferConditions(filter)
+	var n int
+	err := conn(ctx, r.db).QueryRowContext(ctx, `SELECT count(*) FROM stock_transfers WHERE `+where, args...).Scan(&n)
+	return n, err
+}
+
+func transferConditions(f models.TransferFilter) (string, []any) {
+	conds := []string{"TRUE"}
+	var args []any
+	add := func(cond string, arg any) {
+		args = append(args, arg)
+		conds = append(conds, fmt.Sprintf(cond, len(args)))
+	}
+	if f.Status != "" {
+		add("status = $%d", f.Status)
+	}
+	if f.WarehouseID != 0 {
+		add("$%d IN (source_warehouse_id, destination_warehouse_id)", f.WarehouseID)
+	}
+	return strings.Join(conds, " AND "), args
+}
+
+func (r *transferRepo) Create(ctx context.Context, t *models.Transfer) error {
+	db := conn(ctx, r.db)
+	err := db.QueryRowContext(ctx, `
+		INSERT INTO stock_transfers (source_warehouse_id, destination_warehouse_id, status, note, actor)
+		VALUES ($1, $2, $3, $4, $5) RETURNING id, created_at`,
+		t.SourceID, t.DestinationID, t.Status, t.Note, t.Actor).Scan(&t.ID, &t.CreatedAt)
+	if err != nil {
+		return err
+	}
+	for _, l := range t.Lines {
+		_, err := db.ExecContext(ctx,
+			`INSERT INTO stock_transfer_lines (transfer_id, product_id, variant_id, quantity) VALUES ($1, $2, $3, $4)`,
+			t.ID, l.ProductID, l.VariantID, l.Quantity)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *transferRepo) Finish(ctx context.Context, t *models.Transfer, status models.TransferStatus) error {
+	err := conn(ctx, r.db).QueryRowContext(ctx, `
+		UPDATE stock_transfers SET status = $2, finished_at = now()
+		WHERE id = $1 AND status = 'in_transit' RETURNING finished_at`, t.ID, status).Scan(&t.FinishedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrNotFound
+	}
+	if err != nil {
+		return err
+	}
+	t.Status = status
+	return nil
+}
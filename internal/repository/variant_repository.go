@@ -0,0 +1,201 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"product-test/internal/models"
+
+	"github.com/lib/pq"
+)
+
+type VariantRepository interface {
+	// LockProduct locks a live product for the rest of the transaction, so
+	// that its options and variants change one request at a time.
+	LockProduct(ctx context.Context, productID int) error
+	Options(ctx context.Context, productID int) ([]models.ProductOption, error)
+	ReplaceOptions(ctx context.Context, productID int, options []models.ProductOption) error
+	List(ctx context.Context, productID int) ([]models.Variant, error)
+	GetByID(ctx context.Context, productID, id int) (*models.Variant, error)
+	Create(ctx context.Context, v *models.Variant) error
+	Update(ctx context.Context, v *models.Variant) error
+	Delete(ctx context.Context, productID, id int) error
+	// ClaimSKU locks a SKU for the rest of the transaction and fails with a
+	// DuplicateError if a product already has it.
+	ClaimSKU(ctx context.Context, sku string) error
+}
+
+type variantRepo struct {
+	db *sql.DB
+}
+
+func NewVariantRepository(db *sql.DB) VariantRepository {
+	return &variantRepo{db: db}
+}
+
+const variantColumns = `id, product_id, sku, options, price, currency, attributes, created_at, updated_at`
+
+func (r *variantRepo) LockProduct(ctx context.Context, productID int) error {
+	var id int
+	err := conn(ctx, r.db).QueryRowContext(ctx,
+		`SELECT id FROM products WHERE id = $1 AND deleted_at IS NULL FOR UPDATE`, productID).Scan(&id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrNotFound
+	}
+	return err
+}
+
+func (r *variantRepo) Options(ctx context.Context, productID int) ([]models.ProductOption, error) {
+	rows, err := conn(ctx, r.db).QueryContext(ctx,
+		`SELECT name, "values" FROM product_options WHERE product_id = $1 ORDER BY position`, productID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var options []models.ProductOption
+	for rows.Next() {
+		var (
+			o      models.ProductOption
+			values pq.StringArray
+		)
+		if err := rows.Scan(&o.Name, &values); err != nil {
+			return nil, err
+		}
+		o.Values = values
+		options = append(options, o)
+	}
+	return options, rows.Err()
+}
+
+func (r *variantRepo) ReplaceOptions(ctx context.Context, productID int, options []models.ProductOption) error {
+	db := conn(ctx, r.db)
+	if _, err := db.ExecContext(ctx, `DELETE FROM product_options WHERE product_id = $1`, productID); err != nil {
+		return err
+	}
+	for i, o := range options {
+		_, err := db.ExecContext(ctx,
+			`INSERT INTO product_options (product_id, name, position, "values") VALUES ($1, $2, $3, $4)`,
+			productID, o.Name, i, pq.StringArray(o.Values))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanVariant(row rowScanner) (*models.Variant, error) {
+	var (
+		v        models.Variant
+		amount   sql.NullInt64
+		currency sql.NullString
+	)
+	err := row.Scan(&v.ID, &v.ProductID, &v.SKU, &v.Options, &amount, &currency, &v.Attributes, &v.CreatedAt, &v.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	if amount.Valid {
+		v.Price = &models.Money{Amount: amount.Int64, Currency: currency.String}
+	}
+	return &v, nil
+}
+
+// priceArgs returns the price and currency columns of an optional price.
+func priceArgs(m *models.Money) (any, any) {
+	if m == nil {
+		return nil, nil
+	}
+	return m.Amount, m.Currency
+}
+
+func (r *variantRepo) List(ctx context.Context, productID int) ([]models.Variant, error) {
+	rows, err := conn(ctx, r.db).QueryContext(ctx,
+		`SELECT `+variantColumns+` FROM product_variants WHERE product_id = $1 ORDER BY id`, productID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var variants []models.Variant
+	for rows.Next() {
+		v, err := scanVariant(rows)
+		if err != nil {
+			return nil, err
+		}
+		variants = append(variants, *v)
+	}
+	return variants, rows.Err()
+}
+
+func (r *variantRepo) GetByID(ctx context.Context, productID, id int) (*models.Variant, error) {
+	v, err := scanVariant(conn(ctx, r.db).QueryRowContext(ctx,
+		`SELECT `+variantColumns+` FROM product_variants WHERE id = $1 AND product_id = $2`, id, productID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	return v, err
+}
+
+func (r *variantRepo) Create(ctx context.Context, v *models.Variant) error {
+	price, currency := priceArgs(v.Price)
+	err := conn(ctx, r.db).QueryRowContext(ctx, `
+		INSERT INTO product_variants (product_id, sku, options, price, currency, attributes)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, created_at, updated_at`,
+		v.ProductID, v.SKU, v.Options, price, currency, v.Attributes).Scan(&v.ID, &v.CreatedAt, &v.UpdatedAt)
+	return uniqueViolation(err)
+}
+
+func (r *variantRepo) Update(ctx context.Context, v *models.Variant) error {
+	price, currency := priceArgs(v.Price)
+	err := conn(ctx, r.db).QueryRowContext(ctx, `
+		UPDATE product_variants
+		SET sku = $1, options = $2, price = $3, currency = $4, attributes = $5, updated_at = now()
+		WHERE id = $6 AND product_id = $7
+		RETURNING created_at, updated_at`,
+		v.SKU, v.Options, price, currency, v.Attributes, v.ID, v.ProductID).Scan(&v.CreatedAt, &v.UpdatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrNotFound
+	}
+	return uniqueViolation(err)
+}
+
+func (r *variantRepo) Delete(ctx context.Context, productID, id int) error {
+	res, err := conn(ctx, r.db).ExecContext(ctx,
+		`DELETE FROM product_variants WHERE id = $1 AND product_id = $2`, id, productID)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// lockSKU serialises writes of one SKU, which products and variants share,
+// for the rest of the transaction; the unique indexes only cover one table
+// each.
+func lockSKU(ctx context.Context, db DBTX, sku string) error {
+	_, err := db.ExecContext(ctx, `SELECT pg_advisory_xact_lock(hashtext('sku'), hashtext($1))`, sku)
+	return err
+}
+
+func (r *variantRepo) ClaimSKU(ctx context.Context, sku string) error {
+	db := conn(ctx, r.db)
+	if err := lockSKU(ctx, db, sku); err != nil {
+		return err
+	}
+	var taken bool
+	err := db.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM products WHERE sku = $1)`, sku).Scan(&taken)
+	if err != nil {
+		return err
+	}
+	if taken {
+		return &DuplicateError{Column: "sku"}
+	}
+	return nil
+}
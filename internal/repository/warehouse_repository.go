@@ -0,0 +1,133 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"product-test/internal/models"
+)
+
+// ErrWarehouseInUse is returned when deleting a warehouse that has stock
+// movements, transfers or reservations.
+var ErrWarehouseInUse = errors.New("warehouse has stock history")
+
+type WarehouseRepository interface {
+	List(ctx context.Context) ([]models.Warehouse, error)
+	GetByID(ctx context.Context, id int) (*models.Warehouse, error)
+	Default(ctx context.Context) (*models.Warehouse, error)
+	// Create and Update take the default role from the previous default
+	// warehouse when w.IsDefault is set; they must run within a
+	// transaction.
+	Create(ctx context.Context, w *models.Warehouse) error
+	Update(ctx context.Context, w *models.Warehouse) error
+	Delete(ctx context.Context, id int) error
+}
+
+type warehouseRepo struct {
+	db *sql.DB
+}
+
+func NewWarehouseRepository(db *sql.DB) WarehouseRepository {
+	return &warehouseRepo{db: db}
+}
+
+const warehouseSelect = `SELECT id, code, name, is_default, created_at FROM warehouses`
+
+func scanWarehouse(row rowScanner) (*models.Warehouse, error) {
+	var w models.Warehouse
+	err := row.Scan(&w.ID, &w.Code, &w.Name, &w.IsDefault, &w.CreatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &w, nil
+}
+
+func (r *warehouseRepo) List(ctx context.Context) ([]models.Warehouse, error) {
+	rows, err := conn(ctx, r.db).QueryContext(ctx, warehouseSelect+` ORDER BY id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var warehouses []models.Warehouse
+	for rows.Next() {
+		w, err := scanWarehouse(rows)
+		if err != nil {
+			return nil, err
+		}
+		warehouses = append(warehouses, *w)
+	}
+	return warehouses, rows.Err()
+}
+
+func (r *warehouseRepo) GetByID(ctx context.Context, id int) (*models.Warehouse, error) {
+	return scanWarehouse(conn(ctx, r.db).QueryRowContext(ctx, warehouseSelect+` WHERE id = $1`, id))
+}
+
+func (r *warehouseRepo) Default(ctx context.Context) (*models.Warehouse, error) {
+	return scanWarehouse(conn(ctx, r.db).QueryRowContext(ctx, warehouseSelect+` WHERE is_default`))
+}
+
+// clearDefault takes the default role from whichever warehouse has it.
+func clearDefault(ctx context.Context, db DBTX, w *models.Warehouse) error {
+	if !w.IsDefault {
+		return nil
+	}
+	_, err := db.ExecContext(ctx, `UPDATE warehouses SET is_default = false WHERE is_default AND id <> $1`, w.ID)
+	return err
+}
+
+func (r *warehouseRepo) Create(ctx context.Context, w *models.Warehouse) error {
+	db := conn(ctx, r.db)
+	if err := clearDefault(ctx, db, w); err != nil {
+		return err
+	}
+	err := db.QueryRowContext(ctx,
+		`INSERT INTO warehouses (code, name, is_default) VALUES ($1, $2, $3) RETURNING id, created_at`,
+		w.Code, w.Name, w.IsDefault).Scan(&w.ID, &w.CreatedAt)
+	return uniqueViolation(err)
+}
+
+func (r *warehouseRepo) Update(ctx context.Context, w *models.Warehouse) error {
+	db := conn(ctx, r.db)
+	if err := clearDefault(ctx, db, w); err != nil {
+		return err
+	}
+	res, err := db.ExecContext(ctx,
+		`UPDATE warehouses SET code = $2, name = $3, is_default = $4 WHERE id = $1`,
+		w.ID, w.Code, w.Name, w.IsDefault)
+	if err != nil {
+		return uniqueViolation(err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *warehouseRepo) Delete(ctx context.Context, id int) error {
+	db := conn(ctx, r.db)
+	var used bool
+	err := db.QueryRowContext(ctx, `
+		SELECT EXISTS (SELECT 1 FROM stock_movements WHERE warehouse_id = $1)
+			OR EXISTS (SELECT 1 FROM stock_transfers WHERE $1 IN (source_warehouse_id, destination_warehouse_id))
+			OR EXISTS (SELECT 1 FROM reservations WHERE warehouse_id = $1)`,
+		id).Scan(&used)
+	if err != nil {
+		return err
+	}
+	if used {
+		return ErrWarehouseInUse
+	}
+	res, err := db.ExecContext(ctx, `DELETE FROM warehouses WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
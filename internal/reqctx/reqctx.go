@@ -0,0 +1,87 @@
+// Package reqctx carries request metadata (request ID and acting user)
+// through contexts, from the HTTP layer down to the services.
+package reqctx
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+)
+
+const (
+	RequestIDHeader = "X-Request-ID"
+	// ActorHeader names the authenticated user. The API does not
+	// authenticate by itself; it expects the gateway in front of it to set
+	// this header and strip it from client requests.
+	ActorHeader = "X-Actor"
+
+	// SystemActor is reported for changes made by background jobs.
+	SystemActor    = "system"
+	AnonymousActor = "anonymous"
+
+	maxHeaderLength = 128
+)
+
+type (
+	requestIDKey struct{}
+	actorKey     struct{}
+)
+
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+func WithActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+// Actor returns the user the request acts for, or SystemActor outside of
+// HTTP requests.
+func Actor(ctx context.Context) string {
+	if actor, ok := ctx.Value(actorKey{}).(string); ok {
+		return actor
+	}
+	return SystemActor
+}
+
+// Middleware assigns every request an ID, reusing a sane X-Request-ID from
+// the client, echoes it in the response and records the actor.
+func Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(RequestIDHeader)
+		if !valid(id) {
+			id = newRequestID()
+		}
+		actor := r.Header.Get(ActorHeader)
+		if !valid(actor) {
+			actor = AnonymousActor
+		}
+		w.Header().Set(RequestIDHeader, id)
+		ctx := WithActor(WithRequestID(r.Context(), id), actor)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+func valid(v string) bool {
+	if v == "" || len(v) > maxHeaderLength {
+		return false
+	}
+	for _, c := range v {
+		if c < 0x21 || c > 0x7e {
+			return false
+		}
+	}
+	return true
+}
+
+func newRequestID() string {
+	var b [16]byte
+	_, _ = rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
@@ -0,0 +1,93 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"product-test/internal/models"
+	"product-test/internal/repository"
+)
+
+// maxNotifiedAlerts bounds the alerts delivered in one notification.
+const maxNotifiedAlerts = 100
+
+// AlertNotifier delivers alerts outside the API, such as to a webhook.
+type AlertNotifier interface {
+	Notify(ctx context.Context, alerts []models.Alert) error
+}
+
+type AlertService interface {
+	ListAlerts(ctx context.Context, filter models.AlertFilter) ([]models.Alert, int, error)
+	// CheckStock opens low-stock alerts for products at or below their
+	// reorder point, resolves those that recovered and delivers new ones
+	// to the notifier, if any.
+	CheckStock(ctx context.Context) (AlertRun, error)
+}
+
+// AlertRun counts what one stock check did.
+type AlertRun struct {
+	Raised   int64
+	Resolved int64
+	Notified int64
+}
+
+type alertService struct {
+	repo     repository.AlertRepository
+	notifier AlertNotifier
+}
+
+// NewAlertService returns an AlertService; notifier may be nil.
+func NewAlertService(repo repository.AlertRepository, notifier AlertNotifier) AlertService {
+	return &alertService{repo: repo, notifier: notifier}
+}
+
+func (s *alertService) ListAlerts(ctx context.Context, filter models.AlertFilter) ([]models.Alert, int, error) {
+	switch filter.Status {
+	case "", models.AlertOpen, models.AlertResolved:
+	default:
+		return nil, 0, fmt.Errorf("%w: status must be open or resolved", ErrValidation)
+	}
+	alerts, err := s.repo.List(ctx, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+	total, err := s.repo.Count(ctx, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+	return alerts, total, nil
+}
+
+func (s *alertService) CheckStock(ctx context.Context) (AlertRun, error) {
+	var run AlertRun
+	var err error
+	if run.Resolved, err = s.repo.Resolve(ctx); err != nil {
+		return run, fmt.Errorf("resolve alerts: %w", err)
+	}
+	if run.Raised, err = s.repo.Raise(ctx); err != nil {
+		return run, fmt.Errorf("raise alerts: %w", err)
+	}
+	if s.notifier == nil {
+		return run, nil
+	}
+	// Alerts the notifier failed to take are retried on the next check.
+	for {
+		alerts, err := s.repo.Unnotified(ctx, maxNotifiedAlerts)
+		if err != nil || len(alerts) == 0 {
+			return run, err
+		}
+		if err := s.notifier.Notify(ctx, alerts); err != nil {
+			return run, fmt.Errorf("notify alerts: %w", err)
+		}
+		ids := make([]int64, len(alerts))
+		for i, a := range alerts {
+			ids[i] = a.ID
+		}
+		if err := s.repo.MarkNotified(ctx, ids); err != nil {
+			return run, err
+		}
+		run.Notified += int64(len(alerts))
+		if len(alerts) < maxNotifiedAlerts {
+			return run, nil
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"product-test/internal/models"
+	"product-test/internal/repository"
+	"reflect"
+	"slices"
+	"time"
+)
+
+type AuditService interface {
+	ListAudit(ctx context.Context, filter models.AuditFilter) ([]models.AuditEntry, int, error)
+}
+
+type auditService struct {
+	repo repository.AuditRepository
+}
+
+func NewAuditService(repo repository.AuditRepository) AuditService {
+	return &auditService{repo: repo}
+}
+
+// ListAudit returns a page of entries, newest first, and the total count.
+func (s *auditService) ListAudit(ctx context.Context, filter models.AuditFilter) ([]models.AuditEntry, int, error) {
+	switch filter.Action {
+	case "", models.AuditCreate, models.AuditUpdate, models.AuditDelete, models.AuditRestore, models.AuditPurge:
+	default:
+		return nil, 0, fmt.Errorf("%w: unknown action %q", ErrValidation, filter.Action)
+	}
+	if !filter.From.IsZero() && !filter.To.IsZero() && !filter.From.Before(filter.To) {
+		return nil, 0, fmt.Errorf("%w: from must be before to", ErrValidation)
+	}
+	entries, err := s.repo.List(ctx, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+	total, err := s.repo.Count(ctx, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+	return entries, total, nil
+}
+
+// auditedFields are compared when recording a product change.
+var auditedFields = append(slices.DeleteFunc(slices.Clone(models.ProductFields), func(f string) bool {
+	return f == "id"
+}), "deleted_at")
+
+// diffProducts lists the fields that differ between two versions of a
+// product. A nil version stands for "did not exist".
+func diffProducts(before, after *models.Product) []models.FieldChange {
+	changes := []models.FieldChange{}
+	for _, f := range auditedFields {
+		var oldVal, newVal any
+		if before != nil {
+			oldVal = normalize(before.Field(f))
+		}
+		if after != nil {
+			newVal = normalize(after.Field(f))
+		}
+		if before != nil && after != nil && reflect.DeepEqual(oldVal, newVal) {
+			continue
+		}
+		if oldVal == nil && newVal == nil {
+			continue
+		}
+		changes = append(changes, models.FieldChange{Field: f, Old: oldVal, New: newVal})
+	}
+	return changes
+}
+
+// normalize makes field values comparable and JSON-friendly: nil pointers
+// become nil, timestamps are reduced to UTC instants, money is written as
+// "12.34 EUR" and attributes as a JSON object, so that they stay scalars
+// in every format.
+func normalize(v any) any {
+	switch v := v.(type) {
+	case models.Money:
+		return v.String()
+	case models.Attributes:
+		if len(v) == 0 {
+			return nil
+		}
+		return v.String()
+	case *time.Time:
+		if v == nil {
+			return nil
+		}
+		return v.UTC().Truncate(time.Microsecond)
+	}
+	return v
+}
@@ -0,0 +1,282 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"product-test/internal/attrschema"
+	"product-test/internal/models"
+	"product-test/internal/repository"
+	"slices"
+)
+
+const maxProductCategories = 50
+
+type CategoryService interface {
+	ListCategories(ctx context.Context) ([]models.Category, error)
+	GetCategory(ctx context.Context, id int) (*models.Category, error)
+	CreateCategory(ctx context.Context, in models.CategoryInput) (*models.Category, error)
+	// UpdateCategory renames a category and moves it, with its subtree,
+	// below another parent.
+	UpdateCategory(ctx context.Context, id int, in models.CategoryInput) (*models.Category, error)
+	DeleteCategory(ctx context.Context, id int) error
+
+	// The attribute schema of a category applies to the products whose
+	// primary category is the category or one of its subcategories
+	// without a schema of their own.
+	GetAttributeSchema(ctx context.Context, id int) (json.RawMessage, error)
+	SetAttributeSchema(ctx context.Context, id int, schema []byte) (json.RawMessage, error)
+	DeleteAttributeSchema(ctx context.Context, id int) error
+
+	GetProductCategories(ctx context.Context, productID int) ([]models.ProductCategory, error)
+	SetProductCategories(ctx context.Context, productID int, categories []models.ProductCategory) ([]models.ProductCategory, error)
+}
+
+type categoryService struct {
+	repo     repository.CategoryRepository
+	products ProductService
+	tx       repository.TxManager
+}
+
+func NewCategoryService(repo repository.CategoryRepository, products ProductService, tx repository.TxManager) CategoryService {
+	return &categoryService{repo: repo, products: products, tx: tx}
+}
+
+func (s *categoryService) ListCategories(ctx context.Context) ([]models.Category, error) {
+	return s.repo.List(ctx)
+}
+
+func (s *categoryService) GetCategory(ctx context.Context, id int) (*models.Category, error) {
+	c, err := s.repo.GetByID(ctx, id)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, ErrCategoryNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if c.Path, err = s.repo.Path(ctx, id); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// checkParent makes sure the parent of a category exists.
+func (s *categoryService) checkParent(ctx context.Context, parentID *int) error {
+	if parentID == nil {
+		return nil
+	}
+	_, err := s.repo.GetByID(ctx, *parentID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return fmt.Errorf("%w: parent category %d does not exist", ErrValidation, *parentID)
+	}
+	return err
+}
+
+// categoryError turns tree constraint violations into validation errors.
+func categoryError(err error, name string) error {
+	switch {
+	case errors.Is(err, repository.ErrDuplicate):
+		return fmt.Errorf("%w: a category named %q already exists at this level", ErrValidation, name)
+	case errors.Is(err, repository.ErrCategoryCycle):
+		return fmt.Errorf("%w: a category cannot be moved below itself or one of its subcategories", ErrValidation)
+	case errors.Is(err, repository.ErrCategoryNotEmpty):
+		return fmt.Errorf("%w: category has subcategories; move or delete them first", ErrValidation)
+	case errors.Is(err, repository.ErrNotFound):
+		return ErrCategoryNotFound
+	}
+	return err
+}
+
+func (s *categoryService) CreateCategory(ctx context.Context, in models.CategoryInput) (*models.Category, error) {
+	if err := validateName(in.Name); err != nil {
+		return nil, err
+	}
+	c := &models.Category{Name: in.Name, ParentID: in.ParentID}
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.checkParent(ctx, c.ParentID); err != nil {
+			return err
+		}
+		return s.repo.Create(ctx, c)
+	})
+	if err != nil {
+		return nil, categoryError(err, c.Name)
+	}
+	return s.GetCategory(ctx, c.ID)
+}
+
+func (s *categoryService) UpdateCategory(ctx context.Context, id int, in models.CategoryInput) (*models.Category, error) {
+	if err := validateName(in.Name); err != nil {
+		return nil, err
+	}
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		c, err := s.repo.GetByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		if err := s.checkParent(ctx, in.ParentID); err != nil {
+			return err
+		}
+		if !sameParent(c.ParentID, in.ParentID) {
+			if err := s.repo.Move(ctx, id, in.ParentID); err != nil {
+				return err
+			}
+		}
+		if c.Name != in.Name {
+			return s.repo.Rename(ctx, id, in.Name)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, categoryError(err, in.Name)
+	}
+	return s.GetCategory(ctx, id)
+}
+
+// checkAttributes validates the attributes of a product against the
+// schema that applies to its new primary category; like attribute
+// changes, a change of primary category must satisfy the schema.
+func (s *categoryService) checkAttributes(ctx context.Context, p *models.Product, primaryID int) error {
+	raw, err := s.repo.EffectiveSchema(ctx, primaryID)
+	if err != nil {
+		return err
+	}
+	schema, err := parseSchema(raw)
+	if err != nil {
+		return err
+	}
+	return validateAttributes(p.Attributes, schema)
+}
+
+func sameParent(a, b *int) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+// DeleteCategory removes a category without subcategories; its products
+// keep their other categories.
+func (s *categoryService) DeleteCategory(ctx context.Context, id int) error {
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		return s.repo.Delete(ctx, id)
+	})
+	return categoryError(err, "")
+}
+
+func (s *categoryService) GetAttributeSchema(ctx context.Context, id int) (json.RawMessage, error) {
+	schema, err := s.repo.AttributeSchema(ctx, id)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, ErrCategoryNotFound
+	}
+	if err == nil && schema == nil {
+		return nil, ErrSchemaNotFound
+	}
+	return schema, err
+}
+
+// SetAttributeSchema stores a schema for a category. Products already in
+// the category are checked against it the next time their attributes or
+// primary category change.
+func (s *categoryService) SetAttributeSchema(ctx context.Context, id int, schema []byte) (json.RawMessage, error) {
+	if _, err := attrschema.Parse(schema); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrValidation, err)
+	}
+	var compact bytes.Buffer
+	if err := json.Compact(&compact, schema); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrValidation, err)
+	}
+	if err := s.repo.SetAttributeSchema(ctx, id, compact.Bytes()); err != nil {
+		return nil, categoryError(err, "")
+	}
+	return compact.Bytes(), nil
+}
+
+func (s *categoryService) DeleteAttributeSchema(ctx context.Context, id int) error {
+	if _, err := s.GetAttributeSchema(ctx, id); err != nil {
+		return err
+	}
+	return categoryError(s.repo.SetAttributeSchema(ctx, id, nil), "")
+}
+
+func (s *categoryService) GetProductCategories(ctx context.Context, productID int) ([]models.ProductCategory, error) {
+	if _, err := s.products.GetProductByID(ctx, productID); err != nil {
+		return nil, err
+	}
+	categories, err := s.repo.GetProductCategories(ctx, productID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range categories {
+		if categories[i].Path, err = s.repo.Path(ctx, categories[i].ID); err != nil {
+			return nil, err
+		}
+	}
+	if categories == nil {
+		categories = []models.ProductCategory{}
+	}
+	return categories, nil
+}
+
+// SetProductCategories replaces the categories of a product. Exactly one of
+// them is primary; when none is marked, the first one is.
+func (s *categoryService) SetProductCategories(ctx context.Context, productID int, categories []models.ProductCategory) ([]models.ProductCategory, error) {
+	if len(categories) > maxProductCategories {
+		return nil, fmt.Errorf("%w: a product can be in at most %d categories", ErrValidation, maxProductCategories)
+	}
+	assigned := make([]models.ProductCategory, len(categories))
+	seen := make(map[int]bool, len(categories))
+	primary, marked := 0, false
+	for i, c := range categories {
+		if seen[c.ID] {
+			return nil, fmt.Errorf("%w: category %d is listed twice", ErrValidation, c.ID)
+		}
+		seen[c.ID] = true
+		if c.IsPrimary {
+			if marked {
+				return nil, fmt.Errorf("%w: only one category can be primary", ErrValidation)
+			}
+			primary, marked = i, true
+		}
+		assigned[i] = models.ProductCategory{ID: c.ID, IsPrimary: c.IsPrimary}
+	}
+	if len(assigned) > 0 {
+		assigned[primary].IsPrimary = true
+	}
+
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		p, err := s.products.GetProductByID(ctx, productID)
+		if err != nil {
+			return err
+		}
+		for _, c := range assigned {
+			_, err := s.repo.GetByID(ctx, c.ID)
+			if errors.Is(err, repository.ErrNotFound) {
+				return fmt.Errorf("%w: category %d does not exist", ErrValidation, c.ID)
+			}
+			if err != nil {
+				return err
+			}
+		}
+		if len(assigned) > 0 {
+			current, err := s.repo.GetProductCategories(ctx, productID)
+			if err != nil {
+				return err
+			}
+			unchanged := slices.ContainsFunc(current, func(c models.ProductCategory) bool {
+				return c.IsPrimary && c.ID == assigned[primary].ID
+			})
+			if !unchanged {
+				if err := s.checkAttributes(ctx, p, assigned[primary].ID); err != nil {
+					return err
+				}
+			}
+		}
+		return s.repo.ReplaceProductCategories(ctx, productID, assigned)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.GetProductCategories(ctx, productID)
+}
@@ -1,8 +1,63 @@
 package service
 
-import "errors"
+import (
+	"errors"
+	"product-test/internal/models"
+)
 
 var (
 	ErrNotFound    = errors.New("product not found")
 	ErrValidation  = errors.New("validation error")
+	ErrConflict    = errors.New("conflict")
+
+	ErrRevisionNotFound = errors.New("revision not found")
+	ErrNoPrice          = errors.New("no price in effect")
+	ErrCurrencyMismatch = errors.New("price is not in the currency of the product")
+
+	ErrScheduleNotFound = errors.New("price schedule not found")
+	ErrScheduleState    = errors.New("price schedule cannot be changed")
+
+	ErrRateNotFound     = errors.New("exchange rate not found")
+	ErrRoundingNotFound = errors.New("rounding rule not found")
+	ErrNoRate           = errors.New("no exchange rate")
+
+	ErrPriceListNotFound = errors.New("price list not found")
+	ErrGroupNotFound     = errors.New("customer group not found")
+
+	ErrCategoryNotFound = errors.New("category not found")
+	ErrSchemaNotFound   = errors.New("attribute schema not found")
+
+	ErrVariantNotFound = errors.New("variant not found")
+
+	ErrInsufficientStock = errors.New("insufficient stock")
+	ErrExpiredLot        = errors.New("lot has expired")
+
+	ErrWarehouseNotFound = errors.New("warehouse not found")
+	ErrTransferNotFound  = errors.New("transfer not found")
+	ErrTransferState     = errors.New("transfer is no longer in transit")
+
+	ErrReservationNotFound = errors.New("reservation not found")
+	ErrReservationState    = errors.New("reservation is no longer active")
+
+	ErrSupplierNotFound        = errors.New("supplier not found")
+	ErrSupplierProductNotFound = errors.New("product is not supplied by this supplier")
+	ErrPurchaseOrderNotFound   = errors.New("purchase order not found")
+	ErrPurchaseOrderState      = errors.New("purchase order cannot be changed in its status")
+
+	ErrOrderNotFound = errors.New("order not found")
+	ErrOrderState    = errors.New("order cannot move to that status")
 )
+
+// ValidationError is an ErrValidation that points at the offending fields.
+type ValidationError struct {
+	Message string
+	Fields  []models.FieldError
+}
+
+func (e *ValidationError) Error() string {
+	return ErrValidation.Error() + ": " + e.Message
+}
+
+func (e *ValidationError) Unwrap() error {
+	return ErrValidation
+}
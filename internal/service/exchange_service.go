@@ -0,0 +1,353 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"product-test/internal/models"
+	"product-test/internal/repository"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+)
+
+// maxRateDigits matches the scale of the exchange_rates.rate column.
+const maxRateDigits = 12
+
+var rateFormat = regexp.MustCompile(`^[0-9]{1,12}(\.[0-9]{1,12})?$`)
+
+type ExchangeService interface {
+	ListRates(ctx context.Context) ([]models.ExchangeRate, error)
+	SetRate(ctx context.Context, base, quote, rate string) (*models.ExchangeRate, error)
+	DeleteRate(ctx context.Context, base, quote string) error
+	ImportRates(ctx context.Context, rates []models.ExchangeRate) (*models.RateImport, error)
+	ListRoundingRules(ctx context.Context) ([]models.RoundingRule, error)
+	SetRoundingRule(ctx context.Context, currency string, in models.RoundingRuleInput) (*models.RoundingRule, error)
+	DeleteRoundingRule(ctx context.Context, currency string) error
+	// ConvertPrices sets the converted price of each product: its effective
+	// price, or its price if that is not resolved, expressed in currency.
+	// A product that cannot be converted, for want of a rate, keeps none.
+	ConvertPrices(ctx context.Context, products []models.Product, currency string) error
+}
+
+type exchangeService struct {
+	repo repository.ExchangeRepository
+	tx   repository.TxManager
+}
+
+func NewExchangeService(repo repository.ExchangeRepository, tx repository.TxManager) ExchangeService {
+	return &exchangeService{repo: repo, tx: tx}
+}
+
+func (s *exchangeService) ListRates(ctx context.Context) ([]models.ExchangeRate, error) {
+	return s.repo.ListRates(ctx)
+}
+
+func (s *exchangeService) SetRate(ctx context.Context, base, quote, rate string) (*models.ExchangeRate, error) {
+	r := &models.ExchangeRate{
+		Base:      strings.ToUpper(base),
+		Quote:     strings.ToUpper(quote),
+		Rate:      strings.TrimSpace(rate),
+		Source:    "manual",
+		UpdatedAt: time.Now().UTC(),
+	}
+	if err := validateRate(r); err != nil {
+		return nil, err
+	}
+	if err := s.repo.UpsertRate(ctx, r); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func validateCurrency(code string) error {
+	if _, ok := models.CurrencyExponent(code); !ok {
+		return fmt.Errorf("%w: unsupported currency %q", ErrValidation, code)
+	}
+	return nil
+}
+
+func validateRate(r *models.ExchangeRate) error {
+	if err := validateCurrency(r.Base); err != nil {
+		return err
+	}
+	if err := validateCurrency(r.Quote); err != nil {
+		return err
+	}
+	if r.Base == r.Quote {
+		return fmt.Errorf("%w: base and quote currency must differ", ErrValidation)
+	}
+	if !rateFormat.MatchString(r.Rate) {
+		return fmt.Errorf("%w: rate %s/%s must be a decimal number with at most %d digits before and after the point, got %q",
+			ErrValidation, r.Base, r.Quote, maxRateDigits, r.Rate)
+	}
+	if rat, _ := new(big.Rat).SetString(r.Rate); rat.Sign() <= 0 {
+		return fmt.Errorf("%w: rate %s/%s must be positive", ErrValidation, r.Base, r.Quote)
+	}
+	return nil
+}
+
+func (s *exchangeService) DeleteRate(ctx context.Context, base, quote string) error {
+	err := s.repo.DeleteRate(ctx, strings.ToUpper(base), strings.ToUpper(quote))
+	if errors.Is(err, repository.ErrNotFound) {
+		return ErrRateNotFound
+	}
+	return err
+}
+
+// ImportRates stores a batch of rates in one transaction. Rates involving
+// currencies the catalog does not support are skipped and reported; any
+// other invalid rate rejects the whole batch.
+func (s *exchangeService) ImportRates(ctx context.Context, rates []models.ExchangeRate) (*models.RateImport, error) {
+	result := &models.RateImport{Skipped: []string{}}
+	var valid []models.ExchangeRate
+	for _, r := range rates {
+		r.Rate = strings.TrimSpace(r.Rate)
+		skipped := false
+		for _, code := range []string{r.Base, r.Quote} {
+			if _, ok := models.CurrencyExponent(code); !ok {
+				skipped = true
+				if !slices.Contains(result.Skipped, code) {
+					result.Skipped = append(result.Skipped, code)
+				}
+			}
+		}
+		if skipped {
+			continue
+		}
+		if err := validateRate(&r); err != nil {
+			return nil, err
+		}
+		valid = append(valid, r)
+	}
+	if len(valid) == 0 {
+		return nil, fmt.Errorf("%w: the file contains no rates for supported currencies", ErrValidation)
+	}
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		for i := range valid {
+			if err := s.repo.UpsertRate(ctx, &valid[i]); err != nil {
+				return fmt.Errorf("store rate %s/%s: %w", valid[i].Base, valid[i].Quote, err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	result.Imported = len(valid)
+	return result, nil
+}
+
+var roundingModes = []models.RoundingMode{models.RoundHalfUp, models.RoundHalfEven, models.RoundDown, models.RoundUp}
+
+func (s *exchangeService) ListRoundingRules(ctx context.Context) ([]models.RoundingRule, error) {
+	return s.repo.ListRounding(ctx)
+}
+
+func (s *exchangeService) SetRoundingRule(ctx context.Context, currency string, in models.RoundingRuleInput) (*models.RoundingRule, error) {
+	currency = strings.ToUpper(currency)
+	if err := validateCurrency(currency); err != nil {
+		return nil, err
+	}
+	if !slices.Contains(roundingModes, in.Mode) {
+		return nil, fmt.Errorf("%w: mode must be one of half_up, half_even, down, up", ErrValidation)
+	}
+	rule := &models.RoundingRule{Currency: currency, Mode: in.Mode, Increment: models.Money{Amount: 1, Currency: currency}}
+	if in.Increment != "" {
+		inc, err := models.ParseMoney(in.Increment, currency)
+		if err != nil {
+			return nil, fmt.Errorf("%w: increment: %v", ErrValidation, err)
+		}
+		if inc.Amount <= 0 {
+			return nil, fmt.Errorf("%w: increment must be positive", ErrValidation)
+		}
+		rule.Increment = inc
+	}
+	if err := s.repo.UpsertRounding(ctx, rule); err != nil {
+		return nil, err
+	}
+	return rule, nil
+}
+
+func (s *exchangeService) DeleteRoundingRule(ctx context.Context, currency string) error {
+	err := s.repo.DeleteRounding(ctx, strings.ToUpper(currency))
+	if errors.Is(err, repository.ErrNotFound) {
+		return ErrRoundingNotFound
+	}
+	return err
+}
+
+func (s *exchangeService) ConvertPrices(ctx context.Context, products []models.Product, currency string) error {
+	currency = strings.ToUpper(currency)
+	if err := validateCurrency(currency); err != nil {
+		return err
+	}
+	rates, err := s.repo.ListRates(ctx)
+	if err != nil {
+		return err
+	}
+	rule, err := s.repo.GetRounding(ctx, currency)
+	if errors.Is(err, repository.ErrNotFound) {
+		rule = &models.RoundingRule{Currency: currency, Mode: models.RoundHalfUp, Increment: models.Money{Amount: 1, Currency: currency}}
+	} else if err != nil {
+		return err
+	}
+	table := newRateTable(rates)
+	for i := range products {
+		price := products[i].Price
+		if products[i].EffectivePrice != nil {
+			price = *products[i].EffectivePrice
+		}
+		// The error only concerns this product, so the others are still
+		// converted.
+		products[i].ConvertedPrice, _ = table.convert(price, currency, rule)
+	}
+	return nil
+}
+
+type tableRate struct {
+	rate *big.Rat
+	at   time.Time
+}
+
+// rateTable finds the rate between two currencies from the stored pairs:
+// directly, inverted, or across a third currency both are quoted against,
+// as with the EUR-based ECB rates.
+type rateTable struct {
+	pairs      map[[2]string]tableRate
+	currencies []string
+}
+
+func newRateTable(rates []models.ExchangeRate) *rateTable {
+	t := &rateTable{pairs: make(map[[2]string]tableRate)}
+	for _, r := range rates {
+		rat, ok := new(big.Rat).SetString(r.Rate)
+		if !ok || rat.Sign() <= 0 {
+			continue
+		}
+		t.pairs[[2]string{r.Base, r.Quote}] = tableRate{rate: rat, at: r.UpdatedAt}
+		for _, c := range []string{r.Base, r.Quote} {
+			if !slices.Contains(t.currencies, c) {
+				t.currencies = append(t.currencies, c)
+			}
+		}
+	}
+	slices.Sort(t.currencies)
+	return t
+}
+
+func (t *rateTable) direct(from, to string) (tableRate, bool) {
+	if r, ok := t.pairs[[2]string{from, to}]; ok {
+		return r, true
+	}
+	if r, ok := t.pairs[[2]string{to, from}]; ok {
+		return tableRate{rate: new(big.Rat).Inv(r.rate), at: r.at}, true
+	}
+	return tableRate{}, false
+}
+
+// lookup prefers a direct rate. Among cross rates it takes the one whose
+// older leg is the most recent.
+func (t *rateTable) lookup(from, to string) (tableRate, bool) {
+	if r, ok := t.direct(from, to); ok {
+		return r, true
+	}
+	var best tableRate
+	found := false
+	for _, via := range t.currencies {
+		if via == from || via == to {
+			continue
+		}
+		a, ok := t.direct(from, via)
+		if !ok {
+			continue
+		}
+		b, ok := t.direct(via, to)
+		if !ok {
+			continue
+		}
+		at := a.at
+		if b.at.Before(at) {
+			at = b.at
+		}
+		if !found || at.After(best.at) {
+			best, found = tableRate{rate: new(big.Rat).Mul(a.rate, b.rate), at: at}, true
+		}
+	}
+	return best, found
+}
+
+func (t *rateTable) convert(m models.Money, to string, rule *models.RoundingRule) (*models.ConvertedPrice, error) {
+	if m.Currency == to {
+		return &models.ConvertedPrice{Price: m, Rate: "1"}, nil
+	}
+	r, ok := t.lookup(m.Currency, to)
+	if !ok {
+		return nil, fmt.Errorf("%w from %s to %s", ErrNoRate, m.Currency, to)
+	}
+	fromExp, _ := models.CurrencyExponent(m.Currency)
+	toExp, _ := models.CurrencyExponent(to)
+
+	// amount / 10^fromExp * rate * 10^toExp, in steps of the increment.
+	x := new(big.Rat).SetInt64(m.Amount)
+	x.Mul(x, r.rate)
+	x.Mul(x, new(big.Rat).SetFrac(pow10(toExp), pow10(fromExp)))
+	x.Quo(x, new(big.Rat).SetInt64(rule.Increment.Amount))
+	steps, ok := roundRat(x, rule.Mode)
+	if !ok || !steps.IsInt64() {
+		return nil, fmt.Errorf("converting %s to %s: amount out of range", m, to)
+	}
+	amount := new(big.Int).Mul(steps, big.NewInt(rule.Increment.Amount))
+	if !amount.IsInt64() {
+		return nil, fmt.Errorf("converting %s to %s: amount out of range", m, to)
+	}
+	at := r.at
+	return &models.ConvertedPrice{
+		Price:  models.Money{Amount: amount.Int64(), Currency: to},
+		Rate:   formatRate(r.rate),
+		RateAt: &at,
+	}, nil
+}
+
+func pow10(n int) *big.Int {
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(n)), nil)
+}
+
+// roundRat rounds x to an integer using mode.
+func roundRat(x *big.Rat, mode models.RoundingMode) (*big.Int, bool) {
+	q, r := new(big.Int).QuoRem(x.Num(), x.Denom(), new(big.Int))
+	if r.Sign() == 0 {
+		return q, true
+	}
+	// Compare the dropped fraction with one half.
+	half := new(big.Int).Lsh(new(big.Int).Abs(r), 1).Cmp(x.Denom())
+	var away bool
+	switch mode {
+	case models.RoundDown:
+		away = false
+	case models.RoundUp:
+		away = true
+	case models.RoundHalfUp:
+		away = half >= 0
+	case models.RoundHalfEven:
+		away = half > 0 || (half == 0 && new(big.Int).Abs(q).Bit(0) == 1)
+	default:
+		return nil, false
+	}
+	if away {
+		if x.Sign() < 0 {
+			q.Sub(q, big.NewInt(1))
+		} else {
+			q.Add(q, big.NewInt(1))
+		}
+	}
+	return q, true
+}
+
+// formatRate writes a rate with up to maxRateDigits decimal places.
+func formatRate(r *big.Rat) string {
+	s := r.FloatString(maxRateDigits)
+	return strings.TrimSuffix(strings.TrimRight(s, "0"), ".")
+}
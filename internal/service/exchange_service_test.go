@@ -0,0 +1,207 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"math"
+	"math/big"
+	"product-test/internal/models"
+	"product-test/internal/repository"
+	"testing"
+	"time"
+)
+
+func rat(t *testing.T, s string) *big.Rat {
+	t.Helper()
+	r, ok := new(big.Rat).SetString(s)
+	if !ok {
+		t.Fatalf("bad rational %q", s)
+	}
+	return r
+}
+
+func TestRoundRat(t *testing.T) {
+	tests := []struct {
+		x                          string
+		halfUp, halfEven, down, up int64
+	}{
+		{"4", 4, 4, 4, 4},
+		{"2.1", 2, 2, 2, 3},
+		{"2.5", 3, 2, 2, 3},
+		{"3.5", 4, 4, 3, 4},
+		{"2.9", 3, 3, 2, 3},
+		{"-2.1", -2, -2, -2, -3},
+		{"-2.5", -3, -2, -2, -3},
+		{"-3.5", -4, -4, -3, -4},
+		{"1/3", 0, 0, 0, 1},
+		{"0.5", 1, 0, 0, 1},
+	}
+	for _, tt := range tests {
+		for mode, want := range map[models.RoundingMode]int64{
+			models.RoundHalfUp:   tt.halfUp,
+			models.RoundHalfEven: tt.halfEven,
+			models.RoundDown:     tt.down,
+			models.RoundUp:       tt.up,
+		} {
+			got, ok := roundRat(rat(t, tt.x), mode)
+			if !ok || got.Int64() != want {
+				t.Errorf("roundRat(%s, %s) = %v, %v; want %d", tt.x, mode, got, ok, want)
+			}
+		}
+	}
+	if _, ok := roundRat(rat(t, "2.5"), "sideways"); ok {
+		t.Error("roundRat accepted an unknown mode")
+	}
+}
+
+var (
+	jan1 = time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	jan5 = time.Date(2026, 1, 5, 0, 0, 0, 0, time.UTC)
+	jan9 = time.Date(2026, 1, 9, 0, 0, 0, 0, time.UTC)
+)
+
+func TestRateTableLookup(t *testing.T) {
+	table := newRateTable([]models.ExchangeRate{
+		{Base: "EUR", Quote: "USD", Rate: "1.1", UpdatedAt: jan5},
+		{Base: "EUR", Quote: "GBP", Rate: "0.85", UpdatedAt: jan9},
+		{Base: "USD", Quote: "JPY", Rate: "150", UpdatedAt: jan9},
+		{Base: "EUR", Quote: "CHF", Rate: "0.95", UpdatedAt: jan1},
+		{Base: "GBP", Quote: "CHF", Rate: "1.1", UpdatedAt: jan9},
+		{Base: "EUR", Quote: "NOK", Rate: "11.5", UpdatedAt: jan1},
+		{Base: "GBP", Quote: "NOK", Rate: "13.2", UpdatedAt: jan9},
+		{Base: "EUR", Quote: "SEK", Rate: "0", UpdatedAt: jan9},
+	})
+	tests := []struct {
+		name, from, to string
+		rate           string
+		at             time.Time
+	}{
+		{"direct", "EUR", "USD", "1.1", jan5},
+		{"inverse", "USD", "EUR", "10/11", jan5},
+		{"cross through the common base", "USD", "GBP", "17/22", jan5},
+		{"cross through a quote", "EUR", "JPY", "165", jan5},
+		{"cross with the freshest older leg", "CHF", "NOK", "12", jan9},
+		{"direct beats a fresher cross", "EUR", "CHF", "0.95", jan1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, ok := table.lookup(tt.from, tt.to)
+			if !ok {
+				t.Fatalf("lookup(%s, %s) found nothing", tt.from, tt.to)
+			}
+			if r.rate.Cmp(rat(t, tt.rate)) != 0 {
+				t.Errorf("lookup(%s, %s) rate = %s, want %s", tt.from, tt.to, r.rate.RatString(), tt.rate)
+			}
+			if !r.at.Equal(tt.at) {
+				t.Errorf("lookup(%s, %s) at = %s, want %s", tt.from, tt.to, r.at, tt.at)
+			}
+		})
+	}
+	for _, pair := range [][2]string{{"JPY", "GBP"}, {"EUR", "SEK"}, {"EUR", "KWD"}} {
+		if r, ok := table.lookup(pair[0], pair[1]); ok {
+			t.Errorf("lookup(%s, %s) = %s, want none", pair[0], pair[1], r.rate.RatString())
+		}
+	}
+}
+
+func TestRateTableConvert(t *testing.T) {
+	table := newRateTable([]models.ExchangeRate{
+		{Base: "EUR", Quote: "USD", Rate: "1.1", UpdatedAt: jan5},
+		{Base: "EUR", Quote: "JPY", Rate: "161.235", UpdatedAt: jan5},
+		{Base: "EUR", Quote: "KWD", Rate: "0.3345", UpdatedAt: jan5},
+		{Base: "KWD", Quote: "JPY", Rate: "488.5", UpdatedAt: jan5},
+		{Base: "EUR", Quote: "CHF", Rate: "0.9537", UpdatedAt: jan5},
+		{Base: "USD", Quote: "CHF", Rate: "0.9525", UpdatedAt: jan5},
+	})
+	rule := func(currency string, mode models.RoundingMode, increment int64) *models.RoundingRule {
+		return &models.RoundingRule{Currency: currency, Mode: mode, Increment: models.Money{Amount: increment, Currency: currency}}
+	}
+	tests := []struct {
+		name   string
+		from   models.Money
+		rule   *models.RoundingRule
+		amount int64
+		rate   string
+	}{
+		{"same currency", models.Money{Amount: 1999, Currency: "EUR"}, rule("EUR", models.RoundHalfUp, 1), 1999, "1"},
+		{"cents to cents", models.Money{Amount: 1000, Currency: "EUR"}, rule("USD", models.RoundHalfUp, 1), 1100, "1.1"},
+		{"inverse", models.Money{Amount: 1100, Currency: "USD"}, rule("EUR", models.RoundHalfUp, 1), 1000, "0.909090909091"},
+		{"to JPY, exponent 0", models.Money{Amount: 1999, Currency: "EUR"}, rule("JPY", models.RoundHalfUp, 1), 3223, "161.235"},
+		{"to JPY rounding up", models.Money{Amount: 1999, Currency: "EUR"}, rule("JPY", models.RoundUp, 1), 3224, "161.235"},
+		{"to KWD, exponent 3", models.Money{Amount: 1000, Currency: "EUR"}, rule("KWD", models.RoundHalfUp, 1), 3345, "0.3345"},
+		{"JPY to KWD", models.Money{Amount: 1000, Currency: "JPY"}, rule("KWD", models.RoundHalfUp, 1), 2047, "0.002047082907"},
+		{"CHF 0.05 half up", models.Money{Amount: 1000, Currency: "EUR"}, rule("CHF", models.RoundHalfUp, 5), 955, "0.9537"},
+		{"CHF 0.05 down", models.Money{Amount: 1000, Currency: "EUR"}, rule("CHF", models.RoundDown, 5), 950, "0.9537"},
+		{"CHF 0.05 tie half up", models.Money{Amount: 1000, Currency: "USD"}, rule("CHF", models.RoundHalfUp, 5), 955, "0.9525"},
+		{"CHF 0.05 tie half even", models.Money{Amount: 1000, Currency: "USD"}, rule("CHF", models.RoundHalfEven, 5), 950, "0.9525"},
+		{"whole francs", models.Money{Amount: 1000, Currency: "EUR"}, rule("CHF", models.RoundHalfUp, 100), 1000, "0.9537"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := table.convert(tt.from, tt.rule.Currency, tt.rule)
+			if err != nil {
+				t.Fatalf("convert(%s, %s): %v", tt.from, tt.rule.Currency, err)
+			}
+			want := models.Money{Amount: tt.amount, Currency: tt.rule.Currency}
+			if got.Price != want || got.Rate != tt.rate {
+				t.Errorf("convert(%s, %s) = %s at %s, want %s at %s", tt.from, tt.rule.Currency, got.Price, got.Rate, want, tt.rate)
+			}
+			if (got.RateAt == nil) != (tt.from.Currency == tt.rule.Currency) {
+				t.Errorf("convert(%s, %s) rate_at = %v", tt.from, tt.rule.Currency, got.RateAt)
+			}
+		})
+	}
+
+	if _, err := table.convert(models.Money{Amount: 100, Currency: "GBP"}, "USD", rule("USD", models.RoundHalfUp, 1)); !errors.Is(err, ErrNoRate) {
+		t.Errorf("convert without a rate = %v, want ErrNoRate", err)
+	}
+	if _, err := table.convert(models.Money{Amount: math.MaxInt64, Currency: "EUR"}, "JPY", rule("JPY", models.RoundHalfUp, 1)); err == nil {
+		t.Error("convert of an amount out of range succeeded")
+	}
+}
+
+// exchangeRepoStub serves fixed rates; ConvertPrices needs nothing else.
+type exchangeRepoStub struct {
+	repository.ExchangeRepository
+	rates []models.ExchangeRate
+}
+
+func (s exchangeRepoStub) ListRates(context.Context) ([]models.ExchangeRate, error) {
+	return s.rates, nil
+}
+
+func (s exchangeRepoStub) GetRounding(context.Context, string) (*models.RoundingRule, error) {
+	return nil, repository.ErrNotFound
+}
+
+func TestConvertPricesSkipsProductsWithoutRate(t *testing.T) {
+	svc := NewExchangeService(exchangeRepoStub{rates: []models.ExchangeRate{
+		{Base: "EUR", Quote: "USD", Rate: "1.1", UpdatedAt: jan5},
+	}}, nil)
+	sale := models.Money{Amount: 500, Currency: "EUR"}
+	products := []models.Product{
+		{ID: 1, Price: models.Money{Amount: 1000, Currency: "EUR"}},
+		{ID: 2, Price: models.Money{Amount: 1000, Currency: "GBP"}},
+		{ID: 3, Price: models.Money{Amount: 1000, Currency: "EUR"}, EffectivePrice: &sale},
+		{ID: 4, Price: models.Money{Amount: 250, Currency: "USD"}},
+	}
+	if err := svc.ConvertPrices(context.Background(), products, "usd"); err != nil {
+		t.Fatalf("ConvertPrices: %v", err)
+	}
+	want := map[int]int64{1: 1100, 3: 550, 4: 250}
+	for _, p := range products {
+		amount, ok := want[p.ID]
+		switch {
+		case !ok && p.ConvertedPrice != nil:
+			t.Errorf("product %d converted to %s without a rate", p.ID, p.ConvertedPrice)
+		case ok && p.ConvertedPrice == nil:
+			t.Errorf("product %d not converted", p.ID)
+		case ok && p.ConvertedPrice.Price != (models.Money{Amount: amount, Currency: "USD"}):
+			t.Errorf("product %d converted to %s, want %d cents", p.ID, p.ConvertedPrice, amount)
+		}
+	}
+
+	if err := svc.ConvertPrices(context.Background(), products, "XXX"); !errors.Is(err, ErrValidation) {
+		t.Errorf("ConvertPrices to an unknown currency = %v, want ErrValidation", err)
+	}
+}
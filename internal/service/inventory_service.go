@@ -0,0 +1,617 @@
+package service
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"product-test/internal/models"
+	"product-test/internal/repository"
+	"product-test/internal/reqctx"
+	"slices"
+	"strings"
+	"time"
+)
+
+const (
+	maxMovementQuantity = 1_000_000_000
+	maxReferenceLength  = 200
+	maxNoteLength       = 2000
+	maxSuggestionDays   = 365
+	maxExpiringDays     = 365
+	maxLotLength        = 100
+)
+
+type InventoryService interface {
+	// GetStock returns the stock of a live product, or of one of its
+	// variants when the key names one.
+	GetStock(ctx context.Context, key models.StockKey) (*models.Stock, error)
+	SetStockSettings(ctx context.Context, key models.StockKey, in models.StockSettings) (*models.Stock, error)
+	// RecordMovement appends a movement to the ledger of a live product,
+	// or of the variant named in the input, in a warehouse, the default one
+	// if none is given. Stock can only go below zero for products and
+	// variants that allow backorders.
+	RecordMovement(ctx context.Context, productID int, in models.StockMovementInput) (*models.StockMovement, error)
+	ListMovements(ctx context.Context, filter models.MovementFilter) ([]models.StockMovement, int, error)
+	// Post appends movements made by other parts of the inventory, such as
+	// transfers, within the caller's transaction. It fills in their
+	// balances and fails with ErrInsufficientStock when outgoing stock
+	// would take more than is available, leaving reserved and expired stock
+	// alone; only sales and adjustments of items that allow backorders
+	// may do. Outgoing movements without lots take the first lots to
+	// expire, then untracked stock; only adjustments may take an expired
+	// lot, and only by naming it.
+	Post(ctx context.Context, movements []models.StockMovement) error
+	// MovementsByReference returns the movements posted with a reference,
+	// such as those of a transfer, in ledger order.
+	MovementsByReference(ctx context.Context, reference string) ([]models.StockMovement, error)
+	// Lock locks the inventory of products and variants until the end of
+	// the caller's transaction and returns their stock. Stock must only be
+	// checked and changed under this lock.
+	Lock(ctx context.Context, keys []models.StockKey) (map[models.StockKey]*models.Stock, error)
+	// SetCostPrice changes the cost price of a product or variant locked
+	// with Lock, within the caller's transaction.
+	SetCostPrice(ctx context.Context, key models.StockKey, cost models.Money) error
+	// GetAvailability returns the stock of a live product or variant across
+	// all warehouses, or in one when warehouseID is not zero.
+	GetAvailability(ctx context.Context, key models.StockKey, warehouseID int) (*models.Availability, error)
+	// ReorderSuggestions proposes reorders for the products and variants at
+	// or below their reorder point or without enough stock to cover
+	// coverDays of sales at the rate of the last salesDays, least covered
+	// first.
+	ReorderSuggestions(ctx context.Context, salesDays, coverDays int) ([]models.ReorderSuggestion, error)
+	// ListLots returns the lots of a live product or variant with stock, in
+	// all warehouses or in one when warehouseID is not zero.
+	ListLots(ctx context.Context, key models.StockKey, warehouseID int) ([]models.Lot, error)
+	// ExpiringLots returns the lots with stock that have expired or expire
+	// within the given number of days, first to expire first.
+	ExpiringLots(ctx context.Context, withinDays, warehouseID int) ([]models.Lot, error)
+}
+
+type inventoryService struct {
+	repo       repository.InventoryRepository
+	warehouses repository.WarehouseRepository
+	products   repository.ProductRepository
+	variants   repository.VariantRepository
+	tx         repository.TxManager
+}
+
+func NewInventoryService(repo repository.InventoryRepository, warehouses repository.WarehouseRepository, products repository.ProductRepository, variants repository.VariantRepository, tx repository.TxManager) InventoryService {
+	return &inventoryService{repo: repo, warehouses: warehouses, products: products, variants: variants, tx: tx}
+}
+
+// checkItem makes sure a product exists and is not in the trash and that
+// the variant the key names, if any, is one of its variants.
+func (s *inventoryService) checkItem(ctx context.Context, key models.StockKey) error {
+	_, err := s.products.GetByID(ctx, key.ProductID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return ErrNotFound
+	}
+	if err != nil || key.VariantID == 0 {
+		return err
+	}
+	_, err = s.variants.GetByID(ctx, key.ProductID, key.VariantID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return ErrVariantNotFound
+	}
+	return err
+}
+
+// checkStockItem makes sure a product or variant named on a line of a
+// request can be stocked.
+func checkStockItem(ctx context.Context, products repository.ProductRepository, variants repository.VariantRepository, key models.StockKey) error {
+	_, err := products.GetByID(ctx, key.ProductID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return fmt.Errorf("%w: product %d does not exist", ErrValidation, key.ProductID)
+	}
+	if err != nil || key.VariantID == 0 {
+		return err
+	}
+	_, err = variants.GetByID(ctx, key.ProductID, key.VariantID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return fmt.Errorf("%w: product %d has no variant %d", ErrValidation, key.ProductID, key.VariantID)
+	}
+	return err
+}
+
+// resolveWarehouse returns the ID of a warehouse named in a request, or of
+// the default warehouse for zero.
+func resolveWarehouse(ctx context.Context, warehouses repository.WarehouseRepository, id int) (int, error) {
+	var (
+		w   *models.Warehouse
+		err error
+	)
+	if id == 0 {
+		w, err = warehouses.Default(ctx)
+	} else {
+		w, err = warehouses.GetByID(ctx, id)
+	}
+	if errors.Is(err, repository.ErrNotFound) {
+		if id == 0 {
+			return 0, fmt.Errorf("%w: there is no default warehouse; name one with warehouse_id", ErrValidation)
+		}
+		return 0, fmt.Errorf("%w: warehouse %d does not exist", ErrValidation, id)
+	}
+	if err != nil {
+		return 0, err
+	}
+	return w.ID, nil
+}
+
+func (s *inventoryService) GetStock(ctx context.Context, key models.StockKey) (*models.Stock, error) {
+	if err := s.checkItem(ctx, key); err != nil {
+		return nil, err
+	}
+	return s.repo.Stock(ctx, key)
+}
+
+func (s *inventoryService) SetStockSettings(ctx context.Context, key models.StockKey, in models.StockSettings) (*models.Stock, error) {
+	if p := in.ReorderPoint; p != nil && (*p < 0 || *p > maxMovementQuantity) {
+		return nil, fmt.Errorf("%w: reorder_point must be between 0 and %d", ErrValidation, maxMovementQuantity)
+	}
+	if q := in.ReorderQuantity; q != nil && (*q <= 0 || *q > maxMovementQuantity) {
+		return nil, fmt.Errorf("%w: reorder_quantity must be a positive number of units up to %d", ErrValidation, maxMovementQuantity)
+	}
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.checkItem(ctx, key); err != nil {
+			return err
+		}
+		return s.repo.SetSettings(ctx, key, in)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.repo.Stock(ctx, key)
+}
+
+// movementDelta validates a movement and returns its signed quantity.
+func movementDelta(in models.StockMovementInput) (int64, error) {
+	q := in.Quantity
+	if q == 0 || q > maxMovementQuantity || q < -maxMovementQuantity {
+		return 0, fmt.Errorf("%w: quantity must be a non-zero number of units up to %d", ErrValidation, maxMovementQuantity)
+	}
+	if len(in.Reference) > maxReferenceLength {
+		return 0, fmt.Errorf("%w: reference must be at most %d characters", ErrValidation, maxReferenceLength)
+	}
+	if len(in.Note) > maxNoteLength {
+		return 0, fmt.Errorf("%w: note must be at most %d characters", ErrValidation, maxNoteLength)
+	}
+	switch in.Type {
+	case models.MovementAdjustment:
+	case models.MovementReceipt, models.MovementReturn, models.MovementSale:
+		if q < 0 {
+			return 0, fmt.Errorf("%w: the quantity of a %s is a positive number of units", ErrValidation, in.Type)
+		}
+		if in.Type == models.MovementSale {
+			q = -q
+		}
+	default:
+		return 0, fmt.Errorf("%w: type must be receipt, adjustment, sale or return", ErrValidation)
+	}
+	if err := validateLot(in, q); err != nil {
+		return 0, err
+	}
+	return q, nil
+}
+
+// validateLot checks the lot and expiry date of a movement with the given
+// signed quantity.
+func validateLot(in models.StockMovementInput, q int64) error {
+	if len(in.Lot) > maxLotLength || strings.TrimSpace(in.Lot) != in.Lot {
+		return fmt.Errorf("%w: lot must be at most %d characters without surrounding spaces", ErrValidation, maxLotLength)
+	}
+	if in.ExpiresOn == "" {
+		return nil
+	}
+	if in.Lot == "" {
+		return fmt.Errorf("%w: expires_on needs a lot", ErrValidation)
+	}
+	if q < 0 {
+		return fmt.Errorf("%w: expires_on is only given for stock coming in", ErrValidation)
+	}
+	if _, err := time.Parse(time.DateOnly, in.ExpiresOn); err != nil {
+		return fmt.Errorf("%w: expires_on must be a date such as 2006-01-02", ErrValidation)
+	}
+	return nil
+}
+
+func (s *inventoryService) RecordMovement(ctx context.Context, productID int, in models.StockMovementInput) (*models.StockMovement, error) {
+	delta, err := movementDelta(in)
+	if err != nil {
+		return nil, err
+	}
+	movements := []models.StockMovement{{
+		ProductID: productID,
+		VariantID: in.VariantID,
+		Type:      in.Type,
+		Quantity:  delta,
+		Reference: in.Reference,
+		Note:      in.Note,
+	}}
+	if in.Lot != "" {
+		movements[0].Lots = []models.LotQuantity{{Lot: in.Lot, ExpiresOn: in.ExpiresOn, Quantity: delta}}
+	}
+	err = s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.checkItem(ctx, movements[0].Key()); err != nil {
+			return err
+		}
+		warehouseID, err := resolveWarehouse(ctx, s.warehouses, in.WarehouseID)
+		if err != nil {
+			return err
+		}
+		movements[0].WarehouseID = warehouseID
+		return s.Post(ctx, movements)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &movements[0], nil
+}
+
+// Lock locks items in key order so that concurrent transactions touching
+// the same items cannot deadlock.
+func (s *inventoryService) Lock(ctx context.Context, keys []models.StockKey) (map[models.StockKey]*models.Stock, error) {
+	keys = slices.Clone(keys)
+	slices.SortFunc(keys, models.StockKey.Compare)
+	keys = slices.Compact(keys)
+	stocks := make(map[models.StockKey]*models.Stock, len(keys))
+	for _, key := range keys {
+		stock, err := s.repo.Lock(ctx, key)
+		if err != nil {
+			return nil, err
+		}
+		stocks[key] = stock
+	}
+	return stocks, nil
+}
+
+func (s *inventoryService) SetCostPrice(ctx context.Context, key models.StockKey, cost models.Money) error {
+	return s.repo.SetCostPrice(ctx, key, cost)
+}
+
+func (s *inventoryService) Post(ctx context.Context, movements []models.StockMovement) error {
+	keys := make([]models.StockKey, len(movements))
+	for i, m := range movements {
+		if err := checkLots(m); err != nil {
+			return err
+		}
+		keys[i] = m.Key()
+	}
+	return s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		stocks, err := s.Lock(ctx, keys)
+		if err != nil {
+			return err
+		}
+		type key struct {
+			item      models.StockKey
+			warehouse int
+		}
+		type position struct{ onHand, expired, reserved int64 }
+		positions := make(map[key]*position)
+		books := make(map[models.StockKey]*lotBook)
+		for i := range movements {
+			m := &movements[i]
+			stock := stocks[m.Key()]
+			k := key{m.Key(), m.WarehouseID}
+			pos, ok := positions[k]
+			if !ok {
+				ws := stock.InWarehouse(m.WarehouseID)
+				pos = &position{onHand: ws.OnHand, expired: ws.Expired, reserved: ws.Reserved}
+				positions[k] = pos
+			}
+			var expiredDelta int64
+			if m.Quantity < 0 || len(m.Lots) > 0 {
+				book, ok := books[m.Key()]
+				if !ok {
+					lots, err := s.repo.Lots(ctx, m.Key())
+					if err != nil {
+						return err
+					}
+					book = &lotBook{lots: lots, today: time.Now().Format(time.DateOnly)}
+					books[m.Key()] = book
+				}
+				if expiredDelta, err = book.allocate(m); err != nil {
+					return err
+				}
+			}
+			// Stock is sellable unless it is in an expired lot.
+			sellable := pos.onHand - pos.expired
+			sellableAfter := sellable + m.Quantity - expiredDelta
+			m.BalanceAfter = pos.onHand + m.Quantity
+			backorder := stock.AllowBackorder && (m.Type == models.MovementSale || m.Type == models.MovementAdjustment)
+			if sellableAfter < sellable && sellableAfter < pos.reserved && !backorder {
+				return fmt.Errorf("%w: %s has %d available in warehouse %d, %d requested",
+					ErrInsufficientStock, m.Key(), max(sellable-pos.reserved, 0), m.WarehouseID, -m.Quantity)
+			}
+			pos.onHand = m.BalanceAfter
+			pos.expired += expiredDelta
+			m.Actor = reqctx.Actor(ctx)
+			m.RequestID = reqctx.RequestID(ctx)
+			if err := s.repo.Append(ctx, m); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// checkLots makes sure the lots of a movement go the same way as the
+// movement and add up to no more than its quantity.
+func checkLots(m models.StockMovement) error {
+	var total int64
+	for _, l := range m.Lots {
+		if l.Lot == "" || l.Quantity == 0 || (l.Quantity < 0) != (m.Quantity < 0) {
+			return fmt.Errorf("%w: lot %q of %s does not match its movement", ErrValidation, l.Lot, m.Key())
+		}
+		total += l.Quantity
+	}
+	if max(total, -total) > max(m.Quantity, -m.Quantity) {
+		return fmt.Errorf("%w: the lots of %s add up to more than its movement", ErrValidation, m.Key())
+	}
+	return nil
+}
+
+// lotBook keeps the lot balances of a product or variant in every
+// warehouse while movements are posted.
+type lotBook struct {
+	lots  []models.Lot
+	today string
+}
+
+func (b *lotBook) find(warehouseID int, lot string) *models.Lot {
+	for i := range b.lots {
+		if l := &b.lots[i]; l.WarehouseID == warehouseID && l.Lot == lot {
+			return l
+		}
+	}
+	return nil
+}
+
+// allocate fills in the lots of a movement and applies them to the
+// balances. It returns how much the movement changes the stock of expired
+// lots by.
+func (b *lotBook) allocate(m *models.StockMovement) (int64, error) {
+	if m.Quantity > 0 {
+		return b.receive(m)
+	}
+	if len(m.Lots) == 0 {
+		b.pick(m)
+		return 0, nil
+	}
+	var expiredDelta int64
+	for i := range m.Lots {
+		lq := &m.Lots[i]
+		l := b.find(m.WarehouseID, lq.Lot)
+		if l == nil || l.Quantity < -lq.Quantity {
+			var has int64
+			if l != nil {
+				has = max(l.Quantity, 0)
+			}
+			return 0, fmt.Errorf("%w: lot %q of %s has %d in warehouse %d, %d requested",
+				ErrInsufficientStock, lq.Lot, m.Key(), has, m.WarehouseID, -lq.Quantity)
+		}
+		if l.Expired {
+			if m.Type != models.MovementAdjustment {
+				return 0, fmt.Errorf("%w: lot %q of %s expired on %s", ErrExpiredLot, lq.Lot, m.Key(), l.ExpiresOn)
+			}
+			expiredDelta += lq.Quantity
+		}
+		lq.ExpiresOn = l.ExpiresOn
+		l.Quantity += lq.Quantity
+	}
+	return expiredDelta, nil
+}
+
+// receive puts stock into the lots of a movement. A lot number keeps the
+// expiry date it was first received with.
+func (b *lotBook) receive(m *models.StockMovement) (int64, error) {
+	var expiredDelta int64
+	for i := range m.Lots {
+		lq := &m.Lots[i]
+		for _, l := range b.lots {
+			if l.Lot != lq.Lot {
+				continue
+			}
+			if lq.ExpiresOn == "" {
+				lq.ExpiresOn = l.ExpiresOn
+			} else if lq.ExpiresOn != l.ExpiresOn {
+				return 0, fmt.Errorf("%w: lot %q of %s expires on %q", ErrValidation, lq.Lot, m.Key(), l.ExpiresOn)
+			}
+			break
+		}
+		l := b.find(m.WarehouseID, lq.Lot)
+		if l == nil {
+			b.lots = append(b.lots, models.Lot{
+				ProductID:   m.ProductID,
+				VariantID:   m.VariantID,
+				WarehouseID: m.WarehouseID,
+				Lot:         lq.Lot,
+				ExpiresOn:   lq.ExpiresOn,
+				Expired:     lq.ExpiresOn != "" && lq.ExpiresOn < b.today,
+			})
+			l = &b.lots[len(b.lots)-1]
+		}
+		if l.Expired {
+			expiredDelta += lq.Quantity
+		}
+		l.Quantity += lq.Quantity
+	}
+	return expiredDelta, nil
+}
+
+// pick takes outgoing stock out of the lots of the warehouse that have not
+// expired, first to expire first; lots without an expiry date come last,
+// and whatever they do not cover comes out of untracked stock.
+func (b *lotBook) pick(m *models.StockMovement) {
+	slices.SortStableFunc(b.lots, func(x, y models.Lot) int {
+		if (x.ExpiresOn == "") != (y.ExpiresOn == "") {
+			if x.ExpiresOn == "" {
+				return 1
+			}
+			return -1
+		}
+		return cmp.Or(strings.Compare(x.ExpiresOn, y.ExpiresOn), strings.Compare(x.Lot, y.Lot))
+	})
+	remaining := -m.Quantity
+	for i := range b.lots {
+		l := &b.lots[i]
+		if remaining == 0 {
+			break
+		}
+		if l.WarehouseID != m.WarehouseID || l.Expired || l.Quantity <= 0 {
+			continue
+		}
+		take := min(remaining, l.Quantity)
+		m.Lots = append(m.Lots, models.LotQuantity{Lot: l.Lot, ExpiresOn: l.ExpiresOn, Quantity: -take})
+		l.Quantity -= take
+		remaining -= take
+	}
+}
+
+func (s *inventoryService) MovementsByReference(ctx context.Context, reference string) ([]models.StockMovement, error) {
+	return s.repo.ByReference(ctx, reference)
+}
+
+// ListMovements returns a page of movements, newest first, and the total
+// count.
+func (s *inventoryService) ListMovements(ctx context.Context, filter models.MovementFilter) ([]models.StockMovement, int, error) {
+	switch filter.Type {
+	case "", models.MovementReceipt, models.MovementAdjustment, models.MovementSale, models.MovementReturn,
+		models.MovementTransferOut, models.MovementTransferIn:
+	default:
+		return nil, 0, fmt.Errorf("%w: unknown movement type %q", ErrValidation, filter.Type)
+	}
+	if err := s.checkItem(ctx, models.StockKey{ProductID: filter.ProductID, VariantID: filter.VariantID}); err != nil {
+		return nil, 0, err
+	}
+	movements, err := s.repo.Movements(ctx, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+	total, err := s.repo.CountMovements(ctx, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+	return movements, total, nil
+}
+
+func (s *inventoryService) GetAvailability(ctx context.Context, key models.StockKey, warehouseID int) (*models.Availability, error) {
+	if err := s.checkItem(ctx, key); err != nil {
+		return nil, err
+	}
+	if warehouseID != 0 {
+		if _, err := resolveWarehouse(ctx, s.warehouses, warehouseID); err != nil {
+			return nil, err
+		}
+	}
+	warehouses, err := s.repo.Availability(ctx, key, warehouseID)
+	if err != nil {
+		return nil, err
+	}
+	a := &models.Availability{ProductID: key.ProductID, VariantID: key.VariantID, Warehouses: []models.WarehouseAvailability{}}
+	for _, w := range warehouses {
+		a.OnHand += w.OnHand
+		a.Reserved += w.Reserved
+		a.Available += w.Available
+		a.InTransit += w.Incoming
+		a.Warehouses = append(a.Warehouses, w)
+	}
+	return a, nil
+}
+
+func (s *inventoryService) ReorderSuggestions(ctx context.Context, salesDays, coverDays int) ([]models.ReorderSuggestion, error) {
+	if salesDays < 1 || salesDays > maxSuggestionDays || coverDays < 1 || coverDays > maxSuggestionDays {
+		return nil, fmt.Errorf("%w: days and cover_days must be between 1 and %d", ErrValidation, maxSuggestionDays)
+	}
+	candidates, err := s.repo.ReorderCandidates(ctx, time.Now().AddDate(0, 0, -salesDays))
+	if err != nil {
+		return nil, err
+	}
+	suggestions := []models.ReorderSuggestion{}
+	for _, c := range candidates {
+		if suggestReorder(&c, salesDays, coverDays) {
+			suggestions = append(suggestions, c)
+		}
+	}
+	slices.SortStableFunc(suggestions, func(a, b models.ReorderSuggestion) int {
+		switch {
+		case a.DaysOfCover == nil && b.DaysOfCover == nil:
+			return 0
+		case a.DaysOfCover == nil:
+			return 1
+		case b.DaysOfCover == nil:
+			return -1
+		}
+		return cmp.Compare(*a.DaysOfCover, *b.DaysOfCover)
+	})
+	return suggestions, nil
+}
+
+// suggestReorder works out the sales rate and suggested quantity of a
+// candidate and reports whether it needs reordering. The reorder point
+// acts as safety stock on top of the expected demand, and an item at its
+// reorder point is brought above it.
+func suggestReorder(c *models.ReorderSuggestion, salesDays, coverDays int) bool {
+	c.DailySales = float64(max(c.NetSales, 0)) / float64(salesDays)
+	if c.DailySales > 0 {
+		cover := float64(max(c.StockLevel, 0)) / c.DailySales
+		c.DaysOfCover = &cover
+	}
+	demand := int64(math.Ceil(c.DailySales * float64(coverDays)))
+	target := demand
+	low := c.StockLevel < demand
+	if c.ReorderPoint != nil {
+		target = *c.ReorderPoint + max(demand, 1)
+		low = low || c.StockLevel <= *c.ReorderPoint
+	}
+	if !low {
+		return false
+	}
+	c.SuggestedQuantity = target - c.StockLevel
+	if c.ReorderQuantity != nil {
+		c.SuggestedQuantity = max(c.SuggestedQuantity, *c.ReorderQuantity)
+	}
+	return true
+}
+
+func (s *inventoryService) ListLots(ctx context.Context, key models.StockKey, warehouseID int) ([]models.Lot, error) {
+	if err := s.checkItem(ctx, key); err != nil {
+		return nil, err
+	}
+	if warehouseID != 0 {
+		if _, err := resolveWarehouse(ctx, s.warehouses, warehouseID); err != nil {
+			return nil, err
+		}
+	}
+	lots, err := s.repo.Lots(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	inStock := []models.Lot{}
+	for _, l := range lots {
+		if l.Quantity > 0 && (warehouseID == 0 || l.WarehouseID == warehouseID) {
+			inStock = append(inStock, l)
+		}
+	}
+	return inStock, nil
+}
+
+func (s *inventoryService) ExpiringLots(ctx context.Context, withinDays, warehouseID int) ([]models.Lot, error) {
+	if withinDays < 0 || withinDays > maxExpiringDays {
+		return nil, fmt.Errorf("%w: within must be between 0 and %d days", ErrValidation, maxExpiringDays)
+	}
+	if warehouseID != 0 {
+		if _, err := resolveWarehouse(ctx, s.warehouses, warehouseID); err != nil {
+			return nil, err
+		}
+	}
+	lots, err := s.repo.ExpiringLots(ctx, time.Now().AddDate(0, 0, withinDays), warehouseID)
+	if err != nil {
+		return nil, err
+	}
+	if lots == nil {
+		lots = []models.Lot{}
+	}
+	return lots, nil
+}
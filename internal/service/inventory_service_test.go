@@ -0,0 +1,296 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"product-test/internal/models"
+	"product-test/internal/repository"
+	"reflect"
+	"slices"
+	"testing"
+)
+
+// txStub runs the function without a transaction.
+type txStub struct{}
+
+func (txStub) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
+// inventoryRepoStub holds the stock and lots of a single item and records
+// the movements appended to it.
+type inventoryRepoStub struct {
+	repository.InventoryRepository
+	stock    models.Stock
+	lots     []models.Lot
+	appended []models.StockMovement
+}
+
+func (r *inventoryRepoStub) Lock(context.Context, models.StockKey) (*models.Stock, error) {
+	stock := r.stock
+	return &stock, nil
+}
+
+func (r *inventoryRepoStub) Lots(context.Context, models.StockKey) ([]models.Lot, error) {
+	return slices.Clone(r.lots), nil
+}
+
+func (r *inventoryRepoStub) Append(_ context.Context, m *models.StockMovement) error {
+	r.appended = append(r.appended, *m)
+	return nil
+}
+
+const lotToday = "2026-01-10"
+
+// testLots are the lots of product 1: warehouse 1 has lots expiring in
+// March and February, one without an expiry date and one that expired in
+// December; warehouse 2 has a lot of its own.
+func testLots() []models.Lot {
+	return []models.Lot{
+		{ProductID: 1, WarehouseID: 1, Lot: "A", ExpiresOn: "2026-03-01", Quantity: 5},
+		{ProductID: 1, WarehouseID: 1, Lot: "N", Quantity: 10},
+		{ProductID: 1, WarehouseID: 1, Lot: "B", ExpiresOn: "2026-02-01", Quantity: 3},
+		{ProductID: 1, WarehouseID: 1, Lot: "X", ExpiresOn: "2025-12-01", Quantity: 4, Expired: true},
+		{ProductID: 1, WarehouseID: 2, Lot: "W", ExpiresOn: "2026-01-15", Quantity: 7},
+	}
+}
+
+func TestLotBookPick(t *testing.T) {
+	tests := []struct {
+		name      string
+		warehouse int
+		quantity  int64
+		want      []models.LotQuantity
+	}{
+		{"first to expire first", 1, -2, []models.LotQuantity{
+			{Lot: "B", ExpiresOn: "2026-02-01", Quantity: -2},
+		}},
+		{"spills into the next lot", 1, -5, []models.LotQuantity{
+			{Lot: "B", ExpiresOn: "2026-02-01", Quantity: -3},
+			{Lot: "A", ExpiresOn: "2026-03-01", Quantity: -2},
+		}},
+		{"lots without expiry last", 1, -10, []models.LotQuantity{
+			{Lot: "B", ExpiresOn: "2026-02-01", Quantity: -3},
+			{Lot: "A", ExpiresOn: "2026-03-01", Quantity: -5},
+			{Lot: "N", Quantity: -2},
+		}},
+		{"rest from untracked stock", 1, -25, []models.LotQuantity{
+			{Lot: "B", ExpiresOn: "2026-02-01", Quantity: -3},
+			{Lot: "A", ExpiresOn: "2026-03-01", Quantity: -5},
+			{Lot: "N", Quantity: -10},
+		}},
+		{"only the warehouse's lots", 2, -3, []models.LotQuantity{
+			{Lot: "W", ExpiresOn: "2026-01-15", Quantity: -3},
+		}},
+		{"no lots", 3, -3, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			book := &lotBook{lots: testLots(), today: lotToday}
+			m := &models.StockMovement{ProductID: 1, WarehouseID: tt.warehouse, Type: models.MovementSale, Quantity: tt.quantity}
+			expired, err := book.allocate(m)
+			if err != nil || expired != 0 {
+				t.Fatalf("allocate = %d, %v", expired, err)
+			}
+			if !reflect.DeepEqual(m.Lots, tt.want) {
+				t.Errorf("lots = %+v, want %+v", m.Lots, tt.want)
+			}
+		})
+	}
+
+	t.Run("balances carry over", func(t *testing.T) {
+		book := &lotBook{lots: testLots(), today: lotToday}
+		first := &models.StockMovement{ProductID: 1, WarehouseID: 1, Type: models.MovementSale, Quantity: -2}
+		second := &models.StockMovement{ProductID: 1, WarehouseID: 1, Type: models.MovementSale, Quantity: -2}
+		book.allocate(first)
+		book.allocate(second)
+		want := []models.LotQuantity{
+			{Lot: "B", ExpiresOn: "2026-02-01", Quantity: -1},
+			{Lot: "A", ExpiresOn: "2026-03-01", Quantity: -1},
+		}
+		if !reflect.DeepEqual(second.Lots, want) {
+			t.Errorf("second sale lots = %+v, want %+v", second.Lots, want)
+		}
+	})
+}
+
+func TestLotBookAllocateNamed(t *testing.T) {
+	tests := []struct {
+		name        string
+		typ         models.MovementType
+		lot         string
+		quantity    int64
+		wantErr     error
+		wantExpired int64
+		wantExpiry  string
+	}{
+		{"sale from a lot", models.MovementSale, "A", -2, nil, 0, "2026-03-01"},
+		{"sale of an expired lot", models.MovementSale, "X", -1, ErrExpiredLot, 0, ""},
+		{"transfer of an expired lot", models.MovementTransferOut, "X", -1, ErrExpiredLot, 0, ""},
+		{"write-off of an expired lot", models.MovementAdjustment, "X", -4, nil, -4, "2025-12-01"},
+		{"more than the lot has", models.MovementSale, "A", -6, ErrInsufficientStock, 0, ""},
+		{"lot of another warehouse", models.MovementSale, "W", -1, ErrInsufficientStock, 0, ""},
+		{"unknown lot", models.MovementAdjustment, "Z", -1, ErrInsufficientStock, 0, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			book := &lotBook{lots: testLots(), today: lotToday}
+			m := &models.StockMovement{ProductID: 1, WarehouseID: 1, Type: tt.typ, Quantity: tt.quantity,
+				Lots: []models.LotQuantity{{Lot: tt.lot, Quantity: tt.quantity}}}
+			expired, err := book.allocate(m)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("allocate error = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if expired != tt.wantExpired {
+				t.Errorf("expired delta = %d, want %d", expired, tt.wantExpired)
+			}
+			if m.Lots[0].ExpiresOn != tt.wantExpiry {
+				t.Errorf("expires_on = %q, want %q", m.Lots[0].ExpiresOn, tt.wantExpiry)
+			}
+			if l := book.find(1, tt.lot); l.Quantity != testLotQuantity(tt.lot)+tt.quantity {
+				t.Errorf("lot %s has %d after the movement", tt.lot, l.Quantity)
+			}
+		})
+	}
+}
+
+func testLotQuantity(lot string) int64 {
+	for _, l := range testLots() {
+		if l.WarehouseID == 1 && l.Lot == lot {
+			return l.Quantity
+		}
+	}
+	return 0
+}
+
+func TestLotBookReceive(t *testing.T) {
+	tests := []struct {
+		name        string
+		warehouse   int
+		lot         models.LotQuantity
+		wantErr     error
+		wantExpired int64
+		wantExpiry  string
+	}{
+		{"keeps the first expiry date", 1, models.LotQuantity{Lot: "A", Quantity: 4}, nil, 0, "2026-03-01"},
+		{"same expiry date", 1, models.LotQuantity{Lot: "A", ExpiresOn: "2026-03-01", Quantity: 4}, nil, 0, "2026-03-01"},
+		{"other expiry date", 1, models.LotQuantity{Lot: "A", ExpiresOn: "2026-04-01", Quantity: 4}, ErrValidation, 0, ""},
+		{"known lot, new warehouse", 2, models.LotQuantity{Lot: "A", Quantity: 4}, nil, 0, "2026-03-01"},
+		{"new lot", 1, models.LotQuantity{Lot: "C", ExpiresOn: "2026-06-01", Quantity: 4}, nil, 0, "2026-06-01"},
+		{"new lot already expired", 1, models.LotQuantity{Lot: "C", ExpiresOn: "2026-01-09", Quantity: 4}, nil, 4, "2026-01-09"},
+		{"into an expired lot", 1, models.LotQuantity{Lot: "X", Quantity: 4}, nil, 4, "2025-12-01"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			book := &lotBook{lots: testLots(), today: lotToday}
+			m := &models.StockMovement{ProductID: 1, WarehouseID: tt.warehouse, Type: models.MovementReceipt,
+				Quantity: tt.lot.Quantity, Lots: []models.LotQuantity{tt.lot}}
+			expired, err := book.allocate(m)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("allocate error = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if expired != tt.wantExpired {
+				t.Errorf("expired delta = %d, want %d", expired, tt.wantExpired)
+			}
+			l := book.find(tt.warehouse, tt.lot.Lot)
+			if l == nil || l.ExpiresOn != tt.wantExpiry || m.Lots[0].ExpiresOn != tt.wantExpiry {
+				t.Errorf("lot = %+v, movement lot = %+v; want expiry %q", l, m.Lots[0], tt.wantExpiry)
+			}
+		})
+	}
+}
+
+func TestPostAvailability(t *testing.T) {
+	// Warehouse 1 holds 22 units, 4 of them in the expired lot X, so 18
+	// can be sold.
+	stock := models.Stock{ProductID: 1, Warehouses: []models.WarehouseStock{
+		{WarehouseID: 1, OnHand: 22, Expired: 4},
+		{WarehouseID: 2, OnHand: 7},
+	}}
+	sale := func(q int64, lots ...models.LotQuantity) models.StockMovement {
+		return models.StockMovement{ProductID: 1, WarehouseID: 1, Type: models.MovementSale, Quantity: -q, Lots: lots}
+	}
+	tests := []struct {
+		name      string
+		backorder bool
+		reserved  int64
+		movements []models.StockMovement
+		wantErr   error
+		balances  []int64
+	}{
+		{"sells what is sellable", false, 0, []models.StockMovement{sale(18)}, nil, []int64{4}},
+		{"expired stock is not sellable", false, 0, []models.StockMovement{sale(19)}, ErrInsufficientStock, nil},
+		{"later movements see earlier ones", false, 0, []models.StockMovement{sale(10), sale(9)}, ErrInsufficientStock, nil},
+		{"reserved stock is held", false, 15, []models.StockMovement{sale(4)}, ErrInsufficientStock, nil},
+		{"around reserved stock", false, 15, []models.StockMovement{sale(3)}, nil, []int64{19}},
+		{"backorder sale", true, 0, []models.StockMovement{sale(25)}, nil, []int64{-3}},
+		{"backorder adjustment", true, 0, []models.StockMovement{
+			{ProductID: 1, WarehouseID: 1, Type: models.MovementAdjustment, Quantity: -25},
+		}, nil, []int64{-3}},
+		{"no backorder for transfers", true, 0, []models.StockMovement{
+			{ProductID: 1, WarehouseID: 1, Type: models.MovementTransferOut, Quantity: -19},
+		}, ErrInsufficientStock, nil},
+		{"other warehouse", false, 0, []models.StockMovement{
+			{ProductID: 1, WarehouseID: 2, Type: models.MovementSale, Quantity: -8},
+		}, ErrInsufficientStock, nil},
+		{"expired lot not for sale", true, 0, []models.StockMovement{
+			sale(1, models.LotQuantity{Lot: "X", Quantity: -1}),
+		}, ErrExpiredLot, nil},
+		{"expired lot written off", false, 18, []models.StockMovement{
+			{ProductID: 1, WarehouseID: 1, Type: models.MovementAdjustment, Quantity: -4,
+				Lots: []models.LotQuantity{{Lot: "X", Quantity: -4}}},
+		}, nil, []int64{18}},
+		{"receipt always fits", false, 30, []models.StockMovement{
+			{ProductID: 1, WarehouseID: 1, Type: models.MovementReceipt, Quantity: 2},
+		}, nil, []int64{24}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &inventoryRepoStub{stock: stock, lots: testLots()}
+			repo.stock.AllowBackorder = tt.backorder
+			repo.stock.Warehouses = slices.Clone(stock.Warehouses)
+			repo.stock.Warehouses[0].Reserved = tt.reserved
+			svc := &inventoryService{repo: repo, tx: txStub{}}
+
+			err := svc.Post(context.Background(), slices.Clone(tt.movements))
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Post error = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			var balances []int64
+			for _, m := range repo.appended {
+				balances = append(balances, m.BalanceAfter)
+			}
+			if !reflect.DeepEqual(balances, tt.balances) {
+				t.Errorf("balances after = %v, want %v", balances, tt.balances)
+			}
+		})
+	}
+}
+
+func TestCheckLots(t *testing.T) {
+	tests := []struct {
+		name string
+		m    models.StockMovement
+		ok   bool
+	}{
+		{"no lots", models.StockMovement{Quantity: -3}, true},
+		{"part in lots", models.StockMovement{Quantity: -3, Lots: []models.LotQuantity{{Lot: "A", Quantity: -2}}}, true},
+		{"lots exceed the movement", models.StockMovement{Quantity: -3, Lots: []models.LotQuantity{{Lot: "A", Quantity: -2}, {Lot: "B", Quantity: -2}}}, false},
+		{"lot going the other way", models.StockMovement{Quantity: 3, Lots: []models.LotQuantity{{Lot: "A", Quantity: -1}}}, false},
+		{"unnamed lot", models.StockMovement{Quantity: 3, Lots: []models.LotQuantity{{Quantity: 1}}}, false},
+	}
+	for _, tt := range tests {
+		if err := checkLots(tt.m); (err == nil) != tt.ok {
+			t.Errorf("%s: checkLots = %v", tt.name, err)
+		}
+	}
+}
@@ -0,0 +1,281 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"product-test/internal/models"
+	"product-test/internal/repository"
+	"product-test/internal/reqctx"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+const (
+	maxOrderLines     = 500
+	maxCustomerLength = 200
+)
+
+type OrderService interface {
+	ListOrders(ctx context.Context, filter models.OrderFilter) ([]models.Order, int, error)
+	GetOrder(ctx context.Context, id int64) (*models.Order, error)
+	// CreateOrder places a pending order for live products and their
+	// variants at their effective prices and takes its stock out of the
+	// warehouse.
+	CreateOrder(ctx context.Context, in models.OrderInput) (*models.Order, error)
+	PayOrder(ctx context.Context, id int64) (*models.Order, error)
+	FulfillOrder(ctx context.Context, id int64) (*models.Order, error)
+	DeliverOrder(ctx context.Context, id int64) (*models.Order, error)
+	// CancelOrder cancels a pending order and puts its stock back.
+	CancelOrder(ctx context.Context, id int64) (*models.Order, error)
+	// RefundOrder refunds a paid order, fulfilled or not. Stock that never
+	// left the warehouse is put back; returns of shipped goods are posted
+	// as stock movements once they arrive.
+	RefundOrder(ctx context.Context, id int64) (*models.Order, error)
+}
+
+type orderService struct {
+	repo       repository.OrderRepository
+	warehouses repository.WarehouseRepository
+	products   ProductService
+	inventory  InventoryService
+	tx         repository.TxManager
+}
+
+func NewOrderService(repo repository.OrderRepository, warehouses repository.WarehouseRepository, products ProductService, inventory InventoryService, tx repository.TxManager) OrderService {
+	return &orderService{repo: repo, warehouses: warehouses, products: products, inventory: inventory, tx: tx}
+}
+
+// orderTransitions lists the statuses an order can move to from each
+// status; cancelled and refunded orders are final.
+var orderTransitions = map[models.OrderStatus][]models.OrderStatus{
+	models.OrderPending:   {models.OrderPaid, models.OrderCancelled},
+	models.OrderPaid:      {models.OrderFulfilled, models.OrderRefunded},
+	models.OrderFulfilled: {models.OrderDelivered, models.OrderRefunded},
+	models.OrderDelivered: {models.OrderRefunded},
+}
+
+var orderStatuses = []models.OrderStatus{
+	models.OrderPending, models.OrderPaid, models.OrderFulfilled,
+	models.OrderDelivered, models.OrderCancelled, models.OrderRefunded,
+}
+
+func (s *orderService) ListOrders(ctx context.Context, filter models.OrderFilter) ([]models.Order, int, error) {
+	if filter.Status != "" && !slices.Contains(orderStatuses, filter.Status) {
+		return nil, 0, fmt.Errorf("%w: unknown order status %q", ErrValidation, filter.Status)
+	}
+	orders, err := s.repo.List(ctx, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+	total, err := s.repo.Count(ctx, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+	return orders, total, nil
+}
+
+func (s *orderService) GetOrder(ctx context.Context, id int64) (*models.Order, error) {
+	o, err := s.repo.GetByID(ctx, id)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, ErrOrderNotFound
+	}
+	return o, err
+}
+
+func validateOrder(in models.OrderInput) error {
+	if len(in.Lines) == 0 || len(in.Lines) > maxOrderLines {
+		return fmt.Errorf("%w: an order has 1 to %d lines", ErrValidation, maxOrderLines)
+	}
+	if len(in.Customer) > maxCustomerLength {
+		return fmt.Errorf("%w: customer must be at most %d characters", ErrValidation, maxCustomerLength)
+	}
+	if len(in.Note) > maxNoteLength {
+		return fmt.Errorf("%w: note must be at most %d characters", ErrValidation, maxNoteLength)
+	}
+	seen := make(map[models.StockKey]bool, len(in.Lines))
+	for _, l := range in.Lines {
+		if seen[l.Key()] {
+			return fmt.Errorf("%w: %s appears more than once", ErrValidation, l.Key())
+		}
+		seen[l.Key()] = true
+		if l.Quantity <= 0 || l.Quantity > maxMovementQuantity {
+			return fmt.Errorf("%w: quantity of %s must be a positive number of units up to %d", ErrValidation, l.Key(), maxMovementQuantity)
+		}
+	}
+	return nil
+}
+
+func orderReference(id int64) string {
+	return "order:" + strconv.FormatInt(id, 10)
+}
+
+// orderLine snapshots a product, or the variant of it a line names.
+func orderLine(p *models.Product, l models.OrderLineInput) (models.OrderLine, error) {
+	line := models.OrderLine{ProductID: p.ID, Name: p.Name, SKU: p.SKU, Quantity: l.Quantity, UnitPrice: *p.EffectivePrice}
+	if l.VariantID == 0 {
+		return line, nil
+	}
+	i := slices.IndexFunc(p.Variants, func(v models.Variant) bool { return v.ID == l.VariantID })
+	if i < 0 {
+		return line, fmt.Errorf("%w: product %d has no variant %d", ErrValidation, p.ID, l.VariantID)
+	}
+	v := p.Variants[i]
+	line.VariantID, line.Name, line.SKU, line.UnitPrice = v.ID, variantName(p, v), v.SKU, *v.EffectivePrice
+	return line, nil
+}
+
+// variantName names a variant after its product and its option values in
+// the order of the product's options, e.g. "T-shirt (M, red)".
+func variantName(p *models.Product, v models.Variant) string {
+	var values []string
+	for _, o := range p.Options {
+		if value, ok := v.Options[o.Name]; ok {
+			values = append(values, value)
+		}
+	}
+	if len(values) == 0 {
+		return p.Name
+	}
+	return p.Name + " (" + strings.Join(values, ", ") + ")"
+}
+
+func (s *orderService) CreateOrder(ctx context.Context, in models.OrderInput) (*models.Order, error) {
+	in.Customer = strings.TrimSpace(in.Customer)
+	if err := validateOrder(in); err != nil {
+		return nil, err
+	}
+	o := &models.Order{Status: models.OrderPending, Customer: in.Customer, Note: in.Note, Actor: reqctx.Actor(ctx)}
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+		if o.WarehouseID, err = resolveWarehouse(ctx, s.warehouses, in.WarehouseID); err != nil {
+			return err
+		}
+		o.Lines = make([]models.OrderLine, len(in.Lines))
+		for i, l := range in.Lines {
+			p, err := s.products.GetProductByID(ctx, l.ProductID)
+			if errors.Is(err, ErrNotFound) {
+				return fmt.Errorf("%w: product %d does not exist", ErrValidation, l.ProductID)
+			}
+			if err != nil {
+				return err
+			}
+			line, err := orderLine(p, l)
+			if err != nil {
+				return err
+			}
+			if o.Currency == "" {
+				o.Currency = line.UnitPrice.Currency
+			}
+			if line.UnitPrice.Currency != o.Currency {
+				return fmt.Errorf("%w: %s is priced in %s, the rest of the order in %s",
+					ErrValidation, l.Key(), line.UnitPrice.Currency, o.Currency)
+			}
+			o.Lines[i] = line
+		}
+		if err := s.repo.Create(ctx, o); err != nil {
+			return err
+		}
+		movements := make([]models.StockMovement, len(o.Lines))
+		for i, l := range o.Lines {
+			movements[i] = models.StockMovement{
+				ProductID:   l.ProductID,
+				VariantID:   l.VariantID,
+				WarehouseID: o.WarehouseID,
+				Type:        models.MovementSale,
+				Quantity:    -l.Quantity,
+				Reference:   orderReference(o.ID),
+			}
+		}
+		return s.inventory.Post(ctx, movements)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.GetOrder(ctx, o.ID)
+}
+
+// transition moves an order to another status if its current one allows,
+// putting its stock back when the order is cancelled or refunded before it
+// was fulfilled.
+func (s *orderService) transition(ctx context.Context, id int64, to models.OrderStatus) (*models.Order, error) {
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		o, err := s.repo.Lock(ctx, id)
+		if errors.Is(err, repository.ErrNotFound) {
+			return ErrOrderNotFound
+		}
+		if err != nil {
+			return err
+		}
+		if !slices.Contains(orderTransitions[o.Status], to) {
+			return fmt.Errorf("%w: order %d is %s and cannot become %s", ErrOrderState, id, o.Status, to)
+		}
+		from := o.Status
+		if err := s.repo.SetStatus(ctx, o, to); err != nil {
+			return err
+		}
+		if to == models.OrderCancelled || (to == models.OrderRefunded && from == models.OrderPaid) {
+			return s.restock(ctx, o)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.GetOrder(ctx, id)
+}
+
+// restock returns the stock an order took to the warehouse and lots it
+// came from.
+func (s *orderService) restock(ctx context.Context, o *models.Order) error {
+	sold, err := s.inventory.MovementsByReference(ctx, orderReference(o.ID))
+	if err != nil {
+		return err
+	}
+	var movements []models.StockMovement
+	for _, m := range sold {
+		if m.Type != models.MovementSale {
+			continue
+		}
+		lots := make([]models.LotQuantity, len(m.Lots))
+		for i, l := range m.Lots {
+			l.Quantity = -l.Quantity
+			lots[i] = l
+		}
+		movements = append(movements, models.StockMovement{
+			ProductID:   m.ProductID,
+			VariantID:   m.VariantID,
+			WarehouseID: m.WarehouseID,
+			Type:        models.MovementReturn,
+			Quantity:    -m.Quantity,
+			Lots:        lots,
+			Reference:   m.Reference,
+			Note:        "order " + string(o.Status),
+		})
+	}
+	if len(movements) == 0 {
+		return nil
+	}
+	return s.inventory.Post(ctx, movements)
+}
+
+func (s *orderService) PayOrder(ctx context.Context, id int64) (*models.Order, error) {
+	return s.transition(ctx, id, models.OrderPaid)
+}
+
+func (s *orderService) FulfillOrder(ctx context.Context, id int64) (*models.Order, error) {
+	return s.transition(ctx, id, models.OrderFulfilled)
+}
+
+func (s *orderService) DeliverOrder(ctx context.Context, id int64) (*models.Order, error) {
+	return s.transition(ctx, id, models.OrderDelivered)
+}
+
+func (s *orderService) CancelOrder(ctx context.Context, id int64) (*models.Order, error) {
+	return s.transition(ctx, id, models.OrderCancelled)
+}
+
+func (s *orderService) RefundOrder(ctx context.Context, id int64) (*models.Order, error) {
+	return s.transition(ctx, id, models.OrderRefunded)
+}
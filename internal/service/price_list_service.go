@@ -0,0 +1,308 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"product-test/internal/models"
+	"product-test/internal/repository"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+const (
+	MaxQuoteQuantity = 1_000_000
+	maxTiers         = 50
+)
+
+var codeFormat = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,63}$`)
+
+type PriceListService interface {
+	ListPriceLists(ctx context.Context) ([]models.PriceList, error)
+	CreatePriceList(ctx context.Context, in models.PriceListInput) (*models.PriceList, error)
+	UpdatePriceList(ctx context.Context, id int, in models.PriceListInput) (*models.PriceList, error)
+	DeletePriceList(ctx context.Context, id int) error
+	GetTiers(ctx context.Context, listID, productID int) (*models.PriceTiers, error)
+	SetTiers(ctx context.Context, listID, productID int, tiers []models.PriceTier) (*models.PriceTiers, error)
+
+	ListGroups(ctx context.Context) ([]models.CustomerGroup, error)
+	CreateGroup(ctx context.Context, in models.CustomerGroupInput) (*models.CustomerGroup, error)
+	UpdateGroup(ctx context.Context, id int, in models.CustomerGroupInput) (*models.CustomerGroup, error)
+	DeleteGroup(ctx context.Context, id int) error
+
+	// Quote prices qty units of a product, for a price list given by code
+	// or through a customer group; both may be empty for the public price.
+	Quote(ctx context.Context, productID, qty int, priceList, group string) (*models.Quote, error)
+}
+
+type priceListService struct {
+	repo     repository.PriceListRepository
+	products ProductService
+	tx       repository.TxManager
+}
+
+func NewPriceListService(repo repository.PriceListRepository, products ProductService, tx repository.TxManager) PriceListService {
+	return &priceListService{repo: repo, products: products, tx: tx}
+}
+
+func validateCode(code string) error {
+	if !codeFormat.MatchString(code) {
+		return fmt.Errorf("%w: code must be 1-64 lowercase letters, digits, '-' or '_'", ErrValidation)
+	}
+	return nil
+}
+
+func validateName(name string) error {
+	if strings.TrimSpace(name) == "" {
+		return fmt.Errorf("%w: name is required", ErrValidation)
+	}
+	if len(name) > MaxNameLength {
+		return fmt.Errorf("%w: name must be at most %d characters", ErrValidation, MaxNameLength)
+	}
+	return nil
+}
+
+// duplicateCode turns a uniqueness violation into a validation error.
+func duplicateCode(err error, code string) error {
+	if errors.Is(err, repository.ErrDuplicate) {
+		return fmt.Errorf("%w: code %q is already in use", ErrValidation, code)
+	}
+	return err
+}
+
+func (s *priceListService) ListPriceLists(ctx context.Context) ([]models.PriceList, error) {
+	return s.repo.ListPriceLists(ctx)
+}
+
+func (s *priceListService) CreatePriceList(ctx context.Context, in models.PriceListInput) (*models.PriceList, error) {
+	l := &models.PriceList{Code: in.Code, Name: in.Name, Currency: strings.ToUpper(in.Currency)}
+	if err := validateCode(l.Code); err != nil {
+		return nil, err
+	}
+	if err := validateName(l.Name); err != nil {
+		return nil, err
+	}
+	if err := validateCurrency(l.Currency); err != nil {
+		return nil, err
+	}
+	if err := s.repo.CreatePriceList(ctx, l); err != nil {
+		return nil, duplicateCode(err, l.Code)
+	}
+	return l, nil
+}
+
+func (s *priceListService) UpdatePriceList(ctx context.Context, id int, in models.PriceListInput) (*models.PriceList, error) {
+	if err := validateCode(in.Code); err != nil {
+		return nil, err
+	}
+	if err := validateName(in.Name); err != nil {
+		return nil, err
+	}
+	l, err := s.getPriceList(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if in.Currency != "" && strings.ToUpper(in.Currency) != l.Currency {
+		return nil, fmt.Errorf("%w: the currency of a price list cannot change", ErrValidation)
+	}
+	l.Code, l.Name = in.Code, in.Name
+	if err := s.repo.UpdatePriceList(ctx, l); err != nil {
+		return nil, duplicateCode(err, l.Code)
+	}
+	return l, nil
+}
+
+func (s *priceListService) DeletePriceList(ctx context.Context, id int) error {
+	err := s.repo.DeletePriceList(ctx, id)
+	if errors.Is(err, repository.ErrNotFound) {
+		return ErrPriceListNotFound
+	}
+	return err
+}
+
+func (s *priceListService) getPriceList(ctx context.Context, id int) (*models.PriceList, error) {
+	l, err := s.repo.GetPriceList(ctx, id)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, ErrPriceListNotFound
+	}
+	return l, err
+}
+
+func (s *priceListService) GetTiers(ctx context.Context, listID, productID int) (*models.PriceTiers, error) {
+	if _, err := s.getPriceList(ctx, listID); err != nil {
+		return nil, err
+	}
+	if _, err := s.products.GetProductByID(ctx, productID); err != nil {
+		return nil, err
+	}
+	tiers, err := s.repo.GetTiers(ctx, listID, productID)
+	if err != nil {
+		return nil, err
+	}
+	if tiers == nil {
+		tiers = []models.PriceTier{}
+	}
+	return &models.PriceTiers{PriceListID: listID, ProductID: productID, Tiers: tiers}, nil
+}
+
+// SetTiers replaces the tiers of a product in a price list; an empty list
+// removes the product from the price list.
+func (s *priceListService) SetTiers(ctx context.Context, listID, productID int, tiers []models.PriceTier) (*models.PriceTiers, error) {
+	l, err := s.getPriceList(ctx, listID)
+	if err != nil {
+		return nil, err
+	}
+	if len(tiers) > maxTiers {
+		return nil, fmt.Errorf("%w: at most %d tiers per product", ErrValidation, maxTiers)
+	}
+	for _, t := range tiers {
+		if t.MinQuantity < 1 {
+			return nil, fmt.Errorf("%w: min_qty must be at least 1", ErrValidation)
+		}
+		if err := validatePrice(t.Price); err != nil {
+			return nil, err
+		}
+		if t.Price.Currency != l.Currency {
+			return nil, fmt.Errorf("%w: tier prices must be in %s, the currency of the price list", ErrValidation, l.Currency)
+		}
+	}
+	tiers = slices.Clone(tiers)
+	slices.SortFunc(tiers, func(a, b models.PriceTier) int { return a.MinQuantity - b.MinQuantity })
+	for i := 1; i < len(tiers); i++ {
+		if tiers[i].MinQuantity == tiers[i-1].MinQuantity {
+			return nil, fmt.Errorf("%w: duplicate tier for min_qty %d", ErrValidation, tiers[i].MinQuantity)
+		}
+	}
+	err = s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if _, err := s.products.GetProductByID(ctx, productID); err != nil {
+			return err
+		}
+		return s.repo.ReplaceTiers(ctx, listID, productID, tiers)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &models.PriceTiers{PriceListID: listID, ProductID: productID, Tiers: tiers}, nil
+}
+
+func (s *priceListService) ListGroups(ctx context.Context) ([]models.CustomerGroup, error) {
+	return s.repo.ListGroups(ctx)
+}
+
+func (s *priceListService) validateGroup(ctx context.Context, in models.CustomerGroupInput) error {
+	if err := validateCode(in.Code); err != nil {
+		return err
+	}
+	if err := validateName(in.Name); err != nil {
+		return err
+	}
+	if in.PriceListID != nil {
+		if _, err := s.getPriceList(ctx, *in.PriceListID); err != nil {
+			if errors.Is(err, ErrPriceListNotFound) {
+				return fmt.Errorf("%w: price list %d does not exist", ErrValidation, *in.PriceListID)
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *priceListService) CreateGroup(ctx context.Context, in models.CustomerGroupInput) (*models.CustomerGroup, error) {
+	if err := s.validateGroup(ctx, in); err != nil {
+		return nil, err
+	}
+	g := &models.CustomerGroup{Code: in.Code, Name: in.Name, PriceListID: in.PriceListID}
+	if err := s.repo.CreateGroup(ctx, g); err != nil {
+		return nil, duplicateCode(err, g.Code)
+	}
+	return g, nil
+}
+
+func (s *priceListService) UpdateGroup(ctx context.Context, id int, in models.CustomerGroupInput) (*models.CustomerGroup, error) {
+	if err := s.validateGroup(ctx, in); err != nil {
+		return nil, err
+	}
+	g := &models.CustomerGroup{ID: id, Code: in.Code, Name: in.Name, PriceListID: in.PriceListID}
+	if err := s.repo.UpdateGroup(ctx, g); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrGroupNotFound
+		}
+		return nil, duplicateCode(err, g.Code)
+	}
+	return g, nil
+}
+
+func (s *priceListService) DeleteGroup(ctx context.Context, id int) error {
+	err := s.repo.DeleteGroup(ctx, id)
+	if errors.Is(err, repository.ErrNotFound) {
+		return ErrGroupNotFound
+	}
+	return err
+}
+
+// Quote starts from the product's effective price, which already reflects
+// running sales. A price list tier for the quantity replaces it when it is
+// cheaper or in a different currency, so a customer never pays more than
+// the public price for the same currency.
+func (s *priceListService) Quote(ctx context.Context, productID, qty int, priceList, group string) (*models.Quote, error) {
+	if qty < 1 || qty > MaxQuoteQuantity {
+		return nil, fmt.Errorf("%w: qty must be between 1 and %d", ErrValidation, MaxQuoteQuantity)
+	}
+	if priceList != "" && group != "" {
+		return nil, fmt.Errorf("%w: give either price_list or customer_group, not both", ErrValidation)
+	}
+	list, err := s.resolveList(ctx, priceList, group)
+	if err != nil {
+		return nil, err
+	}
+	p, err := s.products.GetProductByID(ctx, productID)
+	if err != nil {
+		return nil, err
+	}
+
+	q := &models.Quote{ProductID: productID, Quantity: qty, BasePrice: p.Price, UnitPrice: p.Price, Source: models.QuoteBase}
+	if p.EffectivePrice != nil && *p.EffectivePrice != p.Price {
+		q.UnitPrice, q.Source = *p.EffectivePrice, models.QuoteSale
+	}
+	if list != nil {
+		q.PriceList = list.Code
+		tier, err := s.repo.FindTier(ctx, list.ID, productID, qty)
+		switch {
+		case errors.Is(err, repository.ErrNotFound):
+		case err != nil:
+			return nil, err
+		case tier.Price.Currency != q.UnitPrice.Currency || tier.Price.Amount < q.UnitPrice.Amount:
+			q.UnitPrice, q.Source, q.MinQuantity = tier.Price, models.QuotePriceList, tier.MinQuantity
+		}
+	}
+	if q.UnitPrice.Amount > math.MaxInt64/int64(qty) {
+		return nil, fmt.Errorf("%w: total is out of range", ErrValidation)
+	}
+	q.Total = models.Money{Amount: q.UnitPrice.Amount * int64(qty), Currency: q.UnitPrice.Currency}
+	return q, nil
+}
+
+// resolveList finds the price list named directly or assigned to the
+// customer group; a group without a list buys at public prices.
+func (s *priceListService) resolveList(ctx context.Context, code, group string) (*models.PriceList, error) {
+	if group != "" {
+		g, err := s.repo.GetGroupByCode(ctx, group)
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrGroupNotFound
+		}
+		if err != nil || g.PriceListID == nil {
+			return nil, err
+		}
+		return s.getPriceList(ctx, *g.PriceListID)
+	}
+	if code == "" {
+		return nil, nil
+	}
+	l, err := s.repo.GetPriceListByCode(ctx, code)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, ErrPriceListNotFound
+	}
+	return l, err
+}
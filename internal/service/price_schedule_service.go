@@ -0,0 +1,232 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"product-test/internal/models"
+	"product-test/internal/repository"
+	"slices"
+	"time"
+)
+
+type PriceScheduleService interface {
+	ListSchedules(ctx context.Context, productID int, status models.ScheduleStatus) ([]models.PriceSchedule, error)
+	CreateSchedule(ctx context.Context, productID int, in models.PriceScheduleInput) (*models.PriceSchedule, error)
+	UpdateSchedule(ctx context.Context, productID int, id int64, in models.PriceScheduleInput) (*models.PriceSchedule, error)
+	CancelSchedule(ctx context.Context, productID int, id int64) error
+	RunSchedules(ctx context.Context) (ScheduleRun, error)
+}
+
+// ScheduleRun counts what one pass of the scheduler did.
+type ScheduleRun struct {
+	Activated int64
+	Expired   int64
+	Applied   int64
+	Failed    int64
+}
+
+type priceScheduleService struct {
+	repo     repository.PriceScheduleRepository
+	products ProductService
+	tx       repository.TxManager
+}
+
+func NewPriceScheduleService(repo repository.PriceScheduleRepository, products ProductService, tx repository.TxManager) PriceScheduleService {
+	return &priceScheduleService{repo: repo, products: products, tx: tx}
+}
+
+var scheduleStatuses = []models.ScheduleStatus{
+	models.ScheduleScheduled, models.ScheduleActive, models.ScheduleExpired, models.ScheduleApplied, models.ScheduleCancelled,
+	models.ScheduleFailed,
+}
+
+func (s *priceScheduleService) ListSchedules(ctx context.Context, productID int, status models.ScheduleStatus) ([]models.PriceSchedule, error) {
+	if status != "" && !slices.Contains(scheduleStatuses, status) {
+		return nil, fmt.Errorf("%w: unknown status %q", ErrValidation, status)
+	}
+	if _, err := s.products.GetProductByID(ctx, productID); err != nil {
+		return nil, err
+	}
+	return s.repo.ListByProduct(ctx, productID, status)
+}
+
+func validateSchedule(in models.PriceScheduleInput, now time.Time) error {
+	if err := validatePrice(in.Price); err != nil {
+		return err
+	}
+	if in.StartsAt.IsZero() {
+		return fmt.Errorf("%w: starts_at is required", ErrValidation)
+	}
+	if in.EndsAt != nil {
+		if !in.EndsAt.After(in.StartsAt) {
+			return fmt.Errorf("%w: ends_at must be after starts_at", ErrValidation)
+		}
+		if !in.EndsAt.After(now) {
+			return fmt.Errorf("%w: ends_at must be in the future", ErrValidation)
+		}
+	}
+	if len(in.Note) > MaxDescriptionLength {
+		return fmt.Errorf("%w: note must be at most %d characters", ErrValidation, MaxDescriptionLength)
+	}
+	return nil
+}
+
+// CreateSchedule adds a schedule to a live product. A start in the past
+// takes effect right away.
+func (s *priceScheduleService) CreateSchedule(ctx context.Context, productID int, in models.PriceScheduleInput) (*models.PriceSchedule, error) {
+	if err := validateSchedule(in, time.Now()); err != nil {
+		return nil, err
+	}
+	if err := s.checkCurrency(ctx, productID, in.Price); err != nil {
+		return nil, err
+	}
+	sched := &models.PriceSchedule{
+		ProductID: productID,
+		Price:     in.Price,
+		StartsAt:  in.StartsAt,
+		EndsAt:    in.EndsAt,
+		Note:      in.Note,
+	}
+	if err := s.repo.Create(ctx, sched); err != nil {
+		return nil, err
+	}
+	return sched, nil
+}
+
+// UpdateSchedule changes a schedule that has not started yet.
+func (s *priceScheduleService) UpdateSchedule(ctx context.Context, productID int, id int64, in models.PriceScheduleInput) (*models.PriceSchedule, error) {
+	now := time.Now()
+	if err := validateSchedule(in, now); err != nil {
+		return nil, err
+	}
+	if err := s.checkCurrency(ctx, productID, in.Price); err != nil {
+		return nil, err
+	}
+	var sched *models.PriceSchedule
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+		if sched, err = s.lock(ctx, productID, id); err != nil {
+			return err
+		}
+		if sched.Status != models.ScheduleScheduled || !sched.StartsAt.After(now) {
+			return fmt.Errorf("%w: only schedules that have not started can be changed", ErrScheduleState)
+		}
+		sched.Price, sched.StartsAt, sched.EndsAt, sched.Note = in.Price, in.StartsAt, in.EndsAt, in.Note
+		return s.repo.Update(ctx, sched)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return sched, nil
+}
+
+// CancelSchedule withdraws a pending schedule or ends a running sale. A sale
+// that has started keeps the part of its window that has passed, so that
+// prices customers saw can still be looked up.
+func (s *priceScheduleService) CancelSchedule(ctx context.Context, productID int, id int64) error {
+	return s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		sched, err := s.lock(ctx, productID, id)
+		if err != nil {
+			return err
+		}
+		if sched.Status != models.ScheduleScheduled && sched.Status != models.ScheduleActive {
+			return fmt.Errorf("%w: schedule is already %s", ErrScheduleState, sched.Status)
+		}
+		_, err = s.repo.Cancel(ctx, id)
+		return err
+	})
+}
+
+// checkCurrency makes sure a scheduled price is in the currency the
+// product is sold in.
+func (s *priceScheduleService) checkCurrency(ctx context.Context, productID int, price models.Money) error {
+	p, err := s.products.GetProductByID(ctx, productID)
+	if err != nil {
+		return err
+	}
+	if price.Currency != p.Price.Currency {
+		return fmt.Errorf("%w: price must be in %s, the currency of the product", ErrValidation, p.Price.Currency)
+	}
+	return nil
+}
+
+func (s *priceScheduleService) lock(ctx context.Context, productID int, id int64) (*models.PriceSchedule, error) {
+	sched, err := s.repo.GetForUpdate(ctx, productID, id)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, ErrScheduleNotFound
+	}
+	return sched, err
+}
+
+// RunSchedules starts and ends sales whose window opened or closed and
+// applies permanent price changes that are due. Each change is applied in
+// its own transaction as a product update of the price alone, so it shows
+// up in the audit trail, revisions and price history. Changes to products that have
+// been deleted or have switched currency in the meantime are cancelled;
+// changes the product rejects fail with the reason, so that they do not
+// hold up the changes due after them. Other errors end the pass and the
+// change is retried on the next one.
+func (s *priceScheduleService) RunSchedules(ctx context.Context) (ScheduleRun, error) {
+	var run ScheduleRun
+	var err error
+	if run.Expired, err = s.repo.Expire(ctx); err != nil {
+		return run, fmt.Errorf("expire schedules: %w", err)
+	}
+	if run.Activated, err = s.repo.Activate(ctx); err != nil {
+		return run, fmt.Errorf("activate schedules: %w", err)
+	}
+	for {
+		var (
+			sched    *models.PriceSchedule
+			applied  bool
+			applyErr error
+		)
+		err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+			var err error
+			if sched, err = s.repo.ClaimDueChange(ctx); err != nil {
+				return err
+			}
+			status := models.ScheduleApplied
+			switch applyErr = s.applyChange(ctx, sched); {
+			case errors.Is(applyErr, ErrNotFound), errors.Is(applyErr, ErrCurrencyMismatch):
+				status = models.ScheduleCancelled
+			case applyErr != nil:
+				return fmt.Errorf("apply schedule %d: %w", sched.ID, applyErr)
+			default:
+				applied = true
+			}
+			return s.repo.SetStatus(ctx, sched.ID, status)
+		})
+		if sched == nil && errors.Is(err, repository.ErrNotFound) {
+			return run, nil
+		}
+		// The failed update may have aborted the transaction, so the
+		// failure is recorded after it has been rolled back.
+		if err != nil && rejectedChange(applyErr) {
+			if err := s.repo.Fail(ctx, sched.ID, applyErr.Error()); err != nil {
+				return run, fmt.Errorf("fail schedule %d: %w", sched.ID, err)
+			}
+			run.Failed++
+			continue
+		}
+		if err != nil {
+			return run, err
+		}
+		if applied {
+			run.Applied++
+		}
+	}
+}
+
+// rejectedChange reports whether a permanent change failed because the
+// product does not accept it, which no retry would change.
+func rejectedChange(err error) bool {
+	return errors.Is(err, ErrValidation) || errors.Is(err, ErrConflict)
+}
+
+// applyChange sets the price only, under the product's lock, so that an
+// update committed since the change was claimed is kept.
+func (s *priceScheduleService) applyChange(ctx context.Context, sched *models.PriceSchedule) error {
+	return s.products.SetPrice(ctx, sched.ProductID, sched.Price)
+}
@@ -0,0 +1,67 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"product-test/internal/models"
+	"product-test/internal/repository"
+	"time"
+)
+
+type PriceService interface {
+	GetPriceHistory(ctx context.Context, filter models.PriceHistoryFilter) ([]models.PricePeriod, int, error)
+	GetPriceAt(ctx context.Context, productID int, at time.Time) (*models.PricePeriod, error)
+}
+
+type priceService struct {
+	repo      repository.PriceRepository
+	schedules repository.PriceScheduleRepository
+}
+
+func NewPriceService(repo repository.PriceRepository, schedules repository.PriceScheduleRepository) PriceService {
+	return &priceService{repo: repo, schedules: schedules}
+}
+
+// GetPriceHistory returns the price periods overlapping the filter's range,
+// oldest first, and their total.
+func (s *priceService) GetPriceHistory(ctx context.Context, filter models.PriceHistoryFilter) ([]models.PricePeriod, int, error) {
+	if !filter.From.IsZero() && !filter.To.IsZero() && !filter.From.Before(filter.To) {
+		return nil, 0, fmt.Errorf("%w: from must be before to", ErrValidation)
+	}
+	periods, err := s.repo.List(ctx, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+	total, err := s.repo.Count(ctx, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+	return periods, total, nil
+}
+
+// GetPriceAt returns the period in effect at the given instant. A price
+// schedule covering that instant takes precedence over the product's price.
+func (s *priceService) GetPriceAt(ctx context.Context, productID int, at time.Time) (*models.PricePeriod, error) {
+	p, err := s.repo.At(ctx, productID, at)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrNoPrice
+		}
+		return nil, err
+	}
+	scheduled, err := s.schedules.EffectivePrices(ctx, []int{productID}, at)
+	if err != nil {
+		return nil, err
+	}
+	if sched, ok := scheduled[productID]; ok && sched.Price.Currency == p.Price.Currency {
+		return &models.PricePeriod{
+			ProductID:     productID,
+			Price:         sched.Price,
+			EffectiveFrom: sched.StartsAt,
+			EffectiveTo:   sched.EndsAt,
+			ScheduleID:    &sched.ID,
+		}, nil
+	}
+	return p, nil
+}
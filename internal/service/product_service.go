@@ -2,38 +2,198 @@ package service
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"product-test/internal/attrschema"
 	"product-test/internal/models"
 	"product-test/internal/repository"
+	"product-test/internal/reqctx"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
 )
 
 const (
 	MaxNameLength        = 500
 	MaxDescriptionLength = 2000
+	maxAttributeFilters  = 20
 )
 
 type ProductService interface {
-	GetAllProducts(ctx context.Context, limit, offset int) ([]models.Product, error)
+	ListProducts(ctx context.Context, filter models.ProductFilter, estimateTotal bool) (*models.ProductPage, error)
+	ExportProducts(ctx context.Context, filter models.ProductFilter, fn func([]models.Product) error) error
 	GetProductByID(ctx context.Context, id int) (*models.Product, error)
+	// GetProductBySKU finds a product by its SKU or that of one of its
+	// variants.
+	GetProductBySKU(ctx context.Context, sku string) (*models.Product, error)
+	// GetProductByBarcode accepts a UPC-A code as well as the EAN-13 it
+	// is stored as.
+	GetProductByBarcode(ctx context.Context, code string) (*models.Product, error)
+	GetProductBySlug(ctx context.Context, slug string) (*models.Product, error)
+	GetProductAsOf(ctx context.Context, id int, at time.Time) (*models.Product, error)
 	CreateProduct(ctx context.Context, product *models.Product) error
 	UpdateProduct(ctx context.Context, product *models.Product) error
+	// SetPrice changes the price of a live product and nothing else. It
+	// fails with ErrCurrencyMismatch if the product is sold in another
+	// currency.
+	SetPrice(ctx context.Context, id int, price models.Money) error
 	DeleteProduct(ctx context.Context, id int) error
+	RestoreProduct(ctx context.Context, id int) (*models.Product, error)
+	PurgeDeletedProducts(ctx context.Context, retention time.Duration) (int64, error)
+	// BackfillSlugs gives the products created before slugs existed one
+	// and returns how many it gave.
+	BackfillSlugs(ctx context.Context) (int, error)
 }
 
 type productService struct {
-	repo repository.ProductRepository
+	repo       repository.ProductRepository
+	audit      repository.AuditRepository
+	revisions  repository.RevisionRepository
+	prices     repository.PriceRepository
+	schedules  repository.PriceScheduleRepository
+	categories repository.CategoryRepository
+	tags       repository.TagRepository
+	variants   repository.VariantRepository
+	inventory  repository.InventoryRepository
+	tx         repository.TxManager
 }
 
-func NewProductService(repo repository.ProductRepository) ProductService {
-	return &productService{repo: repo}
+func NewProductService(repo repository.ProductRepository, audit repository.AuditRepository, revisions repository.RevisionRepository, prices repository.PriceRepository, schedules repository.PriceScheduleRepository, categories repository.CategoryRepository, tags repository.TagRepository, variants repository.VariantRepository, inventory repository.InventoryRepository, tx repository.TxManager) ProductService {
+	return &productService{repo: repo, audit: audit, revisions: revisions, prices: prices, schedules: schedules, categories: categories, tags: tags, variants: variants, inventory: inventory, tx: tx}
 }
 
-func (s *productService) GetAllProducts(ctx context.Context, limit, offset int) ([]models.Product, error) {
-	return s.repo.GetAll(ctx, limit, offset)
+// ListProducts returns a page of products and the total number of matches.
+// One extra row is fetched to tell whether another page follows, so
+// HasMore stays exact even when the total is only estimated.
+func (s *productService) ListProducts(ctx context.Context, filter models.ProductFilter, estimateTotal bool) (*models.ProductPage, error) {
+	if err := s.validateFilter(ctx, &filter); err != nil {
+		return nil, err
+	}
+	if filter.Limit <= 0 {
+		filter.Limit = 100
+	}
+	page := &models.ProductPage{Limit: filter.Limit, Offset: filter.Offset}
+	filter.Limit++
+	items, err := s.repo.GetAll(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	if len(items) > page.Limit {
+		items, page.HasMore = items[:page.Limit], true
+	}
+	page.Items = items
+	// Sparse fieldsets never include the effective price, breadcrumbs, tags
+	// or availability.
+	if len(filter.Fields) == 0 {
+		if err := s.resolvePrices(ctx, page.Items, time.Now()); err != nil {
+			return nil, err
+		}
+		if err := s.resolveLabels(ctx, page.Items); err != nil {
+			return nil, err
+		}
+	}
+
+	// Table statistics cover every row including the trash, so they are
+	// only a fair estimate for the plain listing.
+	total := -1
+	if estimateTotal && !filter.Narrowed() {
+		if total, err = s.repo.EstimateCount(ctx); err != nil {
+			return nil, err
+		}
+		page.TotalEstimated = total >= 0
+	}
+	if total < 0 {
+		if total, err = s.repo.Count(ctx, filter); err != nil {
+			return nil, err
+		}
+	}
+	// Keep an outdated estimate consistent with what was actually read.
+	if seen := page.Offset + len(items); total < seen || (page.HasMore && total == seen) {
+		total = seen
+		if page.HasMore {
+			total++
+		}
+	}
+	page.Total = total
+	return page, nil
+}
+
+func (s *productService) ExportProducts(ctx context.Context, filter models.ProductFilter, fn func([]models.Product) error) error {
+	if err := s.validateFilter(ctx, &filter); err != nil {
+		return err
+	}
+	return s.repo.Stream(ctx, filter, fn)
+}
+
+// validateFilter checks a filter and normalizes its tags.
+func (s *productService) validateFilter(ctx context.Context, f *models.ProductFilter) error {
+	switch f.TagMode {
+	case "":
+		f.TagMode = models.TagModeAll
+	case models.TagModeAll, models.TagModeAny:
+	default:
+		return fmt.Errorf("%w: tag_mode must be all or any", ErrValidation)
+	}
+	tags, err := normalizeTags(f.Tags)
+	if err != nil {
+		return err
+	}
+	f.Tags = tags
+	if len(f.Attributes) > maxAttributeFilters {
+		return fmt.Errorf("%w: at most %d attribute filters are allowed", ErrValidation, maxAttributeFilters)
+	}
+	for _, a := range f.Attributes {
+		if err := validateAttributeFilter(a); err != nil {
+			return err
+		}
+	}
+	if f.CategoryID != 0 {
+		_, err := s.categories.GetByID(ctx, f.CategoryID)
+		if errors.Is(err, repository.ErrNotFound) {
+			return ErrCategoryNotFound
+		}
+		if err != nil {
+			return err
+		}
+	}
+	for _, name := range f.Fields {
+		if !slices.Contains(models.ProductFields, name) {
+			return fmt.Errorf("%w: unknown field %q", ErrValidation, name)
+		}
+	}
+	for _, name := range f.Include {
+		if !slices.Contains(models.ProductIncludes, name) {
+			return fmt.Errorf("%w: unknown include %q", ErrValidation, name)
+		}
+	}
+	return nil
 }
 
-func validateProduct(p *models.Product) error {
+func validateAttributeFilter(f models.AttributeFilter) error {
+	param := "attr." + f.Name
+	if f.Op != models.AttributeEq {
+		param += "_" + string(f.Op)
+	}
+	if !attrschema.ValidName(f.Name) {
+		return fmt.Errorf("%w: %s: invalid attribute name", ErrValidation, param)
+	}
+	if len(f.Values) == 0 {
+		return fmt.Errorf("%w: %s needs a value", ErrValidation, param)
+	}
+	if f.Op == models.AttributeEq {
+		return nil
+	}
+	if len(f.Values) != 1 || !attrschema.IsNumber(f.Values[0]) {
+		return fmt.Errorf("%w: %s must be a single number", ErrValidation, param)
+	}
+	return nil
+}
+
+// validateProduct checks a product before it is written; schema, if not
+// nil, is the attribute schema of its category.
+func validateProduct(p *models.Product, schema *attrschema.Schema) error {
 	if p.Name == "" {
 		return fmt.Errorf("%w: name is required", ErrValidation)
 	}
@@ -43,38 +203,515 @@ func validateProduct(p *models.Product) error {
 	if len(p.Description) > MaxDescriptionLength {
 		return fmt.Errorf("%w: description must be at most %d characters", ErrValidation, MaxDescriptionLength)
 	}
-	if p.Price < 0 {
+	if err := validatePrice(p.Price); err != nil {
+		return err
+	}
+	if err := validateIdentifiers(p); err != nil {
+		return err
+	}
+	return validateAttributes(p.Attributes, schema)
+}
+
+// validateIdentifiers trims the SKU, barcode and slug of a product and
+// checks them; a UPC-A barcode is stored as its EAN-13 and an empty slug is
+// filled in later.
+func validateIdentifiers(p *models.Product) error {
+	p.SKU = strings.TrimSpace(p.SKU)
+	p.Barcode = strings.TrimSpace(p.Barcode)
+	p.Slug = strings.TrimSpace(p.Slug)
+	if p.SKU != "" && !skuPattern.MatchString(p.SKU) {
+		return fmt.Errorf("%w: sku may only contain letters, digits, '.', '_' and '-', up to 64 characters", ErrValidation)
+	}
+	if p.Barcode != "" && !models.ValidBarcode(p.Barcode) {
+		return fmt.Errorf("%w: barcode must be an EAN-13, UPC-A or EAN-8 code with a valid check digit", ErrValidation)
+	}
+	p.Barcode = models.NormalizeBarcode(p.Barcode)
+	if p.Slug != "" && !models.ValidSlug(p.Slug) {
+		return fmt.Errorf("%w: slug must be lowercase letters and digits in words joined by '-', up to %d characters", ErrValidation, models.MaxSlugLength)
+	}
+	return nil
+}
+
+// assignSlug gives a product without a slug one made from its name, with a
+// numeric suffix if another product has it already.
+func (s *productService) assignSlug(ctx context.Context, p *models.Product) error {
+	if p.Slug != "" {
+		return nil
+	}
+	base := models.Slugify(p.Name)
+	if base == "" {
+		base = "product"
+	}
+	// Leave room for a suffix.
+	if len(base) > models.MaxSlugLength-8 {
+		base = strings.TrimRight(base[:models.MaxSlugLength-8], "-")
+	}
+	taken, err := s.repo.SlugsLike(ctx, base, p.ID)
+	if err != nil {
+		return err
+	}
+	slug := base
+	for n := 2; slices.Contains(taken, slug); n++ {
+		slug = base + "-" + strconv.Itoa(n)
+	}
+	p.Slug = slug
+	return nil
+}
+
+// writeIdentifiers prepares the SKU and slug of a product about to be
+// written; before is its current state, or nil for a new product.
+func (s *productService) writeIdentifiers(ctx context.Context, p, before *models.Product) error {
+	if p.SKU != "" && (before == nil || p.SKU != before.SKU) {
+		if err := s.repo.ClaimSKU(ctx, p.SKU); err != nil {
+			return identifierError(err, p)
+		}
+	}
+	// Slugs end up in URLs, so an update without one keeps the old slug
+	// rather than following a renamed product.
+	if p.Slug == "" && before != nil {
+		p.Slug = before.Slug
+	}
+	return s.assignSlug(ctx, p)
+}
+
+// identifierError turns a clash on a unique identifier into ErrConflict.
+func identifierError(err error, p *models.Product) error {
+	var dup *repository.DuplicateError
+	if !errors.As(err, &dup) {
+		return err
+	}
+	value := map[string]string{"sku": p.SKU, "barcode": p.Barcode, "slug": p.Slug}[dup.Column]
+	return fmt.Errorf("%w: %s %q is already in use", ErrConflict, dup.Column, value)
+}
+
+func validateAttributes(attrs models.Attributes, schema *attrschema.Schema) error {
+	if errs := attrschema.CheckShape(attrs); len(errs) > 0 {
+		return &ValidationError{Message: "invalid attributes", Fields: errs}
+	}
+	if schema == nil {
+		return nil
+	}
+	if errs := schema.Validate(attrs); len(errs) > 0 {
+		return &ValidationError{Message: "attributes do not match the category schema", Fields: errs}
+	}
+	return nil
+}
+
+// parseSchema parses a stored attribute schema; nil stands for none.
+func parseSchema(raw json.RawMessage) (*attrschema.Schema, error) {
+	if raw == nil {
+		return nil, nil
+	}
+	schema, err := attrschema.Parse(raw)
+	if err != nil {
+		return nil, fmt.Errorf("stored attribute schema: %w", err)
+	}
+	return schema, nil
+}
+
+// validatePrice checks a price decoded from a request; decoding already
+// rejects amounts with more decimal places than the currency allows.
+func validatePrice(m models.Money) error {
+	if m.Currency == "" {
+		return fmt.Errorf("%w: price with amount and currency is required", ErrValidation)
+	}
+	if _, ok := models.CurrencyExponent(m.Currency); !ok {
+		return fmt.Errorf("%w: unsupported currency %q", ErrValidation, m.Currency)
+	}
+	if m.Amount < 0 {
 		return fmt.Errorf("%w: price cannot be negative", ErrValidation)
 	}
 	return nil
 }
 
 func (s *productService) CreateProduct(ctx context.Context, product *models.Product) error {
-	if err := validateProduct(product); err != nil {
+	// A new product has no category yet, so only the shape of its
+	// attributes is checked.
+	if err := validateProduct(product, nil); err != nil {
 		return err
 	}
-	return s.repo.Create(ctx, product)
+	clearDerived(product)
+	return s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.writeIdentifiers(ctx, product, nil); err != nil {
+			return err
+		}
+		if err := s.repo.Create(ctx, product); err != nil {
+			return identifierError(err, product)
+		}
+		return s.recordChange(ctx, models.AuditCreate, nil, product)
+	})
 }
 
 func (s *productService) GetProductByID(ctx context.Context, id int) (*models.Product, error) {
 	p, err := s.repo.GetByID(ctx, id)
-	if err != nil && errors.Is(err, repository.ErrNotFound) {
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	if p, err = s.resolvePrice(ctx, p, time.Now()); err != nil {
+		return nil, err
+	}
+	products := []models.Product{*p}
+	if err := s.resolveLabels(ctx, products); err != nil {
+		return nil, err
+	}
+	if err := s.resolveVariants(ctx, &products[0]); err != nil {
+		return nil, err
+	}
+	return &products[0], nil
+}
+
+func (s *productService) GetProductBySKU(ctx context.Context, sku string) (*models.Product, error) {
+	id, err := s.repo.FindBySKU(ctx, strings.TrimSpace(sku))
+	return s.found(ctx, id, err)
+}
+
+func (s *productService) GetProductByBarcode(ctx context.Context, code string) (*models.Product, error) {
+	id, err := s.repo.FindByBarcode(ctx, models.NormalizeBarcode(strings.TrimSpace(code)))
+	return s.found(ctx, id, err)
+}
+
+func (s *productService) GetProductBySlug(ctx context.Context, slug string) (*models.Product, error) {
+	id, err := s.repo.FindBySlug(ctx, strings.TrimSpace(slug))
+	return s.found(ctx, id, err)
+}
+
+// found reads the product a lookup by another key returned.
+func (s *productService) found(ctx context.Context, id int, err error) (*models.Product, error) {
+	if errors.Is(err, repository.ErrNotFound) {
 		return nil, ErrNotFound
 	}
-	return p, err
+	if err != nil {
+		return nil, err
+	}
+	return s.GetProductByID(ctx, id)
+}
+
+// GetProductAsOf returns the product as it was at the given instant,
+// reconstructed from its revisions.
+func (s *productService) GetProductAsOf(ctx context.Context, id int, at time.Time) (*models.Product, error) {
+	rev, err := s.revisions.AsOf(ctx, id, at)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	if rev.Snapshot.DeletedAt != nil {
+		return nil, ErrNotFound
+	}
+	return s.resolvePrice(ctx, &rev.Snapshot, at)
+}
+
+// resolvePrices sets the effective price of each product at the given
+// instant.
+func (s *productService) resolvePrices(ctx context.Context, products []models.Product, at time.Time) error {
+	ids := make([]int, len(products))
+	for i := range products {
+		ids[i] = products[i].ID
+	}
+	scheduled, err := s.schedules.EffectivePrices(ctx, ids, at)
+	if err != nil {
+		return fmt.Errorf("resolve scheduled prices: %w", err)
+	}
+	for i := range products {
+		price := products[i].Price
+		// A schedule set up before the product switched currency no
+		// longer applies.
+		if sched, ok := scheduled[products[i].ID]; ok && sched.Price.Currency == price.Currency {
+			price = sched.Price
+		}
+		products[i].EffectivePrice = &price
+	}
+	return nil
+}
+
+// resolveLabels sets the path to the primary category, the tags and the
+// stock available to promise of each product.
+func (s *productService) resolveLabels(ctx context.Context, products []models.Product) error {
+	ids := make([]int, len(products))
+	for i := range products {
+		ids[i] = products[i].ID
+	}
+	crumbs, err := s.categories.Breadcrumbs(ctx, ids)
+	if err != nil {
+		return fmt.Errorf("resolve breadcrumbs: %w", err)
+	}
+	tags, err := s.tags.ForProducts(ctx, ids)
+	if err != nil {
+		return fmt.Errorf("resolve tags: %w", err)
+	}
+	available, err := s.inventory.Available(ctx, ids)
+	if err != nil {
+		return fmt.Errorf("resolve availability: %w", err)
+	}
+	for i := range products {
+		products[i].Breadcrumbs = crumbs[products[i].ID]
+		products[i].Tags = tags[products[i].ID]
+		n := available[products[i].ID]
+		products[i].Available = &n
+	}
+	return nil
+}
+
+// resolveVariants embeds the options and variants of a product.
+func (s *productService) resolveVariants(ctx context.Context, p *models.Product) error {
+	var err error
+	if p.Options, err = s.variants.Options(ctx, p.ID); err != nil {
+		return fmt.Errorf("resolve options: %w", err)
+	}
+	if p.Variants, err = s.variants.List(ctx, p.ID); err != nil {
+		return fmt.Errorf("resolve variants: %w", err)
+	}
+	setVariantPrices(p)
+	return nil
+}
+
+// clearDerived drops the fields that are computed on reads, so that they
+// never end up in revisions or the audit log.
+func clearDerived(p *models.Product) {
+	p.EffectivePrice, p.ConvertedPrice = nil, nil
+	p.Breadcrumbs, p.Tags = nil, nil
+	p.Options, p.Variants = nil, nil
+	p.Available = nil
+}
+
+func (s *productService) resolvePrice(ctx context.Context, p *models.Product, at time.Time) (*models.Product, error) {
+	products := []models.Product{*p}
+	if err := s.resolvePrices(ctx, products, at); err != nil {
+		return nil, err
+	}
+	return &products[0], nil
 }
 
 func (s *productService) UpdateProduct(ctx context.Context, product *models.Product) error {
-	if err := validateProduct(product); err != nil {
+	if err := validateProduct(product, nil); err != nil {
+		return err
+	}
+	clearDerived(product)
+	return s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		before, err := s.lockLive(ctx, product.ID)
+		if err != nil {
+			return err
+		}
+		if product.Price.Currency != before.Price.Currency {
+			if err := s.checkVariantPrices(ctx, product.ID, before.Price.Currency); err != nil {
+				return err
+			}
+		}
+		// The category schema is enforced when attributes change, so that a
+		// schema introduced later does not block unrelated updates such as
+		// scheduled price changes.
+		if product.Attributes.String() != before.Attributes.String() {
+			raw, err := s.categories.ProductSchema(ctx, product.ID)
+			if err != nil {
+				return err
+			}
+			schema, err := parseSchema(raw)
+			if err != nil {
+				return err
+			}
+			if err := validateAttributes(product.Attributes, schema); err != nil {
+				return err
+			}
+		}
+		if err := s.writeIdentifiers(ctx, product, before); err != nil {
+			return err
+		}
+		if err := s.repo.Update(ctx, product); err != nil {
+			return identifierError(err, product)
+		}
+		return s.recordChange(ctx, models.AuditUpdate, before, product)
+	})
+}
+
+// checkVariantPrices refuses to move a product to another currency while
+// variants override its price in the current one, as their prices would
+// no longer match the product's.
+func (s *productService) checkVariantPrices(ctx context.Context, id int, currency string) error {
+	variants, err := s.variants.List(ctx, id)
+	if err != nil {
 		return err
 	}
-	return s.repo.Update(ctx, product)
+	var skus []string
+	for _, v := range variants {
+		if v.Price != nil {
+			skus = append(skus, v.SKU)
+		}
+	}
+	if len(skus) > 0 {
+		return fmt.Errorf("%w: variants %s have prices in %s; change or clear them before the product's currency",
+			ErrConflict, strings.Join(skus, ", "), currency)
+	}
+	return nil
+}
+
+func (s *productService) SetPrice(ctx context.Context, id int, price models.Money) error {
+	if err := validatePrice(price); err != nil {
+		return err
+	}
+	return s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		before, err := s.lockLive(ctx, id)
+		if err != nil {
+			return err
+		}
+		if price.Currency != before.Price.Currency {
+			return fmt.Errorf("%w: product %d is sold in %s", ErrCurrencyMismatch, id, before.Price.Currency)
+		}
+		after := *before
+		after.Price = price
+		if err := s.repo.Update(ctx, &after); err != nil {
+			return err
+		}
+		return s.recordChange(ctx, models.AuditUpdate, before, &after)
+	})
 }
 
 func (s *productService) DeleteProduct(ctx context.Context, id int) error {
-	err := s.repo.Delete(ctx, id)
-	if err != nil && errors.Is(err, repository.ErrNotFound) {
-		return ErrNotFound
+	return s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		before, err := s.lockLive(ctx, id)
+		if err != nil {
+			return err
+		}
+		if err := s.repo.Delete(ctx, id); err != nil {
+			return err
+		}
+		after, err := s.repo.GetForUpdate(ctx, id)
+		if err != nil {
+			return err
+		}
+		return s.recordChange(ctx, models.AuditDelete, before, after)
+	})
+}
+
+func (s *productService) RestoreProduct(ctx context.Context, id int) (*models.Product, error) {
+	var restored models.Product
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		before, err := s.repo.GetForUpdate(ctx, id)
+		if err != nil {
+			if errors.Is(err, repository.ErrNotFound) {
+				return ErrNotFound
+			}
+			return err
+		}
+		if before.DeletedAt == nil {
+			return ErrNotFound
+		}
+		if err := s.repo.Restore(ctx, id); err != nil {
+			return err
+		}
+		restored = *before
+		restored.DeletedAt = nil
+		return s.recordChange(ctx, models.AuditRestore, before, &restored)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &restored, nil
+}
+
+// PurgeDeletedProducts permanently removes products that have been in the
+// trash for longer than retention.
+func (s *productService) PurgeDeletedProducts(ctx context.Context, retention time.Duration) (int64, error) {
+	var purged []models.Product
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+		if purged, err = s.repo.Purge(ctx, time.Now().Add(-retention)); err != nil {
+			return err
+		}
+		for i := range purged {
+			if err := s.recordChange(ctx, models.AuditPurge, &purged[i], nil); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return int64(len(purged)), nil
+}
+
+// lockLive locks a product that is not in the trash and returns its
+// current state.
+func (s *productService) lockLive(ctx context.Context, id int) (*models.Product, error) {
+	p, err := s.repo.GetForUpdate(ctx, id)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	if p.DeletedAt != nil {
+		return nil, ErrNotFound
+	}
+	return p, nil
+}
+
+// recordChange appends an audit entry for a product change and, unless the
+// product is gone, a snapshot of its new state and, if the price changed, a
+// new price period. It must be called inside the transaction that makes
+// the change.
+func (s *productService) recordChange(ctx context.Context, action models.AuditAction, before, after *models.Product) error {
+	id := 0
+	if after != nil {
+		id = after.ID
+	} else if before != nil {
+		id = before.ID
+	}
+	actor, requestID := reqctx.Actor(ctx), reqctx.RequestID(ctx)
+	entry := &models.AuditEntry{
+		ProductID: id,
+		Action:    action,
+		Actor:     actor,
+		RequestID: requestID,
+		Changes:   diffProducts(before, after),
+	}
+	if err := s.audit.Insert(ctx, entry); err != nil {
+		return fmt.Errorf("record %s of product %d: %w", action, id, err)
+	}
+	if after == nil {
+		return nil
+	}
+	rev := &models.ProductRevision{ProductID: id, Snapshot: *after, Actor: actor, RequestID: requestID}
+	if err := s.revisions.Insert(ctx, rev); err != nil {
+		return fmt.Errorf("record revision of product %d: %w", id, err)
+	}
+	if before == nil || before.Price != after.Price {
+		if err := s.prices.SetPrice(ctx, id, after.Price); err != nil {
+			return fmt.Errorf("record price of product %d: %w", id, err)
+		}
+	}
+	return nil
+}
+
+func (s *productService) BackfillSlugs(ctx context.Context) (int, error) {
+	ids, err := s.repo.WithoutSlug(ctx)
+	if err != nil {
+		return 0, err
+	}
+	n := 0
+	for _, id := range ids {
+		err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+			p, err := s.repo.GetForUpdate(ctx, id)
+			if err != nil || p.Slug != "" {
+				// Purged or given a slug in the meantime.
+				return err
+			}
+			if err := s.assignSlug(ctx, p); err != nil {
+				return err
+			}
+			if err := s.repo.SetSlug(ctx, p.ID, p.Slug); err != nil {
+				return err
+			}
+			n++
+			return nil
+		})
+		if err != nil && !errors.Is(err, repository.ErrNotFound) {
+			return n, fmt.Errorf("product %d: %w", id, err)
+		}
 	}
-	return err
+	return n, nil
 }
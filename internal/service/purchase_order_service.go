@@ -0,0 +1,365 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"product-test/internal/models"
+	"product-test/internal/repository"
+	"product-test/internal/reqctx"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+const maxPurchaseOrderLines = 500
+
+// CostMethod is how receiving stock changes the cost price of a product.
+type CostMethod string
+
+const (
+	// CostWeightedAverage averages the cost price of the stock on hand
+	// with the cost of the stock received, weighted by quantity.
+	CostWeightedAverage CostMethod = "weighted_average"
+	// CostLast sets the cost price to the cost of the stock last received.
+	CostLast CostMethod = "last_cost"
+)
+
+type PurchaseOrderService interface {
+	ListPurchaseOrders(ctx context.Context, filter models.PurchaseOrderFilter) ([]models.PurchaseOrder, int, error)
+	GetPurchaseOrder(ctx context.Context, id int64) (*models.PurchaseOrder, error)
+	// CreatePurchaseOrder drafts an order for products the supplier
+	// supplies.
+	CreatePurchaseOrder(ctx context.Context, in models.PurchaseOrderInput) (*models.PurchaseOrder, error)
+	// UpdatePurchaseOrder replaces a draft.
+	UpdatePurchaseOrder(ctx context.Context, id int64, in models.PurchaseOrderInput) (*models.PurchaseOrder, error)
+	SendPurchaseOrder(ctx context.Context, id int64) (*models.PurchaseOrder, error)
+	// CancelPurchaseOrder cancels a draft or a sent order of which nothing
+	// has been received.
+	CancelPurchaseOrder(ctx context.Context, id int64) (*models.PurchaseOrder, error)
+	// ReceivePurchaseOrder puts stock of a sent order that has arrived into
+	// its warehouse and updates the cost prices of the products.
+	ReceivePurchaseOrder(ctx context.Context, id int64, in models.ReceiptInput) (*models.PurchaseOrder, error)
+}
+
+type purchaseOrderService struct {
+	repo       repository.PurchaseOrderRepository
+	suppliers  repository.SupplierRepository
+	warehouses repository.WarehouseRepository
+	products   repository.ProductRepository
+	variants   repository.VariantRepository
+	inventory  InventoryService
+	tx         repository.TxManager
+	costMethod CostMethod
+}
+
+func NewPurchaseOrderService(repo repository.PurchaseOrderRepository, suppliers repository.SupplierRepository, warehouses repository.WarehouseRepository, products repository.ProductRepository, variants repository.VariantRepository, inventory InventoryService, tx repository.TxManager, costMethod CostMethod) PurchaseOrderService {
+	return &purchaseOrderService{repo: repo, suppliers: suppliers, warehouses: warehouses, products: products, variants: variants, inventory: inventory, tx: tx, costMethod: costMethod}
+}
+
+var purchaseOrderStatuses = []models.PurchaseOrderStatus{
+	models.PurchaseOrderDraft, models.PurchaseOrderSent, models.PurchaseOrderPartiallyReceived,
+	models.PurchaseOrderReceived, models.PurchaseOrderCancelled,
+}
+
+func (s *purchaseOrderService) ListPurchaseOrders(ctx context.Context, filter models.PurchaseOrderFilter) ([]models.PurchaseOrder, int, error) {
+	if filter.Status != "" && !slices.Contains(purchaseOrderStatuses, filter.Status) {
+		return nil, 0, fmt.Errorf("%w: unknown purchase order status %q", ErrValidation, filter.Status)
+	}
+	orders, err := s.repo.List(ctx, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+	total, err := s.repo.Count(ctx, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+	return orders, total, nil
+}
+
+func (s *purchaseOrderService) GetPurchaseOrder(ctx context.Context, id int64) (*models.PurchaseOrder, error) {
+	o, err := s.repo.GetByID(ctx, id)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, ErrPurchaseOrderNotFound
+	}
+	return o, err
+}
+
+// lock reads and locks a purchase order that must be in one of the given
+// statuses.
+func (s *purchaseOrderService) lock(ctx context.Context, id int64, statuses ...models.PurchaseOrderStatus) (*models.PurchaseOrder, error) {
+	o, err := s.repo.Lock(ctx, id)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, ErrPurchaseOrderNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !slices.Contains(statuses, o.Status) {
+		return nil, fmt.Errorf("%w: purchase order %d is %s", ErrPurchaseOrderState, id, o.Status)
+	}
+	return o, nil
+}
+
+func validatePurchaseOrder(in models.PurchaseOrderInput) error {
+	if err := validateCurrency(in.Currency); err != nil {
+		return err
+	}
+	if len(in.Lines) == 0 || len(in.Lines) > maxPurchaseOrderLines {
+		return fmt.Errorf("%w: a purchase order has 1 to %d lines", ErrValidation, maxPurchaseOrderLines)
+	}
+	if len(in.Note) > maxNoteLength {
+		return fmt.Errorf("%w: note must be at most %d characters", ErrValidation, maxNoteLength)
+	}
+	seen := make(map[models.StockKey]bool, len(in.Lines))
+	for _, l := range in.Lines {
+		if seen[l.Key()] {
+			return fmt.Errorf("%w: %s appears more than once", ErrValidation, l.Key())
+		}
+		seen[l.Key()] = true
+		if l.Quantity <= 0 || l.Quantity > maxMovementQuantity {
+			return fmt.Errorf("%w: quantity of %s must be a positive number of units up to %d", ErrValidation, l.Key(), maxMovementQuantity)
+		}
+		if c := l.UnitCost; c != nil {
+			if c.Currency != in.Currency {
+				return fmt.Errorf("%w: unit_cost of %s must be in %s", ErrValidation, l.Key(), in.Currency)
+			}
+			if c.Amount < 0 {
+				return fmt.Errorf("%w: unit_cost of %s cannot be negative", ErrValidation, l.Key())
+			}
+		}
+	}
+	return nil
+}
+
+// build checks a purchase order against its supplier and warehouse and
+// fills it in from the input, taking the supplier's SKUs and, where no
+// unit cost is given, cost prices. Variants are supplied as their product
+// is.
+func (s *purchaseOrderService) build(ctx context.Context, o *models.PurchaseOrder, in models.PurchaseOrderInput) error {
+	_, err := s.suppliers.GetByID(ctx, in.SupplierID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return fmt.Errorf("%w: supplier %d does not exist", ErrValidation, in.SupplierID)
+	}
+	if err != nil {
+		return err
+	}
+	if o.WarehouseID, err = resolveWarehouse(ctx, s.warehouses, in.WarehouseID); err != nil {
+		return err
+	}
+	o.SupplierID, o.Currency, o.Note = in.SupplierID, in.Currency, in.Note
+	o.Lines = make([]models.PurchaseOrderLine, len(in.Lines))
+	for i, l := range in.Lines {
+		if err := checkStockItem(ctx, s.products, s.variants, l.Key()); err != nil {
+			return err
+		}
+		sp, err := s.suppliers.Product(ctx, in.SupplierID, l.ProductID)
+		if errors.Is(err, repository.ErrNotFound) {
+			return fmt.Errorf("%w: product %d is not supplied by supplier %d", ErrValidation, l.ProductID, in.SupplierID)
+		}
+		if err != nil {
+			return err
+		}
+		cost := l.UnitCost
+		if cost == nil {
+			if sp.CostPrice == nil || sp.CostPrice.Currency != in.Currency {
+				return fmt.Errorf("%w: unit_cost of %s is required; the supplier has no cost price for it in %s",
+					ErrValidation, l.Key(), in.Currency)
+			}
+			cost = sp.CostPrice
+		}
+		o.Lines[i] = models.PurchaseOrderLine{
+			ProductID:   l.ProductID,
+			VariantID:   l.VariantID,
+			SupplierSKU: sp.SupplierSKU,
+			Quantity:    l.Quantity,
+			UnitCost:    *cost,
+		}
+	}
+	return nil
+}
+
+func normalizePurchaseOrder(in *models.PurchaseOrderInput) {
+	in.Currency = strings.ToUpper(strings.TrimSpace(in.Currency))
+}
+
+func (s *purchaseOrderService) CreatePurchaseOrder(ctx context.Context, in models.PurchaseOrderInput) (*models.PurchaseOrder, error) {
+	normalizePurchaseOrder(&in)
+	if err := validatePurchaseOrder(in); err != nil {
+		return nil, err
+	}
+	o := &models.PurchaseOrder{Status: models.PurchaseOrderDraft, Actor: reqctx.Actor(ctx)}
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.build(ctx, o, in); err != nil {
+			return err
+		}
+		return s.repo.Create(ctx, o)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.GetPurchaseOrder(ctx, o.ID)
+}
+
+func (s *purchaseOrderService) UpdatePurchaseOrder(ctx context.Context, id int64, in models.PurchaseOrderInput) (*models.PurchaseOrder, error) {
+	normalizePurchaseOrder(&in)
+	if err := validatePurchaseOrder(in); err != nil {
+		return nil, err
+	}
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		o, err := s.lock(ctx, id, models.PurchaseOrderDraft)
+		if err != nil {
+			return err
+		}
+		if err := s.build(ctx, o, in); err != nil {
+			return err
+		}
+		return s.repo.Update(ctx, o)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.GetPurchaseOrder(ctx, id)
+}
+
+// transition moves a purchase order in one of the given statuses to
+// another.
+func (s *purchaseOrderService) transition(ctx context.Context, id int64, to models.PurchaseOrderStatus, from ...models.PurchaseOrderStatus) (*models.PurchaseOrder, error) {
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		o, err := s.lock(ctx, id, from...)
+		if err != nil {
+			return err
+		}
+		return s.repo.SetStatus(ctx, o, to)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.GetPurchaseOrder(ctx, id)
+}
+
+func (s *purchaseOrderService) SendPurchaseOrder(ctx context.Context, id int64) (*models.PurchaseOrder, error) {
+	return s.transition(ctx, id, models.PurchaseOrderSent, models.PurchaseOrderDraft)
+}
+
+func (s *purchaseOrderService) CancelPurchaseOrder(ctx context.Context, id int64) (*models.PurchaseOrder, error) {
+	return s.transition(ctx, id, models.PurchaseOrderCancelled, models.PurchaseOrderDraft, models.PurchaseOrderSent)
+}
+
+func validateReceipt(in models.ReceiptInput) error {
+	if len(in.Lines) == 0 || len(in.Lines) > maxPurchaseOrderLines {
+		return fmt.Errorf("%w: a receipt has 1 to %d lines", ErrValidation, maxPurchaseOrderLines)
+	}
+	if len(in.Note) > maxNoteLength {
+		return fmt.Errorf("%w: note must be at most %d characters", ErrValidation, maxNoteLength)
+	}
+	seen := make(map[models.StockKey]bool, len(in.Lines))
+	for _, l := range in.Lines {
+		if seen[l.Key()] {
+			return fmt.Errorf("%w: %s appears more than once", ErrValidation, l.Key())
+		}
+		seen[l.Key()] = true
+		if l.Quantity <= 0 || l.Quantity > maxMovementQuantity {
+			return fmt.Errorf("%w: quantity of %s must be a positive number of units up to %d", ErrValidation, l.Key(), maxMovementQuantity)
+		}
+		if err := validateLot(models.StockMovementInput{Lot: l.Lot, ExpiresOn: l.ExpiresOn}, l.Quantity); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *purchaseOrderService) ReceivePurchaseOrder(ctx context.Context, id int64, in models.ReceiptInput) (*models.PurchaseOrder, error) {
+	if err := validateReceipt(in); err != nil {
+		return nil, err
+	}
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		o, err := s.lock(ctx, id, models.PurchaseOrderSent, models.PurchaseOrderPartiallyReceived)
+		if err != nil {
+			return err
+		}
+		lines := make(map[models.StockKey]*models.PurchaseOrderLine, len(o.Lines))
+		for i := range o.Lines {
+			lines[o.Lines[i].Key()] = &o.Lines[i]
+		}
+		keys := make([]models.StockKey, len(in.Lines))
+		movements := make([]models.StockMovement, len(in.Lines))
+		for i, l := range in.Lines {
+			ol, ok := lines[l.Key()]
+			if !ok {
+				return fmt.Errorf("%w: %s is not on purchase order %d", ErrValidation, l.Key(), id)
+			}
+			if rest := ol.Quantity - ol.Received; l.Quantity > rest {
+				return fmt.Errorf("%w: %d of %s are still to be received, %d given", ErrValidation, rest, l.Key(), l.Quantity)
+			}
+			keys[i] = l.Key()
+			movements[i] = models.StockMovement{
+				ProductID:   l.ProductID,
+				VariantID:   l.VariantID,
+				WarehouseID: o.WarehouseID,
+				Type:        models.MovementReceipt,
+				Quantity:    l.Quantity,
+				Reference:   "purchase_order:" + strconv.FormatInt(id, 10),
+				Note:        in.Note,
+			}
+			if l.Lot != "" {
+				movements[i].Lots = []models.LotQuantity{{Lot: l.Lot, ExpiresOn: l.ExpiresOn, Quantity: l.Quantity}}
+			}
+		}
+		// The cost prices are worked out from the stock on hand before
+		// the receipt.
+		stocks, err := s.inventory.Lock(ctx, keys)
+		if err != nil {
+			return err
+		}
+		if err := s.inventory.Post(ctx, movements); err != nil {
+			return err
+		}
+		for _, l := range in.Lines {
+			ol := lines[l.Key()]
+			cost := costPrice(s.costMethod, stocks[l.Key()], l.Quantity, ol.UnitCost)
+			if err := s.inventory.SetCostPrice(ctx, l.Key(), cost); err != nil {
+				return err
+			}
+			if err := s.repo.Receive(ctx, id, l.Key(), l.Quantity); err != nil {
+				return err
+			}
+			ol.Received += l.Quantity
+		}
+		status := models.PurchaseOrderReceived
+		for _, ol := range o.Lines {
+			if ol.Received < ol.Quantity {
+				status = models.PurchaseOrderPartiallyReceived
+				break
+			}
+		}
+		if status == o.Status {
+			return nil
+		}
+		return s.repo.SetStatus(ctx, o, status)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.GetPurchaseOrder(ctx, id)
+}
+
+// costPrice works out the cost price of an item once quantity units
+// costing unitCost each are received. Stock on hand is only averaged in
+// when it is positive and costed in the same currency; otherwise the cost
+// price starts over from the receipt.
+func costPrice(method CostMethod, stock *models.Stock, quantity int64, unitCost models.Money) models.Money {
+	old := stock.CostPrice
+	if method == CostLast || old == nil || old.Currency != unitCost.Currency || stock.OnHand <= 0 {
+		return unitCost
+	}
+	// (on hand × old + quantity × unit) / (on hand + quantity), rounded
+	// half up; the products can overflow an int64.
+	total := new(big.Int).Mul(big.NewInt(stock.OnHand), big.NewInt(old.Amount))
+	total.Add(total, new(big.Int).Mul(big.NewInt(quantity), big.NewInt(unitCost.Amount)))
+	units := big.NewInt(stock.OnHand + quantity)
+	total.Mul(total, big.NewInt(2)).Add(total, units)
+	total.Quo(total, units.Mul(units, big.NewInt(2)))
+	return models.Money{Amount: total.Int64(), Currency: unitCost.Currency}
+}
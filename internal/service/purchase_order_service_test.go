@@ -0,0 +1,46 @@
+package service
+
+import (
+	"product-test/internal/models"
+	"testing"
+)
+
+func TestCostPrice(t *testing.T) {
+	eur := func(amount int64) models.Money { return models.Money{Amount: amount, Currency: "EUR"} }
+	usd := func(amount int64) models.Money { return models.Money{Amount: amount, Currency: "USD"} }
+	stock := func(onHand int64, cost *models.Money) *models.Stock {
+		return &models.Stock{OnHand: onHand, CostPrice: cost}
+	}
+	old := eur(100)
+	large := eur(1_000_000_000_000)
+	tests := []struct {
+		name            string
+		stock           *models.Stock
+		quantity        int64
+		unitCost        models.Money
+		average, latest models.Money
+	}{
+		{"first receipt", stock(0, nil), 10, eur(250), eur(250), eur(250)},
+		{"no cost price yet", stock(10, nil), 10, eur(250), eur(250), eur(250)},
+		{"even average", stock(10, &old), 10, eur(200), eur(150), eur(200)},
+		{"weighted by quantity", stock(30, &old), 10, eur(200), eur(125), eur(200)},
+		{"half rounds up", stock(1, &old), 1, eur(101), eur(101), eur(101)},
+		{"a third rounds down", stock(2, &old), 1, eur(101), eur(100), eur(101)},
+		{"two thirds round up", stock(1, &old), 2, eur(101), eur(101), eur(101)},
+		{"cheaper receipt", stock(3, &old), 1, eur(1), eur(75), eur(1)},
+		{"currency change starts over", stock(10, &old), 10, usd(300), usd(300), usd(300)},
+		{"nothing on hand starts over", stock(0, &old), 10, eur(200), eur(200), eur(200)},
+		{"negative on hand starts over", stock(-5, &old), 10, eur(200), eur(200), eur(200)},
+		{"no int64 overflow", stock(1_000_000_000_000, &large), 1_000_000_000_000, eur(3_000_000_000_000), eur(2_000_000_000_000), eur(3_000_000_000_000)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := costPrice(CostWeightedAverage, tt.stock, tt.quantity, tt.unitCost); got != tt.average {
+				t.Errorf("weighted average = %s, want %s", got, tt.average)
+			}
+			if got := costPrice(CostLast, tt.stock, tt.quantity, tt.unitCost); got != tt.latest {
+				t.Errorf("last cost = %s, want %s", got, tt.latest)
+			}
+		})
+	}
+}
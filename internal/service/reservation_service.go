@@ -0,0 +1,179 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"product-test/internal/models"
+	"product-test/internal/repository"
+	"product-test/internal/reqctx"
+	"strconv"
+	"time"
+)
+
+const (
+	maxReservationLines = 500
+	// defaultReservationTTL is how long reservations hold stock unless
+	// configured otherwise.
+	defaultReservationTTL = 15 * time.Minute
+)
+
+type ReservationService interface {
+	GetReservation(ctx context.Context, id int64) (*models.Reservation, error)
+	// CreateReservation holds stock in a warehouse, the default one if
+	// none is given, until the reservation expires. It fails with
+	// ErrInsufficientStock when a product or variant has less available
+	// than asked for, unless it allows backorders.
+	CreateReservation(ctx context.Context, in models.ReservationInput) (*models.Reservation, error)
+	// ConfirmReservation sells the reserved stock with sale movements.
+	ConfirmReservation(ctx context.Context, id int64) (*models.Reservation, error)
+	// ReleaseReservation frees the reserved stock.
+	ReleaseReservation(ctx context.Context, id int64) (*models.Reservation, error)
+	// ExpireReservations marks the reservations past their expiry as
+	// expired and returns how many there were.
+	ExpireReservations(ctx context.Context) (int64, error)
+}
+
+type reservationService struct {
+	repo       repository.ReservationRepository
+	warehouses repository.WarehouseRepository
+	products   repository.ProductRepository
+	variants   repository.VariantRepository
+	inventory  InventoryService
+	tx         repository.TxManager
+	ttl        time.Duration
+}
+
+func NewReservationService(repo repository.ReservationRepository, warehouses repository.WarehouseRepository, products repository.ProductRepository, variants repository.VariantRepository, inventory InventoryService, tx repository.TxManager, ttl time.Duration) ReservationService {
+	if ttl <= 0 {
+		ttl = defaultReservationTTL
+	}
+	return &reservationService{repo: repo, warehouses: warehouses, products: products, variants: variants, inventory: inventory, tx: tx, ttl: ttl}
+}
+
+func (s *reservationService) GetReservation(ctx context.Context, id int64) (*models.Reservation, error) {
+	res, err := s.repo.GetByID(ctx, id)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, ErrReservationNotFound
+	}
+	return res, err
+}
+
+func validateReservation(in models.ReservationInput) error {
+	if len(in.Lines) == 0 || len(in.Lines) > maxReservationLines {
+		return fmt.Errorf("%w: a reservation has 1 to %d lines", ErrValidation, maxReservationLines)
+	}
+	if len(in.Reference) > maxReferenceLength {
+		return fmt.Errorf("%w: reference must be at most %d characters", ErrValidation, maxReferenceLength)
+	}
+	seen := make(map[models.StockKey]bool, len(in.Lines))
+	for _, l := range in.Lines {
+		if seen[l.Key()] {
+			return fmt.Errorf("%w: %s appears more than once", ErrValidation, l.Key())
+		}
+		seen[l.Key()] = true
+		if l.Quantity <= 0 || l.Quantity > maxMovementQuantity {
+			return fmt.Errorf("%w: quantity of %s must be a positive number of units up to %d", ErrValidation, l.Key(), maxMovementQuantity)
+		}
+	}
+	return nil
+}
+
+func (s *reservationService) CreateReservation(ctx context.Context, in models.ReservationInput) (*models.Reservation, error) {
+	if err := validateReservation(in); err != nil {
+		return nil, err
+	}
+	res := &models.Reservation{
+		Status:    models.ReservationActive,
+		Lines:     in.Lines,
+		Reference: in.Reference,
+		Actor:     reqctx.Actor(ctx),
+		ExpiresAt: time.Now().Add(s.ttl),
+	}
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		warehouseID, err := resolveWarehouse(ctx, s.warehouses, in.WarehouseID)
+		if err != nil {
+			return err
+		}
+		res.WarehouseID = warehouseID
+		keys := make([]models.StockKey, len(res.Lines))
+		for i, l := range res.Lines {
+			if err := checkStockItem(ctx, s.products, s.variants, l.Key()); err != nil {
+				return err
+			}
+			keys[i] = l.Key()
+		}
+		stocks, err := s.inventory.Lock(ctx, keys)
+		if err != nil {
+			return err
+		}
+		for _, l := range res.Lines {
+			stock := stocks[l.Key()]
+			available := stock.InWarehouse(res.WarehouseID).Available
+			if l.Quantity > available && !stock.AllowBackorder {
+				return fmt.Errorf("%w: %s has %d available in warehouse %d, %d requested",
+					ErrInsufficientStock, l.Key(), max(available, 0), res.WarehouseID, l.Quantity)
+			}
+		}
+		return s.repo.Create(ctx, res)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.GetReservation(ctx, res.ID)
+}
+
+// finish ends an active reservation; a confirmed one sells its stock.
+func (s *reservationService) finish(ctx context.Context, id int64, status models.ReservationStatus) (*models.Reservation, error) {
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		res, err := s.repo.Lock(ctx, id)
+		if errors.Is(err, repository.ErrNotFound) {
+			return ErrReservationNotFound
+		}
+		if err != nil {
+			return err
+		}
+		if res.Status != models.ReservationActive {
+			return fmt.Errorf("%w: reservation %d is %s", ErrReservationState, id, res.Status)
+		}
+		if !res.ExpiresAt.After(time.Now()) {
+			return fmt.Errorf("%w: reservation %d has expired", ErrReservationState, id)
+		}
+		if err := s.repo.Finish(ctx, res, status); err != nil {
+			return err
+		}
+		if status != models.ReservationConfirmed {
+			return nil
+		}
+		// The reservation no longer holds the stock, so the sale can
+		// take it.
+		movements := make([]models.StockMovement, len(res.Lines))
+		for i, l := range res.Lines {
+			movements[i] = models.StockMovement{
+				ProductID:   l.ProductID,
+				VariantID:   l.VariantID,
+				WarehouseID: res.WarehouseID,
+				Type:        models.MovementSale,
+				Quantity:    -l.Quantity,
+				Reference:   "reservation:" + strconv.FormatInt(res.ID, 10),
+			}
+		}
+		return s.inventory.Post(ctx, movements)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.GetReservation(ctx, id)
+}
+
+func (s *reservationService) ConfirmReservation(ctx context.Context, id int64) (*models.Reservation, error) {
+	return s.finish(ctx, id, models.ReservationConfirmed)
+}
+
+func (s *reservationService) ReleaseReservation(ctx context.Context, id int64) (*models.Reservation, error) {
+	return s.finish(ctx, id, models.ReservationReleased)
+}
+
+func (s *reservationService) ExpireReservations(ctx context.Context) (int64, error) {
+	return s.repo.Expire(ctx)
+}
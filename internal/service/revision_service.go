@@ -0,0 +1,83 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"product-test/internal/models"
+	"product-test/internal/repository"
+)
+
+type RevisionService interface {
+	ListRevisions(ctx context.Context, productID, limit, offset int) ([]models.ProductRevision, int, error)
+	DiffRevisions(ctx context.Context, productID, from, to int) (*models.RevisionDiff, error)
+	RevertToRevision(ctx context.Context, productID, revision int) (*models.Product, error)
+}
+
+type revisionService struct {
+	repo     repository.RevisionRepository
+	products ProductService
+}
+
+func NewRevisionService(repo repository.RevisionRepository, products ProductService) RevisionService {
+	return &revisionService{repo: repo, products: products}
+}
+
+// ListRevisions returns a page of revisions, newest first, and their total.
+func (s *revisionService) ListRevisions(ctx context.Context, productID, limit, offset int) ([]models.ProductRevision, int, error) {
+	total, err := s.repo.Count(ctx, productID)
+	if err != nil {
+		return nil, 0, err
+	}
+	if total == 0 {
+		return nil, 0, ErrNotFound
+	}
+	revs, err := s.repo.List(ctx, productID, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	return revs, total, nil
+}
+
+func (s *revisionService) DiffRevisions(ctx context.Context, productID, from, to int) (*models.RevisionDiff, error) {
+	a, err := s.get(ctx, productID, from)
+	if err != nil {
+		return nil, err
+	}
+	b, err := s.get(ctx, productID, to)
+	if err != nil {
+		return nil, err
+	}
+	return &models.RevisionDiff{
+		ProductID: productID,
+		From:      from,
+		To:        to,
+		Changes:   diffProducts(&a.Snapshot, &b.Snapshot),
+	}, nil
+}
+
+// RevertToRevision applies an old snapshot as a regular update, so the
+// revert is validated, audited and becomes a revision of its own.
+func (s *revisionService) RevertToRevision(ctx context.Context, productID, revision int) (*models.Product, error) {
+	rev, err := s.get(ctx, productID, revision)
+	if err != nil {
+		return nil, err
+	}
+	p := rev.Snapshot
+	p.ID = productID
+	p.DeletedAt = nil
+	if err := s.products.UpdateProduct(ctx, &p); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+func (s *revisionService) get(ctx context.Context, productID, revision int) (*models.ProductRevision, error) {
+	rev, err := s.repo.Get(ctx, productID, revision)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrRevisionNotFound
+		}
+		return nil, err
+	}
+	return rev, nil
+}
@@ -0,0 +1,162 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/mail"
+	"product-test/internal/models"
+	"product-test/internal/repository"
+	"strings"
+)
+
+const (
+	maxPhoneLength       = 50
+	maxSupplierSKULength = 64
+)
+
+type SupplierService interface {
+	ListSuppliers(ctx context.Context) ([]models.Supplier, error)
+	GetSupplier(ctx context.Context, id int) (*models.Supplier, error)
+	CreateSupplier(ctx context.Context, in models.SupplierInput) (*models.Supplier, error)
+	UpdateSupplier(ctx context.Context, id int, in models.SupplierInput) (*models.Supplier, error)
+	// DeleteSupplier removes a supplier that has no purchase orders.
+	DeleteSupplier(ctx context.Context, id int) error
+	ListSupplierProducts(ctx context.Context, supplierID int) ([]models.SupplierProduct, error)
+	// SetSupplierProduct adds a live product to a supplier or replaces the
+	// supplier's SKU and cost price of it.
+	SetSupplierProduct(ctx context.Context, supplierID, productID int, in models.SupplierProductInput) (*models.SupplierProduct, error)
+	DeleteSupplierProduct(ctx context.Context, supplierID, productID int) error
+}
+
+type supplierService struct {
+	repo     repository.SupplierRepository
+	products repository.ProductRepository
+	tx       repository.TxManager
+}
+
+func NewSupplierService(repo repository.SupplierRepository, products repository.ProductRepository, tx repository.TxManager) SupplierService {
+	return &supplierService{repo: repo, products: products, tx: tx}
+}
+
+func (s *supplierService) ListSuppliers(ctx context.Context) ([]models.Supplier, error) {
+	return s.repo.List(ctx)
+}
+
+func (s *supplierService) GetSupplier(ctx context.Context, id int) (*models.Supplier, error) {
+	sup, err := s.repo.GetByID(ctx, id)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, ErrSupplierNotFound
+	}
+	return sup, err
+}
+
+func validateSupplier(in models.SupplierInput) error {
+	if err := validateCode(in.Code); err != nil {
+		return err
+	}
+	if err := validateName(in.Name); err != nil {
+		return err
+	}
+	if in.Email != "" {
+		if addr, err := mail.ParseAddress(in.Email); err != nil || addr.Address != in.Email {
+			return fmt.Errorf("%w: email must be an address such as orders@example.com", ErrValidation)
+		}
+	}
+	if len(in.Phone) > maxPhoneLength {
+		return fmt.Errorf("%w: phone must be at most %d characters", ErrValidation, maxPhoneLength)
+	}
+	return nil
+}
+
+func (s *supplierService) CreateSupplier(ctx context.Context, in models.SupplierInput) (*models.Supplier, error) {
+	if err := validateSupplier(in); err != nil {
+		return nil, err
+	}
+	sup := &models.Supplier{Code: in.Code, Name: in.Name, Email: in.Email, Phone: in.Phone}
+	if err := s.repo.Create(ctx, sup); err != nil {
+		return nil, duplicateCode(err, sup.Code)
+	}
+	return sup, nil
+}
+
+func (s *supplierService) UpdateSupplier(ctx context.Context, id int, in models.SupplierInput) (*models.Supplier, error) {
+	if err := validateSupplier(in); err != nil {
+		return nil, err
+	}
+	sup := &models.Supplier{ID: id, Code: in.Code, Name: in.Name, Email: in.Email, Phone: in.Phone}
+	err := s.repo.Update(ctx, sup)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, ErrSupplierNotFound
+	}
+	if err != nil {
+		return nil, duplicateCode(err, sup.Code)
+	}
+	return sup, nil
+}
+
+func (s *supplierService) DeleteSupplier(ctx context.Context, id int) error {
+	err := s.repo.Delete(ctx, id)
+	switch {
+	case errors.Is(err, repository.ErrNotFound):
+		return ErrSupplierNotFound
+	case errors.Is(err, repository.ErrSupplierInUse):
+		return fmt.Errorf("%w: supplier %d has purchase orders", ErrConflict, id)
+	}
+	return err
+}
+
+func (s *supplierService) ListSupplierProducts(ctx context.Context, supplierID int) ([]models.SupplierProduct, error) {
+	if _, err := s.GetSupplier(ctx, supplierID); err != nil {
+		return nil, err
+	}
+	return s.repo.Products(ctx, supplierID)
+}
+
+func (s *supplierService) SetSupplierProduct(ctx context.Context, supplierID, productID int, in models.SupplierProductInput) (*models.SupplierProduct, error) {
+	sp := &models.SupplierProduct{
+		SupplierID:  supplierID,
+		ProductID:   productID,
+		SupplierSKU: strings.TrimSpace(in.SupplierSKU),
+		CostPrice:   in.CostPrice,
+	}
+	if len(sp.SupplierSKU) > maxSupplierSKULength {
+		return nil, fmt.Errorf("%w: supplier_sku must be at most %d characters", ErrValidation, maxSupplierSKULength)
+	}
+	if sp.CostPrice != nil {
+		if err := validatePrice(*sp.CostPrice); err != nil {
+			return nil, err
+		}
+	}
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if _, err := s.GetSupplier(ctx, supplierID); err != nil {
+			return err
+		}
+		_, err := s.products.GetByID(ctx, productID)
+		if errors.Is(err, repository.ErrNotFound) {
+			return ErrNotFound
+		}
+		if err != nil {
+			return err
+		}
+		return s.repo.SetProduct(ctx, sp)
+	})
+	if errors.Is(err, repository.ErrDuplicate) {
+		return nil, fmt.Errorf("%w: supplier_sku %q is already used for another product", ErrValidation, sp.SupplierSKU)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return s.repo.Product(ctx, supplierID, productID)
+}
+
+func (s *supplierService) DeleteSupplierProduct(ctx context.Context, supplierID, productID int) error {
+	if _, err := s.GetSupplier(ctx, supplierID); err != nil {
+		return err
+	}
+	err := s.repo.DeleteProduct(ctx, supplierID, productID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return ErrSupplierProductNotFound
+	}
+	return err
+}
@@ -0,0 +1,122 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"product-test/internal/models"
+	"product-test/internal/repository"
+	"slices"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	maxTagLength     = 64
+	maxProductTags   = 50
+	defaultTagsLimit = 100
+	maxTagsListLimit = 1000
+)
+
+type TagService interface {
+	// ListTags returns the most used tags with their usage counts.
+	ListTags(ctx context.Context, limit int) ([]models.Tag, error)
+	GetProductTags(ctx context.Context, productID int) ([]string, error)
+	AddProductTags(ctx context.Context, productID int, names []string) ([]string, error)
+	RemoveProductTags(ctx context.Context, productID int, names []string) error
+}
+
+type tagService struct {
+	repo     repository.TagRepository
+	products ProductService
+	tx       repository.TxManager
+}
+
+func NewTagService(repo repository.TagRepository, products ProductService, tx repository.TxManager) TagService {
+	return &tagService{repo: repo, products: products, tx: tx}
+}
+
+// normalizeTags normalizes tag names and drops duplicates.
+func normalizeTags(names []string) ([]string, error) {
+	var tags []string
+	for _, name := range names {
+		tag := models.NormalizeTag(name)
+		switch {
+		case tag == "":
+			return nil, fmt.Errorf("%w: tag names cannot be blank", ErrValidation)
+		case utf8.RuneCountInString(tag) > maxTagLength:
+			return nil, fmt.Errorf("%w: tag %q is longer than %d characters", ErrValidation, tag, maxTagLength)
+		case strings.Contains(tag, ","):
+			return nil, fmt.Errorf("%w: tag %q contains a comma", ErrValidation, tag)
+		}
+		if !slices.Contains(tags, tag) {
+			tags = append(tags, tag)
+		}
+	}
+	return tags, nil
+}
+
+func (s *tagService) ListTags(ctx context.Context, limit int) ([]models.Tag, error) {
+	if limit <= 0 {
+		limit = defaultTagsLimit
+	}
+	return s.repo.List(ctx, min(limit, maxTagsListLimit))
+}
+
+func (s *tagService) GetProductTags(ctx context.Context, productID int) ([]string, error) {
+	p, err := s.products.GetProductByID(ctx, productID)
+	if err != nil {
+		return nil, err
+	}
+	if p.Tags == nil {
+		return []string{}, nil
+	}
+	return p.Tags, nil
+}
+
+func (s *tagService) AddProductTags(ctx context.Context, productID int, names []string) ([]string, error) {
+	tags, err := normalizeTags(names)
+	if err != nil {
+		return nil, err
+	}
+	if len(tags) == 0 {
+		return nil, fmt.Errorf("%w: at least one tag is required", ErrValidation)
+	}
+	err = s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		p, err := s.products.GetProductByID(ctx, productID)
+		if err != nil {
+			return err
+		}
+		added := 0
+		for _, tag := range tags {
+			if !slices.Contains(p.Tags, tag) {
+				added++
+			}
+		}
+		if len(p.Tags)+added > maxProductTags {
+			return fmt.Errorf("%w: a product can have at most %d tags", ErrValidation, maxProductTags)
+		}
+		return s.repo.Add(ctx, productID, tags)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.GetProductTags(ctx, productID)
+}
+
+// RemoveProductTags takes tags off a product; tags it does not carry are
+// ignored.
+func (s *tagService) RemoveProductTags(ctx context.Context, productID int, names []string) error {
+	tags, err := normalizeTags(names)
+	if err != nil {
+		return err
+	}
+	if len(tags) == 0 {
+		return fmt.Errorf("%w: at least one tag is required", ErrValidation)
+	}
+	return s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if _, err := s.products.GetProductByID(ctx, productID); err != nil {
+			return err
+		}
+		return s.repo.Remove(ctx, productID, tags)
+	})
+}
@@ -0,0 +1,208 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"product-test/internal/models"
+	"product-test/internal/repository"
+	"product-test/internal/reqctx"
+	"slices"
+	"strconv"
+)
+
+const maxTransferLines = 500
+
+type TransferService interface {
+	ListTransfers(ctx context.Context, filter models.TransferFilter) ([]models.Transfer, int, error)
+	GetTransfer(ctx context.Context, id int64) (*models.Transfer, error)
+	// CreateTransfer ships stock from the source warehouse: the stock
+	// leaves the source and stays in transit until the transfer is
+	// received or cancelled.
+	CreateTransfer(ctx context.Context, in models.TransferInput) (*models.Transfer, error)
+	// ReceiveTransfer puts the stock in transit into the destination.
+	ReceiveTransfer(ctx context.Context, id int64) (*models.Transfer, error)
+	// CancelTransfer puts the stock in transit back into the source.
+	CancelTransfer(ctx context.Context, id int64) (*models.Transfer, error)
+}
+
+type transferService struct {
+	repo       repository.TransferRepository
+	warehouses repository.WarehouseRepository
+	products   repository.ProductRepository
+	variants   repository.VariantRepository
+	inventory  InventoryService
+	tx         repository.TxManager
+}
+
+func NewTransferService(repo repository.TransferRepository, warehouses repository.WarehouseRepository, products repository.ProductRepository, variants repository.VariantRepository, inventory InventoryService, tx repository.TxManager) TransferService {
+	return &transferService{repo: repo, warehouses: warehouses, products: products, variants: variants, inventory: inventory, tx: tx}
+}
+
+var transferStatuses = []models.TransferStatus{models.TransferInTransit, models.TransferReceived, models.TransferCancelled}
+
+func (s *transferService) ListTransfers(ctx context.Context, filter models.TransferFilter) ([]models.Transfer, int, error) {
+	if filter.Status != "" && !slices.Contains(transferStatuses, filter.Status) {
+		return nil, 0, fmt.Errorf("%w: unknown transfer status %q", ErrValidation, filter.Status)
+	}
+	transfers, err := s.repo.List(ctx, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+	total, err := s.repo.Count(ctx, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+	return transfers, total, nil
+}
+
+func (s *transferService) GetTransfer(ctx context.Context, id int64) (*models.Transfer, error) {
+	t, err := s.repo.GetByID(ctx, id)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, ErrTransferNotFound
+	}
+	return t, err
+}
+
+func validateTransfer(in models.TransferInput) error {
+	if in.SourceID == in.DestinationID {
+		return fmt.Errorf("%w: source and destination warehouses must differ", ErrValidation)
+	}
+	if len(in.Lines) == 0 || len(in.Lines) > maxTransferLines {
+		return fmt.Errorf("%w: a transfer has 1 to %d lines", ErrValidation, maxTransferLines)
+	}
+	if len(in.Note) > maxNoteLength {
+		return fmt.Errorf("%w: note must be at most %d characters", ErrValidation, maxNoteLength)
+	}
+	seen := make(map[models.StockKey]bool, len(in.Lines))
+	for _, l := range in.Lines {
+		if seen[l.Key()] {
+			return fmt.Errorf("%w: %s appears more than once", ErrValidation, l.Key())
+		}
+		seen[l.Key()] = true
+		if l.Quantity <= 0 || l.Quantity > maxMovementQuantity {
+			return fmt.Errorf("%w: quantity of %s must be a positive number of units up to %d", ErrValidation, l.Key(), maxMovementQuantity)
+		}
+	}
+	return nil
+}
+
+// checkWarehouse makes sure a warehouse named in a transfer exists.
+func (s *transferService) checkWarehouse(ctx context.Context, id int) error {
+	_, err := s.warehouses.GetByID(ctx, id)
+	if errors.Is(err, repository.ErrNotFound) {
+		return fmt.Errorf("%w: warehouse %d does not exist", ErrValidation, id)
+	}
+	return err
+}
+
+func (s *transferService) CreateTransfer(ctx context.Context, in models.TransferInput) (*models.Transfer, error) {
+	if err := validateTransfer(in); err != nil {
+		return nil, err
+	}
+	t := &models.Transfer{
+		SourceID:      in.SourceID,
+		DestinationID: in.DestinationID,
+		Status:        models.TransferInTransit,
+		Lines:         in.Lines,
+		Note:          in.Note,
+		Actor:         reqctx.Actor(ctx),
+	}
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		for _, id := range []int{t.SourceID, t.DestinationID} {
+			if err := s.checkWarehouse(ctx, id); err != nil {
+				return err
+			}
+		}
+		for _, l := range t.Lines {
+			if err := checkStockItem(ctx, s.products, s.variants, l.Key()); err != nil {
+				return err
+			}
+		}
+		if err := s.repo.Create(ctx, t); err != nil {
+			return err
+		}
+		return s.post(ctx, t, models.MovementTransferOut, t.SourceID, nil)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.GetTransfer(ctx, t.ID)
+}
+
+func transferReference(id int64) string {
+	return "transfer:" + strconv.FormatInt(id, 10)
+}
+
+// post moves the stock of a transfer out of or into a warehouse, into the
+// given lots of each item.
+func (s *transferService) post(ctx context.Context, t *models.Transfer, typ models.MovementType, warehouseID int, lots map[models.StockKey][]models.LotQuantity) error {
+	movements := make([]models.StockMovement, len(t.Lines))
+	for i, l := range t.Lines {
+		q := l.Quantity
+		if typ == models.MovementTransferOut {
+			q = -q
+		}
+		movements[i] = models.StockMovement{
+			ProductID:   l.ProductID,
+			VariantID:   l.VariantID,
+			WarehouseID: warehouseID,
+			Type:        typ,
+			Quantity:    q,
+			Lots:        lots[l.Key()],
+			Reference:   transferReference(t.ID),
+		}
+	}
+	return s.inventory.Post(ctx, movements)
+}
+
+// finish ends an in-transit transfer and puts its stock into a warehouse.
+func (s *transferService) finish(ctx context.Context, id int64, status models.TransferStatus) (*models.Transfer, error) {
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		t, err := s.repo.Lock(ctx, id)
+		if errors.Is(err, repository.ErrNotFound) {
+			return ErrTransferNotFound
+		}
+		if err != nil {
+			return err
+		}
+		if t.Status != models.TransferInTransit {
+			return fmt.Errorf("%w: transfer %d is %s", ErrTransferState, id, t.Status)
+		}
+		if err := s.repo.Finish(ctx, t, status); err != nil {
+			return err
+		}
+		into := t.DestinationID
+		if status == models.TransferCancelled {
+			into = t.SourceID
+		}
+		// The stock arrives in the lots it left in.
+		out, err := s.inventory.MovementsByReference(ctx, transferReference(id))
+		if err != nil {
+			return err
+		}
+		lots := make(map[models.StockKey][]models.LotQuantity)
+		for _, m := range out {
+			if m.Type != models.MovementTransferOut {
+				continue
+			}
+			for _, l := range m.Lots {
+				l.Quantity = -l.Quantity
+				lots[m.Key()] = append(lots[m.Key()], l)
+			}
+		}
+		return s.post(ctx, t, models.MovementTransferIn, into, lots)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.GetTransfer(ctx, id)
+}
+
+func (s *transferService) ReceiveTransfer(ctx context.Context, id int64) (*models.Transfer, error) {
+	return s.finish(ctx, id, models.TransferReceived)
+}
+
+func (s *transferService) CancelTransfer(ctx context.Context, id int64) (*models.Transfer, error) {
+	return s.finish(ctx, id, models.TransferCancelled)
+}
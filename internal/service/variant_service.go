@@ -0,0 +1,466 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"product-test/internal/attrschema"
+	"product-test/internal/models"
+	"product-test/internal/repository"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	maxOptionAxes      = 5
+	maxOptionValues    = 100
+	maxOptionValueLen  = 64
+	maxProductVariants = 1000
+)
+
+var skuPattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]{0,63}$`)
+
+type VariantService interface {
+	GetOptions(ctx context.Context, productID int) ([]models.ProductOption, error)
+	// SetOptions replaces the option axes of a product. Existing variants
+	// must still be valid combinations of the new options.
+	SetOptions(ctx context.Context, productID int, options []models.ProductOption) ([]models.ProductOption, error)
+	ListVariants(ctx context.Context, productID int) ([]models.Variant, error)
+	CreateVariant(ctx context.Context, productID int, in models.VariantInput) (*models.Variant, error)
+	UpdateVariant(ctx context.Context, productID, id int, in models.VariantInput) (*models.Variant, error)
+	DeleteVariant(ctx context.Context, productID, id int) error
+	// GenerateVariants creates a variant for every combination of options
+	// that has none yet and returns the new variants.
+	GenerateVariants(ctx context.Context, productID int, in models.VariantMatrix) ([]models.Variant, error)
+}
+
+type variantService struct {
+	repo       repository.VariantRepository
+	categories repository.CategoryRepository
+	products   ProductService
+	tx         repository.TxManager
+}
+
+func NewVariantService(repo repository.VariantRepository, categories repository.CategoryRepository, products ProductService, tx repository.TxManager) VariantService {
+	return &variantService{repo: repo, categories: categories, products: products, tx: tx}
+}
+
+func (s *variantService) GetOptions(ctx context.Context, productID int) ([]models.ProductOption, error) {
+	p, err := s.products.GetProductByID(ctx, productID)
+	if err != nil {
+		return nil, err
+	}
+	if p.Options == nil {
+		return []models.ProductOption{}, nil
+	}
+	return p.Options, nil
+}
+
+func (s *variantService) SetOptions(ctx context.Context, productID int, options []models.ProductOption) ([]models.ProductOption, error) {
+	options, err := normalizeOptions(options)
+	if err != nil {
+		return nil, err
+	}
+	err = s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		p, err := s.lock(ctx, productID)
+		if err != nil {
+			return err
+		}
+		for _, v := range p.Variants {
+			if err := checkVariantOptions(v.Options, options); err != nil {
+				return fmt.Errorf("%w (variant %s); change or delete it first", err, v.SKU)
+			}
+		}
+		return s.repo.ReplaceOptions(ctx, productID, options)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.GetOptions(ctx, productID)
+}
+
+// normalizeOptions trims option values and checks the axes.
+func normalizeOptions(options []models.ProductOption) ([]models.ProductOption, error) {
+	if len(options) > maxOptionAxes {
+		return nil, fmt.Errorf("%w: a product can have at most %d options", ErrValidation, maxOptionAxes)
+	}
+	out := make([]models.ProductOption, len(options))
+	for i, o := range options {
+		if !attrschema.ValidName(o.Name) {
+			return nil, fmt.Errorf("%w: option %q: names must be lowercase letters, digits or '_', starting with a letter", ErrValidation, o.Name)
+		}
+		if slices.ContainsFunc(out[:i], func(prev models.ProductOption) bool { return prev.Name == o.Name }) {
+			return nil, fmt.Errorf("%w: option %q is given twice", ErrValidation, o.Name)
+		}
+		if len(o.Values) == 0 || len(o.Values) > maxOptionValues {
+			return nil, fmt.Errorf("%w: option %q needs between 1 and %d values", ErrValidation, o.Name, maxOptionValues)
+		}
+		values := make([]string, 0, len(o.Values))
+		for _, v := range o.Values {
+			v = strings.TrimSpace(v)
+			switch {
+			case v == "":
+				return nil, fmt.Errorf("%w: option %q has a blank value", ErrValidation, o.Name)
+			case utf8.RuneCountInString(v) > maxOptionValueLen:
+				return nil, fmt.Errorf("%w: option %q: values must be at most %d characters", ErrValidation, o.Name, maxOptionValueLen)
+			case slices.Contains(values, v):
+				return nil, fmt.Errorf("%w: option %q: value %q is given twice", ErrValidation, o.Name, v)
+			}
+			values = append(values, v)
+		}
+		out[i] = models.ProductOption{Name: o.Name, Values: values}
+	}
+	return out, nil
+}
+
+// checkVariantOptions makes sure a variant picks exactly one of the
+// offered values for every option of its product.
+func checkVariantOptions(values models.OptionValues, options []models.ProductOption) error {
+	for _, o := range options {
+		v, ok := values[o.Name]
+		if !ok {
+			return fmt.Errorf("%w: option %q is not set", ErrValidation, o.Name)
+		}
+		if !slices.Contains(o.Values, v) {
+			return fmt.Errorf("%w: option %q has no value %q", ErrValidation, o.Name, v)
+		}
+	}
+	for name := range values {
+		if !slices.ContainsFunc(options, func(o models.ProductOption) bool { return o.Name == name }) {
+			return fmt.Errorf("%w: the product has no option %q", ErrValidation, name)
+		}
+	}
+	return nil
+}
+
+// optionKey identifies a combination of option values.
+func optionKey(values models.OptionValues, options []models.ProductOption) string {
+	parts := make([]string, len(options))
+	for i, o := range options {
+		parts[i] = values[o.Name]
+	}
+	return strings.Join(parts, "\x00")
+}
+
+func (s *variantService) ListVariants(ctx context.Context, productID int) ([]models.Variant, error) {
+	p, err := s.products.GetProductByID(ctx, productID)
+	if err != nil {
+		return nil, err
+	}
+	if p.Variants == nil {
+		return []models.Variant{}, nil
+	}
+	return p.Variants, nil
+}
+
+func (s *variantService) CreateVariant(ctx context.Context, productID int, in models.VariantInput) (*models.Variant, error) {
+	var v *models.Variant
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		p, err := s.lock(ctx, productID)
+		if err != nil {
+			return err
+		}
+		if v, err = s.validateVariant(ctx, p, 0, in); err != nil {
+			return err
+		}
+		v.ProductID = productID
+		if err := s.claimSKU(ctx, v.SKU); err != nil {
+			return err
+		}
+		return skuError(s.repo.Create(ctx, v), v.SKU)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.read(ctx, productID, v.ID)
+}
+
+func (s *variantService) UpdateVariant(ctx context.Context, productID, id int, in models.VariantInput) (*models.Variant, error) {
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		p, err := s.lock(ctx, productID)
+		if err != nil {
+			return err
+		}
+		i := slices.IndexFunc(p.Variants, func(v models.Variant) bool { return v.ID == id })
+		if i < 0 {
+			return ErrVariantNotFound
+		}
+		v, err := s.validateVariant(ctx, p, id, in)
+		if err != nil {
+			return err
+		}
+		v.ID, v.ProductID = id, productID
+		if v.SKU != p.Variants[i].SKU {
+			if err := s.claimSKU(ctx, v.SKU); err != nil {
+				return err
+			}
+		}
+		err = s.repo.Update(ctx, v)
+		if errors.Is(err, repository.ErrNotFound) {
+			return ErrVariantNotFound
+		}
+		return skuError(err, v.SKU)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.read(ctx, productID, id)
+}
+
+func (s *variantService) DeleteVariant(ctx context.Context, productID, id int) error {
+	return s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if _, err := s.lock(ctx, productID); err != nil {
+			return err
+		}
+		err := s.repo.Delete(ctx, productID, id)
+		if errors.Is(err, repository.ErrNotFound) {
+			return ErrVariantNotFound
+		}
+		return err
+	})
+}
+
+func (s *variantService) GenerateVariants(ctx context.Context, productID int, in models.VariantMatrix) ([]models.Variant, error) {
+	prefix := strings.TrimSpace(in.SKUPrefix)
+	if prefix == "" {
+		prefix = "P" + strconv.Itoa(productID)
+	}
+	if !skuPattern.MatchString(prefix) {
+		return nil, fmt.Errorf("%w: sku_prefix may only contain letters, digits, '.', '_' and '-'", ErrValidation)
+	}
+	var ids []int
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		p, err := s.lock(ctx, productID)
+		if err != nil {
+			return err
+		}
+		if len(p.Options) == 0 {
+			return fmt.Errorf("%w: the product has no options to combine", ErrValidation)
+		}
+		combinations := 1
+		for _, o := range p.Options {
+			combinations *= len(o.Values)
+		}
+		if combinations > maxProductVariants {
+			return fmt.Errorf("%w: the options make %d combinations; a product can have at most %d variants", ErrValidation, combinations, maxProductVariants)
+		}
+		existing := make(map[string]bool, len(p.Variants))
+		for _, v := range p.Variants {
+			existing[optionKey(v.Options, p.Options)] = true
+		}
+		for _, combo := range combine(p.Options) {
+			if existing[optionKey(combo, p.Options)] {
+				continue
+			}
+			v := &models.Variant{ProductID: productID, SKU: generatedSKU(prefix, combo, p.Options), Options: combo}
+			if !skuPattern.MatchString(v.SKU) {
+				return fmt.Errorf("%w: generated sku %q is too long; use a shorter sku_prefix", ErrValidation, v.SKU)
+			}
+			if err := s.claimSKU(ctx, v.SKU); err != nil {
+				return err
+			}
+			if err := skuError(s.repo.Create(ctx, v), v.SKU); err != nil {
+				return err
+			}
+			ids = append(ids, v.ID)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	p, err := s.products.GetProductByID(ctx, productID)
+	if err != nil {
+		return nil, err
+	}
+	created := []models.Variant{}
+	for _, v := range p.Variants {
+		if slices.Contains(ids, v.ID) {
+			created = append(created, v)
+		}
+	}
+	return created, nil
+}
+
+// combine returns every combination of option values, varying the last
+// option fastest.
+func combine(options []models.ProductOption) []models.OptionValues {
+	combos := []models.OptionValues{{}}
+	for _, o := range options {
+		next := make([]models.OptionValues, 0, len(combos)*len(o.Values))
+		for _, c := range combos {
+			for _, v := range o.Values {
+				combo := make(models.OptionValues, len(c)+1)
+				for k, cv := range c {
+					combo[k] = cv
+				}
+				combo[o.Name] = v
+				next = append(next, combo)
+			}
+		}
+		combos = next
+	}
+	return combos
+}
+
+// generatedSKU builds a SKU such as TSHIRT-M-RED from a prefix and the
+// option values. Values without ASCII letters or digits are replaced by
+// their position in the option.
+func generatedSKU(prefix string, values models.OptionValues, options []models.ProductOption) string {
+	parts := []string{prefix}
+	for _, o := range options {
+		part := skuPart(values[o.Name])
+		if part == "" {
+			part = strconv.Itoa(slices.Index(o.Values, values[o.Name]) + 1)
+		}
+		parts = append(parts, part)
+	}
+	return strings.Join(parts, "-")
+}
+
+func skuPart(value string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToUpper(value) {
+		if r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' {
+			if dash && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			b.WriteRune(r)
+			dash = false
+			continue
+		}
+		dash = true
+	}
+	return b.String()
+}
+
+// validateVariant checks a variant of product p; id is the variant being
+// updated, or 0.
+func (s *variantService) validateVariant(ctx context.Context, p *models.Product, id int, in models.VariantInput) (*models.Variant, error) {
+	sku := strings.TrimSpace(in.SKU)
+	if !skuPattern.MatchString(sku) {
+		return nil, fmt.Errorf("%w: sku is required and may only contain letters, digits, '.', '_' and '-', up to 64 characters", ErrValidation)
+	}
+	if len(p.Options) == 0 {
+		return nil, fmt.Errorf("%w: the product has no options; set them first", ErrValidation)
+	}
+	if err := checkVariantOptions(in.Options, p.Options); err != nil {
+		return nil, err
+	}
+	key := optionKey(in.Options, p.Options)
+	for _, v := range p.Variants {
+		if v.ID != id && optionKey(v.Options, p.Options) == key {
+			return nil, fmt.Errorf("%w: variant %s already has these options", ErrValidation, v.SKU)
+		}
+	}
+	if in.Price != nil {
+		if err := validatePrice(*in.Price); err != nil {
+			return nil, err
+		}
+		if in.Price.Currency != p.Price.Currency {
+			return nil, fmt.Errorf("%w: price must be in %s, the currency of the product", ErrValidation, p.Price.Currency)
+		}
+	}
+	if err := s.validateAttributes(ctx, p, in.Attributes); err != nil {
+		return nil, err
+	}
+	return &models.Variant{SKU: sku, Options: in.Options, Price: in.Price, Attributes: in.Attributes}, nil
+}
+
+// validateAttributes checks the attributes of a variant, which override
+// those of its product, against the schema of the product's category.
+// Only the attributes the variant sets are held to the schema.
+func (s *variantService) validateAttributes(ctx context.Context, p *models.Product, attrs models.Attributes) error {
+	if errs := attrschema.CheckShape(attrs); len(errs) > 0 {
+		return &ValidationError{Message: "invalid attributes", Fields: errs}
+	}
+	if len(attrs) == 0 {
+		return nil
+	}
+	raw, err := s.categories.ProductSchema(ctx, p.ID)
+	if err != nil {
+		return err
+	}
+	schema, err := parseSchema(raw)
+	if err != nil || schema == nil {
+		return err
+	}
+	merged := make(models.Attributes, len(p.Attributes)+len(attrs))
+	for k, v := range p.Attributes {
+		merged[k] = v
+	}
+	for k, v := range attrs {
+		merged[k] = v
+	}
+	var errs []models.FieldError
+	for _, e := range schema.Validate(merged) {
+		name, _, _ := strings.Cut(strings.TrimPrefix(e.Field, "attributes."), "[")
+		if _, ok := attrs[name]; ok {
+			errs = append(errs, e)
+		}
+	}
+	if len(errs) > 0 {
+		return &ValidationError{Message: "attributes do not match the category schema", Fields: errs}
+	}
+	return nil
+}
+
+// lock locks a live product and returns it with its options and variants.
+func (s *variantService) lock(ctx context.Context, productID int) (*models.Product, error) {
+	if err := s.repo.LockProduct(ctx, productID); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return s.products.GetProductByID(ctx, productID)
+}
+
+// read returns a variant with its effective price.
+func (s *variantService) read(ctx context.Context, productID, id int) (*models.Variant, error) {
+	p, err := s.products.GetProductByID(ctx, productID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range p.Variants {
+		if p.Variants[i].ID == id {
+			return &p.Variants[i], nil
+		}
+	}
+	return nil, ErrVariantNotFound
+}
+
+// claimSKU makes sure no product has the SKU of a variant; products and
+// variants share one SKU namespace.
+func (s *variantService) claimSKU(ctx context.Context, sku string) error {
+	return skuError(s.repo.ClaimSKU(ctx, sku), sku)
+}
+
+// skuError turns a uniqueness violation into ErrConflict; duplicate option
+// combinations are caught before writing, so it can only be the SKU.
+func skuError(err error, sku string) error {
+	if errors.Is(err, repository.ErrDuplicate) {
+		return fmt.Errorf("%w: sku %q is already in use", ErrConflict, sku)
+	}
+	return err
+}
+
+// setVariantPrices sets what each variant of a product sells for: its own
+// price, or else the effective price of the product.
+func setVariantPrices(p *models.Product) {
+	for i := range p.Variants {
+		v := &p.Variants[i]
+		switch {
+		case v.Price != nil:
+			price := *v.Price
+			v.EffectivePrice = &price
+		case p.EffectivePrice != nil:
+			price := *p.EffectivePrice
+			v.EffectivePrice = &price
+		}
+	}
+}
@@ -0,0 +1,100 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"product-test/internal/models"
+	"product-test/internal/repository"
+)
+
+type WarehouseService interface {
+	ListWarehouses(ctx context.Context) ([]models.Warehouse, error)
+	GetWarehouse(ctx context.Context, id int) (*models.Warehouse, error)
+	CreateWarehouse(ctx context.Context, in models.WarehouseInput) (*models.Warehouse, error)
+	UpdateWarehouse(ctx context.Context, id int, in models.WarehouseInput) (*models.Warehouse, error)
+	// DeleteWarehouse removes a warehouse that never held stock; the
+	// default warehouse cannot be deleted.
+	DeleteWarehouse(ctx context.Context, id int) error
+}
+
+type warehouseService struct {
+	repo repository.WarehouseRepository
+	tx   repository.TxManager
+}
+
+func NewWarehouseService(repo repository.WarehouseRepository, tx repository.TxManager) WarehouseService {
+	return &warehouseService{repo: repo, tx: tx}
+}
+
+func (s *warehouseService) ListWarehouses(ctx context.Context) ([]models.Warehouse, error) {
+	return s.repo.List(ctx)
+}
+
+func (s *warehouseService) GetWarehouse(ctx context.Context, id int) (*models.Warehouse, error) {
+	w, err := s.repo.GetByID(ctx, id)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, ErrWarehouseNotFound
+	}
+	return w, err
+}
+
+func validateWarehouse(in models.WarehouseInput) error {
+	if err := validateCode(in.Code); err != nil {
+		return err
+	}
+	return validateName(in.Name)
+}
+
+func (s *warehouseService) CreateWarehouse(ctx context.Context, in models.WarehouseInput) (*models.Warehouse, error) {
+	if err := validateWarehouse(in); err != nil {
+		return nil, err
+	}
+	w := &models.Warehouse{Code: in.Code, Name: in.Name, IsDefault: in.IsDefault}
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		return s.repo.Create(ctx, w)
+	})
+	if err != nil {
+		return nil, duplicateCode(err, w.Code)
+	}
+	return w, nil
+}
+
+func (s *warehouseService) UpdateWarehouse(ctx context.Context, id int, in models.WarehouseInput) (*models.Warehouse, error) {
+	if err := validateWarehouse(in); err != nil {
+		return nil, err
+	}
+	var w *models.Warehouse
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+		if w, err = s.GetWarehouse(ctx, id); err != nil {
+			return err
+		}
+		if w.IsDefault && !in.IsDefault {
+			return fmt.Errorf("%w: there must be a default warehouse; make another warehouse the default instead", ErrValidation)
+		}
+		w.Code, w.Name, w.IsDefault = in.Code, in.Name, in.IsDefault
+		return s.repo.Update(ctx, w)
+	})
+	if err != nil {
+		return nil, duplicateCode(err, in.Code)
+	}
+	return w, nil
+}
+
+func (s *warehouseService) DeleteWarehouse(ctx context.Context, id int) error {
+	return s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		w, err := s.GetWarehouse(ctx, id)
+		if err != nil {
+			return err
+		}
+		if w.IsDefault {
+			return fmt.Errorf("%w: the default warehouse cannot be deleted", ErrConflict)
+		}
+		err = s.repo.Delete(ctx, id)
+		if errors.Is(err, repository.ErrWarehouseInUse) {
+			return fmt.Errorf("%w: warehouse %q has stock movements, transfers or reservations", ErrConflict, w.Code)
+		}
+		return err
+	})
+}
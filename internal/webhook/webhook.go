@@ -0,0 +1,51 @@
+// Package webhook delivers alerts to an HTTP endpoint.
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"product-test/internal/models"
+	"time"
+)
+
+const timeout = 10 * time.Second
+
+// Notifier posts alerts to a URL as a JSON object {"alerts": [...]}.
+type Notifier struct {
+	url    string
+	client *http.Client
+}
+
+func New(url string) *Notifier {
+	return &Notifier{url: url, client: &http.Client{Timeout: timeout}}
+}
+
+// Notify delivers alerts in one request; any status outside 2xx is an
+// error, and the alerts are delivered again next time.
+func (n *Notifier) Notify(ctx context.Context, alerts []models.Alert) error {
+	body, err := json.Marshal(struct {
+		Alerts []models.Alert `json:"alerts"`
+	}{alerts})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("webhook responded %s", resp.Status)
+	}
+	return nil
+}